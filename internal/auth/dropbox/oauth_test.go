@@ -0,0 +1,122 @@
+package dropbox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTokenServer fakes the Dropbox token endpoint
+func newTokenServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "app-key", r.PostForm.Get("client_id"))
+
+		response := map[string]interface{}{
+			"token_type": "bearer",
+			"expires_in": 14400,
+		}
+		switch r.PostForm.Get("grant_type") {
+		case "authorization_code":
+			if r.PostForm.Get("code") != "good-code" || r.PostForm.Get("code_verifier") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+				return
+			}
+			response["access_token"] = "access-1"
+			response["refresh_token"] = "refresh-1"
+			response["account_id"] = "dbid:alice"
+		case "refresh_token":
+			assert.Equal(t, "refresh-1", r.PostForm.Get("refresh_token"))
+			response["access_token"] = "access-2"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestAuth(t *testing.T, server *httptest.Server) *PulsePointDropboxAuth {
+	auth, err := NewPulsePointDropboxAuth(&Config{
+		AppKey:    "app-key",
+		TokenFile: filepath.Join(t.TempDir(), "tokens", "dropbox_token.json"),
+		TokenURL:  server.URL,
+	})
+	require.NoError(t, err)
+	return auth
+}
+
+func TestDropboxAuthCodeFlow(t *testing.T) {
+	ctx := context.Background()
+	auth := newTestAuth(t, newTokenServer(t))
+	assert.True(t, auth.RequiresInteraction())
+
+	authURL, err := auth.GetAuthURL("state-1")
+	require.NoError(t, err)
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	query := parsed.Query()
+	assert.Equal(t, "offline", query.Get("token_access_type"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.NotEmpty(t, query.Get("code_challenge"))
+	assert.Equal(t, "state-1", query.Get("state"))
+
+	_, err = auth.HandleCallback(ctx, "good-code", "forged")
+	assert.Error(t, err)
+
+	// A rejected callback clears the pending authorization
+	_, err = auth.HandleCallback(ctx, "good-code", "state-1")
+	assert.Error(t, err)
+
+	_, err = auth.GetAuthURL("state-2")
+	require.NoError(t, err)
+	token, err := auth.HandleCallback(ctx, "good-code", "state-2")
+	require.NoError(t, err)
+	assert.Equal(t, "access-1", token.AccessToken)
+	assert.Equal(t, "dbid:alice", token.UserID)
+	assert.True(t, token.IsValid())
+
+	info, err := os.Stat(auth.tokenFile)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	assert.False(t, auth.RequiresInteraction())
+
+	stored, err := auth.LoadToken()
+	require.NoError(t, err)
+	assert.Equal(t, "refresh-1", stored.RefreshToken)
+}
+
+func TestDropboxAuthRefreshKeepsRefreshToken(t *testing.T) {
+	auth := newTestAuth(t, newTokenServer(t))
+	expired := &interfaces.AuthToken{
+		AccessToken:  "access-1",
+		RefreshToken: "refresh-1",
+		ExpiresAt:    time.Now().Add(-time.Hour),
+		UserID:       "dbid:alice",
+	}
+	require.NoError(t, auth.StoreToken(expired))
+
+	// An expired token with a refresh token needs no user interaction
+	assert.False(t, auth.RequiresInteraction())
+
+	token, err := auth.Authenticate(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "access-2", token.AccessToken)
+	assert.Equal(t, "refresh-1", token.RefreshToken)
+	assert.Equal(t, "dbid:alice", token.UserID)
+
+	stored, err := auth.LoadToken()
+	require.NoError(t, err)
+	assert.Equal(t, "access-2", stored.AccessToken)
+}
@@ -0,0 +1,388 @@
+// Package onedrive implements Microsoft identity platform OAuth2 authentication
+// for OneDrive as an interfaces.AuthProvider
+package onedrive
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"github.com/pulsepoint/pulsepoint/pkg/errors"
+	"github.com/pulsepoint/pulsepoint/pkg/logger"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// Provider name
+	providerName = "onedrive"
+
+	// Default Microsoft identity platform endpoint and tenant
+	defaultLoginURL = "https://login.microsoftonline.com"
+	defaultTenant   = "common"
+)
+
+// DefaultScopes are the Graph permissions PulsePoint asks for. offline_access
+// yields a refresh token.
+var DefaultScopes = []string{"Files.ReadWrite", "User.Read", "offline_access"}
+
+// Config holds Microsoft identity platform OAuth2 configuration
+type Config struct {
+	ClientID     string
+	ClientSecret string // Optional; public clients authenticate with PKCE alone
+	Tenant       string // "common", "organizations", "consumers" or a tenant ID
+	RedirectURI  string
+	TokenFile    string
+	Scopes       []string
+	LoginURL     string // Overrides the identity platform endpoint, mainly for tests
+	HTTPClient   *http.Client
+}
+
+// PulsePointOneDriveAuth handles Microsoft identity platform OAuth2 authentication
+type PulsePointOneDriveAuth struct {
+	config    *oauth2.Config
+	tokenFile string
+	client    *http.Client
+	logger    *zap.Logger
+
+	// The pending authorization, between GetAuthURL and HandleCallback
+	mu       sync.Mutex
+	state    string
+	verifier string
+}
+
+// NewPulsePointOneDriveAuth creates a new OneDrive authentication handler
+func NewPulsePointOneDriveAuth(cfg *Config) (*PulsePointOneDriveAuth, error) {
+	if cfg == nil || cfg.ClientID == "" {
+		return nil, errors.NewAuthError("missing Microsoft application (client) ID", nil)
+	}
+
+	redirectURI := cfg.RedirectURI
+	if redirectURI == "" {
+		redirectURI = "http://localhost:8080/callback"
+	}
+
+	loginURL := strings.TrimSuffix(cfg.LoginURL, "/")
+	if loginURL == "" {
+		loginURL = defaultLoginURL
+	}
+	tenant := cfg.Tenant
+	if tenant == "" {
+		tenant = defaultTenant
+	}
+
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = DefaultScopes
+	}
+
+	tokenFile := cfg.TokenFile
+	if tokenFile == "" {
+		tokenFile = GetDefaultTokenPath()
+	}
+
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return &PulsePointOneDriveAuth{
+		config: &oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  redirectURI,
+			Scopes:       scopes,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:   fmt.Sprintf("%s/%s/oauth2/v2.0/authorize", loginURL, tenant),
+				TokenURL:  fmt.Sprintf("%s/%s/oauth2/v2.0/token", loginURL, tenant),
+				AuthStyle: oauth2.AuthStyleInParams,
+			},
+		},
+		tokenFile: tokenFile,
+		client:    client,
+		logger:    logger.Get(),
+	}, nil
+}
+
+// Authenticate returns a valid token, refreshing the stored one or running
+// the interactive flow when needed
+func (a *PulsePointOneDriveAuth) Authenticate(ctx context.Context) (*interfaces.AuthToken, error) {
+	token, err := a.LoadToken()
+	if err == nil && token.IsValid() {
+		a.logger.Info("Using existing valid token")
+		return token, nil
+	}
+
+	if token != nil && token.RefreshToken != "" {
+		a.logger.Info("Refreshing expired token")
+		refreshed, err := a.RefreshToken(ctx, token)
+		if err == nil {
+			if err := a.StoreToken(refreshed); err != nil {
+				a.logger.Warn("Failed to save refreshed token", zap.Error(err))
+			}
+			return refreshed, nil
+		}
+		a.logger.Warn("Failed to refresh token, starting new auth flow", zap.Error(err))
+	}
+
+	a.logger.Info("Starting new OAuth2 authentication flow")
+	token, err = a.performOAuth2Flow(ctx)
+	if err != nil {
+		return nil, errors.NewAuthError("OAuth2 flow failed", err)
+	}
+	return token, nil
+}
+
+// performOAuth2Flow sends the user to Microsoft and waits for the local callback
+func (a *PulsePointOneDriveAuth) performOAuth2Flow(ctx context.Context) (*interfaces.AuthToken, error) {
+	state := generateStateToken()
+	authURL, err := a.GetAuthURL(state)
+	if err != nil {
+		return nil, err
+	}
+
+	redirect, err := url.Parse(a.config.RedirectURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redirect URI: %w", err)
+	}
+
+	// The redirect URI must match the app registration, so the port cannot change
+	listener, err := net.Listen("tcp", redirect.Host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start callback server: %w", err)
+	}
+
+	codeChan := make(chan string, 1)
+	errChan := make(chan error, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc(redirect.Path, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if errCode := query.Get("error"); errCode != "" {
+			http.Error(w, fmt.Sprintf("Authorization failed: %s", errCode), http.StatusBadRequest)
+			errChan <- fmt.Errorf("authorization failed: %s", errCode)
+			return
+		}
+		if query.Get("state") != state {
+			http.Error(w, "Invalid state parameter", http.StatusBadRequest)
+			errChan <- fmt.Errorf("invalid state parameter")
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head><title>PulsePoint Authorization</title></head>
+<body><h1>✓ Authorization Successful!</h1><p>You can now close this window and return to PulsePoint.</p></body></html>`)
+		codeChan <- query.Get("code")
+	})
+
+	server := &http.Server{Handler: mux, ReadTimeout: 10 * time.Second, WriteTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			a.logger.Error("Callback server error", zap.Error(err))
+		}
+	}()
+	defer server.Shutdown(context.WithoutCancel(ctx))
+
+	fmt.Printf("\nPlease visit this URL to authorize PulsePoint:\n%s\n\n", authURL)
+	fmt.Println("Waiting for authorization...")
+
+	select {
+	case code := <-codeChan:
+		token, err := a.HandleCallback(ctx, code, state)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Println("✓ Authorization successful!")
+		return token, nil
+	case err := <-errChan:
+		return nil, fmt.Errorf("callback server error: %w", err)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(5 * time.Minute):
+		return nil, fmt.Errorf("authorization timeout")
+	}
+}
+
+// GetAuthURL returns the authorization URL and remembers state and the PKCE
+// verifier for HandleCallback
+func (a *PulsePointOneDriveAuth) GetAuthURL(state string) (string, error) {
+	verifier := oauth2.GenerateVerifier()
+
+	a.mu.Lock()
+	a.state = state
+	a.verifier = verifier
+	a.mu.Unlock()
+
+	return a.config.AuthCodeURL(state,
+		oauth2.S256ChallengeOption(verifier),
+		oauth2.SetAuthURLParam("prompt", "select_account"),
+	), nil
+}
+
+// HandleCallback exchanges the authorization code for a token and stores it
+func (a *PulsePointOneDriveAuth) HandleCallback(ctx context.Context, code, state string) (*interfaces.AuthToken, error) {
+	a.mu.Lock()
+	expected, verifier := a.state, a.verifier
+	a.state, a.verifier = "", ""
+	a.mu.Unlock()
+
+	if expected == "" || state != expected {
+		return nil, errors.NewAuthError("invalid state parameter", nil)
+	}
+	if code == "" {
+		return nil, errors.NewAuthError("no authorization code received", nil)
+	}
+
+	token, err := a.config.Exchange(a.context(ctx), code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, errors.NewAuthError("failed to exchange code for token", err)
+	}
+
+	authToken := a.fromOAuth2(token)
+	if err := a.StoreToken(authToken); err != nil {
+		return nil, err
+	}
+	return authToken, nil
+}
+
+// RefreshToken exchanges the refresh token for a new access token. Microsoft
+// usually rotates the refresh token; the old one is kept if it does not.
+func (a *PulsePointOneDriveAuth) RefreshToken(ctx context.Context, token *interfaces.AuthToken) (*interfaces.AuthToken, error) {
+	if token == nil || token.RefreshToken == "" {
+		return nil, errors.NewAuthError("no refresh token available", nil)
+	}
+
+	source := a.config.TokenSource(a.context(ctx), &oauth2.Token{
+		RefreshToken: token.RefreshToken,
+		Expiry:       time.Now().Add(-time.Minute),
+	})
+	refreshed, err := source.Token()
+	if err != nil {
+		return nil, errors.NewAuthError("failed to refresh token", err)
+	}
+
+	authToken := a.fromOAuth2(refreshed)
+	if authToken.RefreshToken == "" {
+		authToken.RefreshToken = token.RefreshToken
+	}
+	authToken.UserID = token.UserID
+	authToken.Email = token.Email
+	return authToken, nil
+}
+
+// RevokeToken removes the stored token. The identity platform has no
+// endpoint for revoking a single refresh token, so access has to be removed
+// from https://account.live.com/consent/Manage or by a tenant admin.
+func (a *PulsePointOneDriveAuth) RevokeToken(ctx context.Context, token *interfaces.AuthToken) error {
+	if token == nil {
+		return errors.NewAuthError("no token to revoke", nil)
+	}
+
+	if err := a.DeleteToken(); err != nil {
+		return err
+	}
+
+	a.logger.Info("Token removed successfully")
+	return nil
+}
+
+// ValidateToken checks if a token can still be used without refreshing
+func (a *PulsePointOneDriveAuth) ValidateToken(ctx context.Context, token *interfaces.AuthToken) (bool, error) {
+	return token != nil && token.IsValid(), nil
+}
+
+// StoreToken saves a token to the token file
+func (a *PulsePointOneDriveAuth) StoreToken(token *interfaces.AuthToken) error {
+	if err := os.MkdirAll(filepath.Dir(a.tokenFile), 0700); err != nil {
+		return errors.NewFileSystemError("failed to create token directory", err)
+	}
+
+	data, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		return errors.NewAuthError("failed to marshal token", err)
+	}
+
+	// Save with restricted permissions (user read/write only)
+	if err := os.WriteFile(a.tokenFile, data, 0600); err != nil {
+		return errors.NewFileSystemError("failed to write token file", err)
+	}
+	return nil
+}
+
+// LoadToken loads the stored token
+func (a *PulsePointOneDriveAuth) LoadToken() (*interfaces.AuthToken, error) {
+	data, err := os.ReadFile(a.tokenFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var token interfaces.AuthToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, errors.NewAuthError("invalid token file", err)
+	}
+	return &token, nil
+}
+
+// DeleteToken removes the stored token
+func (a *PulsePointOneDriveAuth) DeleteToken() error {
+	if err := os.Remove(a.tokenFile); err != nil && !os.IsNotExist(err) {
+		return errors.NewFileSystemError("failed to remove token file", err)
+	}
+	return nil
+}
+
+// GetProviderName returns the auth provider name
+func (a *PulsePointOneDriveAuth) GetProviderName() string {
+	return providerName
+}
+
+// RequiresInteraction checks if the user must authorize PulsePoint again
+func (a *PulsePointOneDriveAuth) RequiresInteraction() bool {
+	token, err := a.LoadToken()
+	return err != nil || (!token.IsValid() && token.RefreshToken == "")
+}
+
+// context makes the oauth2 package use the configured HTTP client
+func (a *PulsePointOneDriveAuth) context(ctx context.Context) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, a.client)
+}
+
+// fromOAuth2 converts an oauth2 token
+func (a *PulsePointOneDriveAuth) fromOAuth2(token *oauth2.Token) *interfaces.AuthToken {
+	authToken := &interfaces.AuthToken{
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		TokenType:    token.TokenType,
+		ExpiresAt:    token.Expiry,
+		Provider:     providerName,
+	}
+	if scope, ok := token.Extra("scope").(string); ok {
+		authToken.Scope = scope
+	}
+	return authToken
+}
+
+// generateStateToken generates a random state token for OAuth2 security
+func generateStateToken() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return base64.URLEncoding.EncodeToString(b)
+}
+
+// GetDefaultTokenPath returns the default path for storing tokens
+func GetDefaultTokenPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".pulsepoint", "tokens", "onedrive_token.json")
+}
+
+var _ interfaces.AuthProvider = (*PulsePointOneDriveAuth)(nil)
@@ -0,0 +1,116 @@
+package onedrive
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLoginServer fakes the identity platform token endpoint for one tenant
+func newLoginServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/contoso/oauth2/v2.0/token", r.URL.Path)
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "client-id", r.PostForm.Get("client_id"))
+
+		response := map[string]interface{}{
+			"token_type": "Bearer",
+			"expires_in": 3600,
+			"scope":      "Files.ReadWrite User.Read",
+		}
+		switch r.PostForm.Get("grant_type") {
+		case "authorization_code":
+			if r.PostForm.Get("code") != "good-code" || r.PostForm.Get("code_verifier") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+				return
+			}
+			response["access_token"] = "access-1"
+			response["refresh_token"] = "refresh-1"
+		case "refresh_token":
+			assert.Equal(t, "refresh-1", r.PostForm.Get("refresh_token"))
+			response["access_token"] = "access-2"
+			response["refresh_token"] = "refresh-2"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestAuth(t *testing.T, server *httptest.Server) *PulsePointOneDriveAuth {
+	auth, err := NewPulsePointOneDriveAuth(&Config{
+		ClientID:  "client-id",
+		Tenant:    "contoso",
+		TokenFile: filepath.Join(t.TempDir(), "tokens", "onedrive_token.json"),
+		LoginURL:  server.URL,
+	})
+	require.NoError(t, err)
+	return auth
+}
+
+func TestOneDriveAuthCodeFlow(t *testing.T) {
+	ctx := context.Background()
+	server := newLoginServer(t)
+	auth := newTestAuth(t, server)
+	assert.True(t, auth.RequiresInteraction())
+
+	authURL, err := auth.GetAuthURL("state-1")
+	require.NoError(t, err)
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, "/contoso/oauth2/v2.0/authorize", parsed.Path)
+	query := parsed.Query()
+	assert.Contains(t, strings.Fields(query.Get("scope")), "offline_access")
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.Equal(t, "state-1", query.Get("state"))
+
+	_, err = auth.HandleCallback(ctx, "good-code", "forged")
+	assert.Error(t, err)
+
+	_, err = auth.GetAuthURL("state-2")
+	require.NoError(t, err)
+	token, err := auth.HandleCallback(ctx, "good-code", "state-2")
+	require.NoError(t, err)
+	assert.Equal(t, "access-1", token.AccessToken)
+	assert.Equal(t, "Files.ReadWrite User.Read", token.Scope)
+
+	info, err := os.Stat(auth.tokenFile)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	assert.False(t, auth.RequiresInteraction())
+
+	require.NoError(t, auth.RevokeToken(ctx, token))
+	assert.True(t, auth.RequiresInteraction())
+}
+
+func TestOneDriveAuthRefreshRotatesRefreshToken(t *testing.T) {
+	auth := newTestAuth(t, newLoginServer(t))
+	require.NoError(t, auth.StoreToken(&interfaces.AuthToken{
+		AccessToken:  "access-1",
+		RefreshToken: "refresh-1",
+		ExpiresAt:    time.Now().Add(-time.Hour),
+	}))
+	assert.False(t, auth.RequiresInteraction())
+
+	token, err := auth.Authenticate(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "access-2", token.AccessToken)
+	assert.Equal(t, "refresh-2", token.RefreshToken)
+
+	stored, err := auth.LoadToken()
+	require.NoError(t, err)
+	assert.Equal(t, "refresh-2", stored.RefreshToken)
+}
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/pulsepoint/pulsepoint/internal/auth/google"
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"github.com/pulsepoint/pulsepoint/internal/providers"
 	pplogger "github.com/pulsepoint/pulsepoint/pkg/logger"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -21,11 +23,11 @@ var authCmd = &cobra.Command{
 	
 Currently supported providers:
 - google (Google Drive)
-
-Future support planned for:
 - dropbox (Dropbox)
-- onedrive (Microsoft OneDrive)
-- s3 (Amazon S3)`,
+- onedrive (Microsoft OneDrive and OneDrive for Business)
+
+S3, WebDAV, SFTP and local directories use credentials from the config
+file and need no separate authentication.`,
 	Args: cobra.ExactArgs(1),
 	RunE: runAuth,
 }
@@ -34,7 +36,7 @@ func init() {
 	authCmd.Flags().Bool("revoke", false, "Revoke existing authentication")
 	authCmd.Flags().Bool("status", false, "Check authentication status")
 	authCmd.Flags().String("credentials", "", "Path to Google credentials JSON file")
-	authCmd.Flags().String("token-file", "", "Path to store OAuth2 token (default: ~/.pulsepoint/tokens/google_token.json)")
+	authCmd.Flags().String("token-file", "", "Path to store OAuth2 token (default: ~/.pulsepoint/tokens/<provider>_token.json)")
 }
 
 func runAuth(cmd *cobra.Command, args []string) error {
@@ -51,6 +53,30 @@ func runAuth(cmd *cobra.Command, args []string) error {
 			return revokeGoogleAuth()
 		}
 		return authenticateGoogle()
+	case "dropbox", "onedrive":
+		if tokenFile, _ := cmd.Flags().GetString("token-file"); tokenFile != "" {
+			viper.Set("providers."+provider+".token_file", tokenFile)
+		}
+
+		var auth interfaces.AuthProvider
+		var err error
+		if provider == "dropbox" {
+			auth, err = providers.NewDropboxAuth()
+		} else {
+			auth, err = providers.NewOneDriveAuth()
+		}
+		if err != nil {
+			printOAuthSetupHelp(provider)
+			return err
+		}
+
+		if status {
+			return checkOAuthStatus(provider, auth)
+		}
+		if revoke {
+			return revokeOAuth(provider, auth)
+		}
+		return authenticateOAuth(provider, auth)
 	default:
 		return fmt.Errorf("unsupported provider: %s", provider)
 	}
@@ -316,3 +342,116 @@ func checkGoogleAuthStatus() error {
 
 	return nil
 }
+
+// oauthProviderNames maps provider names to display names
+var oauthProviderNames = map[string]string{
+	"dropbox":  "Dropbox",
+	"onedrive": "OneDrive",
+}
+
+// printOAuthSetupHelp explains how to register PulsePoint with a provider
+func printOAuthSetupHelp(provider string) {
+	switch provider {
+	case "dropbox":
+		fmt.Println("\n⚠️  No Dropbox app key configured!")
+		fmt.Println("\nTo authenticate with Dropbox, you need to:")
+		fmt.Println("1. Go to https://www.dropbox.com/developers/apps")
+		fmt.Println("2. Create an app with Scoped access")
+		fmt.Println("3. Enable files.content.read, files.content.write and files.metadata.read")
+		fmt.Println("4. Add http://localhost:8080/callback as a redirect URI")
+		fmt.Println("5. Set providers.dropbox.app_key in the config file")
+		fmt.Println("   Or set the DROPBOX_APP_KEY environment variable")
+	case "onedrive":
+		fmt.Println("\n⚠️  No OneDrive client ID configured!")
+		fmt.Println("\nTo authenticate with OneDrive, you need to:")
+		fmt.Println("1. Go to https://entra.microsoft.com/ and open App registrations")
+		fmt.Println("2. Register an application for personal and work or school accounts")
+		fmt.Println("3. Add http://localhost:8080/callback as a Mobile and desktop redirect URI")
+		fmt.Println("4. Grant the Files.ReadWrite, User.Read and offline_access permissions")
+		fmt.Println("5. Set providers.onedrive.client_id in the config file")
+		fmt.Println("   Or set the ONEDRIVE_CLIENT_ID environment variable")
+	}
+}
+
+func authenticateOAuth(provider string, auth interfaces.AuthProvider) error {
+	log := pplogger.Get()
+	name := oauthProviderNames[provider]
+	fmt.Printf("🔐 Initiating %s authentication...\n", name)
+
+	if !auth.RequiresInteraction() {
+		fmt.Printf("✅ Already authenticated with %s\n", name)
+		fmt.Println("   Use --revoke to remove existing authentication")
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	token, err := auth.Authenticate(ctx)
+	if err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+
+	if token.UserID != "" {
+		fmt.Printf("✅ Authenticated as account: %s\n", token.UserID)
+	}
+	fmt.Println("🔑 Credentials saved securely")
+
+	viper.Set("providers."+provider+".configured", true)
+	if err := viper.WriteConfig(); err != nil {
+		log.Warn("Failed to update config file", zap.Error(err))
+	}
+
+	return nil
+}
+
+func revokeOAuth(provider string, auth interfaces.AuthProvider) error {
+	fmt.Printf("🔓 Revoking %s authentication...\n", oauthProviderNames[provider])
+
+	token, err := auth.LoadToken()
+	if err != nil {
+		fmt.Println("❌ Not authenticated")
+		return nil
+	}
+
+	if err := auth.RevokeToken(context.Background(), token); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	viper.Set("providers."+provider+".configured", false)
+	viper.WriteConfig()
+
+	fmt.Println("✅ Authentication revoked successfully")
+	return nil
+}
+
+func checkOAuthStatus(provider string, auth interfaces.AuthProvider) error {
+	name := oauthProviderNames[provider]
+	fmt.Printf("🔍 Checking %s authentication status...\n", name)
+
+	token, err := auth.LoadToken()
+	if err != nil {
+		fmt.Println("❌ Not authenticated")
+		fmt.Printf("   Run 'pulsepoint auth %s' to authenticate\n", provider)
+		return nil
+	}
+
+	if auth.RequiresInteraction() {
+		fmt.Println("⚠️  Token exists but is not valid")
+		fmt.Printf("   Run 'pulsepoint auth %s' to re-authenticate\n", provider)
+		return nil
+	}
+
+	fmt.Printf("✅ Authenticated with %s\n", name)
+	if token.UserID != "" {
+		fmt.Printf("👤 Account: %s\n", token.UserID)
+	}
+	if !token.ExpiresAt.IsZero() {
+		fmt.Printf("📅 Token expires: %s\n", token.ExpiresAt.Format("2006-01-02 15:04:05"))
+	}
+	if token.RefreshToken != "" {
+		fmt.Println("🔄 Refresh token available (auto-renewal enabled)")
+	}
+
+	return nil
+}
@@ -0,0 +1,646 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"github.com/pulsepoint/pulsepoint/internal/database"
+	"github.com/pulsepoint/pulsepoint/internal/database/repositories"
+	"github.com/pulsepoint/pulsepoint/internal/sync"
+	pplogger "github.com/pulsepoint/pulsepoint/pkg/logger"
+	"github.com/pulsepoint/pulsepoint/pkg/models"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// conflictsCmd represents the conflicts command
+var conflictsCmd = &cobra.Command{
+	Use:   "conflicts",
+	Short: "Review and resolve sync conflicts",
+	Long: `List, inspect and resolve conflicts that two-way sync could not settle
+on its own. Conflicts are recorded when they are skipped, when a merge leaves
+conflict markers, or when an interactive conflict has nobody to answer it.`,
+}
+
+var conflictsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recorded conflicts",
+	RunE:  runConflictsList,
+}
+
+var conflictsShowCmd = &cobra.Command{
+	Use:   "show [id]",
+	Short: "Show the details of a conflict",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConflictsShow,
+}
+
+var conflictsResolveCmd = &cobra.Command{
+	Use:   "resolve [id]",
+	Short: "Resolve a conflict with the chosen strategy",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConflictsResolve,
+}
+
+var conflictsResolveAllCmd = &cobra.Command{
+	Use:   "resolve-all",
+	Short: "Resolve all matching conflicts with the chosen strategy",
+	RunE:  runConflictsResolveAll,
+}
+
+var conflictsClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove resolved conflicts",
+	RunE:  runConflictsClear,
+}
+
+// conflictStrategies are the strategies a recorded conflict can be resolved with
+var conflictStrategies = map[string]bool{
+	"keep-local":  true,
+	"keep-remote": true,
+	"keep-both":   true,
+	"merge":       true,
+}
+
+func init() {
+	conflictsCmd.AddCommand(conflictsListCmd)
+	conflictsCmd.AddCommand(conflictsShowCmd)
+	conflictsCmd.AddCommand(conflictsResolveCmd)
+	conflictsCmd.AddCommand(conflictsResolveAllCmd)
+	conflictsCmd.AddCommand(conflictsClearCmd)
+
+	for _, cmd := range []*cobra.Command{conflictsListCmd, conflictsResolveAllCmd} {
+		cmd.Flags().String("type", "", "Filter by type (both_modified, delete_modify, ...)")
+		cmd.Flags().String("severity", "", "Filter by severity (low, medium, high, critical)")
+	}
+	conflictsListCmd.Flags().Bool("all", false, "Include resolved conflicts")
+
+	for _, cmd := range []*cobra.Command{conflictsResolveCmd, conflictsResolveAllCmd} {
+		cmd.Flags().String("strategy", "keep-local", "Resolution: keep-local, keep-remote, keep-both, merge")
+	}
+
+	for _, cmd := range []*cobra.Command{conflictsListCmd, conflictsShowCmd, conflictsResolveCmd, conflictsResolveAllCmd} {
+		cmd.Flags().Bool("json", false, "Output in JSON format")
+	}
+
+	conflictsClearCmd.Flags().Bool("all", false, "Remove unresolved conflicts as well")
+}
+
+func runConflictsList(cmd *cobra.Command, args []string) error {
+	all, _ := cmd.Flags().GetBool("all")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	db, repo, err := openConflictRepository()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	conflicts, err := filterConflicts(cmd, repo, all)
+	if err != nil {
+		return err
+	}
+
+	stats, err := repo.GetStatistics()
+	if err != nil {
+		return fmt.Errorf("failed to get conflict statistics: %w", err)
+	}
+
+	if jsonOutput {
+		return printJSON(struct {
+			Conflicts  []*models.Conflict               `json:"conflicts"`
+			Statistics *repositories.ConflictStatistics `json:"statistics"`
+		}{conflicts, stats})
+	}
+
+	fmt.Printf("⚔️  Sync Conflicts\n")
+	fmt.Printf("═══════════════════════════════════════\n\n")
+
+	if len(conflicts) == 0 {
+		fmt.Printf("  No conflicts to resolve\n")
+	} else {
+		fmt.Printf("%-48s %-14s %-9s %-10s %s\n", "ID", "Type", "Severity", "Status", "Path")
+		fmt.Printf("%-48s %-14s %-9s %-10s %s\n", "──", "────", "────────", "──────", "────")
+		for _, conflict := range conflicts {
+			fmt.Printf("%-48s %-14s %-9s %-10s %s\n",
+				conflict.ID, conflict.Type, conflict.Severity, conflict.ResolutionStatus, conflict.Path)
+		}
+	}
+
+	fmt.Printf("\n")
+	fmt.Printf("═══════════════════════════════════════\n")
+	fmt.Printf("📊 Summary: %d conflicts\n", stats.Total)
+	fmt.Printf("   ⏳ Unresolved: %d | ✅ Resolved: %d\n", stats.Unresolved, stats.Resolved)
+	if stats.Unresolved > 0 {
+		fmt.Printf("💡 Tip: Use 'pulsepoint conflicts resolve <id> --strategy keep-local' to resolve one\n")
+	}
+
+	return nil
+}
+
+func runConflictsShow(cmd *cobra.Command, args []string) error {
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	db, repo, err := openConflictRepository()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	conflict, err := repo.Get(args[0])
+	if err != nil {
+		return fmt.Errorf("conflict not found: %w", err)
+	}
+
+	if jsonOutput {
+		return printJSON(conflict)
+	}
+
+	fmt.Printf("⚔️  Conflict %s\n", conflict.ID)
+	fmt.Printf("═══════════════════════════════════════\n\n")
+	fmt.Printf("  Path: %s\n", conflict.Path)
+	fmt.Printf("  Type: %s\n", conflict.Type)
+	fmt.Printf("  Severity: %s\n", conflict.Severity)
+	fmt.Printf("  Status: %s\n", conflict.ResolutionStatus)
+	fmt.Printf("  Detected: %s\n", conflict.DetectedAt.Format("2006-01-02 15:04:05"))
+	if conflict.Description != "" {
+		fmt.Printf("  Description: %s\n", conflict.Description)
+	}
+	fmt.Printf("\n")
+
+	printConflictFile("💾 Local", conflict.LocalFile)
+	printConflictFile("☁️  Remote", conflict.RemoteFile)
+	if conflict.BaseFile != nil {
+		fmt.Printf("🧬 Merge base: %s\n\n", conflict.BaseFile.Hash)
+	}
+
+	if resolution := conflict.Resolution; resolution != nil {
+		fmt.Printf("✅ Resolution\n")
+		fmt.Printf("  Strategy: %s\n", resolution.Strategy)
+		if resolution.Winner != "" {
+			fmt.Printf("  Winner: %s\n", resolution.Winner)
+		}
+		if resolution.BackupPath != "" {
+			fmt.Printf("  Backup: %s\n", resolution.BackupPath)
+		}
+		fmt.Printf("  Resolved: %s\n", resolution.ResolvedAt.Format("2006-01-02 15:04:05"))
+		fmt.Printf("\n")
+	}
+
+	if len(conflict.History) > 0 {
+		fmt.Printf("📜 History\n")
+		for _, entry := range conflict.History {
+			fmt.Printf("  %s\n", entry)
+		}
+	}
+
+	return nil
+}
+
+func runConflictsResolve(cmd *cobra.Command, args []string) error {
+	strategyName, _ := cmd.Flags().GetString("strategy")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	if !conflictStrategies[strategyName] {
+		return fmt.Errorf("unknown resolution strategy: %s", strategyName)
+	}
+	hashAlgorithm, err := localHashAlgorithm(cmd)
+	if err != nil {
+		return err
+	}
+
+	db, repo, err := openConflictRepository()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	conflict, err := repo.Get(args[0])
+	if err != nil {
+		return fmt.Errorf("conflict not found: %w", err)
+	}
+	if conflict.IsResolved() {
+		return fmt.Errorf("conflict %s is already resolved", conflict.ID)
+	}
+
+	ctx := context.Background()
+	resolve, cleanup, err := newConflictSession(ctx, db, strategyName, hashAlgorithm)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	resolution, err := resolve(ctx, conflict)
+	if err != nil {
+		repo.MarkAttempted(conflict.ID)
+		return fmt.Errorf("failed to resolve %s: %w", conflict.Path, err)
+	}
+
+	if err := repo.Resolve(conflict.ID, resolution); err != nil {
+		return fmt.Errorf("failed to record resolution: %w", err)
+	}
+
+	if jsonOutput {
+		return printJSON(resolution)
+	}
+
+	fmt.Printf("✅ Resolved %s with %s\n", conflict.Path, resolution.Strategy)
+	if resolution.BackupPath != "" {
+		fmt.Printf("   💾 Other version kept at %s\n", resolution.BackupPath)
+	}
+
+	return nil
+}
+
+func runConflictsResolveAll(cmd *cobra.Command, args []string) error {
+	strategyName, _ := cmd.Flags().GetString("strategy")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	if !conflictStrategies[strategyName] {
+		return fmt.Errorf("unknown resolution strategy: %s", strategyName)
+	}
+	hashAlgorithm, err := localHashAlgorithm(cmd)
+	if err != nil {
+		return err
+	}
+
+	db, repo, err := openConflictRepository()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	conflicts, err := filterConflicts(cmd, repo, false)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) == 0 {
+		if jsonOutput {
+			return printJSON(map[string]*models.ConflictResolution{})
+		}
+		fmt.Printf("No conflicts to resolve\n")
+		return nil
+	}
+
+	ctx := context.Background()
+	resolve, cleanup, err := newConflictSession(ctx, db, strategyName, hashAlgorithm)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	resolutions := make(map[string]*models.ConflictResolution)
+	failed := 0
+	for _, conflict := range conflicts {
+		resolution, err := resolve(ctx, conflict)
+		if err != nil {
+			repo.MarkAttempted(conflict.ID)
+			failed++
+			if !jsonOutput {
+				fmt.Printf("❌ %s: %s\n", conflict.Path, err)
+			}
+			continue
+		}
+
+		resolutions[conflict.ID] = resolution
+		if !jsonOutput {
+			fmt.Printf("✅ %s\n", conflict.Path)
+		}
+	}
+
+	if err := repo.BatchResolve(resolutions); err != nil {
+		return fmt.Errorf("failed to record resolutions: %w", err)
+	}
+
+	if jsonOutput {
+		if err := printJSON(resolutions); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("\n📊 Resolved %d of %d conflicts\n", len(resolutions), len(conflicts))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d conflicts could not be resolved", failed)
+	}
+	return nil
+}
+
+func runConflictsClear(cmd *cobra.Command, args []string) error {
+	all, _ := cmd.Flags().GetBool("all")
+
+	db, repo, err := openConflictRepository()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if all {
+		if err := repo.Clear(); err != nil {
+			return fmt.Errorf("failed to clear conflicts: %w", err)
+		}
+		fmt.Printf("🗑️  Removed all conflicts\n")
+		return nil
+	}
+
+	if err := repo.ClearResolved(); err != nil {
+		return fmt.Errorf("failed to clear resolved conflicts: %w", err)
+	}
+	fmt.Printf("🗑️  Removed resolved conflicts\n")
+	return nil
+}
+
+// openConflictRepository opens the database holding recorded conflicts
+func openConflictRepository() (*database.Manager, *repositories.ConflictRepository, error) {
+	dbOptions := database.DefaultOptions()
+	dbOptions.Path = getDBPath()
+	db, err := database.NewManager(dbOptions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+	if err := db.Open(); err != nil {
+		return nil, nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	return db, repositories.NewConflictRepository(db), nil
+}
+
+// filterConflicts lists conflicts matching the type and severity flags
+func filterConflicts(cmd *cobra.Command, repo *repositories.ConflictRepository, all bool) ([]*models.Conflict, error) {
+	conflictType, _ := cmd.Flags().GetString("type")
+	severity, _ := cmd.Flags().GetString("severity")
+
+	var conflicts []*models.Conflict
+	var err error
+	if all {
+		conflicts, err = repo.List()
+	} else {
+		conflicts, err = repo.ListUnresolved()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conflicts: %w", err)
+	}
+
+	filtered := conflicts[:0]
+	for _, conflict := range conflicts {
+		if conflictType != "" && string(conflict.Type) != conflictType {
+			continue
+		}
+		if severity != "" && string(conflict.Severity) != severity {
+			continue
+		}
+		filtered = append(filtered, conflict)
+	}
+
+	return filtered, nil
+}
+
+// conflictResolveFunc applies the chosen strategy to a recorded conflict
+type conflictResolveFunc func(ctx context.Context, conflict *models.Conflict) (*models.ConflictResolution, error)
+
+// newConflictSession connects the provider and returns a function that
+// resolves recorded conflicts by syncing them through their folder pair's
+// strategy with the chosen resolution
+func newConflictSession(
+	ctx context.Context,
+	db *database.Manager,
+	strategyName, hashAlgorithm string,
+) (conflictResolveFunc, func(), error) {
+	log := pplogger.Get()
+
+	syncPaths, err := loadSyncPaths()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	provider, err := sync.CreateDefaultProvider(ctx)
+	if err != nil {
+		fmt.Println("\n⚠️  No cloud provider configured!")
+		fmt.Println("   Run 'pulsepoint auth google' to set up Google Drive")
+		return nil, nil, fmt.Errorf("cloud provider not configured: %w", err)
+	}
+
+	stateManager := sync.NewPulsePointStateManager(db, log, &sync.StateManagerConfig{
+		AutoSave:        false,
+		CompactInterval: 0,
+		RetentionPeriod: 30 * 24 * time.Hour,
+		MaxTransactions: 1000,
+	})
+	if err := stateManager.Initialize(getDBPath()); err != nil {
+		provider.Disconnect()
+		return nil, nil, fmt.Errorf("failed to initialize state manager: %w", err)
+	}
+	stateManager.SetBaseStore(sync.NewPulsePointBaseStore(getBaseStoreDir(), 0, log))
+
+	// The conflict is re-detected against the current state of both sides and
+	// settled by the resolver; nothing is recorded again
+	resolver := createConflictResolver(strategyName, "skip", 0, provider, nil, true, stateManager, log)
+	targets := newConflictTargets(syncPaths, pulseTargetOptions{
+		strategy:      "two-way",
+		conflict:      strategyName,
+		hashAlgorithm: hashAlgorithm,
+	}, provider, stateManager, resolver, log)
+
+	resolve := func(ctx context.Context, conflict *models.Conflict) (*models.ConflictResolution, error) {
+		return resolveRecordedConflict(ctx, targets, conflict, strategyName, log)
+	}
+	cleanup := func() {
+		stateManager.Close()
+		provider.Disconnect()
+	}
+
+	return resolve, cleanup, nil
+}
+
+// conflictTargets connects the folder pairs of recorded conflicts the way
+// pulse connects them, each on first use
+type conflictTargets struct {
+	paths        []*sync.SyncPath
+	targets      map[string]*pulseTarget
+	options      pulseTargetOptions
+	provider     interfaces.CloudProvider
+	stateManager *sync.PulsePointStateManager
+	resolver     interfaces.ConflictResolver
+	log          *zap.Logger
+}
+
+// newConflictTargets creates the folder pairs conflicts are resolved in.
+// Pairs without a strategy of their own use the one in options.
+func newConflictTargets(
+	syncPaths []sync.SyncPath,
+	options pulseTargetOptions,
+	provider interfaces.CloudProvider,
+	stateManager *sync.PulsePointStateManager,
+	resolver interfaces.ConflictResolver,
+	log *zap.Logger,
+) *conflictTargets {
+	paths := make([]*sync.SyncPath, len(syncPaths))
+	for i := range syncPaths {
+		paths[i] = &syncPaths[i]
+	}
+
+	return &conflictTargets{
+		paths:        paths,
+		targets:      make(map[string]*pulseTarget),
+		options:      options,
+		provider:     provider,
+		stateManager: stateManager,
+		resolver:     resolver,
+		log:          log,
+	}
+}
+
+// target returns the connected folder pair a conflicted file belongs to.
+// Files outside every configured pair were synced from a folder given on the
+// command line, which is taken to be the file's own folder.
+func (c *conflictTargets) target(localPath, remotePath string) (*pulseTarget, error) {
+	syncPath := sync.MatchSyncPath(c.paths, localPath, "local")
+	if syncPath == nil {
+		dir := filepath.Dir(localPath)
+		syncPath = &sync.SyncPath{
+			Name:      filepath.Base(dir),
+			Local:     dir,
+			Remote:    path.Dir(remotePath),
+			Recursive: true,
+			Enabled:   true,
+		}
+	}
+	if target, ok := c.targets[syncPath.Local]; ok {
+		return target, nil
+	}
+
+	target, err := pulsePointNewTarget(syncPath, "", sharedIgnorePatterns())
+	if err != nil {
+		return nil, err
+	}
+	if err := pulsePointConnectTarget(target, c.options, c.provider, c.stateManager, c.resolver, c.log); err != nil {
+		return nil, err
+	}
+
+	// Only two-way sync settles conflicts; other strategies never see them
+	if name := target.strategy.Name(); name != "two-way" {
+		return nil, fmt.Errorf("%s syncs with the %s strategy, which does not resolve conflicts", syncPath.Name, name)
+	}
+
+	c.targets[syncPath.Local] = target
+	return target, nil
+}
+
+// resolveRecordedConflict syncs the conflicted path once, letting its folder
+// pair's strategy apply the chosen resolution
+func resolveRecordedConflict(
+	ctx context.Context,
+	targets *conflictTargets,
+	conflict *models.Conflict,
+	strategyName string,
+	log *zap.Logger,
+) (*models.ConflictResolution, error) {
+	localPath := conflict.Path
+	remotePath := conflictRemotePath(ctx, targets.stateManager, conflict)
+	if remotePath == "" {
+		return nil, fmt.Errorf("remote path of %s is unknown", localPath)
+	}
+
+	target, err := targets.target(localPath, remotePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// A remote change event makes the strategy inspect both sides afresh
+	result, err := target.strategy.Sync(ctx, target.path.Local, target.path.RemoteRoot(), []interfaces.ChangeEvent{{
+		Type:      interfaces.ChangeTypeModify,
+		Path:      remotePath,
+		Timestamp: time.Now().Unix(),
+		Source:    "remote",
+	}})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Errors) > 0 {
+		return nil, fmt.Errorf("%s", result.Errors[0].Message)
+	}
+
+	resolution := models.NewConflictResolution(models.ResolutionStrategy(parseConflictResolution(strategyName)))
+	resolution.ResolvedBy = "user"
+	resolution.Manual = true
+
+	if len(result.Conflicts) == 0 {
+		// Both sides agree now, or only one of them changed since
+		resolution.Description = "No longer conflicting"
+	} else {
+		applied := result.Conflicts[0].Resolution
+		if len(applied.MergeConflicts) > 0 {
+			return nil, fmt.Errorf("merge left %d conflicting hunks in %s; edit them and resolve with keep-local",
+				len(applied.MergeConflicts), localPath)
+		}
+
+		resolution.Strategy = models.ResolutionStrategy(applied.Strategy)
+		resolution.Winner = applied.Winner
+		resolution.ResolvedPath = applied.ResolvedPath
+		resolution.BackupPath = applied.BackupPath
+		resolution.MergedPath = applied.MergedPath
+		resolution.MergeBase = applied.MergeBase
+	}
+
+	if result.FilesUploaded > 0 {
+		resolution.Actions = append(resolution.Actions, fmt.Sprintf("uploaded %d files", result.FilesUploaded))
+	}
+	if result.FilesDownloaded > 0 {
+		resolution.Actions = append(resolution.Actions, fmt.Sprintf("downloaded %d files", result.FilesDownloaded))
+	}
+	if result.FilesDeleted > 0 {
+		resolution.Actions = append(resolution.Actions, fmt.Sprintf("deleted %d files", result.FilesDeleted))
+	}
+
+	log.Info("Resolved recorded conflict",
+		zap.String("id", conflict.ID),
+		zap.String("path", localPath),
+		zap.String("strategy", string(resolution.Strategy)),
+	)
+
+	return resolution, nil
+}
+
+// conflictRemotePath finds the remote path of a recorded conflict, falling
+// back to the sync state when the remote side was deleted
+func conflictRemotePath(ctx context.Context, stateManager interfaces.StateManager, conflict *models.Conflict) string {
+	if conflict.RemoteFile != nil && conflict.RemoteFile.Path != "" {
+		return conflict.RemoteFile.Path
+	}
+
+	state, err := stateManager.GetFileState(ctx, conflict.Path)
+	if err != nil || state == nil {
+		return ""
+	}
+	remotePath, _ := state.Metadata["remote_path"].(string)
+	return remotePath
+}
+
+// printConflictFile prints one side of a conflict
+func printConflictFile(label string, file *models.File) {
+	fmt.Printf("%s\n", label)
+	if file == nil {
+		fmt.Printf("  (deleted)\n\n")
+		return
+	}
+	fmt.Printf("  Path: %s\n", file.Path)
+	fmt.Printf("  Size: %d bytes\n", file.Size)
+	fmt.Printf("  Modified: %s\n", file.ModifiedTime.Format("2006-01-02 15:04:05"))
+	if file.Hash != "" {
+		fmt.Printf("  Hash: %s\n", file.Hash)
+	}
+	fmt.Printf("\n")
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
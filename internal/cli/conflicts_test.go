@@ -0,0 +1,227 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"github.com/pulsepoint/pulsepoint/internal/database"
+	"github.com/pulsepoint/pulsepoint/internal/database/repositories"
+	"github.com/pulsepoint/pulsepoint/internal/providers/mock"
+	"github.com/pulsepoint/pulsepoint/internal/sync"
+	"github.com/pulsepoint/pulsepoint/pkg/models"
+	"github.com/pulsepoint/pulsepoint/pkg/utils"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// conflictFixture is a folder pair whose notes.md was last synced holding
+// "# Notes\nshared line\n" on both sides
+type conflictFixture struct {
+	db           *database.Manager
+	provider     *mock.MockDriveProvider
+	stateManager *sync.PulsePointStateManager
+	syncPath     sync.SyncPath
+	localPath    string
+}
+
+const fixtureRemotePath = "/Docs/notes.md"
+
+func newConflictFixture(t *testing.T) *conflictFixture {
+	ctx := context.Background()
+	dir := t.TempDir()
+	// Conflict backups are kept under the home directory
+	t.Setenv("HOME", dir)
+
+	f := &conflictFixture{
+		provider: mock.NewMockDriveProvider(),
+		syncPath: sync.SyncPath{
+			Name:      "docs",
+			Local:     filepath.Join(dir, "docs"),
+			Remote:    "/Docs",
+			Recursive: true,
+			Enabled:   true,
+		},
+	}
+	require.NoError(t, os.MkdirAll(f.syncPath.Local, 0755))
+	f.localPath = filepath.Join(f.syncPath.Local, "notes.md")
+
+	options := database.DefaultOptions()
+	options.Path = filepath.Join(dir, "test.db")
+	db, err := database.NewManager(options)
+	require.NoError(t, err)
+	require.NoError(t, db.Open())
+	t.Cleanup(func() { db.Close() })
+	f.db = db
+
+	f.stateManager = sync.NewPulsePointStateManager(db, zap.NewNop(), nil)
+	require.NoError(t, f.stateManager.Initialize(options.Path))
+	f.stateManager.SetBaseStore(sync.NewPulsePointBaseStore(filepath.Join(dir, "bases"), 0, zap.NewNop()))
+	require.NoError(t, f.provider.Initialize(interfaces.ProviderConfig{}))
+
+	base := "# Notes\nshared line\n"
+	f.writeLocal(t, base)
+	f.writeRemote(t, base)
+	localHash, err := utils.FileSHA256(f.localPath)
+	require.NoError(t, err)
+	remote, err := f.provider.GetMetadata(ctx, fixtureRemotePath)
+	require.NoError(t, err)
+	require.NoError(t, f.stateManager.UpdateFileState(ctx, &interfaces.FileState{
+		Path:       f.localPath,
+		LocalHash:  localHash,
+		RemoteHash: remote.Hash,
+		RemoteID:   remote.ID,
+		Status:     interfaces.FileSyncStatusSynced,
+		Metadata:   map[string]interface{}{"remote_path": fixtureRemotePath},
+	}))
+	return f
+}
+
+func (f *conflictFixture) writeLocal(t *testing.T, content string) {
+	require.NoError(t, os.WriteFile(f.localPath, []byte(content), 0644))
+}
+
+func (f *conflictFixture) writeRemote(t *testing.T, content string) {
+	require.NoError(t, f.provider.Upload(context.Background(), &interfaces.File{
+		Path:    fixtureRemotePath,
+		Content: strings.NewReader(content),
+	}))
+}
+
+func (f *conflictFixture) local(t *testing.T) string {
+	data, err := os.ReadFile(f.localPath)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func (f *conflictFixture) remote(t *testing.T) string {
+	file, err := f.provider.Download(context.Background(), fixtureRemotePath)
+	require.NoError(t, err)
+	data, err := io.ReadAll(file.Content)
+	require.NoError(t, err)
+	return string(data)
+}
+
+// resolve resolves a recorded conflict on notes.md the way the conflicts
+// command does
+func (f *conflictFixture) resolve(t *testing.T, strategyName string) (*models.ConflictResolution, error) {
+	resolver := createConflictResolver(strategyName, "skip", 0, f.provider, nil, true, f.stateManager, zap.NewNop())
+	targets := newConflictTargets([]sync.SyncPath{f.syncPath}, pulseTargetOptions{
+		strategy:      "two-way",
+		conflict:      strategyName,
+		hashAlgorithm: "sha256",
+	}, f.provider, f.stateManager, resolver, zap.NewNop())
+
+	conflict := models.NewConflict(f.localPath, models.ConflictTypeBothModified, nil, &models.File{Path: fixtureRemotePath})
+	return resolveRecordedConflict(context.Background(), targets, conflict, strategyName, zap.NewNop())
+}
+
+func TestResolveRecordedConflict(t *testing.T) {
+	t.Run("no longer conflicting", func(t *testing.T) {
+		f := newConflictFixture(t)
+		f.writeLocal(t, "# Notes\nsame line\n")
+		f.writeRemote(t, "# Notes\nsame line\n")
+
+		resolution, err := f.resolve(t, "keep-local")
+		require.NoError(t, err)
+		assert.Equal(t, "No longer conflicting", resolution.Description)
+		assert.True(t, resolution.Manual)
+	})
+
+	t.Run("keep local", func(t *testing.T) {
+		f := newConflictFixture(t)
+		f.writeLocal(t, "# Notes\nlocal line\n")
+		f.writeRemote(t, "# Notes\nremote line\n")
+
+		resolution, err := f.resolve(t, "keep-local")
+		require.NoError(t, err)
+		assert.Equal(t, models.ResolutionStrategy(interfaces.ResolutionKeepLocal), resolution.Strategy)
+		assert.Equal(t, "# Notes\nlocal line\n", f.local(t))
+		assert.Equal(t, "# Notes\nlocal line\n", f.remote(t))
+	})
+
+	t.Run("keep remote", func(t *testing.T) {
+		f := newConflictFixture(t)
+		f.writeLocal(t, "# Notes\nlocal line\n")
+		f.writeRemote(t, "# Notes\nremote line\n")
+
+		resolution, err := f.resolve(t, "keep-remote")
+		require.NoError(t, err)
+		assert.Equal(t, models.ResolutionStrategy(interfaces.ResolutionKeepRemote), resolution.Strategy)
+		assert.Equal(t, "# Notes\nremote line\n", f.local(t))
+		assert.Equal(t, "# Notes\nremote line\n", f.remote(t))
+	})
+
+	t.Run("merge left hunks", func(t *testing.T) {
+		f := newConflictFixture(t)
+		f.writeLocal(t, "# Notes\nlocal line\n")
+		f.writeRemote(t, "# Notes\nremote line\n")
+
+		_, err := f.resolve(t, "merge")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "merge left 1 conflicting hunks")
+		assert.True(t, utils.HasMergeMarkers(f.localPath))
+	})
+
+	t.Run("pair syncs one way", func(t *testing.T) {
+		f := newConflictFixture(t)
+		f.syncPath.Strategy = "one-way"
+
+		_, err := f.resolve(t, "keep-local")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not resolve conflicts")
+	})
+}
+
+func TestFilterConflicts(t *testing.T) {
+	f := newConflictFixture(t)
+	repo := repositories.NewConflictRepository(f.db)
+
+	record := func(path string, conflictType models.ConflictType, severity models.ConflictSeverity) *models.Conflict {
+		conflict := models.NewConflict(path, conflictType, nil, nil)
+		conflict.Severity = severity
+		require.NoError(t, repo.Create(conflict))
+		return conflict
+	}
+	record("/docs/a.md", models.ConflictTypeBothModified, models.ConflictSeverityHigh)
+	record("/docs/b.md", models.ConflictTypeBothModified, models.ConflictSeverityLow)
+	record("/docs/c.md", models.ConflictTypeDeleteModify, models.ConflictSeverityHigh)
+	resolved := record("/docs/d.md", models.ConflictTypeBothModified, models.ConflictSeverityHigh)
+	require.NoError(t, repo.Resolve(resolved.ID, models.NewConflictResolution(models.ResolutionStrategy(interfaces.ResolutionKeepLocal))))
+
+	tests := []struct {
+		name         string
+		conflictType string
+		severity     string
+		all          bool
+		want         []string
+	}{
+		{"no filter", "", "", false, []string{"/docs/a.md", "/docs/b.md", "/docs/c.md"}},
+		{"type", "both_modified", "", false, []string{"/docs/a.md", "/docs/b.md"}},
+		{"severity", "", "high", false, []string{"/docs/a.md", "/docs/c.md"}},
+		{"type and severity", "both_modified", "high", false, []string{"/docs/a.md"}},
+		{"resolved included", "both_modified", "high", true, []string{"/docs/a.md", "/docs/d.md"}},
+		{"no match", "naming", "", false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			cmd.Flags().String("type", tt.conflictType, "")
+			cmd.Flags().String("severity", tt.severity, "")
+
+			conflicts, err := filterConflicts(cmd, repo, tt.all)
+			require.NoError(t, err)
+			var paths []string
+			for _, conflict := range conflicts {
+				paths = append(paths, conflict.Path)
+			}
+			assert.ElementsMatch(t, tt.want, paths)
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -59,9 +61,16 @@ func runInit(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("configuration already exists at %s. Use --force to overwrite", configPath)
 	}
 
+	// Identifies this device in what it uploads
+	deviceID := make([]byte, 8)
+	if _, err := rand.Read(deviceID); err != nil {
+		return fmt.Errorf("failed to generate device ID: %w", err)
+	}
+
 	// Default configuration
 	defaultConfig := map[string]interface{}{
-		"version": "1.0",
+		"version":   "1.0",
+		"device_id": hex.EncodeToString(deviceID),
 		"pulse": map[string]interface{}{
 			"interval":          "30s",
 			"batch_size":        10,
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/pulsepoint/pulsepoint/internal/sync"
+	"github.com/pulsepoint/pulsepoint/pkg/utils"
+	"github.com/spf13/viper"
+)
+
+// defaultIgnorePatterns apply when the config file sets no ignore patterns
+var defaultIgnorePatterns = []string{".git", "node_modules", "*.tmp"}
+
+// syncPathEntry is one entry of the paths list in the config file
+type syncPathEntry struct {
+	Name      string   `mapstructure:"name"`
+	Local     string   `mapstructure:"local"`
+	Remote    string   `mapstructure:"remote"`
+	Recursive *bool    `mapstructure:"recursive"`
+	Enabled   *bool    `mapstructure:"enabled"`
+	Ignore    []string `mapstructure:"ignore"`
+	Strategy  string   `mapstructure:"strategy"`
+}
+
+// loadSyncPaths reads the folder pairs from the paths list in the config file.
+// Entries are recursive and enabled unless they say otherwise, and sync with
+// the --strategy flag unless they name their own strategy.
+func loadSyncPaths() ([]sync.SyncPath, error) {
+	var entries []syncPathEntry
+	if err := viper.UnmarshalKey("paths", &entries); err != nil {
+		return nil, fmt.Errorf("invalid paths configuration: %w", err)
+	}
+
+	paths := make([]sync.SyncPath, 0, len(entries))
+	names := make(map[string]bool, len(entries))
+	for i, entry := range entries {
+		if entry.Local == "" {
+			return nil, fmt.Errorf("paths[%d]: local folder is required", i)
+		}
+
+		local, err := filepath.Abs(utils.CleanPath(entry.Local))
+		if err != nil {
+			return nil, fmt.Errorf("paths[%d]: invalid local folder: %w", i, err)
+		}
+
+		name := entry.Name
+		if name == "" {
+			name = filepath.Base(local)
+		}
+		if names[name] {
+			return nil, fmt.Errorf("paths[%d]: duplicate name %q", i, name)
+		}
+		names[name] = true
+
+		if entry.Strategy != "" && !syncStrategies[entry.Strategy] {
+			return nil, fmt.Errorf("paths[%d]: unknown strategy %q", i, entry.Strategy)
+		}
+
+		paths = append(paths, sync.SyncPath{
+			Name:      name,
+			Local:     local,
+			Remote:    entry.Remote,
+			Recursive: entry.Recursive == nil || *entry.Recursive,
+			Enabled:   entry.Enabled == nil || *entry.Enabled,
+			Ignore:    entry.Ignore,
+			Strategy:  entry.Strategy,
+		})
+	}
+
+	return paths, nil
+}
+
+// sharedIgnorePatterns returns the ignore patterns that apply to every folder pair
+func sharedIgnorePatterns() []string {
+	if viper.IsSet("monitoring.ignore_patterns") {
+		return viper.GetStringSlice("monitoring.ignore_patterns")
+	}
+	return defaultIgnorePatterns
+}
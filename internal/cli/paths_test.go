@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pulsepoint/pulsepoint/internal/database"
+	"github.com/pulsepoint/pulsepoint/internal/providers/mock"
+	"github.com/pulsepoint/pulsepoint/internal/sync"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestLoadSyncPathsStrategy(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	viper.Set("paths", []map[string]interface{}{
+		{"name": "docs", "local": "/home/me/Documents", "strategy": "two-way"},
+		{"name": "photos", "local": "/home/me/Photos"},
+	})
+	paths, err := loadSyncPaths()
+	require.NoError(t, err)
+	require.Len(t, paths, 2)
+	assert.Equal(t, "two-way", paths[0].Strategy)
+	assert.Empty(t, paths[1].Strategy)
+
+	viper.Set("paths", []map[string]interface{}{
+		{"local": "/home/me/Documents", "strategy": "sideways"},
+	})
+	_, err = loadSyncPaths()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `paths[0]: unknown strategy "sideways"`)
+}
+
+func TestConnectTargetUsesPairStrategy(t *testing.T) {
+	options := database.DefaultOptions()
+	options.Path = filepath.Join(t.TempDir(), "test.db")
+	db, err := database.NewManager(options)
+	require.NoError(t, err)
+	require.NoError(t, db.Open())
+	t.Cleanup(func() { db.Close() })
+
+	stateManager := sync.NewPulsePointStateManager(db, zap.NewNop(), nil)
+	require.NoError(t, stateManager.Initialize(options.Path))
+	provider := mock.NewMockDriveProvider()
+	targetOptions := pulseTargetOptions{strategy: "one-way", conflict: "keep-local", hashAlgorithm: "sha256"}
+
+	tests := []struct {
+		name     string
+		strategy string
+		want     string
+	}{
+		{"pair strategy", "two-way", "two-way"},
+		{"default strategy", "", "one-way"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			syncPath := &sync.SyncPath{Name: tt.name, Local: t.TempDir(), Recursive: true, Enabled: true, Strategy: tt.strategy}
+			target, err := pulsePointNewTarget(syncPath, "", nil)
+			require.NoError(t, err)
+
+			require.NoError(t, pulsePointConnectTarget(target, targetOptions, provider, stateManager, nil, zap.NewNop()))
+			assert.Equal(t, tt.want, target.strategy.Name())
+		})
+	}
+}
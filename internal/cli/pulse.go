@@ -10,11 +10,15 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"github.com/pulsepoint/pulsepoint/internal/database"
+	"github.com/pulsepoint/pulsepoint/internal/sync"
 	"github.com/pulsepoint/pulsepoint/internal/watchers"
+	"github.com/pulsepoint/pulsepoint/internal/watchers/ignore"
+	"github.com/pulsepoint/pulsepoint/internal/watchers/remote"
 	pplogger "github.com/pulsepoint/pulsepoint/pkg/logger"
 	"github.com/pulsepoint/pulsepoint/pkg/models"
 	"github.com/spf13/cobra"
-	"go.etcd.io/bbolt"
 	"go.uber.org/zap"
 )
 
@@ -26,8 +30,9 @@ var pulseCmd = &cobra.Command{
 to your configured cloud storage provider.
 
 PulsePoint will continuously monitor the specified directory for changes
-and sync them at the configured interval.`,
-	Args: cobra.ExactArgs(1),
+and sync them at the configured interval. Without a path, every enabled
+folder pair in the paths section of the config file is monitored.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: runPulse,
 }
 
@@ -42,10 +47,37 @@ func init() {
 	pulseCmd.Flags().Int("batch-size", 100, "Number of changes to process in a batch")
 	pulseCmd.Flags().String("ignore-file", "", "Path to ignore file (defaults to .pulseignore or .gitignore)")
 	pulseCmd.Flags().String("hash", "sha256", "Hash algorithm to use (md5 or sha256)")
+	pulseCmd.Flags().String("strategy", "one-way", "Sync strategy: one-way, two-way, mirror, or backup")
+	pulseCmd.Flags().String("conflict", "keep-local", "Conflict resolution: keep-local, keep-remote, keep-both, merge, interactive, skip")
+	pulseCmd.Flags().String("conflict-default", "skip", "Conflict resolution used when an interactive conflict gets no answer")
+	pulseCmd.Flags().Duration("conflict-timeout", 5*time.Minute, "How long to wait for an interactive answer before using --conflict-default")
+	pulseCmd.Flags().Int("max-delete-percent", 50, "Largest share of remote files a mirror cleanup may delete")
+	pulseCmd.Flags().Duration("poll-interval", 30*time.Second, "Interval for polling remote changes (two-way only)")
+}
+
+// pulseTarget is one folder pair synced by pulse or sync, with its own ignore
+// rules, strategy and state namespace
+type pulseTarget struct {
+	path       *sync.SyncPath
+	ignoreFile string
+	patterns   []string
+	ignore     *ignore.PulsePointIgnoreMatcher
+	state      *sync.PulsePointStateManager
+	strategy   interfaces.SyncStrategy
+	syncer     *sync.PulsePointBatchSyncer
+	reconciler *sync.PulsePointReconciler
+}
+
+// pulseTargetOptions are the sync settings every folder pair is connected with.
+// The strategy applies to pairs that do not name their own.
+type pulseTargetOptions struct {
+	strategy         string
+	conflict         string
+	hashAlgorithm    string
+	maxDeletePercent int
 }
 
 func runPulse(cmd *cobra.Command, args []string) error {
-	localPath := args[0]
 	interval, _ := cmd.Flags().GetDuration("interval")
 	remotePath, _ := cmd.Flags().GetString("remote")
 	recursive, _ := cmd.Flags().GetBool("recursive")
@@ -55,21 +87,46 @@ func runPulse(cmd *cobra.Command, args []string) error {
 	debounce, _ := cmd.Flags().GetDuration("debounce")
 	batchSize, _ := cmd.Flags().GetInt("batch-size")
 	ignoreFile, _ := cmd.Flags().GetString("ignore-file")
-	hashAlgorithm, _ := cmd.Flags().GetString("hash")
+	strategyName, _ := cmd.Flags().GetString("strategy")
+	conflictRes, _ := cmd.Flags().GetString("conflict")
+	conflictTimeout, _ := cmd.Flags().GetDuration("conflict-timeout")
+	pollInterval, _ := cmd.Flags().GetDuration("poll-interval")
 
-	// Get absolute path
-	absPath, err := filepath.Abs(localPath)
+	conflictFallback, err := conflictDefault(cmd)
+	if err != nil {
+		return err
+	}
+	deleteLimit, err := maxDeletePercent(cmd)
+	if err != nil {
+		return err
+	}
+	hashAlgorithm, err := localHashAlgorithm(cmd)
 	if err != nil {
-		return fmt.Errorf("failed to get absolute path: %w", err)
+		return err
 	}
 
-	// Check if path exists
-	info, err := os.Stat(absPath)
+	syncPaths, err := syncPathsFromArgs(args, remotePath)
 	if err != nil {
-		return fmt.Errorf("path does not exist: %w", err)
+		return err
 	}
-	if !info.IsDir() {
-		return fmt.Errorf("path is not a directory: %s", absPath)
+	if len(args) == 1 {
+		syncPaths[0].Recursive = recursive
+	}
+
+	// Every enabled pair gets its own target; all of them share one watcher manager
+	sharedPatterns := append(append([]string{}, sharedIgnorePatterns()...), ignorePatterns...)
+	var targets []*pulseTarget
+	for i := range syncPaths {
+		syncPath := &syncPaths[i]
+		if !syncPath.Enabled {
+			continue
+		}
+
+		target, err := pulsePointNewTarget(syncPath, ignoreFile, sharedPatterns)
+		if err != nil {
+			return err
+		}
+		targets = append(targets, target)
 	}
 
 	// Initialize logger
@@ -80,47 +137,95 @@ func runPulse(cmd *cobra.Command, args []string) error {
 	zapLogger := pplogger.Get()
 
 	// Open database
-	dbPath := filepath.Join(os.Getenv("HOME"), ".pulsepoint", "pulsepoint.db")
-	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
-		return fmt.Errorf("failed to create database directory: %w", err)
-	}
-
-	db, err := bbolt.Open(dbPath, 0600, &bbolt.Options{
-		Timeout: 1 * time.Second,
-	})
+	dbOptions := database.DefaultOptions()
+	dbOptions.Path = getDBPath()
+	db, err := database.NewManager(dbOptions)
 	if err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	if err := db.Open(); err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
 	defer db.Close()
 
-	// Look for ignore file if not specified
-	if ignoreFile == "" {
-		// Check for .pulseignore or .gitignore in the directory
-		pulseignore := filepath.Join(absPath, ".pulseignore")
-		gitignore := filepath.Join(absPath, ".gitignore")
+	// Context for in-flight syncs, cancelled on shutdown
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Connect the provider and strategies unless this is a dry run
+	var remoteWatcher *remote.PulsePointRemoteWatcher
+	var stateManager *sync.PulsePointStateManager
+	stateRetention := 30 * 24 * time.Hour
+	if !dryRun {
+		provider, err := sync.CreateDefaultProvider(ctx)
+		if err != nil {
+			fmt.Println("\n⚠️  No cloud provider configured!")
+			fmt.Println("   Run 'pulsepoint auth google' to set up Google Drive")
+			return fmt.Errorf("cloud provider not configured: %w", err)
+		}
+		defer provider.Disconnect()
+
+		// Compaction reopens the database, which the watcher queue shares
+		stateManager = sync.NewPulsePointStateManager(db, zapLogger, &sync.StateManagerConfig{
+			AutoSave:        false,
+			CompactInterval: 0,
+			RetentionPeriod: stateRetention,
+			MaxTransactions: 1000,
+		})
+		if err := stateManager.Initialize(getDBPath()); err != nil {
+			return fmt.Errorf("failed to initialize state manager: %w", err)
+		}
+		stateManager.SetBaseStore(sync.NewPulsePointBaseStore(getBaseStoreDir(), 0, zapLogger))
+
+		// A daemon has no terminal, so interactive conflicts wait in the database
+		resolver := createConflictResolver(conflictRes, conflictFallback, conflictTimeout, provider, db, daemon, stateManager, zapLogger)
 
-		if _, err := os.Stat(pulseignore); err == nil {
-			ignoreFile = pulseignore
-		} else if _, err := os.Stat(gitignore); err == nil {
-			ignoreFile = gitignore
+		options := pulseTargetOptions{
+			strategy:         strategyName,
+			conflict:         conflictRes,
+			hashAlgorithm:    hashAlgorithm,
+			maxDeletePercent: deleteLimit,
+		}
+
+		twoWay := false
+		watchKeys := make([]string, 0, len(targets))
+		for _, target := range targets {
+			if err := pulsePointConnectTarget(target, options, provider, stateManager, resolver, zapLogger); err != nil {
+				return err
+			}
+			twoWay = twoWay || target.strategy.Name() == "two-way"
+			watchKeys = append(watchKeys, target.path.Local)
+		}
+
+		// Two-way sync also needs to hear about changes made on the provider
+		if feed, ok := provider.(interfaces.RemoteChangeFeed); ok && twoWay {
+			remoteWatcher = remote.NewPulsePointRemoteWatcher(feed, db.DB, provider.GetProviderName()+":"+strings.Join(watchKeys, ","), pollInterval)
+			remoteWatcher.SeedPaths(pulsePointKnownRemotePaths(ctx, stateManager))
 		}
 	}
 
 	// Display startup information
 	fmt.Printf("🚀 Starting PulsePoint Monitor\n")
-	fmt.Printf("📁 Local Path: %s\n", absPath)
-	if remotePath != "" {
-		fmt.Printf("☁️  Remote Path: %s\n", remotePath)
+	for _, target := range targets {
+		fmt.Printf("📁 %s: %s → %s\n", target.path.Name, target.path.Local, target.path.RemoteRoot())
+		if !target.path.Recursive {
+			fmt.Printf("   🔄 Recursive: false\n")
+		}
+		if target.path.Strategy != "" {
+			fmt.Printf("   🎯 Strategy: %s\n", target.path.Strategy)
+		}
+		if target.ignoreFile != "" {
+			fmt.Printf("   📝 Using ignore file: %s\n", target.ignoreFile)
+		}
+		if len(target.path.Ignore) > 0 {
+			fmt.Printf("   🚫 Ignore Patterns: %v\n", target.path.Ignore)
+		}
 	}
 	fmt.Printf("⏱️  Flush Interval: %s\n", interval)
 	fmt.Printf("⏳ Debounce Period: %s\n", debounce)
 	fmt.Printf("📦 Batch Size: %d\n", batchSize)
 	fmt.Printf("🔐 Hash Algorithm: %s\n", hashAlgorithm)
-	fmt.Printf("🔄 Recursive: %v\n", recursive)
-
-	if ignoreFile != "" {
-		fmt.Printf("📝 Using ignore file: %s\n", ignoreFile)
-	}
+	fmt.Printf("🎯 Strategy: %s\n", strategyName)
 
 	if len(ignorePatterns) > 0 {
 		fmt.Printf("🚫 Ignore Patterns: %v\n", ignorePatterns)
@@ -136,19 +241,21 @@ func runPulse(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("\n")
 
-	// Create watcher manager configuration
+	// Create watcher manager configuration. Ignore files are loaded per pair.
 	managerConfig := watchers.ManagerConfig{
 		DebouncePeriod: debounce,
 		HashAlgorithm:  hashAlgorithm,
 		MaxQueueSize:   10000,
 		BatchSize:      batchSize,
 		FlushInterval:  interval,
-		IgnoreFile:     ignoreFile,
-		SyncHandler:    pulsePointCreateSyncHandler(zapLogger, dryRun, remotePath),
+		SyncHandler:    pulsePointCreateSyncHandler(ctx, zapLogger, targets, dryRun),
+	}
+	if remoteWatcher != nil {
+		managerConfig.RemoteWatcher = remoteWatcher
 	}
 
 	// Create watcher manager
-	manager, err := watchers.NewPulsePointWatcherManager(db, managerConfig)
+	manager, err := watchers.NewPulsePointWatcherManager(db.DB, managerConfig)
 	if err != nil {
 		return fmt.Errorf("failed to create watcher manager: %w", err)
 	}
@@ -160,15 +267,55 @@ func runPulse(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Remote folders are registered first so that the first poll, which
+	// catches up on changes made while stopped, does not drop them
+	if remoteWatcher != nil {
+		for _, target := range targets {
+			if err := manager.WatchRemotePath(target.path.RemoteRoot()); err != nil {
+				return fmt.Errorf("failed to watch remote path %s: %w", target.path.RemoteRoot(), err)
+			}
+		}
+	}
+
 	// Start the watcher
 	if err := manager.Start(); err != nil {
 		return fmt.Errorf("failed to start watcher: %w", err)
 	}
 	defer manager.Stop()
 
-	// Add the path to watch
-	if err := manager.WatchPath(absPath); err != nil {
-		return fmt.Errorf("failed to watch path: %w", err)
+	// Add the paths to watch
+	for _, target := range targets {
+		if err := manager.WatchPath(target.path.Local); err != nil {
+			return fmt.Errorf("failed to watch path %s: %w", target.path.Local, err)
+		}
+	}
+	if remoteWatcher != nil {
+		fmt.Printf("☁️  Polling remote changes every %s\n", pollInterval)
+	}
+
+	for _, target := range targets {
+		if target.reconciler == nil {
+			continue
+		}
+		fmt.Printf("🔍 Reconciling %s with stored state...\n", target.path.Name)
+		events, stats, err := target.reconciler.Scan(ctx)
+		if err != nil {
+			return fmt.Errorf("reconciliation of %s failed: %w", target.path.Name, err)
+		}
+		if err := manager.Enqueue(events); err != nil {
+			return fmt.Errorf("failed to queue reconciled changes: %w", err)
+		}
+		fmt.Printf("   %d created, %d modified, %d deleted since last run\n", stats.Created, stats.Modified, stats.Deleted)
+
+		// Remote files removed locally while stopped are only found by a full walk
+		cleanup, err := target.syncer.CleanupRemote(ctx)
+		if err != nil {
+			// Monitoring goes on; extras stay until the next start
+			zapLogger.Warn("Remote cleanup failed", zap.String("sync_path", target.path.Name), zap.Error(err))
+			fmt.Printf("   ⚠️  Remote cleanup skipped: %v\n", err)
+		} else if cleanup.FilesDeleted > 0 {
+			fmt.Printf("   %d remote files removed\n", cleanup.FilesDeleted)
+		}
 	}
 
 	fmt.Printf("💓 PulsePoint is monitoring... Press Ctrl+C to stop\n")
@@ -177,9 +324,6 @@ func runPulse(cmd *cobra.Command, args []string) error {
 	fmt.Printf("[%s] 👀 Watching for changes...\n", time.Now().Format("15:04:05"))
 
 	// Setup signal handling for graceful shutdown
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
@@ -187,6 +331,11 @@ func runPulse(cmd *cobra.Command, args []string) error {
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
 
+	// Old transactions and merge bases no file state refers to any more are
+	// dropped while monitoring, since batch syncs never save transactions
+	cleanupTicker := time.NewTicker(time.Hour)
+	defer cleanupTicker.Stop()
+
 	// Main monitoring loop
 	for {
 		select {
@@ -212,104 +361,272 @@ func runPulse(cmd *cobra.Command, args []string) error {
 						time.Now().Format("15:04:05"), pending, processing)
 				}
 			}
+		case <-cleanupTicker.C:
+			if stateManager == nil {
+				continue
+			}
+			if err := stateManager.Cleanup(ctx, time.Now().Add(-stateRetention)); err != nil {
+				zapLogger.Warn("State cleanup failed", zap.Error(err))
+			}
 		case <-ctx.Done():
 			return nil
 		}
 	}
 }
 
-// pulsePointCreateSyncHandler creates a sync handler function for processing file changes
-func pulsePointCreateSyncHandler(zapLogger *zap.Logger, dryRun bool, remotePath string) func([]*models.ChangeEvent) error {
-	return func(events []*models.ChangeEvent) error {
-		timestamp := time.Now().Format("15:04:05")
+// pulsePointIgnoreFile returns the ignore file for a folder, preferring the
+// one given on the command line, then .pulseignore, then .gitignore
+func pulsePointIgnoreFile(ignoreFile, dir string) string {
+	if ignoreFile != "" {
+		return ignoreFile
+	}
+	for _, name := range []string{".pulseignore", ".gitignore"} {
+		candidate := filepath.Join(dir, name)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return ""
+}
 
-		// Group events by type for summary
-		typeCounts := make(map[models.ChangeType]int)
-		for _, event := range events {
-			typeCounts[event.Type]++
+// pulsePointNewTarget checks that a folder pair's local folder exists and
+// builds its ignore rules from the shared patterns, its own patterns and its
+// ignore file
+func pulsePointNewTarget(syncPath *sync.SyncPath, ignoreFile string, sharedPatterns []string) (*pulseTarget, error) {
+	info, err := os.Stat(syncPath.Local)
+	if err != nil {
+		return nil, fmt.Errorf("path does not exist: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("path is not a directory: %s", syncPath.Local)
+	}
+
+	target := &pulseTarget{
+		path:       syncPath,
+		ignoreFile: pulsePointIgnoreFile(ignoreFile, syncPath.Local),
+		patterns:   append(append([]string{}, sharedPatterns...), syncPath.Ignore...),
+		ignore:     syncPath.IgnoreMatcher(sharedPatterns),
+	}
+	if target.ignoreFile != "" {
+		if err := target.ignore.LoadFromFile(target.ignoreFile); err != nil {
+			return nil, fmt.Errorf("failed to load ignore file: %w", err)
 		}
+	}
+	return target, nil
+}
+
+// pulsePointConnectTarget gives a folder pair its own state namespace,
+// strategy, batch syncer and reconciler. The pair's own strategy wins over
+// the one in options.
+func pulsePointConnectTarget(
+	target *pulseTarget,
+	options pulseTargetOptions,
+	provider interfaces.CloudProvider,
+	stateManager *sync.PulsePointStateManager,
+	resolver interfaces.ConflictResolver,
+	log *zap.Logger,
+) error {
+	target.state = stateManager.WithNamespace(target.path.Name, target.path.Local)
+
+	strategyName := options.strategy
+	if target.path.Strategy != "" {
+		strategyName = target.path.Strategy
+	}
+
+	strategy, err := createSyncStrategy(strategyName, options.conflict, options.hashAlgorithm, options.maxDeletePercent,
+		target.patterns, provider, target.state, resolver, log)
+	if err != nil {
+		return err
+	}
+	target.strategy = strategy
+	target.syncer = sync.NewPulsePointBatchSyncer(strategy, target.state, provider, target.path.Local, target.path.RemoteRoot(), log)
+
+	// Catches up on changes made while nothing was watching
+	target.reconciler = sync.NewPulsePointReconciler(provider, target.state, target.path.Local, target.path.RemoteRoot(), log, &sync.ReconcilerConfig{
+		IgnorePatterns: target.patterns,
+		IgnoreFile:     target.ignoreFile,
+		HashAlgorithm:  options.hashAlgorithm,
+		IncludeRemote:  strategy.Name() == "two-way",
+		Recursive:      target.path.Recursive,
+	})
+	return nil
+}
+
+// pulsePointKnownRemotePaths maps remote file IDs to remote paths from stored file state
+func pulsePointKnownRemotePaths(ctx context.Context, stateManager interfaces.StateManager) map[string]string {
+	paths := make(map[string]string)
+
+	states, err := stateManager.ListFileStates(ctx)
+	if err != nil {
+		return paths
+	}
 
-		// Print summary
-		parts := []string{}
-		if count := typeCounts[models.ChangeTypeCreate]; count > 0 {
-			parts = append(parts, fmt.Sprintf("%d created", count))
+	for _, state := range states {
+		if state.RemoteID == "" || state.Metadata == nil {
+			continue
 		}
-		if count := typeCounts[models.ChangeTypeModify]; count > 0 {
-			parts = append(parts, fmt.Sprintf("%d modified", count))
+		if remotePath, ok := state.Metadata["remote_path"].(string); ok {
+			paths[state.RemoteID] = remotePath
 		}
-		if count := typeCounts[models.ChangeTypeDelete]; count > 0 {
-			parts = append(parts, fmt.Sprintf("%d deleted", count))
+	}
+
+	return paths
+}
+
+// pulsePointCreateSyncHandler creates a sync handler function that routes each
+// batch of changes to the folder pairs they belong to
+func pulsePointCreateSyncHandler(ctx context.Context, zapLogger *zap.Logger, targets []*pulseTarget, dryRun bool) func([]*models.ChangeEvent) error {
+	return func(events []*models.ChangeEvent) error {
+		routed := pulsePointRouteEvents(zapLogger, targets, events)
+		for _, target := range targets {
+			if batch := routed[target]; len(batch) > 0 {
+				pulsePointSyncTarget(ctx, zapLogger, target, batch, dryRun)
+			}
 		}
-		if count := typeCounts[models.ChangeTypeRename]; count > 0 {
-			parts = append(parts, fmt.Sprintf("%d renamed", count))
+		return nil
+	}
+}
+
+// pulsePointRouteEvents splits a batch of changes between folder pairs. Changes
+// outside every pair, or ignored by the pair they fall in, are dropped.
+func pulsePointRouteEvents(zapLogger *zap.Logger, targets []*pulseTarget, events []*models.ChangeEvent) map[*pulseTarget][]*models.ChangeEvent {
+	paths := make([]*sync.SyncPath, len(targets))
+	byPath := make(map[*sync.SyncPath]*pulseTarget, len(targets))
+	for i, target := range targets {
+		paths[i] = target.path
+		byPath[target.path] = target
+	}
+
+	routed := make(map[*pulseTarget][]*models.ChangeEvent, len(targets))
+	for _, event := range events {
+		target := byPath[sync.MatchSyncPath(paths, event.Path, event.Source)]
+		if target == nil || !target.path.Contains(event.Path, event.Source) {
+			zapLogger.Debug("Change outside every sync path", zap.String("path", event.Path))
+			event.MarkProcessed()
+			continue
 		}
-		if count := typeCounts[models.ChangeTypeMove]; count > 0 {
-			parts = append(parts, fmt.Sprintf("%d moved", count))
+
+		rel, _ := target.path.Relative(event.Path, event.Source)
+		if target.ignore.ShouldIgnore(rel, event.IsDir) {
+			event.MarkProcessed()
+			continue
 		}
 
-		if len(parts) > 0 {
-			action := "Syncing"
-			if dryRun {
-				action = "Would sync"
-			}
-			fmt.Printf("[%s] 🔄 %s batch: %s\n", timestamp, action, strings.Join(parts, ", "))
-
-			// Log individual changes in verbose mode (only first few)
-			maxShow := 5
-			shown := 0
-			for _, event := range events {
-				if shown >= maxShow {
-					if len(events) > maxShow {
-						fmt.Printf("         ... and %d more changes\n", len(events)-maxShow)
-					}
-					break
-				}
+		routed[target] = append(routed[target], event)
+	}
+	return routed
+}
 
-				relPath := event.Path
-				if home := os.Getenv("HOME"); strings.HasPrefix(relPath, home) {
-					relPath = "~" + strings.TrimPrefix(relPath, home)
-				}
+// pulsePointSyncTarget syncs one folder pair's share of a batch. In dry-run
+// mode the changes are only printed.
+func pulsePointSyncTarget(ctx context.Context, zapLogger *zap.Logger, target *pulseTarget, events []*models.ChangeEvent, dryRun bool) {
+	timestamp := time.Now().Format("15:04:05")
 
-				emoji := "📄"
-				if event.IsDir {
-					emoji = "📁"
-				}
+	// Group events by type for summary
+	typeCounts := make(map[models.ChangeType]int)
+	for _, event := range events {
+		typeCounts[event.Type]++
+	}
+
+	// Print summary
+	parts := []string{}
+	if count := typeCounts[models.ChangeTypeCreate]; count > 0 {
+		parts = append(parts, fmt.Sprintf("%d created", count))
+	}
+	if count := typeCounts[models.ChangeTypeModify]; count > 0 {
+		parts = append(parts, fmt.Sprintf("%d modified", count))
+	}
+	if count := typeCounts[models.ChangeTypeDelete]; count > 0 {
+		parts = append(parts, fmt.Sprintf("%d deleted", count))
+	}
+	if count := typeCounts[models.ChangeTypeRename]; count > 0 {
+		parts = append(parts, fmt.Sprintf("%d renamed", count))
+	}
+	if count := typeCounts[models.ChangeTypeMove]; count > 0 {
+		parts = append(parts, fmt.Sprintf("%d moved", count))
+	}
+
+	if len(parts) > 0 {
+		action := "Syncing"
+		if dryRun {
+			action = "Would sync"
+		}
+		fmt.Printf("[%s] 🔄 %s %s: %s\n", timestamp, action, target.path.Name, strings.Join(parts, ", "))
 
-				var detail string
-				switch event.Type {
-				case models.ChangeTypeCreate:
-					detail = fmt.Sprintf("%s ➕ Created: %s", emoji, relPath)
-				case models.ChangeTypeModify:
-					detail = fmt.Sprintf("%s ✏️  Modified: %s", emoji, relPath)
-				case models.ChangeTypeDelete:
-					detail = fmt.Sprintf("%s 🗑️  Deleted: %s", emoji, relPath)
-				case models.ChangeTypeRename:
-					detail = fmt.Sprintf("%s 🔄 Renamed: %s", emoji, relPath)
-				case models.ChangeTypeMove:
-					detail = fmt.Sprintf("%s 📦 Moved: %s", emoji, relPath)
-				default:
-					detail = fmt.Sprintf("%s ❓ Changed: %s", emoji, relPath)
+		// Log individual changes in verbose mode (only first few)
+		maxShow := 5
+		shown := 0
+		for _, event := range events {
+			if shown >= maxShow {
+				if len(events) > maxShow {
+					fmt.Printf("         ... and %d more changes\n", len(events)-maxShow)
 				}
+				break
+			}
 
-				fmt.Printf("         %s\n", detail)
-				shown++
+			relPath := event.Path
+			if home := os.Getenv("HOME"); strings.HasPrefix(relPath, home) {
+				relPath = "~" + strings.TrimPrefix(relPath, home)
 			}
-		}
 
-		if !dryRun {
-			// TODO: Implement actual sync logic here
-			// This will integrate with CloudProvider interface in Phase 5
-			zapLogger.Info("Batch processed",
-				zap.Int("total_events", len(events)),
-				zap.String("remote_path", remotePath),
-			)
-
-			// Mark events as processed
-			for _, event := range events {
-				event.MarkProcessed()
+			emoji := "📄"
+			if event.IsDir {
+				emoji = "📁"
 			}
+
+			var detail string
+			switch event.Type {
+			case models.ChangeTypeCreate:
+				detail = fmt.Sprintf("%s ➕ Created: %s", emoji, relPath)
+			case models.ChangeTypeModify:
+				detail = fmt.Sprintf("%s ✏️  Modified: %s", emoji, relPath)
+			case models.ChangeTypeDelete:
+				detail = fmt.Sprintf("%s 🗑️  Deleted: %s", emoji, relPath)
+			case models.ChangeTypeRename:
+				detail = fmt.Sprintf("%s 🔄 Renamed: %s", emoji, relPath)
+			case models.ChangeTypeMove:
+				detail = fmt.Sprintf("%s 📦 Moved: %s", emoji, relPath)
+			default:
+				detail = fmt.Sprintf("%s ❓ Changed: %s", emoji, relPath)
+			}
+
+			fmt.Printf("         %s\n", detail)
+			shown++
 		}
+	}
 
-		return nil
+	if dryRun {
+		return
+	}
+
+	result, err := target.syncer.SyncBatch(ctx, events)
+	if err != nil {
+		// Events are marked failed; the watcher manager decides on retries
+		zapLogger.Error("Batch sync failed", zap.Error(err))
+		fmt.Printf("[%s] ❌ Sync failed: %v\n", time.Now().Format("15:04:05"), err)
+		return
+	}
+
+	zapLogger.Info("Batch processed",
+		zap.String("sync_path", target.path.Name),
+		zap.Int("total_events", len(events)),
+		zap.Int("uploaded", result.FilesUploaded),
+		zap.Int("deleted", result.FilesDeleted),
+		zap.Int("errors", len(result.Errors)),
+	)
+
+	fmt.Printf("[%s] ✅ Synced: %d uploaded, %d downloaded, %d deleted, %d skipped\n",
+		time.Now().Format("15:04:05"), result.FilesUploaded, result.FilesDownloaded, result.FilesDeleted, result.FilesSkipped)
+
+	for _, conflict := range result.Conflicts {
+		fmt.Printf("         ⚔️  Conflict: %s (%s)\n", conflict.Path, conflict.Resolution.Strategy)
+	}
+
+	for i, syncErr := range result.Errors {
+		if i >= 5 {
+			fmt.Printf("         ... and %d more failures\n", len(result.Errors)-5)
+			break
+		}
+		fmt.Printf("         ❌ %s: %s\n", syncErr.Path, syncErr.Message)
 	}
 }
@@ -66,6 +66,7 @@ func init() {
 	rootCmd.AddCommand(configCmd)
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(logsCmd)
+	rootCmd.AddCommand(conflictsCmd)
 }
 
 // initConfig reads in config file and ENV variables if set.
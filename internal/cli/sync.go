@@ -5,15 +5,20 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
 	"github.com/pulsepoint/pulsepoint/internal/database"
+	"github.com/pulsepoint/pulsepoint/internal/database/repositories"
 	"github.com/pulsepoint/pulsepoint/internal/strategies"
 	"github.com/pulsepoint/pulsepoint/internal/sync"
 	"github.com/pulsepoint/pulsepoint/internal/watchers/local"
 	pplogger "github.com/pulsepoint/pulsepoint/pkg/logger"
+	"github.com/pulsepoint/pulsepoint/pkg/models"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
 )
 
 // syncCmd represents the sync command for manual synchronization
@@ -21,11 +26,12 @@ var syncCmd = &cobra.Command{
 	Use:   "sync [path]",
 	Short: "Manually trigger a sync operation",
 	Long: `Perform a manual synchronization of the specified directory
-with your cloud storage provider.
+with your cloud storage provider. Without a directory, every enabled
+entry in the paths section of the config file is synced.
 
 Unlike 'pulse' which continuously monitors, 'sync' performs a 
 one-time synchronization and then exits.`,
-	Args: cobra.ExactArgs(1),
+	Args: cobra.MaximumNArgs(1),
 	RunE: runSync,
 }
 
@@ -34,28 +40,67 @@ func init() {
 	syncCmd.Flags().Bool("force", false, "Force sync even if no changes detected")
 	syncCmd.Flags().Bool("full", false, "Perform full sync instead of incremental")
 	syncCmd.Flags().Bool("dry-run", false, "Show what would be synced without actually syncing")
-	syncCmd.Flags().String("strategy", "one-way", "Sync strategy: one-way, mirror, or backup")
-	syncCmd.Flags().String("conflict", "keep-local", "Conflict resolution: keep-local, keep-remote, keep-both, skip")
+	syncCmd.Flags().String("strategy", "one-way", "Sync strategy: one-way, two-way, mirror, or backup")
+	syncCmd.Flags().String("conflict", "keep-local", "Conflict resolution: keep-local, keep-remote, keep-both, merge, interactive, skip")
+	syncCmd.Flags().String("conflict-default", "skip", "Conflict resolution used when an interactive conflict gets no answer")
+	syncCmd.Flags().Duration("conflict-timeout", 5*time.Minute, "How long to wait for an interactive answer before using --conflict-default")
+	syncCmd.Flags().Int("max-delete-percent", 50, "Largest share of remote files a mirror cleanup may delete")
+	syncCmd.Flags().String("hash", "sha256", "Hash algorithm to use (md5 or sha256)")
 	syncCmd.Flags().Int("workers", 4, "Number of concurrent workers")
 }
 
 func runSync(cmd *cobra.Command, args []string) error {
-	localPath := args[0]
 	remotePath, _ := cmd.Flags().GetString("remote")
 	force, _ := cmd.Flags().GetBool("force")
 	full, _ := cmd.Flags().GetBool("full")
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
 	strategyName, _ := cmd.Flags().GetString("strategy")
 	conflictRes, _ := cmd.Flags().GetString("conflict")
+	conflictTimeout, _ := cmd.Flags().GetDuration("conflict-timeout")
 	workers, _ := cmd.Flags().GetInt("workers")
 
 	log := pplogger.Get()
 
-	fmt.Printf("🔄 Starting PulsePoint Sync Operation\n")
-	fmt.Printf("📁 Local Path: %s\n", localPath)
+	conflictFallback, err := conflictDefault(cmd)
+	if err != nil {
+		return err
+	}
+	deleteLimit, err := maxDeletePercent(cmd)
+	if err != nil {
+		return err
+	}
+	hashAlgorithm, err := localHashAlgorithm(cmd)
+	if err != nil {
+		return err
+	}
+
+	syncPaths, err := syncPathsFromArgs(args, remotePath)
+	if err != nil {
+		return err
+	}
+
+	// Folder pairs are set up the same way pulse sets them up
+	var targets []*pulseTarget
+	for i := range syncPaths {
+		if !syncPaths[i].Enabled {
+			continue
+		}
+		target, err := pulsePointNewTarget(&syncPaths[i], "", sharedIgnorePatterns())
+		if err != nil {
+			return err
+		}
+		targets = append(targets, target)
+	}
 
-	if remotePath != "" {
-		fmt.Printf("☁️  Remote Path: %s\n", remotePath)
+	fmt.Printf("🔄 Starting PulsePoint Sync Operation\n")
+	for _, syncPath := range syncPaths {
+		if !syncPath.Enabled {
+			continue
+		}
+		fmt.Printf("📁 %s: %s → %s\n", syncPath.Name, syncPath.Local, syncPath.RemoteRoot())
+		if syncPath.Strategy != "" {
+			fmt.Printf("   🎯 Strategy: %s\n", syncPath.Strategy)
+		}
 	}
 
 	fmt.Printf("🎯 Strategy: %s\n", strategyName)
@@ -100,37 +145,35 @@ func runSync(cmd *cobra.Command, args []string) error {
 	}
 
 	// Initialize file watcher
-	watcher, err := local.NewPulsePointWatcher(100*time.Millisecond, "sha256")
+	watcher, err := local.NewPulsePointWatcher(100*time.Millisecond, hashAlgorithm)
 	if err != nil {
 		return fmt.Errorf("failed to create file watcher: %w", err)
 	}
 
-	// Create sync strategy
-	var strategy interfaces.SyncStrategy
-	strategyConfig := &interfaces.StrategyConfig{
-		ConflictResolution: parseConflictResolution(conflictRes),
-		MaxFileSize:        100 * 1024 * 1024, // 100MB limit
-	}
-
-	switch strategyName {
-	case "one-way":
-		strategy = strategies.NewPulsePointOneWayStrategy(provider, log, strategyConfig)
-	case "mirror":
-		strategy = strategies.NewPulsePointMirrorStrategy(provider, log, strategyConfig)
-	case "backup":
-		strategy = strategies.NewPulsePointBackupStrategy(provider, log, strategyConfig)
-	default:
-		return fmt.Errorf("unknown strategy: %s", strategyName)
-	}
-
 	// Create state manager
 	stateManager := sync.NewPulsePointStateManager(db, log, nil)
 	if err := stateManager.Initialize(getDBPath()); err != nil {
 		return fmt.Errorf("failed to initialize state manager: %w", err)
 	}
+	stateManager.SetBaseStore(sync.NewPulsePointBaseStore(getBaseStoreDir(), 0, log))
+
+	// Each pair gets its own strategy and state namespace
+	resolver := createConflictResolver(conflictRes, conflictFallback, conflictTimeout, provider, db, false, stateManager, log)
+	options := pulseTargetOptions{
+		strategy:         strategyName,
+		conflict:         conflictRes,
+		hashAlgorithm:    hashAlgorithm,
+		maxDeletePercent: deleteLimit,
+	}
+	for _, target := range targets {
+		if err := pulsePointConnectTarget(target, options, provider, stateManager, resolver, log); err != nil {
+			return err
+		}
+	}
 
 	// Create sync engine configuration
 	engineConfig := &sync.EngineConfig{
+		Paths:              syncPaths,
 		SyncInterval:       5 * time.Minute,
 		BatchSize:          50,
 		MaxConcurrent:      workers,
@@ -138,14 +181,15 @@ func runSync(cmd *cobra.Command, args []string) error {
 		RetryDelay:         5 * time.Second,
 		ConflictResolution: conflictRes,
 		MaxFileSize:        100 * 1024 * 1024,
-		IgnorePatterns:     []string{".git", "node_modules", "*.tmp"},
+		IgnorePatterns:     sharedIgnorePatterns(),
 	}
 
-	// Create sync engine
+	// Create sync engine. Every pair syncs with its own strategy, so the
+	// engine's own is never used.
 	engine, err := sync.NewPulsePointEngine(
 		provider,
 		watcher,
-		strategy,
+		targets[0].strategy,
 		stateManager,
 		db,
 		engineConfig,
@@ -153,6 +197,10 @@ func runSync(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to create sync engine: %w", err)
 	}
+	for _, target := range targets {
+		engine.SetPathStrategy(target.path.Local, target.strategy, target.state)
+		engine.SetIgnoreMatcher(target.path.Local, target.ignore)
+	}
 
 	// Start the engine
 	if err := engine.Start(ctx); err != nil {
@@ -168,12 +216,43 @@ func runSync(cmd *cobra.Command, args []string) error {
 	// Perform sync
 	startTime := time.Now()
 
+	// A full sync compares both trees with the stored state, on top of
+	// the changes already queued
+	var reconciled []*models.ChangeEvent
+	if full {
+		for _, target := range targets {
+			events, stats, err := target.reconciler.Scan(ctx)
+			if err != nil {
+				return fmt.Errorf("reconciliation of %s failed: %w", target.path.Name, err)
+			}
+			reconciled = append(reconciled, events...)
+			if !dryRun {
+				if err := engine.Enqueue(events); err != nil {
+					return fmt.Errorf("failed to queue changes: %w", err)
+				}
+			}
+
+			fmt.Printf("   %s: %d new, %d modified and %d deleted files\n", target.path.Name, stats.Created, stats.Modified, stats.Deleted)
+		}
+	}
+
 	if !dryRun {
 		result, err := engine.Sync(ctx)
 		if err != nil {
 			return fmt.Errorf("sync failed: %w", err)
 		}
 
+		// Only a full sync walks the remote tree for files deleted locally
+		if full {
+			cleanup, err := engine.CleanupRemote(ctx)
+			if err != nil {
+				return fmt.Errorf("remote cleanup failed: %w", err)
+			}
+			result.FilesDeleted += cleanup.FilesDeleted
+			result.Errors = append(result.Errors, cleanup.Errors...)
+			result.Success = result.Success && cleanup.Success
+		}
+
 		duration := time.Since(startTime)
 
 		fmt.Printf("\n")
@@ -215,6 +294,11 @@ func runSync(cmd *cobra.Command, args []string) error {
 		}
 
 		fmt.Printf("\n📝 DRY RUN Results:\n")
+		if full {
+			for _, event := range reconciled {
+				fmt.Printf("   Would %s %s (%s)\n", event.Type, event.Path, event.Source)
+			}
+		}
 		fmt.Printf("   Would process %d files\n", status.State.TotalFiles)
 		fmt.Printf("   Total size: %.2f MB\n", float64(status.State.TotalBytes)/(1024*1024))
 	}
@@ -222,6 +306,87 @@ func runSync(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// syncPathsFromArgs returns the folder pair given on the command line, or the
+// configured ones when there is none
+func syncPathsFromArgs(args []string, remotePath string) ([]sync.SyncPath, error) {
+	if len(args) == 0 && remotePath != "" {
+		// Configured pairs each name their own remote folder
+		return nil, fmt.Errorf("--remote needs a folder argument")
+	}
+	if len(args) == 1 {
+		// File state is keyed by absolute local path
+		localPath, err := filepath.Abs(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid path: %w", err)
+		}
+		return []sync.SyncPath{{
+			Name:      filepath.Base(localPath),
+			Local:     localPath,
+			Remote:    remotePath,
+			Recursive: true,
+			Enabled:   true,
+		}}, nil
+	}
+
+	syncPaths, err := loadSyncPaths()
+	if err != nil {
+		return nil, err
+	}
+	for _, syncPath := range syncPaths {
+		if syncPath.Enabled {
+			return syncPaths, nil
+		}
+	}
+	return nil, fmt.Errorf("no folder given and no enabled paths in the config file")
+}
+
+// syncStrategies are the strategies a folder pair can sync with
+var syncStrategies = map[string]bool{
+	"one-way": true,
+	"two-way": true,
+	"mirror":  true,
+	"backup":  true,
+}
+
+// createSyncStrategy creates the named sync strategy for a provider
+func createSyncStrategy(
+	name, conflictRes, hashAlgorithm string,
+	maxDeletePercent int,
+	ignorePatterns []string,
+	provider interfaces.CloudProvider,
+	stateManager interfaces.StateManager,
+	resolver interfaces.ConflictResolver,
+	log *zap.Logger,
+) (interfaces.SyncStrategy, error) {
+	strategyConfig := &interfaces.StrategyConfig{
+		ConflictResolution: parseConflictResolution(conflictRes),
+		IgnorePatterns:     ignorePatterns,
+		MaxFileSize:        100 * 1024 * 1024, // 100MB limit
+		MaxDeletePercent:   maxDeletePercent,
+		CustomSettings: map[string]interface{}{
+			// Local hashes the strategy records must match the watcher's
+			"hash_algorithm": hashAlgorithm,
+		},
+	}
+
+	switch name {
+	case "one-way":
+		return strategies.NewPulsePointOneWayStrategy(provider, log, strategyConfig), nil
+	case "two-way":
+		strategy := strategies.NewPulsePointTwoWayStrategy(provider, stateManager, log, strategyConfig)
+		if resolver != nil {
+			strategy.SetConflictResolver(resolver)
+		}
+		return strategy, nil
+	case "mirror":
+		return strategies.NewPulsePointMirrorStrategy(provider, log, strategyConfig), nil
+	case "backup":
+		return strategies.NewPulsePointBackupStrategy(provider, log, strategyConfig), nil
+	default:
+		return nil, fmt.Errorf("unknown strategy: %s", name)
+	}
+}
+
 // parseConflictResolution converts string to ResolutionStrategy
 func parseConflictResolution(resolution string) interfaces.ResolutionStrategy {
 	switch resolution {
@@ -231,6 +396,10 @@ func parseConflictResolution(resolution string) interfaces.ResolutionStrategy {
 		return interfaces.ResolutionKeepRemote
 	case "keep-both":
 		return interfaces.ResolutionKeepBoth
+	case "merge":
+		return interfaces.ResolutionMerge
+	case "interactive":
+		return interfaces.ResolutionInteractive
 	case "skip":
 		return interfaces.ResolutionSkip
 	default:
@@ -238,8 +407,102 @@ func parseConflictResolution(resolution string) interfaces.ResolutionStrategy {
 	}
 }
 
+// conflictDefault returns the resolution used when an interactive conflict is
+// not answered in time, from --conflict-default or pulse.conflict_default
+func conflictDefault(cmd *cobra.Command) (string, error) {
+	viper.BindPFlag("pulse.conflict_default", cmd.Flags().Lookup("conflict-default"))
+	value := strings.ReplaceAll(viper.GetString("pulse.conflict_default"), "_", "-")
+
+	switch value {
+	case "keep-local", "keep-remote", "keep-both", "merge", "skip":
+		return value, nil
+	case "interactive":
+		return "", fmt.Errorf("conflict default cannot be interactive")
+	default:
+		return "", fmt.Errorf("unknown conflict default: %s", value)
+	}
+}
+
+// maxDeletePercent returns the largest share of remote files a mirror cleanup
+// may delete, from --max-delete-percent or pulse.max_delete_percent
+func maxDeletePercent(cmd *cobra.Command) (int, error) {
+	viper.BindPFlag("pulse.max_delete_percent", cmd.Flags().Lookup("max-delete-percent"))
+	value := viper.GetInt("pulse.max_delete_percent")
+	if value < 1 || value > 100 {
+		return 0, fmt.Errorf("max delete percent must be between 1 and 100, got %d", value)
+	}
+	return value, nil
+}
+
+// localHashAlgorithm returns the algorithm local files are hashed with, from
+// --hash or files.hash_algorithm. The provider factory reads the same setting.
+// Commands without --hash default to sha256 like the flag does.
+func localHashAlgorithm(cmd *cobra.Command) (string, error) {
+	if flag := cmd.Flags().Lookup("hash"); flag != nil {
+		viper.BindPFlag("files.hash_algorithm", flag)
+	}
+	value := viper.GetString("files.hash_algorithm")
+
+	switch value {
+	case "":
+		return "sha256", nil
+	case "md5", "sha256":
+		return value, nil
+	default:
+		return "", fmt.Errorf("unknown hash algorithm: %s", value)
+	}
+}
+
+// createConflictResolver creates the resolver for two-way conflicts. Versions
+// that lose a conflict are kept in the conflict backup directory, and conflicts
+// left unresolved are recorded in db for the conflicts command. Interactive
+// conflicts are prompted for on the terminal, or wait in db when headless;
+// unanswered prompts fall back to conflictDefault.
+func createConflictResolver(
+	conflictRes, conflictDefault string,
+	timeout time.Duration,
+	provider interfaces.CloudProvider,
+	db *database.Manager,
+	headless bool,
+	stateManager *sync.PulsePointStateManager,
+	log *zap.Logger,
+) *sync.PulsePointConflictResolver {
+	strategy := parseConflictResolution(conflictRes)
+
+	resolver := sync.NewPulsePointConflictResolver(log, strategy, &sync.ConflictResolverConfig{
+		DefaultStrategy:    parseConflictResolution(conflictDefault),
+		BackupConflicts:    true,
+		BackupDir:          getConflictBackupDir(),
+		MergeTextFiles:     true,
+		InteractiveTimeout: timeout,
+	})
+	resolver.SetProvider(provider)
+	resolver.SetBaseStore(stateManager.BaseStore())
+
+	if db != nil {
+		resolver.SetConflictRepository(repositories.NewConflictRepository(db))
+	}
+	if strategy == interfaces.ResolutionInteractive && !headless {
+		resolver.SetPrompter(sync.NewPulsePointTerminalPrompter(os.Stdin, os.Stdout))
+	}
+
+	return resolver
+}
+
 // getDBPath returns the database path
 func getDBPath() string {
 	home, _ := os.UserHomeDir()
 	return filepath.Join(home, ".pulsepoint", "pulsepoint.db")
 }
+
+// getConflictBackupDir returns the directory holding versions overwritten by conflict resolution
+func getConflictBackupDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".pulsepoint", "backups", "conflicts")
+}
+
+// getBaseStoreDir returns the directory holding merge bases
+func getBaseStoreDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".pulsepoint", "cache", "bases")
+}
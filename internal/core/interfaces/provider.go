@@ -15,7 +15,8 @@ type CloudProvider interface {
 	// Upload uploads a file to the cloud storage
 	Upload(ctx context.Context, file *File) error
 
-	// Download downloads a file from the cloud storage
+	// Download downloads a file from the cloud storage. The content may
+	// stream from the provider; callers close it when it is an io.Closer.
 	Download(ctx context.Context, path string) (*File, error)
 
 	// Delete deletes a file from the cloud storage
@@ -46,6 +47,41 @@ type CloudProvider interface {
 	Disconnect() error
 }
 
+// RemoteChangeFeed is implemented by providers that can report remote changes incrementally
+type RemoteChangeFeed interface {
+	// GetStartPageToken returns a token for the current position in the change log
+	GetStartPageToken(ctx context.Context) (string, error)
+
+	// ListChanges returns one page of changes made since the given token
+	ListChanges(ctx context.Context, pageToken string) (*RemoteChangePage, error)
+}
+
+// FileDownloader is implemented by providers that can download straight to a
+// local file, continuing an interrupted download instead of starting again
+type FileDownloader interface {
+	// DownloadFile writes a remote file to localPath, replacing it only once
+	// the content is complete and verified
+	DownloadFile(ctx context.Context, path, localPath string) (*File, error)
+}
+
+// RemoteChangePage is one page of remote changes
+type RemoteChangePage struct {
+	Changes           []RemoteChange `json:"changes"`
+	NextPageToken     string         `json:"next_page_token,omitempty"`      // Set while more pages remain
+	NewStartPageToken string         `json:"new_start_page_token,omitempty"` // Set on the last page
+}
+
+// RemoteChange describes a single change to a remote file
+type RemoteChange struct {
+	FileID       string    `json:"file_id"`
+	Path         string    `json:"path,omitempty"` // Empty when the file is gone or outside the root
+	Removed      bool      `json:"removed"`
+	IsFolder     bool      `json:"is_folder"`
+	Size         int64     `json:"size"`
+	Hash         string    `json:"hash,omitempty"`
+	ModifiedTime time.Time `json:"modified_time"`
+}
+
 // ProviderConfig holds configuration for a cloud provider
 type ProviderConfig struct {
 	Type        string                 `json:"type"`
@@ -55,20 +91,21 @@ type ProviderConfig struct {
 
 // File represents a file in the system
 type File struct {
-	ID           string    `json:"id"`
-	Path         string    `json:"path"`
-	Name         string    `json:"name"`
-	Size         int64     `json:"size"`
-	Hash         string    `json:"hash"`
-	MimeType     string    `json:"mime_type"`
-	ModifiedTime time.Time `json:"modified_time"`
-	CreatedTime  time.Time `json:"created_time"`
-	IsFolder     bool      `json:"is_folder"`
-	Content      io.Reader `json:"-"`
-	LocalPath    string    `json:"local_path,omitempty"`
-	RemoteID     string    `json:"remote_id,omitempty"`
-	ParentID     string    `json:"parent_id,omitempty"`
-	Permissions  string    `json:"permissions,omitempty"`
+	ID           string                 `json:"id"`
+	Path         string                 `json:"path"`
+	Name         string                 `json:"name"`
+	Size         int64                  `json:"size"`
+	Hash         string                 `json:"hash"`
+	MimeType     string                 `json:"mime_type"`
+	ModifiedTime time.Time              `json:"modified_time"`
+	CreatedTime  time.Time              `json:"created_time"`
+	IsFolder     bool                   `json:"is_folder"`
+	Content      io.Reader              `json:"-"`
+	LocalPath    string                 `json:"local_path,omitempty"`
+	RemoteID     string                 `json:"remote_id,omitempty"`
+	ParentID     string                 `json:"parent_id,omitempty"`
+	Permissions  string                 `json:"permissions,omitempty"`
+	Attributes   map[string]interface{} `json:"attributes,omitempty"`
 }
 
 // Metadata represents file metadata
@@ -86,6 +123,17 @@ type Metadata struct {
 	Owner        string                 `json:"owner,omitempty"`
 }
 
+// Attributes describing the local file a remote file was uploaded from, set
+// in Metadata.Attributes and File.Attributes by providers that can store
+// PulsePoint's own metadata with a file
+const (
+	AttributeLocalHash         = "localHash"         // Hash of the local file, string
+	AttributeHashAlgorithm     = "hashAlgorithm"     // Algorithm of the local hash, "md5" or "sha256"
+	AttributeLocalModifiedTime = "localModifiedTime" // Modification time of the local file, time.Time
+	AttributeMode              = "mode"              // Permission bits in octal, e.g. "0644"
+	AttributeDeviceID          = "deviceID"          // Device that uploaded the file, string
+)
+
 // QuotaInfo represents storage quota information
 type QuotaInfo struct {
 	Used      int64 `json:"used"`
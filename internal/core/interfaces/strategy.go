@@ -68,12 +68,18 @@ type SyncError struct {
 	Timestamp int64  `json:"timestamp"`
 }
 
+// ConflictResolver decides how a sync conflict is resolved
+type ConflictResolver interface {
+	ResolveConflict(ctx context.Context, conflict *Conflict) (*ConflictResolution, error)
+}
+
 // Conflict represents a sync conflict
 type Conflict struct {
 	Path       string             `json:"path"`
 	Type       ConflictType       `json:"type"`
 	LocalFile  *File              `json:"local_file"`
 	RemoteFile *File              `json:"remote_file"`
+	BaseFile   *File              `json:"base_file,omitempty"` // Common ancestor for three-way merge
 	DetectedAt int64              `json:"detected_at"`
 	Resolution ConflictResolution `json:"resolution,omitempty"`
 }
@@ -98,11 +104,16 @@ const (
 // ConflictResolution represents how a conflict was resolved
 type ConflictResolution struct {
 	Strategy     ResolutionStrategy `json:"strategy"`
-	Winner       string             `json:"winner,omitempty"` // "local" or "remote"
+	Winner       string             `json:"winner,omitempty"` // "local", "remote", or "merged"
 	ResolvedPath string             `json:"resolved_path,omitempty"`
 	BackupPath   string             `json:"backup_path,omitempty"`
+	MergedPath   string             `json:"merged_path,omitempty"`
 	ResolvedAt   int64              `json:"resolved_at"`
 	Manual       bool               `json:"manual"`
+
+	// Merge information (for merge strategy)
+	MergeBase      string   `json:"merge_base,omitempty"`
+	MergeConflicts []string `json:"merge_conflicts,omitempty"`
 }
 
 // ResolutionStrategy defines how to resolve conflicts
@@ -134,6 +145,7 @@ type StrategyConfig struct {
 	IgnorePatterns     []string               `json:"ignore_patterns"`
 	MaxFileSize        int64                  `json:"max_file_size"`
 	PreserveDeleted    bool                   `json:"preserve_deleted"`
+	MaxDeletePercent   int                    `json:"max_delete_percent"` // Largest share of remote files a cleanup may delete
 	VersionControl     bool                   `json:"version_control"`
 	CustomSettings     map[string]interface{} `json:"custom_settings,omitempty"`
 }
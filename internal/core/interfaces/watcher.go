@@ -43,6 +43,7 @@ type ChangeEvent struct {
 	Size      int64      `json:"size,omitempty"`
 	Hash      string     `json:"hash,omitempty"`
 	IsDir     bool       `json:"is_dir"`
+	Source    string     `json:"source,omitempty"` // "local" (default) or "remote"
 	Error     error      `json:"-"`
 }
 
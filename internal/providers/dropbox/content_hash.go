@@ -0,0 +1,64 @@
+package dropbox
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+)
+
+// contentHashBlockSize is the block size of the Dropbox content hash
+const contentHashBlockSize = 4 * 1024 * 1024
+
+// contentHasher computes the Dropbox content_hash: the SHA-256 of the
+// concatenated SHA-256 digests of each 4MB block
+type contentHasher struct {
+	overall  hash.Hash
+	block    hash.Hash
+	blockLen int
+}
+
+func newContentHasher() *contentHasher {
+	return &contentHasher{overall: sha256.New(), block: sha256.New()}
+}
+
+// Write adds data to the hash
+func (h *contentHasher) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		chunk := contentHashBlockSize - h.blockLen
+		if chunk > len(p) {
+			chunk = len(p)
+		}
+		h.block.Write(p[:chunk])
+		h.blockLen += chunk
+		p = p[chunk:]
+
+		if h.blockLen == contentHashBlockSize {
+			h.overall.Write(h.block.Sum(nil))
+			h.block.Reset()
+			h.blockLen = 0
+		}
+	}
+	return n, nil
+}
+
+// hexDigest finishes the hash and returns it hex encoded
+func (h *contentHasher) hexDigest() string {
+	if h.blockLen > 0 {
+		h.overall.Write(h.block.Sum(nil))
+		h.block.Reset()
+		h.blockLen = 0
+	}
+	return hex.EncodeToString(h.overall.Sum(nil))
+}
+
+// ContentHash computes the Dropbox content_hash of r, for comparing local
+// files with Metadata.Hash
+func ContentHash(r io.Reader) (string, error) {
+	h := newContentHasher()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return h.hexDigest(), nil
+}
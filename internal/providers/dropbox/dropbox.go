@@ -0,0 +1,675 @@
+// Package dropbox implements a Dropbox provider for PulsePoint using the Dropbox HTTP API
+package dropbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf16"
+
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	pperrors "github.com/pulsepoint/pulsepoint/pkg/errors"
+	pplogger "github.com/pulsepoint/pulsepoint/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// Provider name
+	providerName = "dropbox"
+
+	// MIME type reported for folders
+	mimeTypeFolder = "application/x-directory"
+
+	// Default API endpoints
+	defaultAPIURL     = "https://api.dropboxapi.com/2"
+	defaultContentURL = "https://content.dropboxapi.com/2"
+
+	// Default upload chunk size. Files larger than one chunk use an upload session.
+	defaultChunkSize = 8 * 1024 * 1024
+
+	// maxChunkSize is the largest request body Dropbox accepts for uploads
+	maxChunkSize = 150 * 1024 * 1024
+
+	// listLimit is the page size requested from list_folder
+	listLimit = 2000
+
+	// Dropbox timestamps are UTC without fractional seconds
+	timeFormat = "2006-01-02T15:04:05Z"
+)
+
+// PulsePointDropboxProvider implements CloudProvider for Dropbox
+type PulsePointDropboxProvider struct {
+	config *Config
+	auth   interfaces.AuthProvider
+	client *http.Client
+	logger *zap.Logger
+
+	// token caches the access token between requests
+	tokenMu sync.Mutex
+	token   *interfaces.AuthToken
+}
+
+// Config holds Dropbox configuration
+type Config struct {
+	Root       string       `json:"root"`       // Folder that acts as the remote root, e.g. /PulsePoint
+	ChunkSize  int64        `json:"chunk_size"` // Upload session chunk size, a multiple of 4MB
+	APIURL     string       `json:"-"`          // Overrides the API endpoints, mainly for tests
+	ContentURL string       `json:"-"`
+	HTTPClient *http.Client `json:"-"`
+}
+
+// NewPulsePointDropboxProvider creates a new Dropbox provider that gets its
+// access tokens from auth
+func NewPulsePointDropboxProvider(config *Config, auth interfaces.AuthProvider) (*PulsePointDropboxProvider, error) {
+	if config == nil {
+		return nil, pperrors.NewConfigError("Dropbox configuration is required", nil)
+	}
+	if auth == nil {
+		return nil, pperrors.NewConfigError("Dropbox authentication is required", nil)
+	}
+
+	if config.ChunkSize == 0 {
+		config.ChunkSize = defaultChunkSize
+	}
+	if config.ChunkSize%contentHashBlockSize != 0 || config.ChunkSize > maxChunkSize {
+		return nil, pperrors.NewConfigError("Dropbox chunk size must be a multiple of 4MB up to 150MB", nil)
+	}
+	if config.APIURL == "" {
+		config.APIURL = defaultAPIURL
+	}
+	if config.ContentURL == "" {
+		config.ContentURL = defaultContentURL
+	}
+
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{}
+	}
+
+	provider := &PulsePointDropboxProvider{
+		config: config,
+		auth:   auth,
+		client: client,
+		logger: pplogger.Get(),
+	}
+
+	provider.logger.Info("Dropbox provider initialized", zap.String("root", config.Root))
+
+	return provider, nil
+}
+
+// Initialize initializes the provider with configuration
+func (p *PulsePointDropboxProvider) Initialize(config interfaces.ProviderConfig) error {
+	// Already initialized in constructor
+	return nil
+}
+
+// Upload uploads a file. Anything larger than one chunk goes through an
+// upload session, and the content hash Dropbox reports is checked against
+// the bytes sent.
+func (p *PulsePointDropboxProvider) Upload(ctx context.Context, file *interfaces.File) error {
+	if file == nil {
+		return pperrors.NewValidationError("file is nil", nil)
+	}
+
+	p.logger.Debug("Uploading file to Dropbox",
+		zap.String("path", file.Path),
+		zap.Int64("size", file.Size))
+
+	// Prefer in-memory content, otherwise read from the local file
+	reader := file.Content
+	if reader == nil {
+		f, err := os.Open(file.LocalPath)
+		if err != nil {
+			return fmt.Errorf("failed to open local file: %w", err)
+		}
+		defer f.Close()
+		reader = f
+	}
+
+	hasher := newContentHasher()
+	reader = io.TeeReader(reader, hasher)
+
+	commit := commitInfo{
+		Path: p.apiPath(file.Path),
+		Mode: "overwrite",
+		Mute: true,
+	}
+	if !file.ModifiedTime.IsZero() {
+		commit.ClientModified = file.ModifiedTime.UTC().Format(timeFormat)
+	}
+
+	chunk := make([]byte, p.config.ChunkSize)
+	n, err := io.ReadFull(reader, chunk)
+	var result entry
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		err = p.content(ctx, "files/upload", file.Path, commit, chunk[:n], &result)
+	case err != nil:
+		return fmt.Errorf("failed to read file: %w", err)
+	default:
+		err = p.uploadSession(ctx, file.Path, reader, chunk, commit, &result)
+	}
+	if err != nil {
+		return err
+	}
+
+	if local := hasher.hexDigest(); result.ContentHash != "" && result.ContentHash != local {
+		return pperrors.NewRetryable(pperrors.ProviderError,
+			fmt.Sprintf("content hash mismatch for %s: sent %s, stored %s", file.Path, local, result.ContentHash), nil)
+	}
+
+	p.logger.Info("File uploaded successfully",
+		zap.String("path", file.Path),
+		zap.Int64("size", result.Size))
+	return nil
+}
+
+// uploadSession uploads a file in chunks. first is a full chunk already read
+// from reader; the final, possibly empty, chunk is sent with the commit.
+func (p *PulsePointDropboxProvider) uploadSession(
+	ctx context.Context,
+	remotePath string,
+	reader io.Reader,
+	first []byte,
+	commit commitInfo,
+	result *entry,
+) error {
+	var session struct {
+		SessionID string `json:"session_id"`
+	}
+	if err := p.content(ctx, "files/upload_session/start", remotePath, map[string]bool{"close": false}, first, &session); err != nil {
+		return err
+	}
+
+	cursor := uploadCursor{SessionID: session.SessionID, Offset: int64(len(first))}
+	chunk := first
+	for {
+		n, err := io.ReadFull(reader, chunk)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			finish := map[string]interface{}{"cursor": cursor, "commit": commit}
+			return p.content(ctx, "files/upload_session/finish", remotePath, finish, chunk[:n], result)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+
+		appendArg := map[string]interface{}{"cursor": cursor, "close": false}
+		if err := p.content(ctx, "files/upload_session/append_v2", remotePath, appendArg, chunk[:n], nil); err != nil {
+			return err
+		}
+		cursor.Offset += int64(n)
+
+		p.logger.Debug("Uploaded chunk",
+			zap.String("path", remotePath),
+			zap.Int64("offset", cursor.Offset))
+	}
+}
+
+// Download streams a file from Dropbox. The caller must close the returned content.
+func (p *PulsePointDropboxProvider) Download(ctx context.Context, remotePath string) (*interfaces.File, error) {
+	p.logger.Debug("Downloading file from Dropbox", zap.String("path", remotePath))
+
+	resp, err := p.send(ctx, p.config.ContentURL+"/files/download", remotePath, map[string]string{"path": p.apiPath(remotePath)}, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+
+	var result entry
+	if err := json.Unmarshal([]byte(resp.Header.Get("Dropbox-API-Result")), &result); err != nil {
+		resp.Body.Close()
+		return nil, pperrors.NewProviderError("failed to decode download metadata", err)
+	}
+
+	meta := p.metadata(&result)
+	return &interfaces.File{
+		ID:           meta.ID,
+		Path:         cleanPath(remotePath),
+		Name:         path.Base(cleanPath(remotePath)),
+		Size:         meta.Size,
+		Hash:         meta.Hash,
+		ModifiedTime: meta.ModifiedTime,
+		Content:      resp.Body,
+	}, nil
+}
+
+// Delete deletes a file, or a folder and everything in it. Deleting a
+// missing path succeeds.
+func (p *PulsePointDropboxProvider) Delete(ctx context.Context, remotePath string) error {
+	p.logger.Debug("Deleting file from Dropbox", zap.String("path", remotePath))
+
+	if p.apiPath(remotePath) == "" {
+		return pperrors.NewValidationError("refusing to delete the root folder", nil)
+	}
+	err := p.rpc(ctx, "files/delete_v2", remotePath, map[string]string{"path": p.apiPath(remotePath)}, nil)
+	if pperrors.IsNotFoundError(err) {
+		p.logger.Debug("File already deleted", zap.String("path", remotePath))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("delete failed: %w", err)
+	}
+
+	p.logger.Info("File deleted successfully", zap.String("path", remotePath))
+	return nil
+}
+
+// List lists the files and folders directly inside a folder, following
+// list_folder/continue cursors until every page is read
+func (p *PulsePointDropboxProvider) List(ctx context.Context, folder string) ([]*interfaces.File, error) {
+	p.logger.Debug("Listing files in folder", zap.String("folder", folder))
+
+	var page listFolderResult
+	err := p.rpc(ctx, "files/list_folder", folder, map[string]interface{}{
+		"path":  p.apiPath(folder),
+		"limit": listLimit,
+	}, &page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	var files []*interfaces.File
+	for {
+		for i := range page.Entries {
+			meta := p.metadata(&page.Entries[i])
+			files = append(files, &interfaces.File{
+				ID:           meta.ID,
+				Path:         meta.Path,
+				Name:         page.Entries[i].Name,
+				Size:         meta.Size,
+				Hash:         meta.Hash,
+				MimeType:     meta.MimeType,
+				ModifiedTime: meta.ModifiedTime,
+				IsFolder:     meta.IsFolder,
+			})
+		}
+		if !page.HasMore {
+			return files, nil
+		}
+
+		cursor := page.Cursor
+		page = listFolderResult{}
+		if err := p.rpc(ctx, "files/list_folder/continue", folder, map[string]string{"cursor": cursor}, &page); err != nil {
+			return nil, fmt.Errorf("failed to list files: %w", err)
+		}
+	}
+}
+
+// GetMetadata gets file metadata. Hash is the Dropbox content_hash.
+func (p *PulsePointDropboxProvider) GetMetadata(ctx context.Context, remotePath string) (*interfaces.Metadata, error) {
+	p.logger.Debug("Getting file metadata", zap.String("path", remotePath))
+
+	// The API has no metadata for the root folder itself
+	if p.apiPath(remotePath) == "" {
+		return &interfaces.Metadata{Path: "/", IsFolder: true, MimeType: mimeTypeFolder}, nil
+	}
+
+	var result entry
+	if err := p.rpc(ctx, "files/get_metadata", remotePath, map[string]string{"path": p.apiPath(remotePath)}, &result); err != nil {
+		return nil, fmt.Errorf("failed to get metadata: %w", err)
+	}
+	return p.metadata(&result), nil
+}
+
+// CreateFolder creates a folder. Dropbox creates missing parents itself.
+func (p *PulsePointDropboxProvider) CreateFolder(ctx context.Context, remotePath string) error {
+	if p.apiPath(remotePath) == "" {
+		return nil
+	}
+
+	err := p.rpc(ctx, "files/create_folder_v2", remotePath, map[string]interface{}{
+		"path":       p.apiPath(remotePath),
+		"autorename": false,
+	}, nil)
+	if err != nil && !strings.HasPrefix(errorSummary(err), "path/conflict/folder") {
+		return err
+	}
+
+	p.logger.Debug("Folder ensured", zap.String("path", remotePath))
+	return nil
+}
+
+// Move moves a file or folder with move_v2, replacing a file at the destination
+func (p *PulsePointDropboxProvider) Move(ctx context.Context, sourcePath, destPath string) error {
+	p.logger.Debug("Moving file",
+		zap.String("source", sourcePath),
+		zap.String("destination", destPath))
+
+	arg := map[string]interface{}{
+		"from_path":  p.apiPath(sourcePath),
+		"to_path":    p.apiPath(destPath),
+		"autorename": false,
+	}
+	err := p.rpc(ctx, "files/move_v2", sourcePath, arg, nil)
+	if err != nil && strings.HasPrefix(errorSummary(err), "to/conflict/file") {
+		// move_v2 never overwrites, so clear the old file and try once more
+		if err := p.Delete(ctx, destPath); err != nil {
+			return err
+		}
+		err = p.rpc(ctx, "files/move_v2", sourcePath, arg, nil)
+	}
+	if err != nil {
+		return fmt.Errorf("move failed: %w", err)
+	}
+
+	p.logger.Info("File moved successfully",
+		zap.String("source", sourcePath),
+		zap.String("destination", destPath))
+
+	return nil
+}
+
+// GetQuota returns the account's space usage
+func (p *PulsePointDropboxProvider) GetQuota(ctx context.Context) (*interfaces.QuotaInfo, error) {
+	var usage struct {
+		Used       int64 `json:"used"`
+		Allocation struct {
+			Tag       string `json:".tag"`
+			Allocated int64  `json:"allocated"`
+		} `json:"allocation"`
+	}
+	if err := p.rpc(ctx, "users/get_space_usage", "/", nil, &usage); err != nil {
+		return nil, err
+	}
+
+	quota := &interfaces.QuotaInfo{
+		Used:  usage.Used,
+		Total: usage.Allocation.Allocated,
+	}
+	if quota.Total > quota.Used {
+		quota.Available = quota.Total - quota.Used
+	}
+	return quota, nil
+}
+
+// GetProviderName returns the provider name
+func (p *PulsePointDropboxProvider) GetProviderName() string {
+	return providerName
+}
+
+// IsConnected checks if the provider is connected
+func (p *PulsePointDropboxProvider) IsConnected() bool {
+	return p.client != nil
+}
+
+// Disconnect closes the connection to the provider
+func (p *PulsePointDropboxProvider) Disconnect() error {
+	if p.client != nil {
+		p.client.CloseIdleConnections()
+	}
+	p.client = nil
+	p.logger.Info("Disconnected from Dropbox")
+	return nil
+}
+
+// accessToken returns a valid access token, refreshing it through the auth
+// provider shortly before it expires
+func (p *PulsePointDropboxProvider) accessToken(ctx context.Context) (string, error) {
+	p.tokenMu.Lock()
+	defer p.tokenMu.Unlock()
+
+	if p.token == nil {
+		token, err := p.auth.LoadToken()
+		if err != nil {
+			return "", pperrors.NewAuthError("no Dropbox token found. Run 'pulsepoint auth dropbox' first", err)
+		}
+		p.token = token
+	}
+
+	if !p.token.ExpiresAt.IsZero() && p.token.TimeUntilExpiry() < time.Minute {
+		refreshed, err := p.auth.RefreshToken(ctx, p.token)
+		if err != nil {
+			return "", pperrors.NewAuthError("failed to refresh Dropbox token", err)
+		}
+		if err := p.auth.StoreToken(refreshed); err != nil {
+			p.logger.Warn("Failed to save refreshed token", zap.Error(err))
+		}
+		p.token = refreshed
+	}
+	return p.token.AccessToken, nil
+}
+
+// rpc calls an RPC endpoint with a JSON argument and decodes the JSON result
+func (p *PulsePointDropboxProvider) rpc(ctx context.Context, endpoint, remotePath string, arg, result interface{}) error {
+	body := []byte("null")
+	if arg != nil {
+		var err error
+		if body, err = json.Marshal(arg); err != nil {
+			return pperrors.NewProviderError("failed to encode request", err)
+		}
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	resp, err := p.send(ctx, p.config.APIURL+"/"+endpoint, remotePath, nil, header, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return pperrors.NewProviderError(fmt.Sprintf("failed to decode %s response", endpoint), err)
+	}
+	return nil
+}
+
+// content calls a content-upload endpoint with data as the body
+func (p *PulsePointDropboxProvider) content(ctx context.Context, endpoint, remotePath string, arg interface{}, data []byte, result interface{}) error {
+	header := http.Header{}
+	header.Set("Content-Type", "application/octet-stream")
+	resp, err := p.send(ctx, p.config.ContentURL+"/"+endpoint, remotePath, arg, header, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return pperrors.NewProviderError(fmt.Sprintf("failed to decode %s response", endpoint), err)
+	}
+	return nil
+}
+
+// send posts an authenticated request. A non-nil arg is sent in the
+// Dropbox-API-Arg header. Responses outside 2xx are returned as errors.
+func (p *PulsePointDropboxProvider) send(ctx context.Context, endpoint, remotePath string, arg interface{}, header http.Header, body []byte) (*http.Response, error) {
+	if p.client == nil {
+		return nil, pperrors.NewProviderError("Dropbox provider is disconnected", nil)
+	}
+
+	token, err := p.accessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, pperrors.NewProviderError("failed to build request", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if arg != nil {
+		encoded, err := apiArg(arg)
+		if err != nil {
+			return nil, pperrors.NewProviderError("failed to encode request", err)
+		}
+		req.Header.Set("Dropbox-API-Arg", encoded)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, pperrors.NewRetryable(pperrors.NetworkError, fmt.Sprintf("request for %s failed", remotePath), err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		return nil, responseError(remotePath, resp)
+	}
+	return resp, nil
+}
+
+// apiPath maps a remote path to a Dropbox path under the root. Dropbox names
+// the root folder with an empty string.
+func (p *PulsePointDropboxProvider) apiPath(remotePath string) string {
+	full := path.Join(cleanPath(p.config.Root), cleanPath(remotePath))
+	if full == "/" {
+		return ""
+	}
+	return full
+}
+
+// remotePath maps a Dropbox path back to a remote path. Dropbox paths are
+// case-insensitive, so the root prefix is matched without case.
+func (p *PulsePointDropboxProvider) remotePath(displayPath string) string {
+	root := cleanPath(p.config.Root)
+	if root == "/" {
+		return cleanPath(displayPath)
+	}
+	if len(displayPath) >= len(root) && strings.EqualFold(displayPath[:len(root)], root) {
+		return cleanPath(displayPath[len(root):])
+	}
+	return cleanPath(displayPath)
+}
+
+// metadata converts a Dropbox metadata entry
+func (p *PulsePointDropboxProvider) metadata(e *entry) *interfaces.Metadata {
+	meta := &interfaces.Metadata{
+		ID:       e.ID,
+		Path:     p.remotePath(e.PathDisplay),
+		IsFolder: e.Tag == "folder",
+		Version:  e.Rev,
+	}
+	if meta.IsFolder {
+		meta.MimeType = mimeTypeFolder
+		return meta
+	}
+
+	meta.Size = e.Size
+	meta.Hash = e.ContentHash
+	// client_modified keeps the mtime PulsePoint uploaded with
+	if modified, err := time.Parse(time.RFC3339, e.ClientModified); err == nil {
+		meta.ModifiedTime = modified
+	}
+	meta.Attributes = map[string]interface{}{"server_modified": e.ServerModified}
+	return meta
+}
+
+// apiArg encodes a Dropbox-API-Arg header value. HTTP headers must be ASCII,
+// so other characters are escaped as JSON \u sequences.
+func apiArg(arg interface{}) (string, error) {
+	data, err := json.Marshal(arg)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, r := range string(data) {
+		switch {
+		case r < 0x7f:
+			b.WriteRune(r)
+		case r > 0xffff:
+			high, low := utf16.EncodeRune(r)
+			fmt.Fprintf(&b, "\\u%04x\\u%04x", high, low)
+		default:
+			fmt.Fprintf(&b, "\\u%04x", r)
+		}
+	}
+	return b.String(), nil
+}
+
+// cleanPath normalises a remote path to a clean absolute path
+func cleanPath(remotePath string) string {
+	return path.Clean("/" + remotePath)
+}
+
+// responseError turns an unsuccessful response into a provider error that
+// keeps the status code and the Dropbox error summary
+func responseError(remotePath string, resp *http.Response) error {
+	var apiErr struct {
+		ErrorSummary string `json:"error_summary"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 16*1024))
+	if json.Unmarshal(data, &apiErr) != nil || apiErr.ErrorSummary == "" {
+		apiErr.ErrorSummary = strings.TrimSpace(string(data))
+	}
+	message := fmt.Sprintf("Dropbox request for %s failed: %s %s", remotePath, resp.Status, apiErr.ErrorSummary)
+
+	var pe *pperrors.PulseError
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		pe = pperrors.NewAuthError(message, nil)
+	case resp.StatusCode == http.StatusConflict && strings.Contains(apiErr.ErrorSummary, "not_found"):
+		pe = pperrors.NewProviderError(fmt.Sprintf("file not found: %s", remotePath), nil)
+		pe.StatusCode = http.StatusNotFound
+		return pe.WithContext("error_summary", apiErr.ErrorSummary)
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		pe = pperrors.NewRetryable(pperrors.ProviderError, message, nil)
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			pe.WithContext("retry_after", time.Duration(seconds)*time.Second)
+		}
+	default:
+		pe = pperrors.NewProviderError(message, nil)
+	}
+	pe.StatusCode = resp.StatusCode
+	return pe.WithContext("error_summary", apiErr.ErrorSummary)
+}
+
+// errorSummary returns the Dropbox error summary of an API error
+func errorSummary(err error) string {
+	if pe, ok := err.(*pperrors.PulseError); ok {
+		if summary, ok := pe.Context["error_summary"].(string); ok {
+			return summary
+		}
+	}
+	return ""
+}
+
+// entry is a Dropbox file or folder metadata object
+type entry struct {
+	Tag            string `json:".tag"`
+	Name           string `json:"name"`
+	ID             string `json:"id"`
+	PathLower      string `json:"path_lower"`
+	PathDisplay    string `json:"path_display"`
+	ClientModified string `json:"client_modified,omitempty"`
+	ServerModified string `json:"server_modified,omitempty"`
+	Rev            string `json:"rev,omitempty"`
+	Size           int64  `json:"size,omitempty"`
+	ContentHash    string `json:"content_hash,omitempty"`
+}
+
+// listFolderResult is one page of list_folder results
+type listFolderResult struct {
+	Entries []entry `json:"entries"`
+	Cursor  string  `json:"cursor"`
+	HasMore bool    `json:"has_more"`
+}
+
+// commitInfo says where and how an upload is stored
+type commitInfo struct {
+	Path           string `json:"path"`
+	Mode           string `json:"mode"`
+	Mute           bool   `json:"mute"`
+	ClientModified string `json:"client_modified,omitempty"`
+}
+
+// uploadCursor is the position in an upload session
+type uploadCursor struct {
+	SessionID string `json:"session_id"`
+	Offset    int64  `json:"offset"`
+}
@@ -0,0 +1,432 @@
+package dropbox
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	dropboxauth "github.com/pulsepoint/pulsepoint/internal/auth/dropbox"
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"github.com/pulsepoint/pulsepoint/internal/providers/providertest"
+	pperrors "github.com/pulsepoint/pulsepoint/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDropbox is an in-memory Dropbox API. list_folder returns two entries
+// per page so cursors are exercised.
+type fakeDropbox struct {
+	t        *testing.T
+	mu       sync.Mutex
+	entries  map[string]*entry // keyed by lower-case path
+	data     map[string][]byte
+	sessions map[string][]byte
+	calls    map[string]int
+}
+
+func newFakeDropbox(t *testing.T) (*fakeDropbox, *httptest.Server) {
+	fake := &fakeDropbox{
+		t:        t,
+		entries:  make(map[string]*entry),
+		data:     make(map[string][]byte),
+		sessions: make(map[string][]byte),
+		calls:    make(map[string]int),
+	}
+	server := httptest.NewServer(http.HandlerFunc(fake.serve))
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func (f *fakeDropbox) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Path == "/oauth2/token" {
+		require.NoError(f.t, r.ParseForm())
+		assert.Equal(f.t, "refresh-1", r.PostForm.Get("refresh_token"))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "fresh-token",
+			"token_type":   "bearer",
+			"expires_in":   14400,
+		})
+		return
+	}
+
+	if auth := r.Header.Get("Authorization"); auth != "Bearer good-token" && auth != "Bearer fresh-token" {
+		f.fail(w, http.StatusUnauthorized, "expired_access_token/")
+		return
+	}
+
+	endpoint := strings.TrimPrefix(r.URL.Path, "/")
+	f.calls[endpoint]++
+
+	var arg map[string]interface{}
+	body, _ := io.ReadAll(r.Body)
+	if header := r.Header.Get("Dropbox-API-Arg"); header != "" {
+		for _, c := range header {
+			require.Less(f.t, c, rune(0x7f), "Dropbox-API-Arg must be ASCII")
+		}
+		require.NoError(f.t, json.Unmarshal([]byte(header), &arg))
+	} else if len(body) > 0 {
+		require.NoError(f.t, json.Unmarshal(body, &arg))
+	}
+
+	switch endpoint {
+	case "files/upload":
+		f.reply(w, f.commit(arg, body))
+	case "files/upload_session/start":
+		id := fmt.Sprintf("session-%d", len(f.sessions)+1)
+		f.sessions[id] = body
+		f.reply(w, map[string]string{"session_id": id})
+	case "files/upload_session/append_v2", "files/upload_session/finish":
+		cursor := arg["cursor"].(map[string]interface{})
+		id := cursor["session_id"].(string)
+		if int(cursor["offset"].(float64)) != len(f.sessions[id]) {
+			f.fail(w, http.StatusConflict, "lookup_failed/incorrect_offset/")
+			return
+		}
+		f.sessions[id] = append(f.sessions[id], body...)
+		if endpoint == "files/upload_session/append_v2" {
+			f.reply(w, nil)
+			return
+		}
+		f.reply(w, f.commit(arg["commit"].(map[string]interface{}), f.sessions[id]))
+		delete(f.sessions, id)
+	case "files/download":
+		e, ok := f.entries[key(arg["path"])]
+		if !ok || e.Tag != "file" {
+			f.fail(w, http.StatusConflict, "path/not_found/")
+			return
+		}
+		result, _ := json.Marshal(e)
+		w.Header().Set("Dropbox-API-Result", string(result))
+		w.Write(f.data[key(arg["path"])])
+	case "files/get_metadata":
+		e, ok := f.entries[key(arg["path"])]
+		if !ok {
+			f.fail(w, http.StatusConflict, "path/not_found/")
+			return
+		}
+		f.reply(w, e)
+	case "files/delete_v2":
+		e, ok := f.entries[key(arg["path"])]
+		if !ok {
+			f.fail(w, http.StatusConflict, "path_lookup/not_found/")
+			return
+		}
+		f.removeTree(key(arg["path"]))
+		f.reply(w, map[string]interface{}{"metadata": e})
+	case "files/create_folder_v2":
+		if _, ok := f.entries[key(arg["path"])]; ok {
+			f.fail(w, http.StatusConflict, "path/conflict/folder/")
+			return
+		}
+		f.mkdirAll(arg["path"].(string))
+		f.reply(w, map[string]interface{}{"metadata": f.entries[key(arg["path"])]})
+	case "files/move_v2":
+		from, to := key(arg["from_path"]), key(arg["to_path"])
+		if _, ok := f.entries[from]; !ok {
+			f.fail(w, http.StatusConflict, "from_lookup/not_found/")
+			return
+		}
+		if _, ok := f.entries[to]; ok {
+			f.fail(w, http.StatusConflict, "to/conflict/file/")
+			return
+		}
+		f.moveTree(from, arg["to_path"].(string))
+		f.reply(w, map[string]interface{}{"metadata": f.entries[to]})
+	case "files/list_folder":
+		folder := key(arg["path"])
+		if e, ok := f.entries[folder]; folder != "" && (!ok || e.Tag != "folder") {
+			f.fail(w, http.StatusConflict, "path/not_found/")
+			return
+		}
+		f.reply(w, f.page(folder, 0))
+	case "files/list_folder/continue":
+		parts := strings.SplitN(arg["cursor"].(string), "|", 2)
+		offset, _ := strconv.Atoi(parts[0])
+		f.reply(w, f.page(parts[1], offset))
+	case "users/get_space_usage":
+		f.reply(w, map[string]interface{}{
+			"used":       1024,
+			"allocation": map[string]interface{}{".tag": "individual", "allocated": 4096},
+		})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// commit stores an uploaded file, creating its parents like Dropbox does
+func (f *fakeDropbox) commit(arg map[string]interface{}, data []byte) *entry {
+	p := arg["path"].(string)
+	f.mkdirAll(path.Dir(p))
+
+	hash, _ := ContentHash(bytes.NewReader(data))
+	modified, _ := arg["client_modified"].(string)
+	if modified == "" {
+		modified = "2024-01-01T00:00:00Z"
+	}
+	e := &entry{
+		Tag:            "file",
+		Name:           path.Base(p),
+		ID:             "id:" + key(p),
+		PathLower:      key(p),
+		PathDisplay:    p,
+		ClientModified: modified,
+		ServerModified: "2024-06-01T00:00:00Z",
+		Rev:            fmt.Sprintf("%09x", len(f.calls)),
+		Size:           int64(len(data)),
+		ContentHash:    hash,
+	}
+	f.entries[key(p)] = e
+	f.data[key(p)] = append([]byte(nil), data...)
+	return e
+}
+
+func (f *fakeDropbox) mkdirAll(p string) {
+	for ; p != "/" && p != "" && p != "."; p = path.Dir(p) {
+		if _, ok := f.entries[key(p)]; !ok {
+			f.entries[key(p)] = &entry{Tag: "folder", Name: path.Base(p), ID: "id:" + key(p), PathLower: key(p), PathDisplay: p}
+		}
+	}
+}
+
+func (f *fakeDropbox) removeTree(k string) {
+	for p := range f.entries {
+		if p == k || strings.HasPrefix(p, k+"/") {
+			delete(f.entries, p)
+			delete(f.data, p)
+		}
+	}
+}
+
+func (f *fakeDropbox) moveTree(from, to string) {
+	f.mkdirAll(path.Dir(to))
+	for p, e := range f.entries {
+		if p != from && !strings.HasPrefix(p, from+"/") {
+			continue
+		}
+		display := to + e.PathDisplay[len(from):]
+		moved := *e
+		moved.PathDisplay, moved.PathLower, moved.Name = display, key(display), path.Base(display)
+		delete(f.entries, p)
+		f.entries[key(display)] = &moved
+		if data, ok := f.data[p]; ok {
+			delete(f.data, p)
+			f.data[key(display)] = data
+		}
+	}
+}
+
+func (f *fakeDropbox) page(folder string, offset int) listFolderResult {
+	var children []string
+	for p := range f.entries {
+		if path.Dir(p) == folder || (folder == "" && path.Dir(p) == "/") {
+			children = append(children, p)
+		}
+	}
+	sort.Strings(children)
+
+	result := listFolderResult{Entries: []entry{}}
+	end := offset + 2
+	if end >= len(children) {
+		end = len(children)
+	} else {
+		result.HasMore = true
+	}
+	for _, p := range children[offset:end] {
+		result.Entries = append(result.Entries, *f.entries[p])
+	}
+	result.Cursor = fmt.Sprintf("%d|%s", end, folder)
+	return result
+}
+
+func (f *fakeDropbox) reply(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func (f *fakeDropbox) fail(w http.ResponseWriter, status int, summary string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error_summary": summary})
+}
+
+func key(p interface{}) string {
+	return strings.ToLower(p.(string))
+}
+
+func newTestProvider(t *testing.T, server *httptest.Server, token *interfaces.AuthToken) *PulsePointDropboxProvider {
+	auth, err := dropboxauth.NewPulsePointDropboxAuth(&dropboxauth.Config{
+		AppKey:    "app-key",
+		TokenFile: filepath.Join(t.TempDir(), "dropbox_token.json"),
+		TokenURL:  server.URL + "/oauth2/token",
+		APIURL:    server.URL,
+	})
+	require.NoError(t, err)
+	require.NoError(t, auth.StoreToken(token))
+
+	provider, err := NewPulsePointDropboxProvider(&Config{
+		Root:       "/PulsePoint",
+		ChunkSize:  contentHashBlockSize,
+		APIURL:     server.URL,
+		ContentURL: server.URL,
+	}, auth)
+	require.NoError(t, err)
+	return provider
+}
+
+func validToken() *interfaces.AuthToken {
+	return &interfaces.AuthToken{
+		AccessToken:  "good-token",
+		RefreshToken: "refresh-1",
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}
+}
+
+func TestDropboxProviderRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	fake, server := newFakeDropbox(t)
+	provider := newTestProvider(t, server, validToken())
+
+	modTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, provider.Upload(ctx, &interfaces.File{
+		Path:         "/Docs/Reports/q1 résumé.txt",
+		Content:      strings.NewReader("quarterly"),
+		ModifiedTime: modTime.Add(250 * time.Millisecond),
+	}))
+	for _, name := range []string{"a.md", "b.md", "c.md"} {
+		require.NoError(t, provider.Upload(ctx, &interfaces.File{
+			Path:    "/Docs/" + name,
+			Content: strings.NewReader(name),
+		}))
+	}
+	assert.Zero(t, fake.calls["files/upload_session/start"])
+
+	// Four entries at two per page needs one continue call
+	files, err := provider.List(ctx, "/Docs")
+	require.NoError(t, err)
+	var paths []string
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	assert.Equal(t, []string{"/Docs/a.md", "/Docs/b.md", "/Docs/c.md", "/Docs/Reports"}, paths)
+	assert.Equal(t, 1, fake.calls["files/list_folder/continue"])
+	assert.True(t, files[3].IsFolder)
+
+	meta, err := provider.GetMetadata(ctx, "/Docs/Reports/q1 résumé.txt")
+	require.NoError(t, err)
+	expected, err := ContentHash(strings.NewReader("quarterly"))
+	require.NoError(t, err)
+	assert.Equal(t, expected, meta.Hash)
+	assert.Equal(t, int64(9), meta.Size)
+	assert.True(t, modTime.Equal(meta.ModifiedTime))
+
+	file, err := provider.Download(ctx, "/Docs/Reports/q1 résumé.txt")
+	require.NoError(t, err)
+	content, err := io.ReadAll(file.Content)
+	require.NoError(t, err)
+	require.NoError(t, file.Content.(io.Closer).Close())
+	assert.Equal(t, "quarterly", string(content))
+	assert.Equal(t, expected, file.Hash)
+
+	// move_v2 refuses to overwrite, so the provider replaces the file itself
+	require.NoError(t, provider.Move(ctx, "/Docs/a.md", "/Docs/b.md"))
+	file, err = provider.Download(ctx, "/Docs/b.md")
+	require.NoError(t, err)
+	content, _ = io.ReadAll(file.Content)
+	assert.Equal(t, "a.md", string(content))
+
+	require.NoError(t, provider.CreateFolder(ctx, "/Docs/Reports"))
+	require.NoError(t, provider.Delete(ctx, "/Docs"))
+	_, err = provider.GetMetadata(ctx, "/Docs/Reports/q1 résumé.txt")
+	assert.True(t, pperrors.IsNotFoundError(err))
+	assert.NoError(t, provider.Delete(ctx, "/Docs"), "deleting a missing path succeeds")
+	_, err = provider.List(ctx, "/Docs")
+	assert.True(t, pperrors.IsNotFoundError(err))
+
+	quota, err := provider.GetQuota(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1024), quota.Used)
+	assert.Equal(t, int64(3072), quota.Available)
+}
+
+func TestDropboxProviderUploadSession(t *testing.T) {
+	ctx := context.Background()
+	fake, server := newFakeDropbox(t)
+	provider := newTestProvider(t, server, validToken())
+
+	data := bytes.Repeat([]byte("0123456789abcdef"), (2*contentHashBlockSize+1000)/16)
+	require.NoError(t, provider.Upload(ctx, &interfaces.File{
+		Path:    "/backup.tar",
+		Content: bytes.NewReader(data),
+		Size:    int64(len(data)),
+	}))
+	assert.Equal(t, 1, fake.calls["files/upload_session/start"])
+	assert.Equal(t, 1, fake.calls["files/upload_session/append_v2"])
+	assert.Equal(t, 1, fake.calls["files/upload_session/finish"])
+	assert.Equal(t, data, fake.data["/pulsepoint/backup.tar"])
+}
+
+func TestDropboxProviderRefreshesExpiredToken(t *testing.T) {
+	ctx := context.Background()
+	_, server := newFakeDropbox(t)
+	provider := newTestProvider(t, server, &interfaces.AuthToken{
+		AccessToken:  "stale-token",
+		RefreshToken: "refresh-1",
+		ExpiresAt:    time.Now().Add(-time.Minute),
+	})
+
+	_, err := provider.GetQuota(ctx)
+	require.NoError(t, err)
+
+	stored, err := provider.auth.LoadToken()
+	require.NoError(t, err)
+	assert.Equal(t, "fresh-token", stored.AccessToken)
+	assert.Equal(t, "refresh-1", stored.RefreshToken)
+}
+
+func TestContentHash(t *testing.T) {
+	// Two full blocks and a partial one, hashed independently
+	data := bytes.Repeat([]byte{0x5a}, 2*contentHashBlockSize+10)
+	var digests []byte
+	for offset := 0; offset < len(data); offset += contentHashBlockSize {
+		end := offset + contentHashBlockSize
+		if end > len(data) {
+			end = len(data)
+		}
+		sum := sha256.Sum256(data[offset:end])
+		digests = append(digests, sum[:]...)
+	}
+	want := sha256.Sum256(digests)
+
+	got, err := ContentHash(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(want[:]), got)
+
+	empty, err := ContentHash(strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", empty)
+}
+
+func TestDropboxProviderConformance(t *testing.T) {
+	providertest.Run(t, func(t *testing.T) interfaces.CloudProvider {
+		_, server := newFakeDropbox(t)
+		return newTestProvider(t, server, validToken())
+	})
+}
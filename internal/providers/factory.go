@@ -4,11 +4,22 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
+	"time"
 
+	dropboxauth "github.com/pulsepoint/pulsepoint/internal/auth/dropbox"
 	ppauth "github.com/pulsepoint/pulsepoint/internal/auth/google"
+	onedriveauth "github.com/pulsepoint/pulsepoint/internal/auth/onedrive"
 	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"github.com/pulsepoint/pulsepoint/internal/providers/dropbox"
+	"github.com/pulsepoint/pulsepoint/internal/providers/filesystem"
 	gdrive "github.com/pulsepoint/pulsepoint/internal/providers/google"
 	"github.com/pulsepoint/pulsepoint/internal/providers/mock"
+	"github.com/pulsepoint/pulsepoint/internal/providers/onedrive"
+	"github.com/pulsepoint/pulsepoint/internal/providers/s3"
+	"github.com/pulsepoint/pulsepoint/internal/providers/sftp"
+	"github.com/pulsepoint/pulsepoint/internal/providers/webdav"
 	"github.com/pulsepoint/pulsepoint/pkg/errors"
 	"github.com/spf13/viper"
 )
@@ -19,12 +30,18 @@ type ProviderType string
 const (
 	// GoogleDrive provider type
 	GoogleDrive ProviderType = "google"
-	// Dropbox provider type (future)
+	// Dropbox provider type
 	Dropbox ProviderType = "dropbox"
-	// OneDrive provider type (future)
+	// OneDrive provider type, also used for OneDrive for Business
 	OneDrive ProviderType = "onedrive"
-	// S3 provider type (future)
+	// S3 provider type, also used for S3-compatible services
 	S3 ProviderType = "s3"
+	// WebDAV provider type, e.g. Nextcloud or ownCloud
+	WebDAV ProviderType = "webdav"
+	// SFTP provider type, for any server reachable over SSH
+	SFTP ProviderType = "sftp"
+	// Local provider type, a directory such as a mounted NAS or USB disk
+	Local ProviderType = "local"
 	// Mock provider type (for testing)
 	Mock ProviderType = "mock"
 )
@@ -57,11 +74,17 @@ func (f *PulsePointProviderFactory) CreateProvider(providerType ProviderType) (i
 		provider.Initialize(config)
 		return provider, nil
 	case Dropbox:
-		return nil, errors.NewProviderError("Dropbox provider not yet implemented", nil)
+		return f.createDropboxProvider()
 	case OneDrive:
-		return nil, errors.NewProviderError("OneDrive provider not yet implemented", nil)
+		return f.createOneDriveProvider()
 	case S3:
-		return nil, errors.NewProviderError("S3 provider not yet implemented", nil)
+		return f.createS3Provider()
+	case WebDAV:
+		return f.createWebDAVProvider()
+	case SFTP:
+		return f.createSFTPProvider()
+	case Local:
+		return f.createLocalProvider()
 	default:
 		return nil, errors.NewProviderError(fmt.Sprintf("unknown provider type: %s", providerType), nil)
 	}
@@ -106,8 +129,30 @@ func (f *PulsePointProviderFactory) createGoogleDriveProvider() (interfaces.Clou
 		return nil, errors.NewConfigError("Google client ID and secret are required", nil)
 	}
 
+	// Upload sessions and cached folder IDs outlive the process here
+	stateFile := viper.GetString("providers.google.state_file")
+	if stateFile == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			stateFile = filepath.Join(home, ".pulsepoint", "google_drive.db")
+		}
+	}
+
+	// Uploads record the local file's hash; Drive supports md5 or sha256
+	hashAlgorithm := viper.GetString("files.hash_algorithm")
+	if hashAlgorithm != "md5" {
+		hashAlgorithm = "sha256"
+	}
+
+	// Uploads record the device they came from
+	deviceID := viper.GetString("device_id")
+	if deviceID == "" {
+		deviceID, _ = os.Hostname()
+	}
+
 	// Create provider config
 	config := &gdrive.Config{
+		ClientID:                 clientID,
+		ClientSecret:             clientSecret,
 		CredentialsFile:          credentialsPath,
 		TokenFile:                tokenFile,
 		RootFolderID:             viper.GetString("providers.google.root_folder_id"),
@@ -115,7 +160,11 @@ func (f *PulsePointProviderFactory) createGoogleDriveProvider() (interfaces.Clou
 		SimpleUploadThreshold:    viper.GetInt64("providers.google.simple_upload_threshold"),
 		ResumableUploadThreshold: viper.GetInt64("providers.google.resumable_upload_threshold"),
 		ChunkSize:                viper.GetInt64("providers.google.chunk_size"),
+		StateFile:                stateFile,
+		CacheTTL:                 cacheTTL(),
 		MaxRetries:               viper.GetInt("providers.google.max_retries"),
+		HashAlgorithm:            hashAlgorithm,
+		DeviceID:                 deviceID,
 		RateLimit:                viper.GetInt("providers.google.rate_limit"),
 	}
 
@@ -128,6 +177,251 @@ func (f *PulsePointProviderFactory) createGoogleDriveProvider() (interfaces.Clou
 	return provider, nil
 }
 
+// cacheTTL reads performance.cache_ttl, given in seconds or as a duration
+// such as "5m". It is negative when caching is turned off.
+func cacheTTL() time.Duration {
+	if viper.IsSet("performance.enable_caching") && !viper.GetBool("performance.enable_caching") {
+		return -1
+	}
+	value := viper.GetString("performance.cache_ttl")
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	ttl, _ := time.ParseDuration(value)
+	return ttl
+}
+
+// createDropboxProvider creates a Dropbox provider instance. The app key and
+// secret fall back to the DROPBOX_APP_KEY and DROPBOX_APP_SECRET environment
+// variables.
+func (f *PulsePointProviderFactory) createDropboxProvider() (interfaces.CloudProvider, error) {
+	if !viper.GetBool("providers.dropbox.configured") {
+		return nil, errors.NewConfigError("Dropbox is not configured. Run 'pulsepoint auth dropbox' first", nil)
+	}
+
+	auth, err := NewDropboxAuth()
+	if err != nil {
+		return nil, err
+	}
+	if auth.RequiresInteraction() {
+		return nil, errors.NewConfigError("no usable Dropbox token found. Run 'pulsepoint auth dropbox' first", nil)
+	}
+
+	config := &dropbox.Config{
+		Root:      viper.GetString("providers.dropbox.root"),
+		ChunkSize: viper.GetInt64("providers.dropbox.chunk_size"),
+	}
+
+	provider, err := dropbox.NewPulsePointDropboxProvider(config, auth)
+	if err != nil {
+		return nil, errors.NewProviderError("failed to create Dropbox provider", err)
+	}
+
+	return provider, nil
+}
+
+// NewDropboxAuth creates the Dropbox authentication handler from the config file
+func NewDropboxAuth() (*dropboxauth.PulsePointDropboxAuth, error) {
+	appKey := viper.GetString("providers.dropbox.app_key")
+	if appKey == "" {
+		appKey = os.Getenv("DROPBOX_APP_KEY")
+	}
+	appSecret := viper.GetString("providers.dropbox.app_secret")
+	if appSecret == "" {
+		appSecret = os.Getenv("DROPBOX_APP_SECRET")
+	}
+	if appKey == "" {
+		return nil, errors.NewConfigError("Dropbox app key is required. Set providers.dropbox.app_key or DROPBOX_APP_KEY", nil)
+	}
+
+	return dropboxauth.NewPulsePointDropboxAuth(&dropboxauth.Config{
+		AppKey:      appKey,
+		AppSecret:   appSecret,
+		RedirectURI: viper.GetString("providers.dropbox.redirect_uri"),
+		TokenFile:   viper.GetString("providers.dropbox.token_file"),
+	})
+}
+
+// createOneDriveProvider creates a OneDrive provider instance. The client ID
+// and secret fall back to the ONEDRIVE_CLIENT_ID and ONEDRIVE_CLIENT_SECRET
+// environment variables.
+func (f *PulsePointProviderFactory) createOneDriveProvider() (interfaces.CloudProvider, error) {
+	if !viper.GetBool("providers.onedrive.configured") {
+		return nil, errors.NewConfigError("OneDrive is not configured. Run 'pulsepoint auth onedrive' first", nil)
+	}
+
+	auth, err := NewOneDriveAuth()
+	if err != nil {
+		return nil, err
+	}
+	if auth.RequiresInteraction() {
+		return nil, errors.NewConfigError("no usable OneDrive token found. Run 'pulsepoint auth onedrive' first", nil)
+	}
+
+	config := &onedrive.Config{
+		DriveID:   viper.GetString("providers.onedrive.drive_id"),
+		Root:      viper.GetString("providers.onedrive.root"),
+		ChunkSize: viper.GetInt64("providers.onedrive.chunk_size"),
+	}
+
+	provider, err := onedrive.NewPulsePointOneDriveProvider(config, auth)
+	if err != nil {
+		return nil, errors.NewProviderError("failed to create OneDrive provider", err)
+	}
+
+	return provider, nil
+}
+
+// NewOneDriveAuth creates the OneDrive authentication handler from the config file
+func NewOneDriveAuth() (*onedriveauth.PulsePointOneDriveAuth, error) {
+	clientID := viper.GetString("providers.onedrive.client_id")
+	if clientID == "" {
+		clientID = os.Getenv("ONEDRIVE_CLIENT_ID")
+	}
+	clientSecret := viper.GetString("providers.onedrive.client_secret")
+	if clientSecret == "" {
+		clientSecret = os.Getenv("ONEDRIVE_CLIENT_SECRET")
+	}
+	if clientID == "" {
+		return nil, errors.NewConfigError("OneDrive client ID is required. Set providers.onedrive.client_id or ONEDRIVE_CLIENT_ID", nil)
+	}
+
+	return onedriveauth.NewPulsePointOneDriveAuth(&onedriveauth.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Tenant:       viper.GetString("providers.onedrive.tenant"),
+		RedirectURI:  viper.GetString("providers.onedrive.redirect_uri"),
+		TokenFile:    viper.GetString("providers.onedrive.token_file"),
+	})
+}
+
+// createS3Provider creates an S3 provider instance. Keys fall back to the
+// standard AWS environment variables.
+func (f *PulsePointProviderFactory) createS3Provider() (interfaces.CloudProvider, error) {
+	if !viper.GetBool("providers.s3.configured") {
+		return nil, errors.NewConfigError("S3 is not configured. Set providers.s3 in the config file", nil)
+	}
+
+	accessKeyID := viper.GetString("providers.s3.access_key_id")
+	secretAccessKey := viper.GetString("providers.s3.secret_access_key")
+	sessionToken := viper.GetString("providers.s3.session_token")
+	if accessKeyID == "" && secretAccessKey == "" {
+		accessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		secretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		sessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+
+	region := viper.GetString("providers.s3.region")
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+
+	// Custom endpoints such as MinIO usually expect path-style addressing
+	endpoint := viper.GetString("providers.s3.endpoint")
+	pathStyle := endpoint != ""
+	if viper.IsSet("providers.s3.path_style") {
+		pathStyle = viper.GetBool("providers.s3.path_style")
+	}
+
+	config := &s3.Config{
+		Bucket:             viper.GetString("providers.s3.bucket"),
+		Region:             region,
+		Endpoint:           endpoint,
+		PathStyle:          pathStyle,
+		Prefix:             viper.GetString("providers.s3.prefix"),
+		AccessKeyID:        accessKeyID,
+		SecretAccessKey:    secretAccessKey,
+		SessionToken:       sessionToken,
+		MultipartThreshold: viper.GetInt64("providers.s3.multipart_threshold"),
+		PartSize:           viper.GetInt64("providers.s3.part_size"),
+	}
+
+	provider, err := s3.NewPulsePointS3Provider(config)
+	if err != nil {
+		return nil, errors.NewProviderError("failed to create S3 provider", err)
+	}
+
+	return provider, nil
+}
+
+// createWebDAVProvider creates a WebDAV provider instance. The password falls
+// back to the PULSEPOINT_WEBDAV_PASSWORD environment variable.
+func (f *PulsePointProviderFactory) createWebDAVProvider() (interfaces.CloudProvider, error) {
+	if !viper.GetBool("providers.webdav.configured") {
+		return nil, errors.NewConfigError("WebDAV is not configured. Set providers.webdav in the config file", nil)
+	}
+
+	password := viper.GetString("providers.webdav.password")
+	if password == "" {
+		password = os.Getenv("PULSEPOINT_WEBDAV_PASSWORD")
+	}
+
+	config := &webdav.Config{
+		URL:      viper.GetString("providers.webdav.url"),
+		Root:     viper.GetString("providers.webdav.root"),
+		Username: viper.GetString("providers.webdav.username"),
+		Password: password,
+	}
+
+	provider, err := webdav.NewPulsePointWebDAVProvider(config)
+	if err != nil {
+		return nil, errors.NewProviderError("failed to create WebDAV provider", err)
+	}
+
+	return provider, nil
+}
+
+// createSFTPProvider creates an SFTP provider instance. The key passphrase
+// falls back to the PULSEPOINT_SFTP_PASSPHRASE environment variable.
+func (f *PulsePointProviderFactory) createSFTPProvider() (interfaces.CloudProvider, error) {
+	if !viper.GetBool("providers.sftp.configured") {
+		return nil, errors.NewConfigError("SFTP is not configured. Set providers.sftp in the config file", nil)
+	}
+
+	passphrase := viper.GetString("providers.sftp.passphrase")
+	if passphrase == "" {
+		passphrase = os.Getenv("PULSEPOINT_SFTP_PASSPHRASE")
+	}
+
+	config := &sftp.Config{
+		Host:                  viper.GetString("providers.sftp.host"),
+		Port:                  viper.GetInt("providers.sftp.port"),
+		User:                  viper.GetString("providers.sftp.user"),
+		Root:                  viper.GetString("providers.sftp.root"),
+		PrivateKeyPath:        viper.GetString("providers.sftp.private_key"),
+		Passphrase:            passphrase,
+		UseAgent:              viper.GetBool("providers.sftp.use_agent"),
+		KnownHostsPath:        viper.GetString("providers.sftp.known_hosts"),
+		InsecureIgnoreHostKey: viper.GetBool("providers.sftp.insecure_ignore_host_key"),
+		Timeout:               viper.GetDuration("providers.sftp.timeout"),
+	}
+
+	provider, err := sftp.NewPulsePointSFTPProvider(config)
+	if err != nil {
+		return nil, errors.NewProviderError("failed to create SFTP provider", err)
+	}
+
+	return provider, nil
+}
+
+// createLocalProvider creates a local directory provider instance
+func (f *PulsePointProviderFactory) createLocalProvider() (interfaces.CloudProvider, error) {
+	if !viper.GetBool("providers.local.configured") {
+		return nil, errors.NewConfigError("Local directory provider is not configured. Set providers.local in the config file", nil)
+	}
+
+	config := &filesystem.Config{
+		Root: viper.GetString("providers.local.root"),
+	}
+
+	provider, err := filesystem.NewPulsePointFilesystemProvider(config)
+	if err != nil {
+		return nil, errors.NewProviderError("failed to create local directory provider", err)
+	}
+
+	return provider, nil
+}
+
 // GetConfiguredProviders returns a list of configured providers
 func (f *PulsePointProviderFactory) GetConfiguredProviders() []ProviderType {
 	var providers []ProviderType
@@ -135,7 +429,24 @@ func (f *PulsePointProviderFactory) GetConfiguredProviders() []ProviderType {
 	if viper.GetBool("providers.google.configured") {
 		providers = append(providers, GoogleDrive)
 	}
-	// Future: check for other providers
+	if viper.GetBool("providers.dropbox.configured") {
+		providers = append(providers, Dropbox)
+	}
+	if viper.GetBool("providers.onedrive.configured") {
+		providers = append(providers, OneDrive)
+	}
+	if viper.GetBool("providers.s3.configured") {
+		providers = append(providers, S3)
+	}
+	if viper.GetBool("providers.webdav.configured") {
+		providers = append(providers, WebDAV)
+	}
+	if viper.GetBool("providers.sftp.configured") {
+		providers = append(providers, SFTP)
+	}
+	if viper.GetBool("providers.local.configured") {
+		providers = append(providers, Local)
+	}
 
 	return providers
 }
@@ -145,6 +456,18 @@ func (f *PulsePointProviderFactory) IsProviderConfigured(providerType ProviderTy
 	switch providerType {
 	case GoogleDrive:
 		return viper.GetBool("providers.google.configured")
+	case Dropbox:
+		return viper.GetBool("providers.dropbox.configured")
+	case OneDrive:
+		return viper.GetBool("providers.onedrive.configured")
+	case S3:
+		return viper.GetBool("providers.s3.configured")
+	case WebDAV:
+		return viper.GetBool("providers.webdav.configured")
+	case SFTP:
+		return viper.GetBool("providers.sftp.configured")
+	case Local:
+		return viper.GetBool("providers.local.configured")
 	default:
 		return false
 	}
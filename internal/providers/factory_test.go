@@ -3,8 +3,11 @@ package providers
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 )
@@ -23,6 +26,9 @@ func TestProviderTypeConstants(t *testing.T) {
 	assert.Equal(t, ProviderType("dropbox"), Dropbox)
 	assert.Equal(t, ProviderType("onedrive"), OneDrive)
 	assert.Equal(t, ProviderType("s3"), S3)
+	assert.Equal(t, ProviderType("webdav"), WebDAV)
+	assert.Equal(t, ProviderType("sftp"), SFTP)
+	assert.Equal(t, ProviderType("local"), Local)
 }
 
 func TestCreateProvider_UnsupportedProvider(t *testing.T) {
@@ -34,17 +40,17 @@ func TestCreateProvider_UnsupportedProvider(t *testing.T) {
 		wantErr      bool
 	}{
 		{
-			name:         "dropbox not implemented",
+			name:         "dropbox not configured",
 			providerType: Dropbox,
 			wantErr:      true,
 		},
 		{
-			name:         "onedrive not implemented",
+			name:         "onedrive not configured",
 			providerType: OneDrive,
 			wantErr:      true,
 		},
 		{
-			name:         "s3 not implemented",
+			name:         "s3 not configured",
 			providerType: S3,
 			wantErr:      true,
 		},
@@ -101,6 +107,140 @@ func TestCreateGoogleDriveProvider_MissingCredentials(t *testing.T) {
 	assert.Nil(t, provider)
 }
 
+func TestCreateDropboxProvider(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	factory := NewPulsePointProviderFactory(context.Background())
+	tokenFile := filepath.Join(t.TempDir(), "dropbox_token.json")
+	viper.Set("providers.dropbox.configured", true)
+	viper.Set("providers.dropbox.app_key", "app-key")
+	viper.Set("providers.dropbox.token_file", tokenFile)
+
+	// Without a stored token the user has to authenticate first
+	_, err := factory.CreateProvider(Dropbox)
+	assert.Error(t, err)
+
+	auth, err := NewDropboxAuth()
+	assert.NoError(t, err)
+	assert.NoError(t, auth.StoreToken(&interfaces.AuthToken{
+		AccessToken:  "access",
+		RefreshToken: "refresh",
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}))
+
+	provider, err := factory.CreateProvider(Dropbox)
+	assert.NoError(t, err)
+	assert.Equal(t, "dropbox", provider.GetProviderName())
+	assert.True(t, factory.IsProviderConfigured(Dropbox))
+}
+
+func TestCreateOneDriveProvider(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	factory := NewPulsePointProviderFactory(context.Background())
+	viper.Set("providers.onedrive.configured", true)
+	viper.Set("providers.onedrive.client_id", "client-id")
+	viper.Set("providers.onedrive.token_file", filepath.Join(t.TempDir(), "onedrive_token.json"))
+
+	// Without a stored token the user has to authenticate first
+	_, err := factory.CreateProvider(OneDrive)
+	assert.Error(t, err)
+
+	auth, err := NewOneDriveAuth()
+	assert.NoError(t, err)
+	assert.NoError(t, auth.StoreToken(&interfaces.AuthToken{
+		AccessToken:  "access",
+		RefreshToken: "refresh",
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}))
+
+	provider, err := factory.CreateProvider(OneDrive)
+	assert.NoError(t, err)
+	assert.Equal(t, "onedrive", provider.GetProviderName())
+	_, isFeed := provider.(interfaces.RemoteChangeFeed)
+	assert.True(t, isFeed)
+	assert.True(t, factory.IsProviderConfigured(OneDrive))
+}
+
+func TestCreateS3Provider(t *testing.T) {
+	viper.Reset()
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+
+	viper.Set("providers.s3.configured", true)
+	viper.Set("providers.s3.bucket", "backups")
+	viper.Set("providers.s3.endpoint", "http://localhost:9000")
+
+	factory := NewPulsePointProviderFactory(context.Background())
+	provider, err := factory.CreateProvider(S3)
+	assert.Error(t, err, "keys are required")
+	assert.Nil(t, provider)
+
+	viper.Set("providers.s3.access_key_id", "minioadmin")
+	viper.Set("providers.s3.secret_access_key", "minioadmin")
+	provider, err = factory.CreateProvider(S3)
+	assert.NoError(t, err)
+	assert.Equal(t, "s3", provider.GetProviderName())
+	assert.Equal(t, []ProviderType{S3}, factory.GetConfiguredProviders())
+}
+
+func TestCreateWebDAVProvider(t *testing.T) {
+	viper.Reset()
+	t.Setenv("PULSEPOINT_WEBDAV_PASSWORD", "app-password")
+
+	factory := NewPulsePointProviderFactory(context.Background())
+	_, err := factory.CreateProvider(WebDAV)
+	assert.Error(t, err)
+
+	viper.Set("providers.webdav.configured", true)
+	viper.Set("providers.webdav.url", "https://cloud.example.com/remote.php/dav/files/alice/")
+	viper.Set("providers.webdav.username", "alice")
+	provider, err := factory.CreateProvider(WebDAV)
+	assert.NoError(t, err)
+	assert.Equal(t, "webdav", provider.GetProviderName())
+	assert.True(t, factory.IsProviderConfigured(WebDAV))
+}
+
+func TestCreateSFTPProvider(t *testing.T) {
+	viper.Reset()
+	t.Setenv("SSH_AUTH_SOCK", "")
+
+	factory := NewPulsePointProviderFactory(context.Background())
+	_, err := factory.CreateProvider(SFTP)
+	assert.Error(t, err)
+
+	// Without a key or an agent there is no way to authenticate
+	viper.Set("providers.sftp.configured", true)
+	viper.Set("providers.sftp.host", "lab-01.example.com")
+	viper.Set("providers.sftp.user", "alice")
+	_, err = factory.CreateProvider(SFTP)
+	assert.Error(t, err)
+
+	t.Setenv("SSH_AUTH_SOCK", "/tmp/agent.sock")
+	viper.Set("providers.sftp.insecure_ignore_host_key", true)
+	provider, err := factory.CreateProvider(SFTP)
+	assert.NoError(t, err)
+	assert.Equal(t, "sftp", provider.GetProviderName())
+	assert.True(t, factory.IsProviderConfigured(SFTP))
+}
+
+func TestCreateLocalProvider(t *testing.T) {
+	viper.Reset()
+
+	factory := NewPulsePointProviderFactory(context.Background())
+	_, err := factory.CreateProvider(Local)
+	assert.Error(t, err)
+
+	viper.Set("providers.local.configured", true)
+	viper.Set("providers.local.root", t.TempDir())
+	provider, err := factory.CreateProvider(Local)
+	assert.NoError(t, err)
+	assert.Equal(t, "local", provider.GetProviderName())
+	assert.True(t, factory.IsProviderConfigured(Local))
+}
+
 func TestGetConfiguredProviders(t *testing.T) {
 	factory := NewPulsePointProviderFactory(context.Background())
 
@@ -0,0 +1,417 @@
+// Package filesystem implements a provider whose remote is another directory,
+// such as a mounted NAS share, a USB disk or a second drive
+package filesystem
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	pperrors "github.com/pulsepoint/pulsepoint/pkg/errors"
+	pplogger "github.com/pulsepoint/pulsepoint/pkg/logger"
+	"github.com/pulsepoint/pulsepoint/pkg/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	// Provider name
+	providerName = "local"
+
+	// MIME type reported for folders
+	mimeTypeFolder = "inode/directory"
+
+	// tempSuffix marks in-progress writes, which List hides
+	tempSuffix = ".pulsepoint-tmp"
+)
+
+// PulsePointFilesystemProvider implements CloudProvider on a local directory
+type PulsePointFilesystemProvider struct {
+	config    *Config
+	root      string
+	logger    *zap.Logger
+	connected bool
+}
+
+// Config holds local directory configuration
+type Config struct {
+	Root string `json:"root"` // Directory that acts as the remote root
+}
+
+// NewPulsePointFilesystemProvider creates a new local directory provider. The
+// root must already exist, so an unmounted drive is not silently replaced by
+// an empty folder on the system disk.
+func NewPulsePointFilesystemProvider(config *Config) (*PulsePointFilesystemProvider, error) {
+	if config == nil || config.Root == "" {
+		return nil, pperrors.NewConfigError("local provider root directory is required", nil)
+	}
+
+	root, err := filepath.Abs(utils.CleanPath(config.Root))
+	if err != nil {
+		return nil, pperrors.NewConfigError(fmt.Sprintf("invalid root directory: %s", config.Root), err)
+	}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, pperrors.NewConfigError(fmt.Sprintf("root directory %s is not available. Is the drive mounted?", root), err)
+	}
+	if !info.IsDir() {
+		return nil, pperrors.NewConfigError(fmt.Sprintf("root %s is not a directory", root), nil)
+	}
+
+	provider := &PulsePointFilesystemProvider{
+		config:    config,
+		root:      root,
+		logger:    pplogger.Get(),
+		connected: true,
+	}
+
+	provider.logger.Info("Local directory provider initialized", zap.String("root", root))
+
+	return provider, nil
+}
+
+// Initialize initializes the provider with configuration
+func (p *PulsePointFilesystemProvider) Initialize(config interfaces.ProviderConfig) error {
+	// Already initialized in constructor
+	return nil
+}
+
+// Upload writes a file to a temporary name beside its target and renames it
+// into place, so readers never see a partial file
+func (p *PulsePointFilesystemProvider) Upload(ctx context.Context, file *interfaces.File) error {
+	if file == nil {
+		return pperrors.NewValidationError("file is nil", nil)
+	}
+	if err := p.check(ctx); err != nil {
+		return err
+	}
+
+	p.logger.Debug("Writing file to local directory",
+		zap.String("path", file.Path),
+		zap.Int64("size", file.Size))
+
+	target := p.fullPath(file.Path)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return p.wrapError("create folder", path.Dir(cleanPath(file.Path)), err)
+	}
+
+	// Prefer in-memory content, otherwise read from the local file
+	reader := file.Content
+	if reader == nil {
+		f, err := os.Open(file.LocalPath)
+		if err != nil {
+			return fmt.Errorf("failed to open local file: %w", err)
+		}
+		defer f.Close()
+		reader = f
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+".*"+tempSuffix)
+	if err != nil {
+		return p.wrapError("upload", file.Path, err)
+	}
+
+	written, err := io.Copy(temp, &contextReader{ctx: ctx, reader: reader})
+	if err == nil {
+		// Flush to the device before the rename makes the file visible
+		err = temp.Sync()
+	}
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && !file.ModifiedTime.IsZero() {
+		err = os.Chtimes(temp.Name(), file.ModifiedTime, file.ModifiedTime)
+	}
+	if err == nil {
+		err = os.Rename(temp.Name(), target)
+	}
+	if err != nil {
+		os.Remove(temp.Name())
+		return p.wrapError("upload", file.Path, err)
+	}
+
+	p.logger.Info("File uploaded successfully",
+		zap.String("path", file.Path),
+		zap.Int64("size", written))
+	return nil
+}
+
+// Download opens a file for reading. The caller must close the returned content.
+func (p *PulsePointFilesystemProvider) Download(ctx context.Context, remotePath string) (*interfaces.File, error) {
+	if err := p.check(ctx); err != nil {
+		return nil, err
+	}
+
+	p.logger.Debug("Reading file from local directory", zap.String("path", remotePath))
+
+	f, err := os.Open(p.fullPath(remotePath))
+	if err != nil {
+		return nil, p.wrapError("download", remotePath, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, p.wrapError("download", remotePath, err)
+	}
+	if info.IsDir() {
+		f.Close()
+		return nil, pperrors.NewValidationError(fmt.Sprintf("cannot download folder: %s", remotePath), nil)
+	}
+
+	return &interfaces.File{
+		ID:           cleanPath(remotePath),
+		Path:         cleanPath(remotePath),
+		Name:         path.Base(cleanPath(remotePath)),
+		Size:         info.Size(),
+		ModifiedTime: info.ModTime(),
+		Content:      f,
+	}, nil
+}
+
+// Delete deletes a file, or a folder and everything in it. Deleting a
+// missing path succeeds.
+func (p *PulsePointFilesystemProvider) Delete(ctx context.Context, remotePath string) error {
+	if err := p.check(ctx); err != nil {
+		return err
+	}
+
+	p.logger.Debug("Deleting file from local directory", zap.String("path", remotePath))
+
+	target := p.fullPath(remotePath)
+	if target == p.root {
+		return pperrors.NewValidationError("refusing to delete the root directory", nil)
+	}
+	if _, err := os.Lstat(target); os.IsNotExist(err) {
+		p.logger.Debug("File already deleted", zap.String("path", remotePath))
+		return nil
+	} else if err != nil {
+		return p.wrapError("delete", remotePath, err)
+	}
+	if err := os.RemoveAll(target); err != nil {
+		return p.wrapError("delete", remotePath, err)
+	}
+
+	p.logger.Info("File deleted successfully", zap.String("path", remotePath))
+	return nil
+}
+
+// List lists the files and folders directly inside a folder. Hashes are left
+// empty since computing them means reading every file; GetMetadata has them.
+func (p *PulsePointFilesystemProvider) List(ctx context.Context, folder string) ([]*interfaces.File, error) {
+	if err := p.check(ctx); err != nil {
+		return nil, err
+	}
+
+	p.logger.Debug("Listing files in folder", zap.String("folder", folder))
+
+	entries, err := os.ReadDir(p.fullPath(folder))
+	if err != nil {
+		return nil, p.wrapError("list", folder, err)
+	}
+
+	var files []*interfaces.File
+	for _, entry := range entries {
+		// Skip symlinks, devices and in-progress writes
+		if strings.HasSuffix(entry.Name(), tempSuffix) || (!entry.IsDir() && !entry.Type().IsRegular()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			// Removed since ReadDir
+			continue
+		}
+
+		meta := metadata(path.Join(cleanPath(folder), entry.Name()), info)
+		files = append(files, &interfaces.File{
+			ID:           meta.ID,
+			Path:         meta.Path,
+			Name:         entry.Name(),
+			Size:         meta.Size,
+			MimeType:     meta.MimeType,
+			ModifiedTime: meta.ModifiedTime,
+			IsFolder:     meta.IsFolder,
+			Permissions:  meta.Attributes["mode"].(string),
+		})
+	}
+
+	return files, nil
+}
+
+// GetMetadata gets file metadata, hashing the file with MD5 like the cloud providers
+func (p *PulsePointFilesystemProvider) GetMetadata(ctx context.Context, remotePath string) (*interfaces.Metadata, error) {
+	if err := p.check(ctx); err != nil {
+		return nil, err
+	}
+
+	p.logger.Debug("Getting file metadata", zap.String("path", remotePath))
+
+	target := p.fullPath(remotePath)
+	info, err := os.Stat(target)
+	if err != nil {
+		return nil, p.wrapError("stat", remotePath, err)
+	}
+
+	meta := metadata(cleanPath(remotePath), info)
+	if !meta.IsFolder {
+		hash, err := utils.FileHash(target)
+		if err != nil {
+			return nil, p.wrapError("hash", remotePath, err)
+		}
+		meta.Hash = hash
+	}
+	return meta, nil
+}
+
+// CreateFolder creates a folder and any missing parents
+func (p *PulsePointFilesystemProvider) CreateFolder(ctx context.Context, remotePath string) error {
+	if err := p.check(ctx); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(p.fullPath(remotePath), 0755); err != nil {
+		return p.wrapError("create folder", remotePath, err)
+	}
+
+	p.logger.Debug("Folder ensured", zap.String("path", remotePath))
+	return nil
+}
+
+// Move renames a file or folder, creating the destination's parent folders
+func (p *PulsePointFilesystemProvider) Move(ctx context.Context, sourcePath, destPath string) error {
+	if err := p.check(ctx); err != nil {
+		return err
+	}
+
+	p.logger.Debug("Moving file",
+		zap.String("source", sourcePath),
+		zap.String("destination", destPath))
+
+	source := p.fullPath(sourcePath)
+	if _, err := os.Lstat(source); err != nil {
+		return p.wrapError("move", sourcePath, err)
+	}
+
+	target := p.fullPath(destPath)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return p.wrapError("create folder", path.Dir(cleanPath(destPath)), err)
+	}
+	if err := os.Rename(source, target); err != nil {
+		return p.wrapError("move", sourcePath, err)
+	}
+
+	p.logger.Info("File moved successfully",
+		zap.String("source", sourcePath),
+		zap.String("destination", destPath))
+
+	return nil
+}
+
+// GetQuota reports the file system holding the root. Platforms without
+// statfs report an empty quota.
+func (p *PulsePointFilesystemProvider) GetQuota(ctx context.Context) (*interfaces.QuotaInfo, error) {
+	if err := p.check(ctx); err != nil {
+		return nil, err
+	}
+
+	quota, err := diskUsage(p.root)
+	if err != nil {
+		return nil, p.wrapError("statfs", "/", err)
+	}
+	return quota, nil
+}
+
+// GetProviderName returns the provider name
+func (p *PulsePointFilesystemProvider) GetProviderName() string {
+	return providerName
+}
+
+// IsConnected checks if the provider is connected
+func (p *PulsePointFilesystemProvider) IsConnected() bool {
+	return p.connected
+}
+
+// Disconnect closes the connection to the provider
+func (p *PulsePointFilesystemProvider) Disconnect() error {
+	p.connected = false
+	p.logger.Info("Disconnected from local directory")
+	return nil
+}
+
+// check fails once the provider is disconnected or the context is done
+func (p *PulsePointFilesystemProvider) check(ctx context.Context) error {
+	if !p.connected {
+		return pperrors.NewProviderError("local provider is disconnected", nil)
+	}
+	return ctx.Err()
+}
+
+// fullPath maps a remote path to a path under the root. Cleaning it as an
+// absolute path first keeps ".." from escaping the root.
+func (p *PulsePointFilesystemProvider) fullPath(remotePath string) string {
+	return filepath.Join(p.root, filepath.FromSlash(cleanPath(remotePath)))
+}
+
+// wrapError turns a file system error into a provider error
+func (p *PulsePointFilesystemProvider) wrapError(op, remotePath string, err error) error {
+	switch {
+	case os.IsNotExist(err):
+		return notFoundError(remotePath)
+	case os.IsPermission(err):
+		pe := pperrors.NewFileSystemError(fmt.Sprintf("%s %s: permission denied", op, remotePath), err)
+		pe.StatusCode = 403
+		return pe
+	case err == context.Canceled, err == context.DeadlineExceeded:
+		return err
+	default:
+		return pperrors.NewFileSystemError(fmt.Sprintf("%s %s failed", op, remotePath), err)
+	}
+}
+
+// contextReader stops a copy once its context is done
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (r *contextReader) Read(b []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.reader.Read(b)
+}
+
+// metadata converts file info to provider metadata
+func metadata(remotePath string, info os.FileInfo) *interfaces.Metadata {
+	meta := &interfaces.Metadata{
+		ID:           remotePath,
+		Path:         remotePath,
+		Size:         info.Size(),
+		ModifiedTime: info.ModTime(),
+		IsFolder:     info.IsDir(),
+		Attributes: map[string]interface{}{
+			"mode": fmt.Sprintf("%04o", info.Mode().Perm()),
+		},
+	}
+	if meta.IsFolder {
+		meta.Size = 0
+		meta.MimeType = mimeTypeFolder
+	}
+	return meta
+}
+
+// cleanPath normalises a remote path to a clean absolute path
+func cleanPath(remotePath string) string {
+	return path.Clean("/" + filepath.ToSlash(remotePath))
+}
+
+// notFoundError returns a provider error that pperrors.IsNotFoundError recognises
+func notFoundError(remotePath string) error {
+	err := pperrors.NewProviderError(fmt.Sprintf("file not found: %s", remotePath), nil)
+	err.StatusCode = 404
+	return err
+}
@@ -0,0 +1,101 @@
+package filesystem
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"github.com/pulsepoint/pulsepoint/internal/providers/providertest"
+	pperrors "github.com/pulsepoint/pulsepoint/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilesystemProviderRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	provider, err := NewPulsePointFilesystemProvider(&Config{Root: root})
+	require.NoError(t, err)
+
+	modTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, provider.Upload(ctx, &interfaces.File{
+		Path:         "/Docs/Reports/q1.txt",
+		Content:      strings.NewReader("quarterly"),
+		ModifiedTime: modTime,
+	}))
+
+	meta, err := provider.GetMetadata(ctx, "/Docs/Reports/q1.txt")
+	require.NoError(t, err)
+	assert.Equal(t, int64(9), meta.Size)
+	assert.Equal(t, "4aeebd4aa1cedd190731299c3810a5db", meta.Hash)
+	assert.True(t, modTime.Equal(meta.ModifiedTime))
+
+	file, err := provider.Download(ctx, "/Docs/Reports/q1.txt")
+	require.NoError(t, err)
+	content, err := io.ReadAll(file.Content)
+	require.NoError(t, err)
+	require.NoError(t, file.Content.(io.Closer).Close())
+	assert.Equal(t, "quarterly", string(content))
+
+	require.NoError(t, provider.Move(ctx, "/Docs/Reports", "/Archive/2024/Reports"))
+	_, err = provider.GetMetadata(ctx, "/Docs/Reports/q1.txt")
+	assert.True(t, pperrors.IsNotFoundError(err))
+
+	files, err := provider.List(ctx, "/Archive/2024")
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "/Archive/2024/Reports", files[0].Path)
+	assert.True(t, files[0].IsFolder)
+
+	require.NoError(t, provider.Delete(ctx, "/Archive"))
+	assert.NoError(t, provider.Delete(ctx, "/Archive"), "deleting a missing path succeeds")
+
+	quota, err := provider.GetQuota(ctx)
+	require.NoError(t, err)
+	assert.Positive(t, quota.Total)
+}
+
+func TestFilesystemProviderStaysInsideRoot(t *testing.T) {
+	ctx := context.Background()
+	parent := t.TempDir()
+	root := filepath.Join(parent, "nas")
+	require.NoError(t, os.Mkdir(root, 0755))
+
+	provider, err := NewPulsePointFilesystemProvider(&Config{Root: root})
+	require.NoError(t, err)
+
+	require.NoError(t, provider.Upload(ctx, &interfaces.File{
+		Path:    "../escape.txt",
+		Content: strings.NewReader("contained"),
+	}))
+	_, err = os.Stat(filepath.Join(root, "escape.txt"))
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(parent, "escape.txt"))
+	assert.True(t, os.IsNotExist(err))
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(root)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	assert.Error(t, provider.Delete(ctx, "/"))
+}
+
+func TestFilesystemProviderRequiresExistingRoot(t *testing.T) {
+	_, err := NewPulsePointFilesystemProvider(&Config{Root: filepath.Join(t.TempDir(), "unmounted")})
+	require.Error(t, err)
+	assert.True(t, pperrors.IsConfigError(err))
+}
+
+func TestFilesystemProviderConformance(t *testing.T) {
+	providertest.Run(t, func(t *testing.T) interfaces.CloudProvider {
+		provider, err := NewPulsePointFilesystemProvider(&Config{Root: t.TempDir()})
+		require.NoError(t, err)
+		return provider
+	})
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package filesystem
+
+import "github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+
+// diskUsage is not available on this platform, so the quota is left empty
+func diskUsage(dir string) (*interfaces.QuotaInfo, error) {
+	return &interfaces.QuotaInfo{}, nil
+}
@@ -0,0 +1,23 @@
+//go:build linux || darwin || freebsd
+
+package filesystem
+
+import (
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"golang.org/x/sys/unix"
+)
+
+// diskUsage reads the size and free space of the file system holding dir
+func diskUsage(dir string) (*interfaces.QuotaInfo, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(dir, &stat); err != nil {
+		return nil, err
+	}
+
+	blockSize := uint64(stat.Bsize)
+	return &interfaces.QuotaInfo{
+		Total:     int64(uint64(stat.Blocks) * blockSize),
+		Available: int64(uint64(stat.Bavail) * blockSize),
+		Used:      int64((uint64(stat.Blocks) - uint64(stat.Bfree)) * blockSize),
+	}, nil
+}
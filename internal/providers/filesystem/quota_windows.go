@@ -0,0 +1,26 @@
+//go:build windows
+
+package filesystem
+
+import (
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"golang.org/x/sys/windows"
+)
+
+// diskUsage reads the size and free space of the volume holding dir
+func diskUsage(dir string) (*interfaces.QuotaInfo, error) {
+	name, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var available, total, free uint64
+	if err := windows.GetDiskFreeSpaceEx(name, &available, &total, &free); err != nil {
+		return nil, err
+	}
+	return &interfaces.QuotaInfo{
+		Total:     int64(total),
+		Available: int64(available),
+		Used:      int64(total - free),
+	}, nil
+}
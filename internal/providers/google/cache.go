@@ -0,0 +1,148 @@
+package google
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"google.golang.org/api/drive/v3"
+)
+
+// Default time cached path lookups are trusted
+const defaultCacheTTL = 5 * time.Minute
+
+// cachedPath is the file a path resolved to
+type cachedPath struct {
+	ID       string    `json:"id"`
+	Folder   bool      `json:"folder"`
+	CachedAt time.Time `json:"cached_at"`
+}
+
+// pathCache remembers which file ID each remote path resolved to, and the
+// path of each ID, so that lookups need not walk the path one folder at a
+// time. Entries expire after the TTL and are dropped when the provider
+// changes a path or the change feed reports a change. A nil cache is empty.
+type pathCache struct {
+	store *stateStore
+	ttl   time.Duration
+	paths string // bucket of path → cachedPath
+	ids   string // bucket of file ID → path
+}
+
+// newPathCache returns the cache for paths beneath a root folder, or nil
+// when there is no store to keep it in
+func newPathCache(store *stateStore, rootFolderID string, ttl time.Duration) *pathCache {
+	if store == nil || ttl <= 0 {
+		return nil
+	}
+	return &pathCache{
+		store: store,
+		ttl:   ttl,
+		paths: "paths:" + rootFolderID,
+		ids:   "ids:" + rootFolderID,
+	}
+}
+
+// lookup returns the unexpired entry for a path, or nil
+func (c *pathCache) lookup(remotePath string) *cachedPath {
+	if c == nil {
+		return nil
+	}
+	entry := &cachedPath{}
+	if !c.store.get(c.paths, remotePath, entry) || time.Since(entry.CachedAt) > c.ttl {
+		return nil
+	}
+	return entry
+}
+
+// lookupFolder returns the deepest cached folder at or above a path, and
+// the ID it resolved to
+func (c *pathCache) lookupFolder(remotePath string) (string, string) {
+	for folder := remotePath; folder != "/"; folder = parentPath(folder) {
+		if entry := c.lookup(folder); entry != nil && entry.Folder {
+			return folder, entry.ID
+		}
+	}
+	return "/", ""
+}
+
+// put records the file a path resolved to
+func (c *pathCache) put(remotePath string, file *drive.File) {
+	if c == nil || remotePath == "/" || file == nil || file.Id == "" {
+		return
+	}
+	entry := &cachedPath{ID: file.Id, Folder: file.MimeType == mimeTypeFolder, CachedAt: time.Now()}
+	c.store.put(c.paths, remotePath, entry)
+	c.store.put(c.ids, file.Id, remotePath)
+}
+
+// forget drops a path and everything cached beneath it
+func (c *pathCache) forget(remotePath string) {
+	if c == nil {
+		return
+	}
+
+	var keys [][]byte
+	var ids []string
+	c.store.view(c.paths, func(paths *bolt.Bucket) error {
+		if paths == nil {
+			return nil
+		}
+		prefix := []byte(strings.TrimSuffix(remotePath, "/"))
+		cursor := paths.Cursor()
+		for key, value := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, value = cursor.Next() {
+			if !isBeneath(string(key), remotePath) {
+				continue
+			}
+			keys = append(keys, append([]byte(nil), key...))
+			entry := &cachedPath{}
+			if json.Unmarshal(value, entry) == nil {
+				ids = append(ids, entry.ID)
+			}
+		}
+		return nil
+	})
+	if len(keys) == 0 {
+		return
+	}
+
+	c.store.update(c.paths, func(paths *bolt.Bucket) error {
+		for _, key := range keys {
+			if err := paths.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	c.store.update(c.ids, func(idPaths *bolt.Bucket) error {
+		for _, id := range ids {
+			if err := idPaths.Delete([]byte(id)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// forgetID drops the path a file ID was cached under, and everything beneath it
+func (c *pathCache) forgetID(fileID string) {
+	if c == nil {
+		return
+	}
+	var remotePath string
+	if c.store.get(c.ids, fileID, &remotePath) && remotePath != "" {
+		c.forget(remotePath)
+	}
+	c.store.delete(c.ids, fileID)
+}
+
+// parentPath returns the folder containing a path
+func parentPath(remotePath string) string {
+	i := strings.LastIndex(remotePath, "/")
+	if i <= 0 {
+		return "/"
+	}
+	return remotePath[:i]
+}
@@ -0,0 +1,296 @@
+package google
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	pperrors "github.com/pulsepoint/pulsepoint/pkg/errors"
+	"go.uber.org/zap"
+	"google.golang.org/api/drive/v3"
+)
+
+// errContentMismatch is returned when downloaded content does not match the
+// size or checksum Drive reported for it
+var errContentMismatch = errors.New("downloaded content does not match")
+
+// downloadStream reads the content of a Drive file as it arrives. A dropped
+// connection is picked up with a range request from the last byte read, and
+// the MD5 checksum is verified once all content has been read.
+type downloadStream struct {
+	ctx      context.Context
+	provider *PulsePointGoogleDriveProvider
+	file     *drive.File
+	path     string
+	body     io.ReadCloser
+	hash     hash.Hash
+	offset   int64
+	failures int
+}
+
+// newDownloadStream returns a stream of a file's content from offset
+// onwards. prefix, the content before offset, is read to seed the checksum.
+func (p *PulsePointGoogleDriveProvider) newDownloadStream(ctx context.Context, remotePath string, file *drive.File, prefix io.Reader, offset int64) (*downloadStream, error) {
+	stream := &downloadStream{
+		ctx:      ctx,
+		provider: p,
+		file:     file,
+		path:     remotePath,
+		hash:     md5.New(),
+		offset:   offset,
+	}
+	if prefix != nil {
+		if _, err := io.CopyN(stream.hash, prefix, offset); err != nil {
+			return nil, pperrors.NewFileSystemError("failed to read partial download", err)
+		}
+	}
+
+	// Open the connection now so that failures surface from Download
+	if !stream.complete() {
+		if err := stream.open(); err != nil {
+			return nil, err
+		}
+	}
+	return stream, nil
+}
+
+// Read reads content, reconnecting when the transfer breaks off
+func (s *downloadStream) Read(b []byte) (int, error) {
+	for {
+		if s.body == nil {
+			if s.complete() {
+				return 0, s.verify()
+			}
+			if err := s.open(); err != nil {
+				return 0, err
+			}
+		}
+
+		n, err := s.body.Read(b)
+		s.offset += int64(n)
+		s.hash.Write(b[:n])
+
+		if err == io.EOF && (s.file.Md5Checksum == "" || s.offset >= s.file.Size) {
+			return n, s.verify()
+		}
+		if err != nil {
+			// The connection dropped or closed early, continue from here
+			s.body.Close()
+			s.body = nil
+			s.failures++
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			if s.ctx.Err() != nil || s.failures > s.provider.config.MaxRetries {
+				return n, apiError(fmt.Sprintf("download failed: %s", s.path), err)
+			}
+			s.provider.logger.Debug("Resuming download",
+				zap.String("path", s.path),
+				zap.Int64("offset", s.offset),
+				zap.Error(err))
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// Close closes the connection
+func (s *downloadStream) Close() error {
+	if s.body == nil {
+		return nil
+	}
+	err := s.body.Close()
+	s.body = nil
+	return err
+}
+
+// complete reports whether every byte of a file with known content has been read
+func (s *downloadStream) complete() bool {
+	return s.file.Md5Checksum != "" && s.offset >= s.file.Size
+}
+
+// open requests the content from the current offset
+func (s *downloadStream) open() error {
+	p := s.provider
+	err := p.withRetry(s.ctx, "download", func() error {
+		call := p.service.Files.Get(s.file.Id).Context(s.ctx)
+		if s.offset > 0 {
+			call.Header().Set("Range", fmt.Sprintf("bytes=%d-", s.offset))
+		}
+		resp, err := call.Download()
+		if err != nil {
+			return err
+		}
+
+		// A server that ignores the range sends everything again
+		if s.offset > 0 && resp.StatusCode != http.StatusPartialContent {
+			if _, err := io.CopyN(io.Discard, resp.Body, s.offset); err != nil {
+				resp.Body.Close()
+				return err
+			}
+		}
+		s.body = resp.Body
+		return nil
+	})
+	if err != nil {
+		return apiError(fmt.Sprintf("download failed: %s", s.path), err)
+	}
+	return nil
+}
+
+// verify checks the content read against the size and checksum Drive reported
+func (s *downloadStream) verify() error {
+	if s.file.Md5Checksum == "" {
+		return io.EOF
+	}
+	if s.offset != s.file.Size {
+		return pperrors.NewRetryable(pperrors.ProviderError,
+			fmt.Sprintf("download of %s returned %d bytes, expected %d", s.path, s.offset, s.file.Size), errContentMismatch)
+	}
+	if sum := hex.EncodeToString(s.hash.Sum(nil)); sum != s.file.Md5Checksum {
+		return pperrors.NewRetryable(pperrors.ProviderError,
+			fmt.Sprintf("checksum mismatch downloading %s: got %s, expected %s", s.path, sum, s.file.Md5Checksum), errContentMismatch)
+	}
+	return io.EOF
+}
+
+// DownloadFile downloads a file to localPath. The content is written to a
+// hidden partial file beside it, which replaces localPath once its checksum
+// has been verified. A partial file left by an interrupted download of the
+// same content is continued rather than started again.
+func (p *PulsePointGoogleDriveProvider) DownloadFile(ctx context.Context, remotePath, localPath string) (*interfaces.File, error) {
+	remotePath = cleanPath(remotePath)
+	p.logger.Debug("Downloading file from Google Drive",
+		zap.String("path", remotePath),
+		zap.String("local_path", localPath))
+
+	driveFile, err := p.findFileByPath(ctx, remotePath)
+	if err != nil {
+		return nil, err
+	}
+	if driveFile.MimeType == mimeTypeFolder {
+		return nil, pperrors.NewValidationError(fmt.Sprintf("cannot download folder: %s", remotePath), nil)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return nil, pperrors.NewFileSystemError(fmt.Sprintf("failed to create directory for %s", localPath), err)
+	}
+
+	partialPath := partialDownloadPath(localPath, driveFile)
+	removeStalePartials(localPath, partialPath)
+	resumed, err := p.downloadPartial(ctx, remotePath, partialPath, driveFile)
+	if resumed && errors.Is(err, errContentMismatch) {
+		// The partial file held something else, start over
+		_, err = p.downloadPartial(ctx, remotePath, partialPath, driveFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Rename(partialPath, localPath); err != nil {
+		return nil, pperrors.NewFileSystemError(fmt.Sprintf("failed to replace %s", localPath), err)
+	}
+
+	return &interfaces.File{
+		Path:         remotePath,
+		Name:         driveFile.Name,
+		Size:         driveFile.Size,
+		Hash:         driveFile.Md5Checksum,
+		ModifiedTime: modifiedTime(driveFile),
+		MimeType:     driveFile.MimeType,
+		LocalPath:    localPath,
+		RemoteID:     driveFile.Id,
+	}, nil
+}
+
+// downloadPartial appends the rest of a file's content to its partial file,
+// reporting whether there was content to continue from. The partial file is
+// kept when the transfer breaks off and removed when its content turns out
+// to be wrong.
+func (p *PulsePointGoogleDriveProvider) downloadPartial(ctx context.Context, remotePath, partialPath string, driveFile *drive.File) (bool, error) {
+	partial, err := os.OpenFile(partialPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return false, pperrors.NewFileSystemError(fmt.Sprintf("failed to create %s", partialPath), err)
+	}
+	defer partial.Close()
+
+	info, err := partial.Stat()
+	if err != nil {
+		return false, pperrors.NewFileSystemError(fmt.Sprintf("failed to read %s", partialPath), err)
+	}
+	offset := info.Size()
+	if driveFile.Md5Checksum == "" || offset > driveFile.Size {
+		offset = 0
+	}
+	if err := partial.Truncate(offset); err != nil {
+		return false, pperrors.NewFileSystemError(fmt.Sprintf("failed to truncate %s", partialPath), err)
+	}
+	if offset > 0 {
+		p.logger.Info("Continuing partial download",
+			zap.String("path", remotePath),
+			zap.Int64("offset", offset),
+			zap.Int64("size", driveFile.Size))
+	}
+
+	resumed := offset > 0
+
+	stream, err := p.newDownloadStream(ctx, remotePath, driveFile, partial, offset)
+	if err != nil {
+		return resumed, err
+	}
+	defer stream.Close()
+
+	if _, err := partial.Seek(offset, io.SeekStart); err != nil {
+		return resumed, pperrors.NewFileSystemError(fmt.Sprintf("failed to write %s", partialPath), err)
+	}
+	if _, err := io.Copy(partial, stream); err != nil {
+		if errors.Is(err, errContentMismatch) {
+			partial.Close()
+			os.Remove(partialPath)
+		}
+		var pe *pperrors.PulseError
+		if errors.As(err, &pe) {
+			return resumed, err
+		}
+		return resumed, pperrors.NewFileSystemError(fmt.Sprintf("failed to write %s", partialPath), err)
+	}
+
+	return resumed, partial.Close()
+}
+
+// partialDownloadPath names the partial file for a download after the
+// content's checksum, so that only the same content is ever continued. The
+// watchers never sync hidden .tmp names, see ignore.IsTempName.
+func partialDownloadPath(localPath string, driveFile *drive.File) string {
+	version := driveFile.Md5Checksum
+	if len(version) > 12 {
+		version = version[:12]
+	}
+	if version == "" {
+		version = "download"
+	}
+	return filepath.Join(filepath.Dir(localPath), "."+filepath.Base(localPath)+"."+version+".tmp")
+}
+
+// removeStalePartials removes partial files left by downloads of older
+// content of the same file
+func removeStalePartials(localPath, keep string) {
+	pattern := filepath.Join(filepath.Dir(localPath), "."+filepath.Base(localPath)+".*.tmp")
+	stale, _ := filepath.Glob(pattern)
+	for _, name := range stale {
+		if name != keep {
+			os.Remove(name)
+		}
+	}
+}
+
+var _ interfaces.FileDownloader = (*PulsePointGoogleDriveProvider)(nil)
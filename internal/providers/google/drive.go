@@ -2,13 +2,17 @@
 package google
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
+	"net/http"
 	"os"
+	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -35,28 +39,50 @@ const (
 
 	// Batch operation size
 	batchSize = 100
+
+	// Default number of retries for requests that fail transiently
+	defaultMaxRetries = 3
+
+	// Fields fetched whenever a file is looked up
+	fileFields = "id, name, size, modifiedTime, createdTime, md5Checksum, mimeType, parents, version, appProperties"
+
+	// Extra fields fetched by GetMetadata
+	metadataFields = fileFields + ", webViewLink, webContentLink, iconLink, thumbnailLink, owners, permissions, shared, starred, writersCanShare"
 )
 
 // PulsePointGoogleDriveProvider implements CloudProvider for Google Drive
 type PulsePointGoogleDriveProvider struct {
 	service      *drive.Service
+	client       *http.Client // Authorized client for upload sessions
 	config       *Config
 	logger       *zap.Logger
 	rootFolderID string
 	tokenSource  oauth2.TokenSource
+	state        *stateStore
+	cache        *pathCache
+	retryDelay   time.Duration
 }
 
-// Config holds Google Drive configuration
+// Config holds Google Drive configuration. The OAuth client is ClientID and
+// ClientSecret when both are set, otherwise it is read from CredentialsFile.
 type Config struct {
-	CredentialsFile          string   `json:"credentials_file"`
-	TokenFile                string   `json:"token_file"`
-	RootFolderID             string   `json:"root_folder_id"`
-	Scopes                   []string `json:"scopes"`
-	SimpleUploadThreshold    int64    `json:"simple_upload_threshold"`
-	ResumableUploadThreshold int64    `json:"resumable_upload_threshold"`
-	ChunkSize                int64    `json:"chunk_size"`
-	MaxRetries               int      `json:"max_retries"`
-	RateLimit                int      `json:"rate_limit"`
+	ClientID                 string        `json:"client_id"`
+	ClientSecret             string        `json:"client_secret"`
+	CredentialsFile          string        `json:"credentials_file"`
+	TokenFile                string        `json:"token_file"`
+	RootFolderID             string        `json:"root_folder_id"` // Optional: specific folder to use as root
+	Scopes                   []string      `json:"scopes"`
+	SimpleUploadThreshold    int64         `json:"simple_upload_threshold"`    // Smaller files are uploaded in one request (default 5MB)
+	ResumableUploadThreshold int64         `json:"resumable_upload_threshold"` // Larger files use sessions that survive restarts (default 100MB)
+	ChunkSize                int64         `json:"chunk_size"`                 // Chunk size for resumable uploads, a multiple of 256KiB (default 8MB)
+	StateFile                string        `json:"state_file"`                 // bbolt file for upload sessions and the path cache; empty to keep neither
+	CacheTTL                 time.Duration `json:"cache_ttl"`                  // How long cached path lookups are trusted (default 5m)
+	MaxRetries               int           `json:"max_retries"`                // Retries for transient failures (default 3)
+	HashAlgorithm            string        `json:"hash_algorithm"`             // Local hash recorded with uploads, md5 or sha256 (default sha256)
+	DeviceID                 string        `json:"device_id"`                  // Recorded with uploads to tell devices apart
+	RateLimit                int           `json:"rate_limit"`
+	Endpoint                 string        `json:"endpoint"` // Empty for Google, e.g. http://localhost:8080/drive/v3/ for a fake
+	HTTPClient               *http.Client  `json:"-"`
 }
 
 // NewPulsePointGoogleDriveProvider creates a new Google Drive provider
@@ -77,16 +103,37 @@ func NewPulsePointGoogleDriveProvider(config *Config) (*PulsePointGoogleDrivePro
 	if config.ChunkSize == 0 {
 		config.ChunkSize = uploadChunkSize
 	}
+	if config.ChunkSize < chunkAlignment {
+		config.ChunkSize = chunkAlignment
+	}
+	config.ChunkSize -= config.ChunkSize % chunkAlignment
+	if config.MaxRetries == 0 {
+		config.MaxRetries = defaultMaxRetries
+	}
+	if config.CacheTTL == 0 {
+		config.CacheTTL = defaultCacheTTL
+	}
+	if config.HashAlgorithm == "" {
+		config.HashAlgorithm = defaultHashAlgorithm
+	}
+	if config.HashAlgorithm != "md5" && config.HashAlgorithm != "sha256" {
+		return nil, pperrors.NewConfigError(fmt.Sprintf("unsupported hash algorithm: %s", config.HashAlgorithm), nil)
+	}
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{drive.DriveScope}
+	}
 
 	provider := &PulsePointGoogleDriveProvider{
 		config:       config,
 		logger:       logger,
 		rootFolderID: config.RootFolderID,
+		state:        newStateStore(config.StateFile, logger),
+		retryDelay:   2 * time.Second,
 	}
 
 	// Initialize OAuth2 client
 	if err := provider.initializeClient(); err != nil {
-		return nil, fmt.Errorf("failed to initialize Google Drive client: %w", err)
+		return nil, err
 	}
 
 	return provider, nil
@@ -95,38 +142,42 @@ func NewPulsePointGoogleDriveProvider(config *Config) (*PulsePointGoogleDrivePro
 // initializeClient initializes the Google Drive API client
 func (p *PulsePointGoogleDriveProvider) initializeClient() error {
 	ctx := context.Background()
-
-	// Read credentials file
-	b, err := os.ReadFile(p.config.CredentialsFile)
-	if err != nil {
-		return fmt.Errorf("unable to read credentials file: %w", err)
+	if p.config.HTTPClient != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, p.config.HTTPClient)
 	}
 
-	// Parse credentials
-	config, err := google.ConfigFromJSON(b, p.config.Scopes...)
+	config, err := p.oauthConfig()
 	if err != nil {
-		return fmt.Errorf("unable to parse credentials: %w", err)
+		return err
 	}
 
 	// Get token
 	token, err := p.loadToken()
 	if err != nil {
-		return fmt.Errorf("unable to load token: %w", err)
+		return pperrors.NewAuthError("unable to load Google Drive token. Run 'pulsepoint auth google' first", err)
 	}
 
 	// Create token source
 	p.tokenSource = config.TokenSource(ctx, token)
 
 	// Create Drive service
-	p.service, err = drive.NewService(ctx, option.WithTokenSource(p.tokenSource))
+	p.client = oauth2.NewClient(ctx, p.tokenSource)
+	options := []option.ClientOption{option.WithHTTPClient(p.client)}
+	if p.config.Endpoint != "" {
+		options = append(options, option.WithEndpoint(p.config.Endpoint))
+	}
+	p.service, err = drive.NewService(ctx, options...)
 	if err != nil {
-		return fmt.Errorf("unable to create Drive service: %w", err)
+		return pperrors.NewProviderError("unable to create Drive service", err)
 	}
 
 	// If no root folder specified, use root
 	if p.rootFolderID == "" {
 		p.rootFolderID = "root"
+	} else if err := p.verifyRootFolder(ctx); err != nil {
+		return err
 	}
+	p.cache = newPathCache(p.state, p.rootFolderID, p.config.CacheTTL)
 
 	p.logger.Info("Google Drive provider initialized",
 		zap.String("root_folder", p.rootFolderID))
@@ -134,6 +185,31 @@ func (p *PulsePointGoogleDriveProvider) initializeClient() error {
 	return nil
 }
 
+// oauthConfig returns the OAuth2 client configuration
+func (p *PulsePointGoogleDriveProvider) oauthConfig() (*oauth2.Config, error) {
+	if p.config.ClientID != "" && p.config.ClientSecret != "" {
+		return &oauth2.Config{
+			ClientID:     p.config.ClientID,
+			ClientSecret: p.config.ClientSecret,
+			Scopes:       p.config.Scopes,
+			Endpoint:     google.Endpoint,
+		}, nil
+	}
+
+	// Read credentials file
+	b, err := os.ReadFile(p.config.CredentialsFile)
+	if err != nil {
+		return nil, pperrors.NewConfigError("unable to read Google credentials file", err)
+	}
+
+	// Parse credentials
+	config, err := google.ConfigFromJSON(b, p.config.Scopes...)
+	if err != nil {
+		return nil, pperrors.NewConfigError("unable to parse Google credentials", err)
+	}
+	return config, nil
+}
+
 // loadToken loads the OAuth2 token from file
 func (p *PulsePointGoogleDriveProvider) loadToken() (*oauth2.Token, error) {
 	f, err := os.Open(p.config.TokenFile)
@@ -147,170 +223,251 @@ func (p *PulsePointGoogleDriveProvider) loadToken() (*oauth2.Token, error) {
 	return token, err
 }
 
+// verifyRootFolder verifies that the configured root folder exists and is a folder
+func (p *PulsePointGoogleDriveProvider) verifyRootFolder(ctx context.Context) error {
+	var root *drive.File
+	err := p.withRetry(ctx, "verify root folder", func() error {
+		var err error
+		root, err = p.service.Files.Get(p.rootFolderID).Fields("id, name, mimeType").Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return apiError(fmt.Sprintf("root folder %s not found or inaccessible", p.rootFolderID), err)
+	}
+	if root.MimeType != mimeTypeFolder {
+		return pperrors.NewConfigError(fmt.Sprintf("root folder %s is not a folder", p.rootFolderID), nil)
+	}
+	return nil
+}
+
 // Initialize initializes the provider with configuration
 func (p *PulsePointGoogleDriveProvider) Initialize(config interfaces.ProviderConfig) error {
 	// Already initialized in constructor
 	return nil
 }
 
-// Upload uploads a file to Google Drive
+// Upload uploads a file to Google Drive, replacing the content of an
+// existing file at the same path
 func (p *PulsePointGoogleDriveProvider) Upload(ctx context.Context, file *interfaces.File) error {
 	if file == nil {
 		return pperrors.NewValidationError("file is nil", nil)
 	}
 
+	remotePath := cleanPath(file.Path)
 	p.logger.Debug("Uploading file to Google Drive",
-		zap.String("path", file.Path),
+		zap.String("path", remotePath),
 		zap.Int64("size", file.Size))
 
 	// Ensure parent folder exists
-	parentID, err := p.ensureParentFolder(ctx, file.Path)
+	parentID, err := p.ensureParentFolder(ctx, remotePath)
 	if err != nil {
-		return fmt.Errorf("failed to ensure parent folder: %w", err)
+		return err
 	}
 
-	// Open local file
-	localFile, err := os.Open(file.LocalPath)
+	// Prefer in-memory content, otherwise read from the local file
+	content := file.Content
+	if content == nil {
+		f, err := os.Open(file.LocalPath)
+		if err != nil {
+			return pperrors.NewFileSystemError(fmt.Sprintf("failed to open local file: %s", file.LocalPath), err)
+		}
+		defer f.Close()
+		content = f
+	}
+
+	// Check if file already exists
+	existing, err := p.findChild(ctx, parentID, path.Base(remotePath), "")
 	if err != nil {
-		return fmt.Errorf("failed to open local file: %w", err)
+		return err
+	}
+	if existing != nil && existing.MimeType == mimeTypeFolder {
+		return pperrors.NewValidationError(fmt.Sprintf("cannot upload over folder: %s", remotePath), nil)
+	}
+
+	// Record the local file so that it can be compared without downloading
+	properties, err := p.appProperties(file, content)
+	if err != nil {
+		return pperrors.NewFileSystemError("failed to read upload content", err)
 	}
-	defer localFile.Close()
 
 	// Create Drive file metadata
 	driveFile := &drive.File{
-		Name:     filepath.Base(file.Path),
-		Parents:  []string{parentID},
-		MimeType: file.MimeType,
+		Name:          path.Base(remotePath),
+		MimeType:      file.MimeType,
+		AppProperties: properties,
+	}
+	if driveFile.MimeType == "" {
+		driveFile.MimeType = getMimeType(remotePath)
 	}
 
 	// Set modification time if available
 	if !file.ModifiedTime.IsZero() {
-		driveFile.ModifiedTime = file.ModifiedTime.Format(time.RFC3339)
+		driveFile.ModifiedTime = file.ModifiedTime.UTC().Format(time.RFC3339Nano)
 	}
 
-	// Check if file already exists
-	existingFile, err := p.findFileByPath(ctx, file.Path)
-	if err == nil && existingFile != nil {
-		// Update existing file
-		return p.updateFile(ctx, existingFile.Id, localFile, file)
+	var existingID string
+	if existing != nil {
+		existingID = existing.Id
 	}
 
-	// Create new file
-	var uploadCall *drive.FilesCreateCall
-
-	// Choose upload method based on file size
-	if file.Size < p.config.SimpleUploadThreshold {
-		// Simple upload for small files
-		uploadCall = p.service.Files.Create(driveFile).Media(localFile)
-	} else {
-		// Resumable upload for large files
-		uploadCall = p.service.Files.Create(driveFile).
-			Media(localFile, googleapi.ChunkSize(int(p.config.ChunkSize)))
+	var uploaded *drive.File
+	upload := func() error {
+		var err error
+		if existing != nil {
+			uploaded, err = p.updateFile(ctx, existing.Id, driveFile, content, file.Size)
+		} else {
+			uploaded, err = p.createFile(ctx, parentID, driveFile, content, file.Size)
+		}
+		return err
 	}
 
-	// Add fields to retrieve
-	uploadCall = uploadCall.Fields("id, name, size, modifiedTime, md5Checksum")
-
-	// Execute upload
-	uploadedFile, err := uploadCall.Context(ctx).Do()
+	// Large files go through a session that a later run can resume. Other
+	// content that can be rewound is retried, anything else gets one attempt.
+	seeker, seekable := content.(io.ReadSeeker)
+	switch {
+	case seekable && file.Size >= p.config.ResumableUploadThreshold:
+		uploaded, err = p.resumableUpload(ctx, remotePath, existingID, parentID, driveFile, seeker, file.ModifiedTime)
+	case seekable:
+		var start int64
+		start, err = seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return pperrors.NewFileSystemError("failed to read upload content", err)
+		}
+		err = p.withRetry(ctx, "upload", func() error {
+			if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+				return err
+			}
+			return upload()
+		})
+	default:
+		err = upload()
+	}
 	if err != nil {
-		return fmt.Errorf("upload failed: %w", err)
+		return apiError(fmt.Sprintf("upload failed: %s", remotePath), err)
 	}
+	p.cache.put(remotePath, uploaded)
 
 	p.logger.Info("File uploaded successfully",
-		zap.String("path", file.Path),
-		zap.String("id", uploadedFile.Id))
+		zap.String("path", remotePath),
+		zap.String("id", uploaded.Id))
 
 	return nil
 }
 
-// updateFile updates an existing file
-func (p *PulsePointGoogleDriveProvider) updateFile(ctx context.Context, fileID string, reader io.Reader, file *interfaces.File) error {
-	updateCall := p.service.Files.Update(fileID, &drive.File{
-		ModifiedTime: file.ModifiedTime.Format(time.RFC3339),
-	})
+// createFile creates a new file in the parent folder
+func (p *PulsePointGoogleDriveProvider) createFile(ctx context.Context, parentID string, driveFile *drive.File, content io.Reader, size int64) (*drive.File, error) {
+	create := *driveFile
+	create.Parents = []string{parentID}
 
-	// Choose upload method based on file size
-	if file.Size < p.config.SimpleUploadThreshold {
-		updateCall = updateCall.Media(reader)
-	} else {
-		updateCall = updateCall.Media(reader, googleapi.ChunkSize(int(p.config.ChunkSize)))
-	}
+	return p.service.Files.Create(&create).
+		Media(content, p.mediaOptions(driveFile.MimeType, size)...).
+		Fields(fileFields).
+		Context(ctx).
+		Do()
+}
 
-	_, err := updateCall.Context(ctx).Do()
-	return err
+// updateFile replaces the content of an existing file
+func (p *PulsePointGoogleDriveProvider) updateFile(ctx context.Context, fileID string, driveFile *drive.File, content io.Reader, size int64) (*drive.File, error) {
+	return p.service.Files.Update(fileID, driveFile).
+		Media(content, p.mediaOptions(driveFile.MimeType, size)...).
+		Fields(fileFields).
+		Context(ctx).
+		Do()
 }
 
-// Download downloads a file from Google Drive
-func (p *PulsePointGoogleDriveProvider) Download(ctx context.Context, path string) (*interfaces.File, error) {
-	p.logger.Debug("Downloading file from Google Drive", zap.String("path", path))
+// mediaOptions sends small files in one request and larger ones in
+// resumable chunks of the configured size
+func (p *PulsePointGoogleDriveProvider) mediaOptions(mimeType string, size int64) []googleapi.MediaOption {
+	chunkSize := 0
+	if size >= p.config.SimpleUploadThreshold {
+		chunkSize = int(p.config.ChunkSize)
+	}
+	return []googleapi.MediaOption{
+		googleapi.ContentType(mimeType),
+		googleapi.ChunkSize(chunkSize),
+	}
+}
+
+// Download downloads a file from Google Drive. The content streams from
+// Drive and its checksum is verified as it is read, so callers must read it
+// to the end to know it is intact, and close it.
+func (p *PulsePointGoogleDriveProvider) Download(ctx context.Context, remotePath string) (*interfaces.File, error) {
+	remotePath = cleanPath(remotePath)
+	p.logger.Debug("Downloading file from Google Drive", zap.String("path", remotePath))
 
 	// Find file by path
-	driveFile, err := p.findFileByPath(ctx, path)
+	driveFile, err := p.findFileByPath(ctx, remotePath)
 	if err != nil {
-		return nil, fmt.Errorf("file not found: %w", err)
+		return nil, err
 	}
-
-	// Get file content
-	response, err := p.service.Files.Get(driveFile.Id).Download()
-	if err != nil {
-		return nil, fmt.Errorf("download failed: %w", err)
+	if driveFile.MimeType == mimeTypeFolder {
+		return nil, pperrors.NewValidationError(fmt.Sprintf("cannot download folder: %s", remotePath), nil)
 	}
-	defer response.Body.Close()
 
-	// Read content
-	content, err := io.ReadAll(response.Body)
+	// Stream the content instead of holding it in memory
+	stream, err := p.newDownloadStream(ctx, remotePath, driveFile, nil, 0)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read content: %w", err)
+		return nil, err
 	}
 
-	// Parse modification time
-	modTime, _ := time.Parse(time.RFC3339, driveFile.ModifiedTime)
-
 	return &interfaces.File{
-		Path:         path,
+		Path:         remotePath,
 		Name:         driveFile.Name,
 		Size:         driveFile.Size,
 		Hash:         driveFile.Md5Checksum,
-		ModifiedTime: modTime,
+		ModifiedTime: modifiedTime(driveFile),
 		MimeType:     driveFile.MimeType,
-		Content:      bytes.NewReader(content),
+		Content:      stream,
 		IsFolder:     false,
 	}, nil
 }
 
-// Delete deletes a file from Google Drive
-func (p *PulsePointGoogleDriveProvider) Delete(ctx context.Context, path string) error {
-	p.logger.Debug("Deleting file from Google Drive", zap.String("path", path))
+// Delete deletes a file or folder from Google Drive. Deleting a path that
+// does not exist succeeds.
+func (p *PulsePointGoogleDriveProvider) Delete(ctx context.Context, remotePath string) error {
+	remotePath = cleanPath(remotePath)
+	p.logger.Debug("Deleting file from Google Drive", zap.String("path", remotePath))
+
+	if remotePath == "/" {
+		return pperrors.NewValidationError("cannot delete the root folder", nil)
+	}
 
 	// Find file by path
-	driveFile, err := p.findFileByPath(ctx, path)
+	driveFile, err := p.findFileByPath(ctx, remotePath)
+	if pperrors.IsNotFoundError(err) {
+		p.logger.Debug("File already deleted", zap.String("path", remotePath))
+		p.cache.forget(remotePath)
+		return nil
+	}
 	if err != nil {
-		return fmt.Errorf("file not found: %w", err)
+		return err
 	}
 
 	// Delete file
-	err = p.service.Files.Delete(driveFile.Id).Context(ctx).Do()
-	if err != nil {
-		return fmt.Errorf("delete failed: %w", err)
+	err = p.withRetry(ctx, "delete", func() error {
+		return p.service.Files.Delete(driveFile.Id).Context(ctx).Do()
+	})
+	if err != nil && !isNotFound(err) {
+		return apiError(fmt.Sprintf("delete failed: %s", remotePath), err)
 	}
+	p.cache.forget(remotePath)
 
-	p.logger.Info("File deleted successfully", zap.String("path", path))
+	p.logger.Info("File deleted successfully", zap.String("path", remotePath))
 	return nil
 }
 
 // List lists files in a folder
 func (p *PulsePointGoogleDriveProvider) List(ctx context.Context, folder string) ([]*interfaces.File, error) {
+	folder = cleanPath(folder)
 	p.logger.Debug("Listing files in folder", zap.String("folder", folder))
 
 	// Get folder ID
-	folderID := p.rootFolderID
-	if folder != "" && folder != "/" {
-		driveFolder, err := p.findFileByPath(ctx, folder)
-		if err != nil {
-			return nil, fmt.Errorf("folder not found: %w", err)
-		}
-		folderID = driveFolder.Id
+	driveFolder, err := p.findFileByPath(ctx, folder)
+	if err != nil {
+		return nil, err
+	}
+	if driveFolder.MimeType != mimeTypeFolder {
+		return nil, pperrors.NewValidationError(fmt.Sprintf("not a folder: %s", folder), nil)
 	}
 
 	// List files
@@ -318,34 +475,41 @@ func (p *PulsePointGoogleDriveProvider) List(ctx context.Context, folder string)
 	pageToken := ""
 
 	for {
-		query := fmt.Sprintf("'%s' in parents and trashed = false", folderID)
+		query := fmt.Sprintf("'%s' in parents and trashed = false", escapeQueryString(driveFolder.Id))
 		call := p.service.Files.List().
 			Q(query).
-			Fields("nextPageToken, files(id, name, size, modifiedTime, md5Checksum, mimeType)").
+			Fields("nextPageToken, files(" + fileFields + ")").
 			PageSize(int64(batchSize))
 
 		if pageToken != "" {
 			call = call.PageToken(pageToken)
 		}
 
-		result, err := call.Context(ctx).Do()
+		var result *drive.FileList
+		err := p.withRetry(ctx, "list", func() error {
+			var err error
+			result, err = call.Context(ctx).Do()
+			return err
+		})
 		if err != nil {
-			return nil, fmt.Errorf("list failed: %w", err)
+			return nil, apiError(fmt.Sprintf("list failed: %s", folder), err)
 		}
 
 		for _, driveFile := range result.Files {
-			modTime, _ := time.Parse(time.RFC3339, driveFile.ModifiedTime)
-
-			file := &interfaces.File{
-				Path:         filepath.Join(folder, driveFile.Name),
+			attributes := pulsePointAttributes(driveFile)
+			mode, _ := attributes[interfaces.AttributeMode].(string)
+			files = append(files, &interfaces.File{
+				ID:           driveFile.Id,
+				Path:         path.Join(folder, driveFile.Name),
 				Name:         driveFile.Name,
 				Size:         driveFile.Size,
 				Hash:         driveFile.Md5Checksum,
-				ModifiedTime: modTime,
+				ModifiedTime: modifiedTime(driveFile),
 				MimeType:     driveFile.MimeType,
 				IsFolder:     driveFile.MimeType == mimeTypeFolder,
-			}
-			files = append(files, file)
+				Permissions:  mode,
+				Attributes:   attributes,
+			})
 		}
 
 		pageToken = result.NextPageToken
@@ -358,39 +522,47 @@ func (p *PulsePointGoogleDriveProvider) List(ctx context.Context, folder string)
 }
 
 // GetMetadata gets file metadata
-func (p *PulsePointGoogleDriveProvider) GetMetadata(ctx context.Context, path string) (*interfaces.Metadata, error) {
-	p.logger.Debug("Getting file metadata", zap.String("path", path))
+func (p *PulsePointGoogleDriveProvider) GetMetadata(ctx context.Context, remotePath string) (*interfaces.Metadata, error) {
+	remotePath = cleanPath(remotePath)
+	p.logger.Debug("Getting file metadata", zap.String("path", remotePath))
 
 	// Find file by path
-	driveFile, err := p.findFileByPath(ctx, path)
+	driveFile, err := p.findFileByPath(ctx, remotePath)
 	if err != nil {
-		return nil, fmt.Errorf("file not found: %w", err)
+		return nil, err
 	}
 
 	// Get detailed metadata
-	file, err := p.service.Files.Get(driveFile.Id).
-		Fields("id, name, size, modifiedTime, createdTime, md5Checksum, mimeType, parents, webViewLink, owners, permissions").
-		Context(ctx).
-		Do()
+	var file *drive.File
+	err = p.withRetry(ctx, "get metadata", func() error {
+		var err error
+		file, err = p.service.Files.Get(driveFile.Id).Fields(metadataFields).Context(ctx).Do()
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get metadata: %w", err)
+		return nil, apiError(fmt.Sprintf("failed to get metadata: %s", remotePath), err)
 	}
 
-	modTime, _ := time.Parse(time.RFC3339, file.ModifiedTime)
-	createdTime, _ := time.Parse(time.RFC3339, file.CreatedTime)
-
 	metadata := &interfaces.Metadata{
-		Path:         path,
+		ID:           file.Id,
+		Path:         remotePath,
 		Size:         file.Size,
-		ModifiedTime: modTime,
-		CreatedTime:  createdTime,
+		ModifiedTime: modifiedTime(file),
+		CreatedTime:  parseTime(file.CreatedTime),
 		Hash:         file.Md5Checksum,
 		MimeType:     file.MimeType,
 		IsFolder:     file.MimeType == mimeTypeFolder,
+		Version:      strconv.FormatInt(file.Version, 10),
 		Attributes: map[string]interface{}{
-			"id":          file.Id,
-			"webViewLink": file.WebViewLink,
-			"parents":     file.Parents,
+			"id":              file.Id,
+			"parents":         file.Parents,
+			"webViewLink":     file.WebViewLink,
+			"webContentLink":  file.WebContentLink,
+			"iconLink":        file.IconLink,
+			"thumbnailLink":   file.ThumbnailLink,
+			"shared":          file.Shared,
+			"starred":         file.Starred,
+			"writersCanShare": file.WritersCanShare,
 		},
 	}
 
@@ -405,97 +577,76 @@ func (p *PulsePointGoogleDriveProvider) GetMetadata(ctx context.Context, path st
 		metadata.Attributes["permissionsCount"] = len(file.Permissions)
 	}
 
-	return metadata, nil
-}
-
-// CreateFolder creates a folder in Google Drive
-func (p *PulsePointGoogleDriveProvider) CreateFolder(ctx context.Context, path string) error {
-	p.logger.Debug("Creating folder", zap.String("path", path))
-
-	// Check if folder already exists
-	existing, _ := p.findFileByPath(ctx, path)
-	if existing != nil {
-		p.logger.Debug("Folder already exists", zap.String("path", path))
-		return nil
-	}
-
-	// Get parent folder ID
-	parentPath := filepath.Dir(path)
-	parentID := p.rootFolderID
-	if parentPath != "" && parentPath != "/" && parentPath != "." {
-		parentFolder, err := p.ensureParentFolder(ctx, path)
-		if err != nil {
-			return err
-		}
-		parentID = parentFolder
-	}
-
-	// Create folder
-	folder := &drive.File{
-		Name:     filepath.Base(path),
-		MimeType: mimeTypeFolder,
-		Parents:  []string{parentID},
+	// Add what PulsePoint recorded about the local file
+	for key, value := range pulsePointAttributes(file) {
+		metadata.Attributes[key] = value
 	}
 
-	createdFolder, err := p.service.Files.Create(folder).
-		Fields("id, name").
-		Context(ctx).
-		Do()
-	if err != nil {
-		return fmt.Errorf("failed to create folder: %w", err)
-	}
+	return metadata, nil
+}
 
-	p.logger.Info("Folder created successfully",
-		zap.String("path", path),
-		zap.String("id", createdFolder.Id))
+// CreateFolder creates a folder and any missing parents in Google Drive
+func (p *PulsePointGoogleDriveProvider) CreateFolder(ctx context.Context, remotePath string) error {
+	p.logger.Debug("Creating folder", zap.String("path", remotePath))
 
-	return nil
+	_, err := p.ensureFolder(ctx, remotePath)
+	return err
 }
 
-// Move moves a file or folder
+// Move moves a file or folder, replacing a file at the destination
 func (p *PulsePointGoogleDriveProvider) Move(ctx context.Context, sourcePath, destPath string) error {
+	sourcePath, destPath = cleanPath(sourcePath), cleanPath(destPath)
 	p.logger.Debug("Moving file",
 		zap.String("source", sourcePath),
 		zap.String("destination", destPath))
 
 	// Find source file
-	sourceFile, err := p.findFileByPath(ctx, sourcePath)
+	source, err := p.findFileByPath(ctx, sourcePath)
 	if err != nil {
-		return fmt.Errorf("source file not found: %w", err)
+		return err
 	}
 
 	// Get new parent folder
-	newParentPath := filepath.Dir(destPath)
-	newParentID := p.rootFolderID
-	if newParentPath != "" && newParentPath != "/" && newParentPath != "." {
-		newParentFolder, err := p.ensureParentFolder(ctx, destPath)
-		if err != nil {
-			return err
-		}
-		newParentID = newParentFolder
+	newParentID, err := p.ensureParentFolder(ctx, destPath)
+	if err != nil {
+		return err
 	}
 
-	// Get current parents
-	file, err := p.service.Files.Get(sourceFile.Id).Fields("parents").Context(ctx).Do()
+	// Drive allows duplicate names, so clear the destination first
+	existing, err := p.findChild(ctx, newParentID, path.Base(destPath), "")
 	if err != nil {
-		return fmt.Errorf("failed to get current parents: %w", err)
+		return err
 	}
-
-	// Update file with new parent and name
-	update := &drive.File{
-		Name: filepath.Base(destPath),
+	if existing != nil && existing.Id != source.Id {
+		if existing.MimeType == mimeTypeFolder {
+			return pperrors.NewValidationError(fmt.Sprintf("cannot move over folder: %s", destPath), nil)
+		}
+		err := p.withRetry(ctx, "delete", func() error {
+			return p.service.Files.Delete(existing.Id).Context(ctx).Do()
+		})
+		if err != nil && !isNotFound(err) {
+			return apiError(fmt.Sprintf("failed to replace %s", destPath), err)
+		}
+		p.cache.forget(destPath)
 	}
 
-	_, err = p.service.Files.Update(sourceFile.Id, update).
-		AddParents(newParentID).
-		RemoveParents(strings.Join(file.Parents, ",")).
-		Fields("id, name, parents").
-		Context(ctx).
-		Do()
+	// Update file with new name and, when the folder changes, new parent
+	call := p.service.Files.Update(source.Id, &drive.File{Name: path.Base(destPath)}).Fields(fileFields)
+	if path.Dir(sourcePath) != path.Dir(destPath) {
+		call = call.AddParents(newParentID).RemoveParents(strings.Join(source.Parents, ","))
+	}
 
+	var moved *drive.File
+	err = p.withRetry(ctx, "move", func() error {
+		var err error
+		moved, err = call.Context(ctx).Do()
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("move failed: %w", err)
+		return apiError(fmt.Sprintf("move failed: %s", sourcePath), err)
 	}
+	p.cache.forget(sourcePath)
+	p.cache.put(destPath, moved)
 
 	p.logger.Info("File moved successfully",
 		zap.String("source", sourcePath),
@@ -506,18 +657,24 @@ func (p *PulsePointGoogleDriveProvider) Move(ctx context.Context, sourcePath, de
 
 // GetQuota gets storage quota information
 func (p *PulsePointGoogleDriveProvider) GetQuota(ctx context.Context) (*interfaces.QuotaInfo, error) {
-	about, err := p.service.About.Get().
-		Fields("storageQuota, user").
-		Context(ctx).
-		Do()
+	var about *drive.About
+	err := p.withRetry(ctx, "get quota", func() error {
+		var err error
+		about, err = p.service.About.Get().Fields("storageQuota, user").Context(ctx).Do()
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get quota: %w", err)
+		return nil, apiError("failed to get quota", err)
 	}
 
 	quota := &interfaces.QuotaInfo{
-		Used:      about.StorageQuota.Usage,
-		Available: about.StorageQuota.Limit - about.StorageQuota.Usage,
-		Total:     about.StorageQuota.Limit,
+		Used:  about.StorageQuota.Usage,
+		Total: about.StorageQuota.Limit,
+	}
+
+	// Unlimited accounts report no limit
+	if quota.Total > 0 {
+		quota.Available = quota.Total - quota.Used
 	}
 
 	return quota, nil
@@ -538,126 +695,469 @@ func (p *PulsePointGoogleDriveProvider) Disconnect() error {
 	// Google Drive client doesn't need explicit disconnect
 	// but we can clear the service reference
 	p.service = nil
+	p.client = nil
 	p.tokenSource = nil
+	if err := p.state.close(); err != nil {
+		p.logger.Warn("Failed to close Google Drive state", zap.Error(err))
+	}
 	p.logger.Info("Disconnected from Google Drive")
 	return nil
 }
 
-// Helper methods
+// GetStartPageToken returns the current position in the Drive change log
+func (p *PulsePointGoogleDriveProvider) GetStartPageToken(ctx context.Context) (string, error) {
+	token, err := p.service.Changes.GetStartPageToken().Context(ctx).Do()
+	if err != nil {
+		return "", apiError("failed to get start page token", err)
+	}
+	return token.StartPageToken, nil
+}
 
-// findFileByPath finds a file by its path
-func (p *PulsePointGoogleDriveProvider) findFileByPath(ctx context.Context, path string) (*drive.File, error) {
-	// Clean and split path
-	path = filepath.Clean(path)
-	if path == "/" || path == "." {
-		// Return root folder
-		return &drive.File{Id: p.rootFolderID}, nil
+// ListChanges returns one page of Drive changes since the given page token
+func (p *PulsePointGoogleDriveProvider) ListChanges(ctx context.Context, pageToken string) (*interfaces.RemoteChangePage, error) {
+	resp, err := p.service.Changes.List(pageToken).
+		Fields("nextPageToken, newStartPageToken, changes(changeType, fileId, removed, file(id, name, size, modifiedTime, md5Checksum, mimeType, parents, trashed, appProperties))").
+		IncludeRemoved(true).
+		Spaces("drive").
+		PageSize(1000).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, apiError("failed to list changes", err)
 	}
 
-	parts := strings.Split(path, string(filepath.Separator))
-	currentParentID := p.rootFolderID
+	page := &interfaces.RemoteChangePage{
+		NextPageToken:     resp.NextPageToken,
+		NewStartPageToken: resp.NewStartPageToken,
+	}
 
-	// Navigate through path
-	for _, part := range parts {
-		if part == "" {
+	rootID, err := p.resolveRootID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	folderPaths := make(map[string]string)
+	for _, c := range resp.Changes {
+		// Shared drive changes have no file path
+		if c.ChangeType != "" && c.ChangeType != "file" {
 			continue
 		}
 
-		query := fmt.Sprintf("name = '%s' and '%s' in parents and trashed = false", part, currentParentID)
-		result, err := p.service.Files.List().
-			Q(query).
-			Fields("files(id, name, mimeType)").
-			PageSize(1).
-			Context(ctx).
-			Do()
+		change := interfaces.RemoteChange{
+			FileID:  c.FileId,
+			Removed: c.Removed,
+		}
+
+		// The file may have moved or gone, so its cached path cannot be trusted
+		p.cache.forgetID(c.FileId)
+
+		if c.File != nil {
+			change.Removed = change.Removed || c.File.Trashed
+			change.IsFolder = c.File.MimeType == mimeTypeFolder
+			change.Size = c.File.Size
+			change.Hash = c.File.Md5Checksum
+			change.ModifiedTime = modifiedTime(c.File)
+			change.Path = p.resolveChangePath(ctx, c.File, rootID, folderPaths)
+			if !change.Removed && change.Path != "" {
+				p.cache.put(change.Path, c.File)
+			}
+		}
+
+		page.Changes = append(page.Changes, change)
+	}
+
+	return page, nil
+}
+
+// resolveRootID returns the real ID of the root folder, which may be the "root" alias
+func (p *PulsePointGoogleDriveProvider) resolveRootID(ctx context.Context) (string, error) {
+	root, err := p.service.Files.Get(p.rootFolderID).Fields("id").Context(ctx).Do()
+	if err != nil {
+		return "", apiError("failed to resolve root folder", err)
+	}
+	return root.Id, nil
+}
+
+// resolveChangePath builds the path of a changed file by walking its parents.
+// It returns an empty path for files outside the root folder.
+func (p *PulsePointGoogleDriveProvider) resolveChangePath(
+	ctx context.Context,
+	file *drive.File,
+	rootID string,
+	folderPaths map[string]string,
+) string {
+	names := []string{file.Name}
+	var visited []string
+	parents := file.Parents
+
+	// Bound the walk in case of cycles in shared folders
+	for depth := 0; depth < 64; depth++ {
+		if len(parents) == 0 {
+			return ""
+		}
+
+		parentID := parents[0]
+		base, known := folderPaths[parentID]
+		if parentID == rootID {
+			base, known = "/", true
+		}
+
+		if known {
+			// Remember every folder walked through for the rest of the page
+			for i, id := range visited {
+				folderPaths[id] = joinReversed(base, names[i+1:])
+			}
+			return joinReversed(base, names)
+		}
+
+		parent, err := p.service.Files.Get(parentID).Fields("id, name, parents").Context(ctx).Do()
+		if err != nil {
+			return ""
+		}
+
+		visited = append(visited, parentID)
+		names = append(names, parent.Name)
+		parents = parent.Parents
+	}
+
+	return ""
+}
+
+// joinReversed joins names, which are ordered from leaf to root, beneath base
+func joinReversed(base string, names []string) string {
+	parts := make([]string, 0, len(names)+1)
+	parts = append(parts, base)
+	for i := len(names) - 1; i >= 0; i-- {
+		parts = append(parts, names[i])
+	}
+	return path.Join(parts...)
+}
 
+// Helper methods
+
+// findFileByPath finds a file or folder by its path. A cached ID takes one
+// request, otherwise the path is walked from the deepest cached folder.
+func (p *PulsePointGoogleDriveProvider) findFileByPath(ctx context.Context, remotePath string) (*drive.File, error) {
+	remotePath = cleanPath(remotePath)
+	if remotePath == "/" {
+		var root *drive.File
+		err := p.withRetry(ctx, "get root folder", func() error {
+			var err error
+			root, err = p.service.Files.Get(p.rootFolderID).Fields(fileFields).Context(ctx).Do()
+			return err
+		})
 		if err != nil {
-			return nil, err
+			return nil, apiError("failed to get root folder", err)
 		}
+		return root, nil
+	}
 
-		if len(result.Files) == 0 {
-			return nil, fmt.Errorf("file not found: %s", part)
+	if entry := p.cache.lookup(remotePath); entry != nil {
+		file, err := p.getCachedFile(ctx, remotePath, entry.ID, fileFields)
+		if file != nil || err != nil {
+			return file, err
 		}
+	}
+
+	return p.walk(ctx, remotePath, false)
+}
 
-		currentParentID = result.Files[0].Id
+// getCachedFile gets the file a path was cached as. It returns nil, and
+// forgets the path, when the file is no longer there.
+func (p *PulsePointGoogleDriveProvider) getCachedFile(ctx context.Context, remotePath, fileID, fields string) (*drive.File, error) {
+	var file *drive.File
+	err := p.withRetry(ctx, "get cached file", func() error {
+		var err error
+		file, err = p.service.Files.Get(fileID).Fields(googleapi.Field(fields + ", trashed")).Context(ctx).Do()
+		return err
+	})
+	if err != nil && !isNotFound(err) {
+		return nil, apiError(fmt.Sprintf("failed to look up %s", remotePath), err)
 	}
+	if err != nil || file.Trashed || file.Name != path.Base(remotePath) {
+		p.cache.forget(remotePath)
+		return nil, nil
+	}
+	return file, nil
+}
 
-	// Get the final file
-	file, err := p.service.Files.Get(currentParentID).
-		Fields("id, name, size, modifiedTime, md5Checksum, mimeType").
-		Context(ctx).
-		Do()
+// walk resolves a path one folder at a time, starting from the deepest
+// cached folder above it. With create set, missing folders are created and
+// every component must be a folder.
+func (p *PulsePointGoogleDriveProvider) walk(ctx context.Context, remotePath string, create bool) (*drive.File, error) {
+	start, startID := p.cache.lookupFolder(parentPath(remotePath))
+	if startID != "" {
+		file, stale, err := p.walkFrom(ctx, remotePath, start, startID, create)
+		if !stale {
+			return file, err
+		}
+		p.cache.forget(start)
+	}
 
+	file, _, err := p.walkFrom(ctx, remotePath, "/", p.rootFolderID, create)
 	return file, err
 }
 
+// walkFrom resolves the components of a path below the folder start. It
+// reports the folder as stale when a component is missing and the folder
+// is no longer where the cache says.
+func (p *PulsePointGoogleDriveProvider) walkFrom(ctx context.Context, remotePath, start, startID string, create bool) (*drive.File, bool, error) {
+	var file *drive.File
+	currentPath := strings.TrimSuffix(start, "/")
+	parentID := startID
+	for _, name := range strings.Split(strings.TrimPrefix(remotePath, currentPath+"/"), "/") {
+		currentPath += "/" + name
+
+		child, err := p.findChild(ctx, parentID, name, "")
+		if err != nil {
+			return nil, false, err
+		}
+
+		if child == nil && start != "/" {
+			// Only check the cached folder when it matters
+			moved, err := p.getCachedFile(ctx, start, startID, "id, name")
+			if err != nil {
+				return nil, false, err
+			}
+			if moved == nil {
+				return nil, true, nil
+			}
+			start = "/"
+		}
+
+		switch {
+		case child == nil && !create:
+			return nil, false, notFoundError(remotePath)
+		case child == nil:
+			child, err = p.createFolder(ctx, currentPath, parentID, name)
+			if err != nil {
+				return nil, false, err
+			}
+		case create && child.MimeType != mimeTypeFolder:
+			return nil, false, pperrors.NewValidationError(fmt.Sprintf("path exists but is not a folder: %s", currentPath), nil)
+		}
+
+		p.cache.put(currentPath, child)
+		file = child
+		parentID = child.Id
+	}
+
+	return file, false, nil
+}
+
+// findChild finds a direct child of a folder by name, optionally limited to
+// one MIME type. It returns nil when there is no such child.
+func (p *PulsePointGoogleDriveProvider) findChild(ctx context.Context, parentID, name, mimeType string) (*drive.File, error) {
+	query := fmt.Sprintf("name = '%s' and '%s' in parents and trashed = false",
+		escapeQueryString(name), escapeQueryString(parentID))
+	if mimeType != "" {
+		query += fmt.Sprintf(" and mimeType = '%s'", mimeType)
+	}
+
+	var result *drive.FileList
+	err := p.withRetry(ctx, "find file", func() error {
+		var err error
+		result, err = p.service.Files.List().
+			Q(query).
+			Fields("files(" + fileFields + ")").
+			PageSize(1).
+			Context(ctx).
+			Do()
+		return err
+	})
+	if err != nil {
+		return nil, apiError(fmt.Sprintf("failed to look up %s", name), err)
+	}
+
+	if len(result.Files) == 0 {
+		return nil, nil
+	}
+	return result.Files[0], nil
+}
+
 // ensureParentFolder ensures parent folder exists, creating if necessary
 func (p *PulsePointGoogleDriveProvider) ensureParentFolder(ctx context.Context, filePath string) (string, error) {
-	parentPath := filepath.Dir(filePath)
-	if parentPath == "" || parentPath == "/" || parentPath == "." {
+	return p.ensureFolder(ctx, path.Dir(cleanPath(filePath)))
+}
+
+// ensureFolder returns the ID of a folder, creating it and any missing
+// parents as necessary
+func (p *PulsePointGoogleDriveProvider) ensureFolder(ctx context.Context, folderPath string) (string, error) {
+	folderPath = cleanPath(folderPath)
+	if folderPath == "/" {
 		return p.rootFolderID, nil
 	}
+	if entry := p.cache.lookup(folderPath); entry != nil && entry.Folder {
+		return entry.ID, nil
+	}
 
-	// Try to find existing folder
-	folder, err := p.findFileByPath(ctx, parentPath)
-	if err == nil {
-		return folder.Id, nil
+	folder, err := p.walk(ctx, folderPath, true)
+	if err != nil {
+		return "", err
 	}
+	return folder.Id, nil
+}
 
-	// Create folder hierarchy
-	parts := strings.Split(parentPath, string(filepath.Separator))
-	currentParentID := p.rootFolderID
-	currentPath := ""
+// createFolder creates one folder in a parent
+func (p *PulsePointGoogleDriveProvider) createFolder(ctx context.Context, folderPath, parentID, name string) (*drive.File, error) {
+	folder := &drive.File{
+		Name:     name,
+		MimeType: mimeTypeFolder,
+		Parents:  []string{parentID},
+	}
 
-	for _, part := range parts {
-		if part == "" {
-			continue
+	var created *drive.File
+	err := p.withRetry(ctx, "create folder", func() error {
+		var err error
+		created, err = p.service.Files.Create(folder).Fields(fileFields).Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return nil, apiError(fmt.Sprintf("failed to create folder %s", folderPath), err)
+	}
+
+	p.logger.Debug("Created folder", zap.String("path", folderPath))
+	return created, nil
+}
+
+// withRetry runs fn until it succeeds, fails with an error that is not
+// transient, or MaxRetries retries have been made
+func (p *PulsePointGoogleDriveProvider) withRetry(ctx context.Context, operation string, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt > p.config.MaxRetries || !isRetryableError(err) {
+			return err
 		}
 
-		if currentPath == "" {
-			currentPath = part
-		} else {
-			currentPath = filepath.Join(currentPath, part)
+		p.logger.Debug("Retrying Google Drive request",
+			zap.String("operation", operation),
+			zap.Int("attempt", attempt),
+			zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(p.retryDelay * time.Duration(attempt)):
 		}
+	}
+}
 
-		// Check if folder exists
-		query := fmt.Sprintf("name = '%s' and '%s' in parents and mimeType = '%s' and trashed = false",
-			part, currentParentID, mimeTypeFolder)
+// getMimeType gets the MIME type of a file from its extension
+func getMimeType(remotePath string) string {
+	switch strings.ToLower(path.Ext(remotePath)) {
+	case ".txt":
+		return "text/plain"
+	case ".html", ".htm":
+		return "text/html"
+	case ".css":
+		return "text/css"
+	case ".js":
+		return "application/javascript"
+	case ".json":
+		return "application/json"
+	case ".xml":
+		return "application/xml"
+	case ".pdf":
+		return "application/pdf"
+	case ".zip":
+		return "application/zip"
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".png":
+		return "image/png"
+	case ".gif":
+		return "image/gif"
+	case ".mp4":
+		return "video/mp4"
+	case ".mp3":
+		return "audio/mpeg"
+	default:
+		return "application/octet-stream"
+	}
+}
 
-		result, err := p.service.Files.List().
-			Q(query).
-			Fields("files(id)").
-			PageSize(1).
-			Context(ctx).
-			Do()
+// Helper functions
 
-		if err != nil {
-			return "", err
+// cleanPath returns the absolute, slash-separated form of a remote path
+func cleanPath(remotePath string) string {
+	return path.Clean("/" + filepath.ToSlash(remotePath))
+}
+
+func parseTime(timeStr string) time.Time {
+	t, _ := time.Parse(time.RFC3339, timeStr)
+	return t
+}
+
+// escapeQueryString escapes a value for a quoted Drive query string
+func escapeQueryString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return strings.ReplaceAll(s, "'", `\'`)
+}
+
+// notFoundError is the error returned for paths that do not exist
+func notFoundError(remotePath string) error {
+	err := pperrors.NewProviderError(fmt.Sprintf("file not found: %s", remotePath), nil)
+	err.StatusCode = http.StatusNotFound
+	return err
+}
+
+// apiError turns a Drive API failure into a provider error that keeps the
+// HTTP status
+func apiError(message string, err error) error {
+	var pe *pperrors.PulseError
+	if errors.As(err, &pe) {
+		return err
+	}
+
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		if isRetryableError(err) {
+			return pperrors.NewRetryable(pperrors.NetworkError, message, err)
 		}
+		return pperrors.NewProviderError(message, err)
+	}
 
-		if len(result.Files) > 0 {
-			// Folder exists
-			currentParentID = result.Files[0].Id
-		} else {
-			// Create folder
-			folder := &drive.File{
-				Name:     part,
-				MimeType: mimeTypeFolder,
-				Parents:  []string{currentParentID},
-			}
+	switch {
+	case apiErr.Code == http.StatusUnauthorized:
+		pe = pperrors.NewAuthError(message, err)
+	case isRetryableError(apiErr):
+		pe = pperrors.NewRetryable(pperrors.ProviderError, message, err)
+	default:
+		pe = pperrors.NewProviderError(message, err)
+	}
+	pe.StatusCode = apiErr.Code
+	return pe
+}
 
-			created, err := p.service.Files.Create(folder).
-				Fields("id").
-				Context(ctx).
-				Do()
-			if err != nil {
-				return "", fmt.Errorf("failed to create folder %s: %w", currentPath, err)
-			}
+// isNotFound reports whether a Drive API call failed with 404
+func isNotFound(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound
+}
+
+// isRetryableError reports whether a request failed transiently
+func isRetryableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
 
-			currentParentID = created.Id
-			p.logger.Debug("Created folder", zap.String("path", currentPath))
+	// Retry on rate limit, server errors, and timeout
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		if apiErr.Code == http.StatusForbidden {
+			// Drive reports exceeded rate limits as 403
+			for _, item := range apiErr.Errors {
+				if item.Reason == "rateLimitExceeded" || item.Reason == "userRateLimitExceeded" {
+					return true
+				}
+			}
 		}
+		return apiErr.Code == http.StatusTooManyRequests ||
+			apiErr.Code == http.StatusRequestTimeout ||
+			apiErr.Code >= 500
 	}
 
-	return currentParentID, nil
+	// Retry on network errors
+	var netErr net.Error
+	return errors.As(err, &netErr)
 }
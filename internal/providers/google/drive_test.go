@@ -0,0 +1,854 @@
+package google
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"github.com/pulsepoint/pulsepoint/internal/providers/providertest"
+	pperrors "github.com/pulsepoint/pulsepoint/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/drive/v3"
+)
+
+const rootID = "root-id"
+
+var (
+	nameQuery    = regexp.MustCompile(`name = '((?:[^'\\]|\\.)*)'`)
+	parentQuery  = regexp.MustCompile(`'((?:[^'\\]|\\.)*)' in parents`)
+	mimeQuery    = regexp.MustCompile(`mimeType = '([^']*)'`)
+	queryEscapes = strings.NewReplacer(`\\`, `\`, `\'`, `'`)
+)
+
+// fakeFile is a file or folder in the fake drive
+type fakeFile struct {
+	id       string
+	name     string
+	mimeType string
+	parents  []string
+	data     []byte
+	modified time.Time
+	version  int64
+	props    map[string]string // appProperties
+}
+
+// fakeSession is a resumable upload in progress
+type fakeSession struct {
+	meta   drive.File
+	target *fakeFile // nil when the upload creates a new file
+	data   []byte
+}
+
+// fakeDrive is an in-memory Drive v3 API covering the calls the provider
+// makes, including multipart and resumable media uploads
+type fakeDrive struct {
+	t        *testing.T
+	url      string
+	mu       sync.Mutex
+	files    map[string]*fakeFile
+	sessions map[string]*fakeSession
+	nextID   int
+	failures int // requests still to fail with 503
+	calls    map[string]int
+
+	chunks     int // chunks stored by upload sessions
+	dropChunks int // chunks stored before further chunks fail with 503, 0 for no limit
+
+	changes []*drive.Change // served by the next changes.list
+
+	dropDownloads int   // downloads still to break off half way
+	corrupt       bool  // flip the first byte of downloaded content
+	ranges        []int // start offsets of range requests
+}
+
+func newFakeDrive(t *testing.T) *fakeDrive {
+	fake := &fakeDrive{
+		t:        t,
+		files:    map[string]*fakeFile{rootID: {id: rootID, name: "My Drive", mimeType: mimeTypeFolder}},
+		sessions: make(map[string]*fakeSession),
+		calls:    make(map[string]int),
+	}
+	server := httptest.NewServer(http.HandlerFunc(fake.serve))
+	t.Cleanup(server.Close)
+	fake.url = server.URL
+	return fake
+}
+
+func (f *fakeDrive) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer good-token" {
+		f.fail(w, http.StatusUnauthorized, "authError")
+		return
+	}
+	if f.failures > 0 {
+		f.failures--
+		f.fail(w, http.StatusServiceUnavailable, "backendError")
+		return
+	}
+
+	query := r.URL.Query()
+	upload := strings.HasPrefix(r.URL.Path, "/upload/")
+	route := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/upload"), "/drive/v3")
+	switch {
+	case upload && query.Get("upload_id") != "":
+		f.calls["chunk"]++
+		f.uploadChunk(w, r, query.Get("upload_id"))
+	case route == "/changes":
+		f.reply(w, &drive.ChangeList{Changes: f.changes, NewStartPageToken: "next"})
+		f.changes = nil
+	case route == "/about":
+		f.reply(w, &drive.About{StorageQuota: &drive.AboutStorageQuota{Limit: 4096, Usage: 1024}})
+	case route == "/files" && r.Method == http.MethodGet:
+		f.list(w, r)
+	case route == "/files" && r.Method == http.MethodPost:
+		f.write(w, r, nil, upload)
+	case strings.HasPrefix(route, "/files/"):
+		id := strings.TrimPrefix(route, "/files/")
+		if id == "root" {
+			id = rootID
+		}
+		file, ok := f.files[id]
+		if !ok {
+			f.fail(w, http.StatusNotFound, "notFound")
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			if query.Get("alt") == "media" {
+				f.media(w, r, file)
+				return
+			}
+			f.reply(w, f.view(file))
+		case http.MethodPatch:
+			f.write(w, r, file, upload)
+		case http.MethodDelete:
+			f.remove(id)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	default:
+		f.fail(w, http.StatusBadRequest, "badRequest")
+	}
+}
+
+// list answers files.list for the name, parent and MIME type queries the provider sends
+func (f *fakeDrive) list(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query().Get("q")
+	assert.Contains(f.t, q, "trashed = false")
+	f.calls["list"]++
+
+	var matches []*fakeFile
+	for _, file := range f.files {
+		if m := parentQuery.FindStringSubmatch(q); m != nil && !contains(file.parents, f.resolveID(queryEscapes.Replace(m[1]))) {
+			continue
+		}
+		if m := nameQuery.FindStringSubmatch(q); m != nil && file.name != queryEscapes.Replace(m[1]) {
+			continue
+		}
+		if m := mimeQuery.FindStringSubmatch(q); m != nil && file.mimeType != m[1] {
+			continue
+		}
+		matches = append(matches, file)
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].name < matches[j].name })
+
+	offset, _ := strconv.Atoi(r.URL.Query().Get("pageToken"))
+	size, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+	result := &drive.FileList{}
+	for i := offset; i < len(matches) && i < offset+size; i++ {
+		result.Files = append(result.Files, f.view(matches[i]))
+	}
+	if offset+size < len(matches) {
+		result.NextPageToken = strconv.Itoa(offset + size)
+	}
+	f.reply(w, result)
+}
+
+// write handles files.create and files.update, with or without media
+func (f *fakeDrive) write(w http.ResponseWriter, r *http.Request, target *fakeFile, upload bool) {
+	var meta drive.File
+	var data []byte
+
+	mediaType, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch {
+	case mediaType == "multipart/related":
+		reader := multipart.NewReader(r.Body, params["boundary"])
+		part, err := reader.NextPart()
+		require.NoError(f.t, err)
+		require.NoError(f.t, json.NewDecoder(part).Decode(&meta))
+		part, err = reader.NextPart()
+		require.NoError(f.t, err)
+		data, _ = io.ReadAll(part)
+	default:
+		body, _ := io.ReadAll(r.Body)
+		if len(body) > 0 {
+			require.NoError(f.t, json.Unmarshal(body, &meta))
+		}
+	}
+
+	f.calls[r.Method+" "+r.URL.Query().Get("uploadType")]++
+	if upload && r.URL.Query().Get("uploadType") == "resumable" {
+		f.nextID++
+		id := strconv.Itoa(f.nextID)
+		f.sessions[id] = &fakeSession{meta: meta, target: target}
+		w.Header().Set("Location", fmt.Sprintf("%s/upload/drive/v3/files?uploadType=resumable&upload_id=%s", f.url, id))
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if target != nil {
+		query := r.URL.Query()
+		if add := query.Get("addParents"); add != "" {
+			var parents []string
+			for _, id := range target.parents {
+				if !contains(strings.Split(query.Get("removeParents"), ","), id) {
+					parents = append(parents, id)
+				}
+			}
+			target.parents = append(parents, f.resolveID(add))
+		}
+	}
+	f.reply(w, f.view(f.store(target, &meta, data, upload)))
+}
+
+// uploadChunk stores one chunk of a resumable upload
+func (f *fakeDrive) uploadChunk(w http.ResponseWriter, r *http.Request, id string) {
+	session, ok := f.sessions[id]
+	if !ok {
+		f.fail(w, http.StatusNotFound, "notFound")
+		return
+	}
+
+	if f.dropChunks > 0 && f.chunks >= f.dropChunks {
+		f.fail(w, http.StatusServiceUnavailable, "backendError")
+		return
+	}
+
+	// Content-Range is "bytes first-last/total", with "*" for unknown parts.
+	// "bytes */total" without a body asks how much has been received.
+	spec := strings.TrimPrefix(r.Header.Get("Content-Range"), "bytes ")
+	byteRange, total, _ := strings.Cut(spec, "/")
+	if byteRange != "*" {
+		first, _, _ := strings.Cut(byteRange, "-")
+		start, _ := strconv.Atoi(first)
+		assert.Equal(f.t, len(session.data), start, "chunks arrive in order")
+		data, _ := io.ReadAll(r.Body)
+		session.data = append(session.data, data...)
+		f.chunks++
+	}
+
+	if total != "*" && total == strconv.Itoa(len(session.data)) {
+		delete(f.sessions, id)
+		f.reply(w, f.view(f.store(session.target, &session.meta, session.data, true)))
+		return
+	}
+
+	// The Go client library asks for 308 to be sent as 200 with an override
+	if len(session.data) > 0 {
+		w.Header().Set("Range", fmt.Sprintf("bytes=0-%d", len(session.data)-1))
+	}
+	if r.Header.Get("X-GUploader-No-308") == "yes" {
+		w.Header().Set("X-Http-Status-Code-Override", "308")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	w.WriteHeader(http.StatusPermanentRedirect)
+}
+
+// media serves file content, honouring "bytes=N-" ranges
+func (f *fakeDrive) media(w http.ResponseWriter, r *http.Request, file *fakeFile) {
+	data := file.data
+	if f.corrupt && len(data) > 0 {
+		data = append([]byte{data[0] ^ 0xff}, data[1:]...)
+	}
+
+	status := http.StatusOK
+	if spec := r.Header.Get("Range"); spec != "" {
+		var start int
+		_, err := fmt.Sscanf(spec, "bytes=%d-", &start)
+		require.NoError(f.t, err)
+		f.ranges = append(f.ranges, start)
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(data)-1, len(data)))
+		data = data[start:]
+		status = http.StatusPartialContent
+	}
+
+	// A response shorter than its Content-Length looks like a dropped connection
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(status)
+	if f.dropDownloads > 0 {
+		f.dropDownloads--
+		data = data[:len(data)/2]
+	}
+	w.Write(data)
+}
+
+// store applies metadata and content to a file, creating it when target is nil
+func (f *fakeDrive) store(target *fakeFile, meta *drive.File, data []byte, media bool) *fakeFile {
+	if target == nil {
+		f.nextID++
+		target = &fakeFile{id: fmt.Sprintf("file-%d", f.nextID), modified: time.Now()}
+		for _, parent := range meta.Parents {
+			target.parents = append(target.parents, f.resolveID(parent))
+		}
+		f.files[target.id] = target
+	}
+	if meta.Name != "" {
+		target.name = meta.Name
+	}
+	if meta.MimeType != "" {
+		target.mimeType = meta.MimeType
+	}
+	if media {
+		target.data = data
+		target.modified = time.Now()
+	}
+	if modified, err := time.Parse(time.RFC3339Nano, meta.ModifiedTime); err == nil {
+		// Drive keeps modification times to the millisecond
+		target.modified = modified.Truncate(time.Millisecond)
+	}
+	for key, value := range meta.AppProperties {
+		if target.props == nil {
+			target.props = make(map[string]string)
+		}
+		target.props[key] = value
+	}
+	target.version++
+	return target
+}
+
+// remove deletes a file and everything beneath it
+func (f *fakeDrive) remove(id string) {
+	delete(f.files, id)
+	for childID, child := range f.files {
+		if contains(child.parents, id) {
+			f.remove(childID)
+		}
+	}
+}
+
+func (f *fakeDrive) resolveID(id string) string {
+	if id == "root" {
+		return rootID
+	}
+	return id
+}
+
+func (f *fakeDrive) view(file *fakeFile) *drive.File {
+	view := &drive.File{
+		Id:            file.id,
+		Name:          file.name,
+		MimeType:      file.mimeType,
+		Parents:       file.parents,
+		ModifiedTime:  file.modified.UTC().Format(time.RFC3339Nano),
+		Version:       file.version,
+		AppProperties: file.props,
+	}
+	if file.mimeType != mimeTypeFolder {
+		sum := md5.Sum(file.data)
+		view.Size = int64(len(file.data))
+		view.Md5Checksum = hex.EncodeToString(sum[:])
+	}
+	return view
+}
+
+func (f *fakeDrive) reply(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
+
+func (f *fakeDrive) fail(w http.ResponseWriter, status int, reason string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    status,
+			"message": reason,
+			"errors":  []map[string]string{{"reason": reason}},
+		},
+	})
+}
+
+// children returns the names beneath a folder
+func (f *fakeDrive) children(parentID string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var names []string
+	for _, file := range f.files {
+		if contains(file.parents, parentID) {
+			names = append(names, file.name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// newTestConfig returns a configuration for the fake with a valid token on disk
+func newTestConfig(t *testing.T, fake *fakeDrive) *Config {
+	tokenFile := filepath.Join(t.TempDir(), "google_token.json")
+	token := fmt.Sprintf(`{"access_token":"good-token","token_type":"Bearer","expiry":%q}`,
+		time.Now().Add(time.Hour).Format(time.RFC3339))
+	require.NoError(t, os.WriteFile(tokenFile, []byte(token), 0600))
+
+	return &Config{
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		TokenFile:    tokenFile,
+		StateFile:    filepath.Join(t.TempDir(), "google_drive.db"),
+		Endpoint:     fake.url + "/drive/v3/",
+	}
+}
+
+func newTestProvider(t *testing.T, fake *fakeDrive, configure func(*Config)) *PulsePointGoogleDriveProvider {
+	config := newTestConfig(t, fake)
+	if configure != nil {
+		configure(config)
+	}
+
+	provider, err := NewPulsePointGoogleDriveProvider(config)
+	require.NoError(t, err)
+	provider.retryDelay = time.Millisecond
+	t.Cleanup(func() { provider.Disconnect() })
+	return provider
+}
+
+func TestGoogleDriveProviderRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeDrive(t)
+	provider := newTestProvider(t, fake, nil)
+
+	// Quotes and backslashes must be escaped in Drive queries
+	name := `it's a \ test.txt`
+	content := []byte("hello drive")
+	modified := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, provider.Upload(ctx, &interfaces.File{
+		Path:         "/docs/" + name,
+		Size:         int64(len(content)),
+		Content:      bytes.NewReader(content),
+		ModifiedTime: modified,
+	}))
+
+	file, err := provider.Download(ctx, "docs/"+name)
+	require.NoError(t, err)
+	data, err := io.ReadAll(file.Content)
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+	assert.Equal(t, "/docs/"+name, file.Path)
+	assert.Equal(t, "text/plain", file.MimeType)
+	assert.True(t, modified.Equal(file.ModifiedTime))
+
+	sum := md5.Sum(content)
+	meta, err := provider.GetMetadata(ctx, "/docs/"+name)
+	require.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(sum[:]), meta.Hash)
+	assert.Equal(t, int64(len(content)), meta.Size)
+	assert.Equal(t, "1", meta.Version)
+
+	// Uploading again replaces the content instead of adding a duplicate
+	require.NoError(t, provider.Upload(ctx, &interfaces.File{
+		Path:    "/docs/" + name,
+		Content: strings.NewReader("v2"),
+	}))
+	files, err := provider.List(ctx, "/docs")
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, int64(2), files[0].Size)
+
+	// Moving across folders creates the destination and replaces its file
+	require.NoError(t, provider.Upload(ctx, &interfaces.File{Path: "/archive/2024/old.txt", Content: strings.NewReader("old")}))
+	require.NoError(t, provider.Move(ctx, "/docs/"+name, "/archive/2024/old.txt"))
+	file, err = provider.Download(ctx, "/archive/2024/old.txt")
+	require.NoError(t, err)
+	data, _ = io.ReadAll(file.Content)
+	assert.Equal(t, "v2", string(data))
+	files, err = provider.List(ctx, "/docs")
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	root, err := provider.List(ctx, "/")
+	require.NoError(t, err)
+	require.Len(t, root, 2)
+	assert.Equal(t, "/archive", root[0].Path)
+	assert.True(t, root[0].IsFolder)
+
+	require.NoError(t, provider.Delete(ctx, "/archive"))
+	require.NoError(t, provider.Delete(ctx, "/archive"), "deleting a missing path succeeds")
+	_, err = provider.GetMetadata(ctx, "/archive/2024/old.txt")
+	assert.True(t, pperrors.IsNotFoundError(err))
+
+	quota, err := provider.GetQuota(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3072), quota.Available)
+}
+
+func TestGoogleDriveProviderChunkedUpload(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeDrive(t)
+	provider := newTestProvider(t, fake, func(config *Config) {
+		config.SimpleUploadThreshold = 1024
+		config.ChunkSize = 256 * 1024
+	})
+
+	// Uploads without in-memory content read the local file
+	content := bytes.Repeat([]byte("0123456789"), 60*1024)
+	localPath := filepath.Join(t.TempDir(), "video.mp4")
+	require.NoError(t, os.WriteFile(localPath, content, 0644))
+
+	require.NoError(t, provider.Upload(ctx, &interfaces.File{
+		Path:      "/media/video.mp4",
+		LocalPath: localPath,
+		Size:      int64(len(content)),
+	}))
+	assert.Equal(t, 1, fake.calls["POST resumable"], "one session is opened")
+	assert.Equal(t, 3, fake.calls["chunk"], "600KiB goes up in three 256KiB chunks")
+
+	file, err := provider.Download(ctx, "/media/video.mp4")
+	require.NoError(t, err)
+	data, err := io.ReadAll(file.Content)
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+	assert.Equal(t, "video/mp4", file.MimeType)
+}
+
+func TestGoogleDriveProviderResumableUpload(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeDrive(t)
+	sessionFile := filepath.Join(t.TempDir(), "uploads.db")
+	configure := func(config *Config) {
+		config.ResumableUploadThreshold = 512 * 1024
+		config.ChunkSize = 256 * 1024
+		config.StateFile = sessionFile
+		config.MaxRetries = 1
+	}
+
+	content := bytes.Repeat([]byte("pulsepoint"), 140*1024) // 1.4MB, six chunks
+	localPath := filepath.Join(t.TempDir(), "backup.tar")
+	require.NoError(t, os.WriteFile(localPath, content, 0644))
+	file := &interfaces.File{
+		Path:         "/backups/backup.tar",
+		LocalPath:    localPath,
+		Size:         int64(len(content)),
+		ModifiedTime: time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC),
+	}
+
+	// The connection drops after two chunks
+	fake.dropChunks = 2
+	interrupted := newTestProvider(t, fake, configure)
+	err := interrupted.Upload(ctx, file)
+	pe, ok := err.(*pperrors.PulseError)
+	require.True(t, ok, "%v", err)
+	assert.True(t, pe.IsRetryable(), "an interrupted upload can be retried")
+	files, err := interrupted.List(ctx, "/backups")
+	require.NoError(t, err)
+	assert.Empty(t, files)
+	require.NoError(t, interrupted.Disconnect())
+
+	// A new process picks the session up where the last one stopped
+	fake.dropChunks = 0
+	fake.calls = make(map[string]int)
+	resumed := newTestProvider(t, fake, configure)
+	require.NoError(t, resumed.Upload(ctx, file))
+	assert.Zero(t, fake.calls["POST resumable"], "no new session is opened")
+	assert.Equal(t, 5, fake.calls["chunk"], "one status query and the four remaining chunks")
+	assert.Equal(t, 6, fake.chunks)
+
+	downloaded, err := resumed.Download(ctx, file.Path)
+	require.NoError(t, err)
+	data, err := io.ReadAll(downloaded.Content)
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+	assert.Nil(t, resumed.state.loadSession(file.Path), "finished sessions are forgotten")
+
+	// Replacing the file resumes too, and a session Drive has forgotten starts over
+	content = bytes.Repeat([]byte("PULSEPOINT"), 140*1024)
+	require.NoError(t, os.WriteFile(localPath, content, 0644))
+	file.ModifiedTime = file.ModifiedTime.Add(time.Hour)
+	fake.chunks = 0
+	fake.dropChunks = 3
+	require.Error(t, resumed.Upload(ctx, file))
+	assert.Equal(t, 1, fake.calls["PATCH resumable"])
+
+	fake.mu.Lock()
+	fake.sessions = make(map[string]*fakeSession)
+	fake.mu.Unlock()
+	fake.dropChunks = 0
+	require.NoError(t, resumed.Upload(ctx, file))
+	assert.Equal(t, 2, fake.calls["PATCH resumable"], "an expired session is replaced")
+
+	downloaded, err = resumed.Download(ctx, file.Path)
+	require.NoError(t, err)
+	data, err = io.ReadAll(downloaded.Content)
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+	files, err = resumed.List(ctx, "/backups")
+	require.NoError(t, err)
+	assert.Len(t, files, 1, "the file is replaced, not duplicated")
+}
+
+func TestGoogleDriveProviderStreamingDownload(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeDrive(t)
+	provider := newTestProvider(t, fake, nil)
+
+	content := bytes.Repeat([]byte("streamed "), 100*1024)
+	require.NoError(t, provider.Upload(ctx, &interfaces.File{Path: "/video.bin", Content: bytes.NewReader(content)}))
+
+	// A dropped connection is continued with a range request
+	fake.dropDownloads = 1
+	file, err := provider.Download(ctx, "/video.bin")
+	require.NoError(t, err)
+	stream, ok := file.Content.(io.ReadCloser)
+	require.True(t, ok, "content streams")
+	data, err := io.ReadAll(stream)
+	require.NoError(t, err)
+	require.NoError(t, stream.Close())
+	assert.Equal(t, content, data)
+	assert.Equal(t, []int{len(content) / 2}, fake.ranges)
+
+	// Content that does not match its checksum is an error
+	fake.corrupt = true
+	file, err = provider.Download(ctx, "/video.bin")
+	require.NoError(t, err)
+	_, err = io.ReadAll(file.Content)
+	assert.ErrorIs(t, err, errContentMismatch)
+	fake.corrupt = false
+
+	// Downloading to a path continues the partial file a broken transfer left
+	localPath := filepath.Join(t.TempDir(), "videos", "video.bin")
+	fake.dropDownloads = defaultMaxRetries + 1
+	_, err = provider.DownloadFile(ctx, "/video.bin", localPath)
+	require.Error(t, err)
+	assert.NoFileExists(t, localPath, "nothing is written until the content is complete")
+
+	fake.ranges = nil
+	downloaded, err := provider.DownloadFile(ctx, "/video.bin", localPath)
+	require.NoError(t, err)
+	require.Len(t, fake.ranges, 1)
+	assert.Positive(t, fake.ranges[0], "the partial file is continued")
+	data, err = os.ReadFile(localPath)
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+	assert.Equal(t, int64(len(content)), downloaded.Size)
+	entries, err := os.ReadDir(filepath.Dir(localPath))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "the partial file is renamed into place")
+
+	// A partial file with the wrong content is thrown away
+	meta, err := provider.GetMetadata(ctx, "/video.bin")
+	require.NoError(t, err)
+	partialPath := partialDownloadPath(localPath, &drive.File{Md5Checksum: meta.Hash})
+	require.NoError(t, os.WriteFile(partialPath, []byte("garbage"), 0644))
+	fake.ranges = nil
+	_, err = provider.DownloadFile(ctx, "/video.bin", localPath)
+	require.NoError(t, err)
+	assert.Equal(t, []int{len("garbage")}, fake.ranges)
+	data, err = os.ReadFile(localPath)
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+	assert.NoFileExists(t, partialPath)
+
+	// Partial files of older content are removed
+	stalePath := partialDownloadPath(localPath, &drive.File{Md5Checksum: "0123456789abcdef"})
+	require.NoError(t, os.WriteFile(stalePath, []byte("old"), 0644))
+	_, err = provider.DownloadFile(ctx, "/video.bin", localPath)
+	require.NoError(t, err)
+	assert.NoFileExists(t, stalePath)
+}
+
+func TestGoogleDriveProviderPathCache(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeDrive(t)
+	provider := newTestProvider(t, fake, nil)
+
+	deep := "/a/b/c/d/e/f/g/h/i/j/file.txt"
+	require.NoError(t, provider.Upload(ctx, &interfaces.File{Path: deep, Content: strings.NewReader("v1")}))
+
+	// Known folders and files take no lookups
+	fake.calls = make(map[string]int)
+	require.NoError(t, provider.Upload(ctx, &interfaces.File{Path: deep, Content: strings.NewReader("v2")}))
+	assert.Equal(t, 1, fake.calls["list"], "only the file itself is looked up")
+	assert.Equal(t, 1, fake.calls["PATCH multipart"], "the file is replaced, not duplicated")
+
+	fake.calls = make(map[string]int)
+	meta, err := provider.GetMetadata(ctx, deep)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), meta.Size)
+	require.NoError(t, provider.Upload(ctx, &interfaces.File{Path: "/a/b/c/d/e/f/g/h/i/j/new.txt", Content: strings.NewReader("new")}))
+	assert.Equal(t, 1, fake.calls["list"], "a new file in a known folder takes one lookup")
+
+	// The provider's own changes keep the cache right
+	require.NoError(t, provider.Move(ctx, "/a/b/c", "/x/c"))
+	_, err = provider.GetMetadata(ctx, deep)
+	assert.True(t, pperrors.IsNotFoundError(err), "moved paths are forgotten: %v", err)
+	_, err = provider.GetMetadata(ctx, "/x/c/d/e/f/g/h/i/j/file.txt")
+	require.NoError(t, err)
+
+	require.NoError(t, provider.Delete(ctx, "/x/c/d/e"))
+	_, err = provider.GetMetadata(ctx, "/x/c/d/e/f/g/h/i/j/file.txt")
+	assert.True(t, pperrors.IsNotFoundError(err), "deleted paths are forgotten: %v", err)
+	require.NoError(t, provider.Upload(ctx, &interfaces.File{Path: "/x/c/d/e/again.txt", Content: strings.NewReader("again")}))
+	assert.Equal(t, []string{"again.txt"}, fake.children(provider.cache.lookup("/x/c/d/e").ID), "deleted folders are recreated")
+
+	// Changes made elsewhere are picked up from the change feed
+	folder := provider.cache.lookup("/x/c/d")
+	require.NotNil(t, folder)
+	fake.mu.Lock()
+	renamed := fake.files[folder.ID]
+	renamed.name = "renamed"
+	fake.changes = []*drive.Change{{ChangeType: "file", FileId: renamed.id, File: fake.view(renamed)}}
+	fake.mu.Unlock()
+
+	page, err := provider.ListChanges(ctx, "start")
+	require.NoError(t, err)
+	require.Len(t, page.Changes, 1)
+	assert.Equal(t, "/x/c/renamed", page.Changes[0].Path)
+	_, err = provider.GetMetadata(ctx, "/x/c/d/e/again.txt")
+	assert.True(t, pperrors.IsNotFoundError(err), "renamed paths are forgotten: %v", err)
+	file, err := provider.Download(ctx, "/x/c/renamed/e/again.txt")
+	require.NoError(t, err)
+	data, err := io.ReadAll(file.Content)
+	require.NoError(t, err)
+	assert.Equal(t, "again", string(data))
+
+	// The cache survives a restart and expires
+	require.NoError(t, provider.Disconnect())
+	restarted := newTestProvider(t, fake, func(config *Config) { config.StateFile = provider.config.StateFile })
+	fake.calls = make(map[string]int)
+	_, err = restarted.GetMetadata(ctx, "/x/c/renamed/e/again.txt")
+	require.NoError(t, err)
+	assert.Zero(t, fake.calls["list"])
+
+	restarted.cache.ttl = time.Nanosecond
+	_, err = restarted.GetMetadata(ctx, "/x/c/renamed/e/again.txt")
+	require.NoError(t, err)
+	assert.Equal(t, 5, fake.calls["list"], "expired entries are looked up again")
+}
+
+func TestGoogleDriveProviderAppProperties(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeDrive(t)
+	provider := newTestProvider(t, fake, func(config *Config) { config.DeviceID = "laptop" })
+
+	content := []byte("recorded")
+	modified := time.Date(2024, 3, 1, 12, 30, 0, 123456789, time.UTC)
+	require.NoError(t, provider.Upload(ctx, &interfaces.File{
+		Path:         "/notes/todo.txt",
+		Content:      bytes.NewReader(content),
+		ModifiedTime: modified,
+		Permissions:  "0640",
+	}))
+	sum := sha256.Sum256(content)
+	localHash := hex.EncodeToString(sum[:])
+
+	// Metadata carries what was recorded about the local file
+	meta, err := provider.GetMetadata(ctx, "/notes/todo.txt")
+	require.NoError(t, err)
+	assert.True(t, meta.ModifiedTime.Equal(modified), "modification time keeps nanoseconds: %s", meta.ModifiedTime)
+	assert.Equal(t, localHash, meta.Attributes[interfaces.AttributeLocalHash])
+	assert.Equal(t, "sha256", meta.Attributes[interfaces.AttributeHashAlgorithm])
+	assert.Equal(t, "0640", meta.Attributes[interfaces.AttributeMode])
+	assert.Equal(t, "laptop", meta.Attributes[interfaces.AttributeDeviceID])
+
+	files, err := provider.List(ctx, "/notes")
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "0640", files[0].Permissions)
+	assert.Equal(t, localHash, files[0].Attributes[interfaces.AttributeLocalHash])
+	assert.True(t, files[0].ModifiedTime.Equal(modified))
+
+	// Content changed by another client is no longer described
+	fake.mu.Lock()
+	for _, file := range fake.files {
+		if file.name == "todo.txt" {
+			file.data = []byte("edited elsewhere")
+		}
+	}
+	fake.mu.Unlock()
+
+	meta, err = provider.GetMetadata(ctx, "/notes/todo.txt")
+	require.NoError(t, err)
+	assert.NotContains(t, meta.Attributes, interfaces.AttributeLocalHash)
+	assert.False(t, meta.ModifiedTime.Equal(modified))
+}
+
+func TestGoogleDriveProviderRetries(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeDrive(t)
+	provider := newTestProvider(t, fake, nil)
+
+	// Rewindable content is sent again after a transient failure
+	fake.failures = 1
+	require.NoError(t, provider.Upload(ctx, &interfaces.File{Path: "/a.txt", Content: strings.NewReader("retried")}))
+	assert.Equal(t, []string{"a.txt"}, fake.children(rootID))
+
+	fake.failures = defaultMaxRetries + 1
+	_, err := provider.GetQuota(ctx)
+	require.Error(t, err)
+	pe, ok := err.(*pperrors.PulseError)
+	require.True(t, ok)
+	assert.True(t, pe.IsRetryable())
+	assert.Equal(t, http.StatusServiceUnavailable, pe.StatusCode)
+}
+
+func TestGoogleDriveProviderRootFolder(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeDrive(t)
+
+	config := newTestConfig(t, fake)
+	config.TokenFile = filepath.Join(t.TempDir(), "missing.json")
+	_, err := NewPulsePointGoogleDriveProvider(config)
+	assert.True(t, pperrors.IsAuthError(err), "a token is required")
+
+	config = newTestConfig(t, fake)
+	config.RootFolderID = "no-such-folder"
+	_, err = NewPulsePointGoogleDriveProvider(config)
+	assert.True(t, pperrors.IsNotFoundError(err), "the root folder is verified")
+
+	provider := newTestProvider(t, fake, nil)
+	require.NoError(t, provider.CreateFolder(ctx, "/Backups/laptop"))
+	folder, err := provider.GetMetadata(ctx, "/Backups/laptop")
+	require.NoError(t, err)
+	assert.True(t, folder.IsFolder)
+
+	// Paths are relative to the configured root folder
+	scoped := newTestProvider(t, fake, func(config *Config) { config.RootFolderID = folder.ID })
+	require.NoError(t, scoped.Upload(ctx, &interfaces.File{Path: "/notes.txt", Content: strings.NewReader("x")}))
+	assert.Equal(t, []string{"notes.txt"}, fake.children(folder.ID))
+}
+
+func TestGoogleDriveProviderConformance(t *testing.T) {
+	providertest.Run(t, func(t *testing.T) interfaces.CloudProvider {
+		return newTestProvider(t, newFakeDrive(t), nil)
+	})
+}
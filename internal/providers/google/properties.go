@@ -0,0 +1,112 @@
+package google
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"google.golang.org/api/drive/v3"
+)
+
+// Drive appProperties recording the local file an upload came from. Only
+// PulsePoint sees them. propertyMD5 is the content they describe, so they
+// are ignored once another client has changed the content.
+const (
+	propertyHash          = "pulsepointHash"
+	propertyHashAlgorithm = "pulsepointHashAlgorithm"
+	propertyModifiedTime  = "pulsepointMtime"
+	propertyMode          = "pulsepointMode"
+	propertyDeviceID      = "pulsepointDevice"
+	propertyMD5           = "pulsepointMd5"
+
+	// Default algorithm for the recorded local hash, as used by the watcher
+	defaultHashAlgorithm = "sha256"
+)
+
+// appProperties describes the local file being uploaded. Content that can be
+// rewound is hashed first, with the configured algorithm and with MD5.
+func (p *PulsePointGoogleDriveProvider) appProperties(file *interfaces.File, content io.Reader) (map[string]string, error) {
+	properties := make(map[string]string)
+	if p.config.DeviceID != "" {
+		properties[propertyDeviceID] = p.config.DeviceID
+	}
+	if !file.ModifiedTime.IsZero() {
+		properties[propertyModifiedTime] = file.ModifiedTime.UTC().Format(time.RFC3339Nano)
+	}
+
+	mode := file.Permissions
+	if mode == "" && file.LocalPath != "" {
+		if info, err := os.Stat(file.LocalPath); err == nil {
+			mode = fmt.Sprintf("%04o", info.Mode().Perm())
+		}
+	}
+	if mode != "" {
+		properties[propertyMode] = mode
+	}
+
+	seeker, ok := content.(io.ReadSeeker)
+	if !ok {
+		return properties, nil
+	}
+
+	local, sum := sha256.New(), md5.New()
+	if p.config.HashAlgorithm == "md5" {
+		local = md5.New()
+	}
+
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(io.MultiWriter(local, sum), seeker); err != nil {
+		return nil, err
+	}
+	if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	properties[propertyHash] = hex.EncodeToString(local.Sum(nil))
+	properties[propertyHashAlgorithm] = p.config.HashAlgorithm
+	properties[propertyMD5] = hex.EncodeToString(sum.Sum(nil))
+	return properties, nil
+}
+
+// pulsePointAttributes returns the recorded description of the local file
+// as metadata attributes, or nil when there is none or it no longer
+// describes the file's content
+func pulsePointAttributes(file *drive.File) map[string]interface{} {
+	properties := file.AppProperties
+	if len(properties) == 0 || properties[propertyMD5] != file.Md5Checksum {
+		return nil
+	}
+
+	attributes := make(map[string]interface{})
+	if value := properties[propertyHash]; value != "" {
+		attributes[interfaces.AttributeLocalHash] = value
+		attributes[interfaces.AttributeHashAlgorithm] = properties[propertyHashAlgorithm]
+	}
+	if modified, err := time.Parse(time.RFC3339Nano, properties[propertyModifiedTime]); err == nil {
+		attributes[interfaces.AttributeLocalModifiedTime] = modified
+	}
+	if value := properties[propertyMode]; value != "" {
+		attributes[interfaces.AttributeMode] = value
+	}
+	if value := properties[propertyDeviceID]; value != "" {
+		attributes[interfaces.AttributeDeviceID] = value
+	}
+	return attributes
+}
+
+// modifiedTime returns a file's modification time, to the nanosecond when
+// PulsePoint recorded it at upload
+func modifiedTime(file *drive.File) time.Time {
+	if modified, ok := pulsePointAttributes(file)[interfaces.AttributeLocalModifiedTime].(time.Time); ok {
+		return modified
+	}
+	return parseTime(file.ModifiedTime)
+}
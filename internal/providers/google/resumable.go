@@ -0,0 +1,283 @@
+package google
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	pperrors "github.com/pulsepoint/pulsepoint/pkg/errors"
+	"go.uber.org/zap"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// Drive requires every chunk but the last to be a multiple of 256KiB
+const chunkAlignment = 256 * 1024
+
+// errSessionExpired is returned when Drive no longer knows an upload session
+var errSessionExpired = errors.New("upload session expired")
+
+// resumableUpload uploads content through a Drive upload session, recording
+// its progress so that a later call for the same file continues where this
+// one stopped. fileID is the file being replaced, empty to create one in
+// parentID.
+func (p *PulsePointGoogleDriveProvider) resumableUpload(
+	ctx context.Context,
+	remotePath, fileID, parentID string,
+	driveFile *drive.File,
+	content io.ReadSeeker,
+	modified time.Time,
+) (*drive.File, error) {
+	start, err := content.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, pperrors.NewFileSystemError("failed to read upload content", err)
+	}
+	end, err := content.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, pperrors.NewFileSystemError("failed to read upload content", err)
+	}
+	size := end - start
+
+	// Pick up an earlier session for the same content, if Drive still has it
+	session := p.state.loadSession(remotePath)
+	if session != nil && !session.matches(fileID, parentID, size, modified) {
+		p.state.removeSession(remotePath)
+		session = nil
+	}
+	if session != nil {
+		var uploaded *drive.File
+		err := p.withRetry(ctx, "query upload session", func() error {
+			var err error
+			session.Offset, uploaded, err = p.querySession(ctx, session.URI, size)
+			return err
+		})
+		switch {
+		case err == nil && uploaded != nil:
+			p.state.removeSession(remotePath)
+			return uploaded, nil
+		case err == nil:
+			p.logger.Info("Resuming upload",
+				zap.String("path", remotePath),
+				zap.Int64("offset", session.Offset),
+				zap.Int64("size", size))
+		case errors.Is(err, errSessionExpired):
+			p.state.removeSession(remotePath)
+			session = nil
+		default:
+			return nil, err
+		}
+	}
+
+	restarted := false
+	for {
+		if session == nil {
+			session = &uploadSession{
+				FileID:       fileID,
+				ParentID:     parentID,
+				Size:         size,
+				ModifiedTime: modified,
+				CreatedAt:    time.Now(),
+			}
+			err := p.withRetry(ctx, "start upload session", func() error {
+				var err error
+				session.URI, err = p.startSession(ctx, fileID, parentID, driveFile, size)
+				return err
+			})
+			if err != nil {
+				return nil, err
+			}
+			p.state.saveSession(remotePath, session)
+		}
+
+		uploaded, err := p.uploadChunks(ctx, remotePath, session, content, start)
+		switch {
+		case err == nil:
+			p.state.removeSession(remotePath)
+			return uploaded, nil
+		case errors.Is(err, errSessionExpired) && !restarted:
+			// The session vanished part way through, start a new one
+			p.logger.Warn("Upload session expired, restarting upload", zap.String("path", remotePath))
+			p.state.removeSession(remotePath)
+			session = nil
+			restarted = true
+		case isRetryableError(err) || ctx.Err() != nil:
+			// Keep the session so the next attempt resumes
+			return nil, err
+		default:
+			p.state.removeSession(remotePath)
+			return nil, err
+		}
+	}
+}
+
+// uploadChunks sends the content from the session's offset onwards, one
+// chunk at a time, saving the offset after every chunk
+func (p *PulsePointGoogleDriveProvider) uploadChunks(
+	ctx context.Context,
+	remotePath string,
+	session *uploadSession,
+	content io.ReadSeeker,
+	start int64,
+) (*drive.File, error) {
+	resync := false
+	for {
+		var uploaded *drive.File
+		err := p.withRetry(ctx, "upload chunk", func() error {
+			var err error
+			// After a failed chunk Drive may have kept part of it
+			if resync {
+				session.Offset, uploaded, err = p.querySession(ctx, session.URI, session.Size)
+				if err != nil || uploaded != nil {
+					return err
+				}
+				resync = false
+			}
+			session.Offset, uploaded, err = p.sendChunk(ctx, session, content, start)
+			resync = err != nil
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		if uploaded != nil {
+			return uploaded, nil
+		}
+
+		p.state.saveSession(remotePath, session)
+		p.logger.Debug("Uploaded chunk",
+			zap.String("path", remotePath),
+			zap.Int64("offset", session.Offset),
+			zap.Int64("size", session.Size))
+	}
+}
+
+// startSession opens an upload session and returns its URI
+func (p *PulsePointGoogleDriveProvider) startSession(ctx context.Context, fileID, parentID string, driveFile *drive.File, size int64) (string, error) {
+	meta := *driveFile
+	method, target := http.MethodPost, p.uploadURL("files")
+	if fileID != "" {
+		method, target = http.MethodPatch, p.uploadURL("files/"+fileID)
+	} else {
+		meta.Parents = []string{parentID}
+	}
+
+	body, err := json.Marshal(&meta)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{"uploadType": {"resumable"}, "fields": {fileFields}}
+	req, err := http.NewRequestWithContext(ctx, method, target+"?"+query.Encode(), bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	req.Header.Set("X-Upload-Content-Type", meta.MimeType)
+	req.Header.Set("X-Upload-Content-Length", strconv.FormatInt(size, 10))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if err := googleapi.CheckResponse(resp); err != nil {
+		return "", err
+	}
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return "", pperrors.NewProviderError("Drive did not return an upload session", nil)
+	}
+	return location, nil
+}
+
+// sendChunk sends the chunk starting at the session's offset. It returns
+// the new offset, or the file once the last chunk has been accepted.
+func (p *PulsePointGoogleDriveProvider) sendChunk(ctx context.Context, session *uploadSession, content io.ReadSeeker, start int64) (int64, *drive.File, error) {
+	length := session.Size - session.Offset
+	if length > p.config.ChunkSize {
+		length = p.config.ChunkSize
+	}
+	if _, err := content.Seek(start+session.Offset, io.SeekStart); err != nil {
+		return session.Offset, nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, session.URI, io.LimitReader(content, length))
+	if err != nil {
+		return session.Offset, nil, err
+	}
+	req.ContentLength = length
+	if length > 0 {
+		req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", session.Offset, session.Offset+length-1, session.Size))
+	} else {
+		req.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", session.Size))
+	}
+
+	return p.sessionResponse(req, session.Offset)
+}
+
+// querySession asks Drive how much of an upload it has received
+func (p *PulsePointGoogleDriveProvider) querySession(ctx context.Context, uri string, size int64) (int64, *drive.File, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, uri, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+
+	return p.sessionResponse(req, 0)
+}
+
+// sessionResponse sends a request to an upload session. Drive answers 308
+// with the received range while the upload is incomplete, and the file once
+// it is complete.
+func (p *PulsePointGoogleDriveProvider) sessionResponse(req *http.Request, offset int64) (int64, *drive.File, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return offset, nil, err
+	}
+	defer resp.Body.Close()
+
+	incomplete := resp.StatusCode == http.StatusPermanentRedirect ||
+		resp.Header.Get("X-Http-Status-Code-Override") == "308"
+	switch {
+	case incomplete:
+		// Range is "bytes=0-N", and missing when nothing has been received
+		received := strings.TrimPrefix(resp.Header.Get("Range"), "bytes=")
+		if _, last, ok := strings.Cut(received, "-"); ok {
+			n, err := strconv.ParseInt(last, 10, 64)
+			if err != nil {
+				return offset, nil, pperrors.NewProviderError(fmt.Sprintf("invalid upload range %q", received), err)
+			}
+			return n + 1, nil, nil
+		}
+		return 0, nil, nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return offset, nil, errSessionExpired
+	}
+
+	if err := googleapi.CheckResponse(resp); err != nil {
+		return offset, nil, err
+	}
+	uploaded := &drive.File{}
+	if err := json.NewDecoder(resp.Body).Decode(uploaded); err != nil {
+		return offset, nil, err
+	}
+	return offset, uploaded, nil
+}
+
+// uploadURL returns the media upload URL of a Drive resource
+func (p *PulsePointGoogleDriveProvider) uploadURL(resource string) string {
+	base, err := url.Parse(p.service.BasePath)
+	if err != nil {
+		return "https://www.googleapis.com/upload/drive/v3/" + resource
+	}
+	return base.ResolveReference(&url.URL{Path: "/upload/drive/v3/" + resource}).String()
+}
@@ -0,0 +1,53 @@
+package google
+
+import (
+	"time"
+)
+
+const (
+	// Bucket holding resumable upload sessions, keyed by remote path
+	bucketUploadSessions = "upload_sessions"
+
+	// Drive keeps upload sessions for a week; give up on them a day early
+	uploadSessionLifetime = 6 * 24 * time.Hour
+)
+
+// uploadSession is a resumable upload that can be picked up again after a
+// crash or a dropped connection
+type uploadSession struct {
+	URI          string    `json:"uri"`
+	FileID       string    `json:"file_id"` // File being replaced, empty when creating one
+	ParentID     string    `json:"parent_id"`
+	Size         int64     `json:"size"`
+	ModifiedTime time.Time `json:"modified_time"`
+	Offset       int64     `json:"offset"` // Bytes the server has acknowledged
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// matches reports whether the session uploads the same content to the same file
+func (s *uploadSession) matches(fileID, parentID string, size int64, modified time.Time) bool {
+	return s.FileID == fileID &&
+		s.ParentID == parentID &&
+		s.Size == size &&
+		s.ModifiedTime.Equal(modified) &&
+		time.Since(s.CreatedAt) < uploadSessionLifetime
+}
+
+// loadSession returns the session recorded for a remote path, or nil
+func (s *stateStore) loadSession(remotePath string) *uploadSession {
+	session := &uploadSession{}
+	if !s.get(bucketUploadSessions, remotePath, session) {
+		return nil
+	}
+	return session
+}
+
+// saveSession records the progress of a session
+func (s *stateStore) saveSession(remotePath string, session *uploadSession) {
+	s.put(bucketUploadSessions, remotePath, session)
+}
+
+// removeSession forgets the session for a remote path
+func (s *stateStore) removeSession(remotePath string) {
+	s.delete(bucketUploadSessions, remotePath)
+}
@@ -0,0 +1,160 @@
+package google
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// stateStore keeps the provider's upload sessions and path cache in a bbolt
+// file of its own, so the provider does not contend for the sync database's
+// lock. The file is opened on first use. Failures are logged rather than
+// returned: without the store the provider only loses its shortcuts. A nil
+// store remembers nothing.
+type stateStore struct {
+	path   string
+	logger *zap.Logger
+	mu     sync.Mutex
+	db     *bolt.DB
+	broken bool // the file could not be opened, stop trying
+}
+
+// newStateStore returns a store backed by the file at path, or nil when
+// path is empty
+func newStateStore(path string, logger *zap.Logger) *stateStore {
+	if path == "" {
+		return nil
+	}
+	return &stateStore{path: path, logger: logger}
+}
+
+// open opens the state file if it is not open yet
+func (s *stateStore) open() (*bolt.DB, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.db != nil || s.broken {
+		return s.db, s.db != nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		s.fail(err)
+		return nil, false
+	}
+	db, err := bolt.Open(s.path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		s.fail(err)
+		return nil, false
+	}
+	s.db = db
+	return db, true
+}
+
+// fail gives up on the state file
+func (s *stateStore) fail(err error) {
+	s.broken = true
+	s.logger.Warn("Failed to open Google Drive state, continuing without it",
+		zap.String("path", s.path),
+		zap.Error(err))
+}
+
+// view runs fn with the bucket, which is nil when nothing has been stored in it
+func (s *stateStore) view(bucket string, fn func(b *bolt.Bucket) error) {
+	if s == nil {
+		return
+	}
+	db, ok := s.open()
+	if !ok {
+		return
+	}
+
+	err := db.View(func(tx *bolt.Tx) error {
+		return fn(tx.Bucket([]byte(bucket)))
+	})
+	if err != nil {
+		s.logger.Warn("Failed to read Google Drive state", zap.String("bucket", bucket), zap.Error(err))
+	}
+}
+
+// update runs fn with the bucket in a read-write transaction
+func (s *stateStore) update(bucket string, fn func(b *bolt.Bucket) error) {
+	if s == nil {
+		return
+	}
+	db, ok := s.open()
+	if !ok {
+		return
+	}
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return err
+		}
+		return fn(b)
+	})
+	if err != nil {
+		s.logger.Warn("Failed to update Google Drive state", zap.String("bucket", bucket), zap.Error(err))
+	}
+}
+
+// get decodes the value stored under key into v, reporting whether there was one
+func (s *stateStore) get(bucket, key string, v interface{}) bool {
+	found := false
+	s.view(bucket, func(b *bolt.Bucket) error {
+		if b == nil {
+			return nil
+		}
+		data := b.Get([]byte(key))
+		if data == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(data, v)
+	})
+	return found
+}
+
+// put stores v under key
+func (s *stateStore) put(bucket, key string, v interface{}) {
+	s.update(bucket, func(b *bolt.Bucket) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), data)
+	})
+}
+
+// delete removes key
+func (s *stateStore) delete(bucket, key string) {
+	s.update(bucket, func(b *bolt.Bucket) error {
+		return b.Delete([]byte(key))
+	})
+}
+
+// close closes the state file if it was opened
+func (s *stateStore) close() error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.db == nil {
+		return nil
+	}
+	err := s.db.Close()
+	s.db = nil
+	return err
+}
+
+// isBeneath reports whether key is the path dir or lies beneath it
+func isBeneath(key, dir string) bool {
+	return dir == "/" || key == dir || strings.HasPrefix(key, dir+"/")
+}
@@ -4,14 +4,20 @@ package mock
 import (
 	"bytes"
 	"context"
+	"crypto/md5"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	pperrors "github.com/pulsepoint/pulsepoint/pkg/errors"
 	"github.com/pulsepoint/pulsepoint/pkg/logger"
 	"go.uber.org/zap"
 )
@@ -51,6 +57,10 @@ func (m *MockDriveProvider) Initialize(config interfaces.ProviderConfig) error {
 
 // Upload uploads a file to mock storage
 func (m *MockDriveProvider) Upload(ctx context.Context, file *interfaces.File) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -72,17 +82,14 @@ func (m *MockDriveProvider) Upload(ctx context.Context, file *interfaces.File) e
 		return fmt.Errorf("no content or local path provided")
 	}
 
-	// Store in mock storage
-	remotePath := file.Path
-	m.files[remotePath] = content
-	m.used += int64(len(content))
-
-	// Create parent directories
-	dir := filepath.Dir(remotePath)
-	for dir != "/" && dir != "." {
-		m.folders[dir] = true
-		dir = filepath.Dir(dir)
+	// Store in mock storage, replacing any earlier content
+	remotePath := cleanPath(file.Path)
+	if m.folders[remotePath] {
+		return fmt.Errorf("cannot upload over folder: %s", remotePath)
 	}
+	m.used += int64(len(content)) - int64(len(m.files[remotePath]))
+	m.files[remotePath] = content
+	m.addParents(remotePath)
 
 	m.logger.Info("Mock upload successful",
 		zap.String("path", remotePath),
@@ -92,85 +99,112 @@ func (m *MockDriveProvider) Upload(ctx context.Context, file *interfaces.File) e
 }
 
 // Download downloads a file from mock storage
-func (m *MockDriveProvider) Download(ctx context.Context, path string) (*interfaces.File, error) {
+func (m *MockDriveProvider) Download(ctx context.Context, remotePath string) (*interfaces.File, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	remotePath = cleanPath(remotePath)
 	m.mu.RLock()
-	content, exists := m.files[path]
+	content, exists := m.files[remotePath]
 	m.mu.RUnlock()
 
 	if !exists {
-		return nil, fmt.Errorf("file not found: %s", path)
+		return nil, notFoundError(remotePath)
 	}
 
 	file := &interfaces.File{
-		Path:         path,
-		Name:         filepath.Base(path),
+		Path:         remotePath,
+		Name:         path.Base(remotePath),
 		Size:         int64(len(content)),
-		Hash:         fmt.Sprintf("mock-hash-%s", filepath.Base(path)),
+		Hash:         contentHash(content),
 		MimeType:     "application/octet-stream",
 		ModifiedTime: time.Now(),
 		Content:      bytes.NewReader(content),
-		RemoteID:     fmt.Sprintf("mock-id-%s", path),
+		RemoteID:     fmt.Sprintf("file-%s", remotePath),
 	}
 
 	m.logger.Info("Mock download successful",
-		zap.String("path", path),
+		zap.String("path", remotePath),
 		zap.Int("size", len(content)))
 
 	return file, nil
 }
 
-// Delete deletes a file from mock storage
-func (m *MockDriveProvider) Delete(ctx context.Context, path string) error {
+// Delete deletes a file, or a folder and its contents, from mock storage.
+// Deleting a missing path succeeds.
+func (m *MockDriveProvider) Delete(ctx context.Context, remotePath string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	remotePath = cleanPath(remotePath)
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if content, exists := m.files[path]; exists {
+	if content, exists := m.files[remotePath]; exists {
 		m.used -= int64(len(content))
-		delete(m.files, path)
-		m.logger.Info("Mock file deleted", zap.String("path", path))
+		delete(m.files, remotePath)
+		m.logger.Info("Mock file deleted", zap.String("path", remotePath))
 		return nil
 	}
 
-	if _, exists := m.folders[path]; exists {
-		delete(m.folders, path)
-		m.logger.Info("Mock folder deleted", zap.String("path", path))
-		return nil
+	if _, exists := m.folders[remotePath]; exists {
+		prefix := strings.TrimSuffix(remotePath, "/") + "/"
+		for p, content := range m.files {
+			if strings.HasPrefix(p, prefix) {
+				m.used -= int64(len(content))
+				delete(m.files, p)
+			}
+		}
+		for p := range m.folders {
+			if strings.HasPrefix(p, prefix) {
+				delete(m.folders, p)
+			}
+		}
+		delete(m.folders, remotePath)
+		m.logger.Info("Mock folder deleted", zap.String("path", remotePath))
 	}
 
-	return fmt.Errorf("path not found: %s", path)
+	return nil
 }
 
 // List lists files in mock storage
 func (m *MockDriveProvider) List(ctx context.Context, folder string) ([]*interfaces.File, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	folder = cleanPath(folder)
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	var results []*interfaces.File
 
 	// Add folders
-	for path := range m.folders {
-		if filepath.Dir(path) == folder || (folder == "/" && path != "/") {
+	for p := range m.folders {
+		if p != "/" && path.Dir(p) == folder {
 			results = append(results, &interfaces.File{
-				Path:     path,
-				Name:     filepath.Base(path),
+				Path:     p,
+				Name:     path.Base(p),
 				IsFolder: true,
-				RemoteID: fmt.Sprintf("folder-%s", path),
+				RemoteID: fmt.Sprintf("folder-%s", p),
 			})
 		}
 	}
 
 	// Add files
-	for path, content := range m.files {
-		if filepath.Dir(path) == folder {
+	for p, content := range m.files {
+		if path.Dir(p) == folder {
 			results = append(results, &interfaces.File{
-				Path:         path,
-				Name:         filepath.Base(path),
+				Path:         p,
+				Name:         path.Base(p),
 				Size:         int64(len(content)),
 				IsFolder:     false,
 				ModifiedTime: time.Now(),
-				Hash:         fmt.Sprintf("mock-hash-%s", filepath.Base(path)),
+				Hash:         contentHash(content),
 				MimeType:     "application/octet-stream",
-				RemoteID:     fmt.Sprintf("file-%s", path),
+				RemoteID:     fmt.Sprintf("file-%s", p),
 			})
 		}
 	}
@@ -183,61 +217,80 @@ func (m *MockDriveProvider) List(ctx context.Context, folder string) ([]*interfa
 }
 
 // GetMetadata gets metadata for a file
-func (m *MockDriveProvider) GetMetadata(ctx context.Context, path string) (*interfaces.Metadata, error) {
+func (m *MockDriveProvider) GetMetadata(ctx context.Context, remotePath string) (*interfaces.Metadata, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	remotePath = cleanPath(remotePath)
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	if content, exists := m.files[path]; exists {
+	if content, exists := m.files[remotePath]; exists {
 		return &interfaces.Metadata{
-			ID:           fmt.Sprintf("file-%s", path),
-			Path:         path,
+			ID:           fmt.Sprintf("file-%s", remotePath),
+			Path:         remotePath,
 			Size:         int64(len(content)),
 			ModifiedTime: time.Now(),
 			IsFolder:     false,
-			Hash:         fmt.Sprintf("mock-hash-%s", filepath.Base(path)),
+			Hash:         contentHash(content),
 			MimeType:     "application/octet-stream",
 		}, nil
 	}
 
-	if _, exists := m.folders[path]; exists {
+	if _, exists := m.folders[remotePath]; exists || remotePath == "/" {
 		return &interfaces.Metadata{
-			ID:       fmt.Sprintf("folder-%s", path),
-			Path:     path,
+			ID:       fmt.Sprintf("folder-%s", remotePath),
+			Path:     remotePath,
 			IsFolder: true,
 			MimeType: "application/vnd.google-apps.folder",
 		}, nil
 	}
 
-	return nil, fmt.Errorf("path not found: %s", path)
+	return nil, notFoundError(remotePath)
 }
 
 // CreateFolder creates a folder in mock storage
-func (m *MockDriveProvider) CreateFolder(ctx context.Context, path string) error {
+func (m *MockDriveProvider) CreateFolder(ctx context.Context, remotePath string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	remotePath = cleanPath(remotePath)
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	m.folders[path] = true
-
-	// Create parent directories
-	dir := filepath.Dir(path)
-	for dir != "/" && dir != "." {
-		m.folders[dir] = true
-		dir = filepath.Dir(dir)
+	if _, exists := m.files[remotePath]; exists {
+		return fmt.Errorf("path exists but is not a folder: %s", remotePath)
+	}
+	if remotePath != "/" {
+		m.folders[remotePath] = true
 	}
+	m.addParents(remotePath)
 
-	m.logger.Info("Mock folder created", zap.String("path", path))
+	m.logger.Info("Mock folder created", zap.String("path", remotePath))
 	return nil
 }
 
 // Move moves a file in mock storage
 func (m *MockDriveProvider) Move(ctx context.Context, sourcePath, destPath string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	sourcePath, destPath = cleanPath(sourcePath), cleanPath(destPath)
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	// Move file
+	// Move file, replacing any file at the destination
 	if content, exists := m.files[sourcePath]; exists {
+		if m.folders[destPath] {
+			return fmt.Errorf("cannot move over folder: %s", destPath)
+		}
+		m.used -= int64(len(m.files[destPath]))
 		m.files[destPath] = content
 		delete(m.files, sourcePath)
+		m.addParents(destPath)
 		m.logger.Info("Mock file moved",
 			zap.String("source", sourcePath),
 			zap.String("dest", destPath))
@@ -248,13 +301,20 @@ func (m *MockDriveProvider) Move(ctx context.Context, sourcePath, destPath strin
 	if _, exists := m.folders[sourcePath]; exists {
 		m.folders[destPath] = true
 		delete(m.folders, sourcePath)
+		m.addParents(destPath)
 
 		// Move all children
-		for path, content := range m.files {
-			if len(path) > len(sourcePath) && path[:len(sourcePath)] == sourcePath {
-				newPath := filepath.Join(destPath, path[len(sourcePath):])
-				m.files[newPath] = content
-				delete(m.files, path)
+		prefix := sourcePath + "/"
+		for p := range m.folders {
+			if strings.HasPrefix(p, prefix) {
+				m.folders[path.Join(destPath, strings.TrimPrefix(p, prefix))] = true
+				delete(m.folders, p)
+			}
+		}
+		for p, content := range m.files {
+			if strings.HasPrefix(p, prefix) {
+				m.files[path.Join(destPath, strings.TrimPrefix(p, prefix))] = content
+				delete(m.files, p)
 			}
 		}
 
@@ -264,7 +324,7 @@ func (m *MockDriveProvider) Move(ctx context.Context, sourcePath, destPath strin
 		return nil
 	}
 
-	return fmt.Errorf("source path not found: %s", sourcePath)
+	return notFoundError(sourcePath)
 }
 
 // GetQuota returns mock quota information
@@ -302,3 +362,28 @@ func (m *MockDriveProvider) GetMockStats() (files int, folders int, totalSize in
 
 	return len(m.files), len(m.folders), m.used
 }
+
+// addParents records the folders above remotePath. The caller holds the lock.
+func (m *MockDriveProvider) addParents(remotePath string) {
+	for dir := path.Dir(remotePath); dir != "/"; dir = path.Dir(dir) {
+		m.folders[dir] = true
+	}
+}
+
+// cleanPath returns the absolute, slash-separated form of a remote path
+func cleanPath(remotePath string) string {
+	return path.Clean("/" + filepath.ToSlash(remotePath))
+}
+
+// contentHash returns the MD5 of content, as Google Drive reports it
+func contentHash(content []byte) string {
+	sum := md5.Sum(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// notFoundError is the error returned for paths that do not exist
+func notFoundError(remotePath string) error {
+	err := pperrors.NewProviderError(fmt.Sprintf("path not found: %s", remotePath), nil)
+	err.StatusCode = http.StatusNotFound
+	return err
+}
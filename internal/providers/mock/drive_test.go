@@ -0,0 +1,14 @@
+package mock
+
+import (
+	"testing"
+
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"github.com/pulsepoint/pulsepoint/internal/providers/providertest"
+)
+
+func TestMockDriveProviderConformance(t *testing.T) {
+	providertest.Run(t, func(t *testing.T) interfaces.CloudProvider {
+		return NewMockDriveProvider()
+	})
+}
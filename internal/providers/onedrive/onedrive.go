@@ -0,0 +1,966 @@
+// Package onedrive implements a OneDrive provider for PulsePoint using Microsoft Graph
+package onedrive
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	pperrors "github.com/pulsepoint/pulsepoint/pkg/errors"
+	pplogger "github.com/pulsepoint/pulsepoint/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// Provider name
+	providerName = "onedrive"
+
+	// MIME type reported for folders
+	mimeTypeFolder = "application/x-directory"
+
+	// Default Graph endpoint
+	defaultGraphURL = "https://graph.microsoft.com/v1.0"
+
+	// simpleUploadLimit is the largest file sent with a single PUT
+	simpleUploadLimit = 4 * 1024 * 1024
+
+	// Upload session chunks must be a multiple of 320KiB and at most 60MiB
+	chunkUnit        = 320 * 1024
+	defaultChunkSize = 32 * chunkUnit // 10MiB
+	maxChunkSize     = 60 * 1024 * 1024
+
+	// listPageSize is the page size requested when listing children
+	listPageSize = 200
+)
+
+// PulsePointOneDriveProvider implements CloudProvider and RemoteChangeFeed for OneDrive
+type PulsePointOneDriveProvider struct {
+	config *Config
+	auth   interfaces.AuthProvider
+	client *http.Client
+	logger *zap.Logger
+
+	// token caches the access token between requests
+	tokenMu sync.Mutex
+	token   *interfaces.AuthToken
+
+	// folderPaths maps folder IDs to drive paths for resolving delta items,
+	// which carry only their parent's ID. knownFolders holds the lower-case
+	// drive paths of folders known to exist.
+	foldersMu    sync.Mutex
+	folderPaths  map[string]string
+	knownFolders map[string]bool
+}
+
+// Config holds OneDrive configuration
+type Config struct {
+	DriveID    string       `json:"drive_id"`   // Empty for the signed-in user's drive
+	Root       string       `json:"root"`       // Folder that acts as the remote root, e.g. /PulsePoint
+	ChunkSize  int64        `json:"chunk_size"` // Upload session chunk size, a multiple of 320KiB
+	GraphURL   string       `json:"-"`          // Overrides the Graph endpoint, mainly for tests
+	HTTPClient *http.Client `json:"-"`
+}
+
+// NewPulsePointOneDriveProvider creates a new OneDrive provider that gets its
+// access tokens from auth
+func NewPulsePointOneDriveProvider(config *Config, auth interfaces.AuthProvider) (*PulsePointOneDriveProvider, error) {
+	if config == nil {
+		return nil, pperrors.NewConfigError("OneDrive configuration is required", nil)
+	}
+	if auth == nil {
+		return nil, pperrors.NewConfigError("OneDrive authentication is required", nil)
+	}
+
+	if config.ChunkSize == 0 {
+		config.ChunkSize = defaultChunkSize
+	}
+	if config.ChunkSize%chunkUnit != 0 || config.ChunkSize > maxChunkSize {
+		return nil, pperrors.NewConfigError("OneDrive chunk size must be a multiple of 320KiB up to 60MiB", nil)
+	}
+	if config.GraphURL == "" {
+		config.GraphURL = defaultGraphURL
+	}
+	config.GraphURL = strings.TrimSuffix(config.GraphURL, "/")
+
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{}
+	}
+
+	provider := &PulsePointOneDriveProvider{
+		config:       config,
+		auth:         auth,
+		client:       client,
+		logger:       pplogger.Get(),
+		folderPaths:  make(map[string]string),
+		knownFolders: make(map[string]bool),
+	}
+
+	provider.logger.Info("OneDrive provider initialized",
+		zap.String("drive", config.DriveID),
+		zap.String("root", config.Root))
+
+	return provider, nil
+}
+
+// Initialize initializes the provider with configuration
+func (p *PulsePointOneDriveProvider) Initialize(config interfaces.ProviderConfig) error {
+	// Already initialized in constructor
+	return nil
+}
+
+// Upload uploads a file. Small files are sent with one PUT, larger ones
+// through an upload session, and the QuickXorHash OneDrive reports is checked
+// against the bytes sent.
+func (p *PulsePointOneDriveProvider) Upload(ctx context.Context, file *interfaces.File) error {
+	if file == nil {
+		return pperrors.NewValidationError("file is nil", nil)
+	}
+
+	p.logger.Debug("Uploading file to OneDrive",
+		zap.String("path", file.Path),
+		zap.Int64("size", file.Size))
+
+	// Prefer in-memory content, otherwise read from the local file
+	reader := file.Content
+	size := file.Size
+	if reader == nil {
+		f, err := os.Open(file.LocalPath)
+		if err != nil {
+			return fmt.Errorf("failed to open local file: %w", err)
+		}
+		defer f.Close()
+		if info, err := f.Stat(); err == nil {
+			size = info.Size()
+		}
+		reader = f
+	}
+
+	if err := p.ensureFolder(ctx, path.Dir(p.drivePath(file.Path))); err != nil {
+		return err
+	}
+
+	hasher := newQuickXorHash()
+	reader = io.TeeReader(reader, hasher)
+
+	// Read one byte past the simple upload limit to learn which way to go
+	head := make([]byte, simpleUploadLimit+1)
+	n, err := io.ReadFull(reader, head)
+	var item *driveItem
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		item, err = p.simpleUpload(ctx, file, head[:n])
+	case err != nil:
+		return fmt.Errorf("failed to read file: %w", err)
+	default:
+		body := io.MultiReader(bytes.NewReader(head), reader)
+		if size <= simpleUploadLimit {
+			// Upload sessions need the total size up front
+			spool, spooled, spoolErr := spoolToTemp(body)
+			if spoolErr != nil {
+				return spoolErr
+			}
+			defer os.Remove(spool.Name())
+			defer spool.Close()
+			body, size = spool, spooled
+		}
+		item, err = p.sessionUpload(ctx, file, body, size)
+	}
+	if err != nil {
+		return err
+	}
+
+	local := base64.StdEncoding.EncodeToString(hasher.Sum(nil))
+	if remote := item.hash(); remote != "" && remote != local {
+		return pperrors.NewRetryable(pperrors.ProviderError,
+			fmt.Sprintf("QuickXorHash mismatch for %s: sent %s, stored %s", file.Path, local, remote), nil)
+	}
+
+	p.logger.Info("File uploaded successfully",
+		zap.String("path", file.Path),
+		zap.Int64("size", item.Size))
+	return nil
+}
+
+// simpleUpload uploads a small file with one PUT, then sets its modified time
+func (p *PulsePointOneDriveProvider) simpleUpload(ctx context.Context, file *interfaces.File, data []byte) (*driveItem, error) {
+	var item driveItem
+	err := p.call(ctx, http.MethodPut, p.itemURL(file.Path)+"/content", file.Path, "application/octet-stream", bytes.NewReader(data), &item)
+	if err != nil {
+		return nil, err
+	}
+	if file.ModifiedTime.IsZero() {
+		return &item, nil
+	}
+
+	// PATCH returns the item without hashes, so keep the upload response
+	patch := map[string]interface{}{"fileSystemInfo": fileSystemInfo(file.ModifiedTime)}
+	if err := p.callJSON(ctx, http.MethodPatch, p.itemIDURL(item.ID), file.Path, patch, nil); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// sessionUpload uploads size bytes from reader through an upload session,
+// one chunk per request
+func (p *PulsePointOneDriveProvider) sessionUpload(ctx context.Context, file *interfaces.File, reader io.Reader, size int64) (*driveItem, error) {
+	request := map[string]interface{}{
+		"@microsoft.graph.conflictBehavior": "replace",
+	}
+	if !file.ModifiedTime.IsZero() {
+		request["fileSystemInfo"] = fileSystemInfo(file.ModifiedTime)
+	}
+
+	var session struct {
+		UploadURL string `json:"uploadUrl"`
+	}
+	err := p.callJSON(ctx, http.MethodPost, p.itemURL(file.Path)+"/createUploadSession", file.Path,
+		map[string]interface{}{"item": request}, &session)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := p.uploadChunks(ctx, file.Path, session.UploadURL, reader, size)
+	if err != nil {
+		// Cancel the session so the partial upload is discarded
+		if req, reqErr := http.NewRequestWithContext(context.WithoutCancel(ctx), http.MethodDelete, session.UploadURL, nil); reqErr == nil {
+			if resp, doErr := p.client.Do(req); doErr == nil {
+				resp.Body.Close()
+			}
+		}
+		return nil, err
+	}
+	return item, nil
+}
+
+// uploadChunks sends the chunks of an upload session. The upload URL is
+// pre-authenticated, so no access token is sent with it.
+func (p *PulsePointOneDriveProvider) uploadChunks(ctx context.Context, remotePath, uploadURL string, reader io.Reader, size int64) (*driveItem, error) {
+	chunk := make([]byte, p.config.ChunkSize)
+	for offset := int64(0); offset < size; {
+		n := int64(len(chunk))
+		if size-offset < n {
+			n = size - offset
+		}
+		if _, err := io.ReadFull(reader, chunk[:n]); err != nil {
+			return nil, pperrors.NewSyncError(fmt.Sprintf("file %s changed during upload", remotePath), err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, uploadURL, bytes.NewReader(chunk[:n]))
+		if err != nil {
+			return nil, pperrors.NewProviderError("failed to build request", err)
+		}
+		req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+n-1, size))
+
+		resp, err := p.client.Do(req)
+		if err != nil {
+			return nil, pperrors.NewRetryable(pperrors.NetworkError, fmt.Sprintf("request for %s failed", remotePath), err)
+		}
+		offset += n
+
+		switch resp.StatusCode {
+		case http.StatusAccepted:
+			resp.Body.Close()
+			p.logger.Debug("Uploaded chunk",
+				zap.String("path", remotePath),
+				zap.Int64("offset", offset))
+		case http.StatusOK, http.StatusCreated:
+			defer resp.Body.Close()
+			if offset != size {
+				return nil, pperrors.NewProviderError(fmt.Sprintf("upload of %s completed early", remotePath), nil)
+			}
+			var item driveItem
+			if err := json.NewDecoder(resp.Body).Decode(&item); err != nil {
+				return nil, pperrors.NewProviderError("failed to decode upload response", err)
+			}
+			return &item, nil
+		default:
+			defer resp.Body.Close()
+			return nil, responseError(remotePath, resp)
+		}
+	}
+
+	// Nothing left to send but the session never completed
+	return nil, pperrors.NewProviderError(fmt.Sprintf("upload session for %s did not complete", remotePath), nil)
+}
+
+// Download streams a file from OneDrive. The caller must close the returned content.
+func (p *PulsePointOneDriveProvider) Download(ctx context.Context, remotePath string) (*interfaces.File, error) {
+	p.logger.Debug("Downloading file from OneDrive", zap.String("path", remotePath))
+
+	meta, err := p.GetMetadata(ctx, remotePath)
+	if err != nil {
+		return nil, err
+	}
+	if meta.IsFolder {
+		return nil, pperrors.NewValidationError(fmt.Sprintf("%s is a folder", remotePath), nil)
+	}
+
+	// Graph redirects to a pre-authenticated download URL
+	resp, err := p.send(ctx, http.MethodGet, p.itemIDURL(meta.ID)+"/content", remotePath, "", nil)
+	if err != nil {
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+
+	return &interfaces.File{
+		ID:           meta.ID,
+		Path:         meta.Path,
+		Name:         path.Base(meta.Path),
+		Size:         meta.Size,
+		Hash:         meta.Hash,
+		MimeType:     meta.MimeType,
+		ModifiedTime: meta.ModifiedTime,
+		Content:      resp.Body,
+	}, nil
+}
+
+// Delete moves a file or folder to the OneDrive recycle bin. Deleting a
+// missing path succeeds.
+func (p *PulsePointOneDriveProvider) Delete(ctx context.Context, remotePath string) error {
+	p.logger.Debug("Deleting file from OneDrive", zap.String("path", remotePath))
+
+	if cleanPath(remotePath) == "/" {
+		return pperrors.NewValidationError("refusing to delete the root folder", nil)
+	}
+	err := p.call(ctx, http.MethodDelete, p.itemURL(remotePath), remotePath, "", nil, nil)
+	if pperrors.IsNotFoundError(err) {
+		p.logger.Debug("File already deleted", zap.String("path", remotePath))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("delete failed: %w", err)
+	}
+	p.forgetFolders(p.drivePath(remotePath))
+
+	p.logger.Info("File deleted successfully", zap.String("path", remotePath))
+	return nil
+}
+
+// List lists the files and folders directly inside a folder, following
+// @odata.nextLink until every page is read
+func (p *PulsePointOneDriveProvider) List(ctx context.Context, folder string) ([]*interfaces.File, error) {
+	p.logger.Debug("Listing files in folder", zap.String("folder", folder))
+
+	folder = cleanPath(folder)
+	next := p.itemURL(folder) + "/children?$top=" + strconv.Itoa(listPageSize)
+
+	var files []*interfaces.File
+	for next != "" {
+		var page struct {
+			Value    []driveItem `json:"value"`
+			NextLink string      `json:"@odata.nextLink"`
+		}
+		if err := p.callJSON(ctx, http.MethodGet, next, folder, nil, &page); err != nil {
+			return nil, fmt.Errorf("failed to list files: %w", err)
+		}
+
+		for i := range page.Value {
+			meta := p.metadata(&page.Value[i], path.Join(folder, page.Value[i].Name))
+			files = append(files, &interfaces.File{
+				ID:           meta.ID,
+				Path:         meta.Path,
+				Name:         page.Value[i].Name,
+				Size:         meta.Size,
+				Hash:         meta.Hash,
+				MimeType:     meta.MimeType,
+				ModifiedTime: meta.ModifiedTime,
+				CreatedTime:  meta.CreatedTime,
+				IsFolder:     meta.IsFolder,
+			})
+		}
+		next = page.NextLink
+	}
+	return files, nil
+}
+
+// GetMetadata gets file metadata. Hash is the OneDrive QuickXorHash.
+func (p *PulsePointOneDriveProvider) GetMetadata(ctx context.Context, remotePath string) (*interfaces.Metadata, error) {
+	p.logger.Debug("Getting file metadata", zap.String("path", remotePath))
+
+	var item driveItem
+	if err := p.callJSON(ctx, http.MethodGet, p.itemURL(remotePath), remotePath, nil, &item); err != nil {
+		return nil, fmt.Errorf("failed to get metadata: %w", err)
+	}
+	return p.metadata(&item, cleanPath(remotePath)), nil
+}
+
+// CreateFolder creates a folder and any missing parents
+func (p *PulsePointOneDriveProvider) CreateFolder(ctx context.Context, remotePath string) error {
+	return p.ensureFolder(ctx, p.drivePath(remotePath))
+}
+
+// ensureFolder creates a folder, given by its drive path, one level at a
+// time. This includes the root folder itself. Folders already created or
+// seen are remembered, so uploads into them cost no extra requests.
+func (p *PulsePointOneDriveProvider) ensureFolder(ctx context.Context, drivePath string) error {
+	if drivePath == "/" || p.knownFolder(drivePath) {
+		return nil
+	}
+	if err := p.ensureFolder(ctx, path.Dir(drivePath)); err != nil {
+		return err
+	}
+
+	request := map[string]interface{}{
+		"name":                              path.Base(drivePath),
+		"folder":                            map[string]interface{}{},
+		"@microsoft.graph.conflictBehavior": "fail",
+	}
+	var item driveItem
+	err := p.callJSON(ctx, http.MethodPost, p.driveItemURL(path.Dir(drivePath))+"/children", drivePath, request, &item)
+	if err != nil && statusCode(err) != http.StatusConflict {
+		return err
+	}
+	p.rememberFolder(item.ID, drivePath)
+
+	p.logger.Debug("Folder ensured", zap.String("path", drivePath))
+	return nil
+}
+
+// Move moves or renames a file or folder, replacing a file at the destination
+func (p *PulsePointOneDriveProvider) Move(ctx context.Context, sourcePath, destPath string) error {
+	p.logger.Debug("Moving file",
+		zap.String("source", sourcePath),
+		zap.String("destination", destPath))
+
+	source, err := p.GetMetadata(ctx, sourcePath)
+	if err != nil {
+		return err
+	}
+
+	destDir := path.Dir(cleanPath(destPath))
+	if err := p.ensureFolder(ctx, p.drivePath(destDir)); err != nil {
+		return err
+	}
+	parent, err := p.GetMetadata(ctx, destDir)
+	if err != nil {
+		return err
+	}
+
+	patch := map[string]interface{}{
+		"name":            path.Base(cleanPath(destPath)),
+		"parentReference": map[string]string{"id": parent.ID},
+	}
+	moveURL := p.itemIDURL(source.ID)
+	err = p.callJSON(ctx, http.MethodPatch, moveURL, sourcePath, patch, nil)
+	if statusCode(err) == http.StatusConflict && !source.IsFolder {
+		// Moves never overwrite, so clear the old file and try once more
+		if err := p.Delete(ctx, destPath); err != nil {
+			return err
+		}
+		err = p.callJSON(ctx, http.MethodPatch, moveURL, sourcePath, patch, nil)
+	}
+	if err != nil {
+		return fmt.Errorf("move failed: %w", err)
+	}
+	p.forgetFolders(p.drivePath(sourcePath))
+
+	p.logger.Info("File moved successfully",
+		zap.String("source", sourcePath),
+		zap.String("destination", destPath))
+
+	return nil
+}
+
+// GetQuota returns the drive's quota
+func (p *PulsePointOneDriveProvider) GetQuota(ctx context.Context) (*interfaces.QuotaInfo, error) {
+	var drive struct {
+		Quota struct {
+			Total     int64 `json:"total"`
+			Used      int64 `json:"used"`
+			Remaining int64 `json:"remaining"`
+		} `json:"quota"`
+	}
+	if err := p.callJSON(ctx, http.MethodGet, p.driveURL(), "/", nil, &drive); err != nil {
+		return nil, err
+	}
+
+	return &interfaces.QuotaInfo{
+		Total:     drive.Quota.Total,
+		Used:      drive.Quota.Used,
+		Available: drive.Quota.Remaining,
+	}, nil
+}
+
+// GetStartPageToken returns a delta link for the current state of the drive.
+// OneDrive uses delta links, which are full URLs, as page tokens.
+func (p *PulsePointOneDriveProvider) GetStartPageToken(ctx context.Context) (string, error) {
+	var page deltaPage
+	if err := p.callJSON(ctx, http.MethodGet, p.driveURL()+"/root/delta?token=latest", "/", nil, &page); err != nil {
+		return "", err
+	}
+	if page.DeltaLink == "" {
+		return "", pperrors.NewProviderError("delta response has no delta link", nil)
+	}
+	return page.DeltaLink, nil
+}
+
+// ListChanges returns one page of drive changes since the given delta or next link
+func (p *PulsePointOneDriveProvider) ListChanges(ctx context.Context, pageToken string) (*interfaces.RemoteChangePage, error) {
+	if !strings.HasPrefix(pageToken, p.config.GraphURL+"/") {
+		return nil, pperrors.NewValidationError("page token is not a Graph delta link", nil)
+	}
+
+	var page deltaPage
+	err := p.callJSON(ctx, http.MethodGet, pageToken, "/", nil, &page)
+	if statusCode(err) == http.StatusGone {
+		// The delta link expired. Resume from now; a full sync catches anything missed.
+		p.logger.Warn("OneDrive delta link expired, resuming from the current state")
+		token, err := p.GetStartPageToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &interfaces.RemoteChangePage{NewStartPageToken: token}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &interfaces.RemoteChangePage{
+		NextPageToken:     page.NextLink,
+		NewStartPageToken: page.DeltaLink,
+	}
+
+	for i := range page.Value {
+		item := &page.Value[i]
+		if item.Root != nil {
+			p.rememberFolder(item.ID, "/")
+			continue
+		}
+
+		change := interfaces.RemoteChange{
+			FileID:   item.ID,
+			Removed:  item.Deleted != nil,
+			IsFolder: item.Folder != nil,
+		}
+		if change.Removed {
+			// Deleted items have no usable parent; the watcher knows their last path
+			if change.IsFolder {
+				p.forgetFolderID(item.ID)
+			}
+			result.Changes = append(result.Changes, change)
+			continue
+		}
+
+		parentPath, err := p.folderPath(ctx, item.ParentReference.ID)
+		if err != nil && !pperrors.IsNotFoundError(err) {
+			return nil, err
+		}
+		if err == nil {
+			drivePath := path.Join(parentPath, item.Name)
+			if change.IsFolder {
+				p.rememberFolder(item.ID, drivePath)
+			}
+			change.Path = p.remotePath(drivePath)
+		}
+
+		// The sync root itself is not a change inside it
+		if change.Path == "/" {
+			continue
+		}
+
+		meta := p.metadata(item, change.Path)
+		change.Size = meta.Size
+		change.Hash = meta.Hash
+		change.ModifiedTime = meta.ModifiedTime
+		result.Changes = append(result.Changes, change)
+	}
+
+	return result, nil
+}
+
+// GetProviderName returns the provider name
+func (p *PulsePointOneDriveProvider) GetProviderName() string {
+	return providerName
+}
+
+// IsConnected checks if the provider is connected
+func (p *PulsePointOneDriveProvider) IsConnected() bool {
+	return p.client != nil
+}
+
+// Disconnect closes the connection to the provider
+func (p *PulsePointOneDriveProvider) Disconnect() error {
+	if p.client != nil {
+		p.client.CloseIdleConnections()
+	}
+	p.client = nil
+	p.logger.Info("Disconnected from OneDrive")
+	return nil
+}
+
+// folderPath returns the drive path of a folder, asking Graph for it when
+// the folder has not been seen yet
+func (p *PulsePointOneDriveProvider) folderPath(ctx context.Context, id string) (string, error) {
+	p.foldersMu.Lock()
+	cached, ok := p.folderPaths[id]
+	p.foldersMu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	var item driveItem
+	if err := p.callJSON(ctx, http.MethodGet, p.itemIDURL(id)+"?$select=id,name,root,parentReference", id, nil, &item); err != nil {
+		return "", err
+	}
+
+	drivePath := "/"
+	if item.Root == nil {
+		drivePath = path.Join(parentDrivePath(item.ParentReference.Path), item.Name)
+	}
+	p.rememberFolder(id, drivePath)
+	return drivePath, nil
+}
+
+// rememberFolder records that a folder exists at drivePath, and its ID when known
+func (p *PulsePointOneDriveProvider) rememberFolder(id, drivePath string) {
+	p.foldersMu.Lock()
+	defer p.foldersMu.Unlock()
+	if id != "" {
+		p.folderPaths[id] = drivePath
+	}
+	p.knownFolders[strings.ToLower(drivePath)] = true
+}
+
+// knownFolder checks if a folder at drivePath has been seen
+func (p *PulsePointOneDriveProvider) knownFolder(drivePath string) bool {
+	p.foldersMu.Lock()
+	defer p.foldersMu.Unlock()
+	return p.knownFolders[strings.ToLower(drivePath)]
+}
+
+// forgetFolders drops drivePath and everything below it after a delete or move
+func (p *PulsePointOneDriveProvider) forgetFolders(drivePath string) {
+	p.foldersMu.Lock()
+	defer p.foldersMu.Unlock()
+
+	lower := strings.ToLower(drivePath)
+	below := func(known string) bool {
+		known = strings.ToLower(known)
+		return known == lower || strings.HasPrefix(known, lower+"/")
+	}
+	for id, known := range p.folderPaths {
+		if below(known) {
+			delete(p.folderPaths, id)
+		}
+	}
+	for known := range p.knownFolders {
+		if below(known) {
+			delete(p.knownFolders, known)
+		}
+	}
+}
+
+// forgetFolderID drops a folder and everything below it by ID
+func (p *PulsePointOneDriveProvider) forgetFolderID(id string) {
+	p.foldersMu.Lock()
+	drivePath, ok := p.folderPaths[id]
+	p.foldersMu.Unlock()
+	if ok {
+		p.forgetFolders(drivePath)
+	}
+}
+
+// accessToken returns a valid access token, refreshing it through the auth
+// provider shortly before it expires
+func (p *PulsePointOneDriveProvider) accessToken(ctx context.Context) (string, error) {
+	p.tokenMu.Lock()
+	defer p.tokenMu.Unlock()
+
+	if p.token == nil {
+		token, err := p.auth.LoadToken()
+		if err != nil {
+			return "", pperrors.NewAuthError("no OneDrive token found. Run 'pulsepoint auth onedrive' first", err)
+		}
+		p.token = token
+	}
+
+	if !p.token.ExpiresAt.IsZero() && p.token.TimeUntilExpiry() < time.Minute {
+		refreshed, err := p.auth.RefreshToken(ctx, p.token)
+		if err != nil {
+			return "", pperrors.NewAuthError("failed to refresh OneDrive token", err)
+		}
+		if err := p.auth.StoreToken(refreshed); err != nil {
+			p.logger.Warn("Failed to save refreshed token", zap.Error(err))
+		}
+		p.token = refreshed
+	}
+	return p.token.AccessToken, nil
+}
+
+// callJSON sends a request with an optional JSON body and decodes the JSON result
+func (p *PulsePointOneDriveProvider) callJSON(ctx context.Context, method, endpoint, remotePath string, body, result interface{}) error {
+	if body == nil {
+		return p.call(ctx, method, endpoint, remotePath, "", nil, result)
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return pperrors.NewProviderError("failed to encode request", err)
+	}
+	return p.call(ctx, method, endpoint, remotePath, "application/json", bytes.NewReader(data), result)
+}
+
+// call sends a request and decodes the JSON result, if one is wanted
+func (p *PulsePointOneDriveProvider) call(ctx context.Context, method, endpoint, remotePath, contentType string, body io.Reader, result interface{}) error {
+	resp, err := p.send(ctx, method, endpoint, remotePath, contentType, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return pperrors.NewProviderError("failed to decode Graph response", err)
+	}
+	return nil
+}
+
+// send sends an authenticated request. Responses outside 2xx are returned as
+// errors.
+func (p *PulsePointOneDriveProvider) send(ctx context.Context, method, endpoint, remotePath, contentType string, body io.Reader) (*http.Response, error) {
+	if p.client == nil {
+		return nil, pperrors.NewProviderError("OneDrive provider is disconnected", nil)
+	}
+
+	token, err := p.accessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, pperrors.NewProviderError("failed to build request", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, pperrors.NewRetryable(pperrors.NetworkError, fmt.Sprintf("request for %s failed", remotePath), err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		return nil, responseError(remotePath, resp)
+	}
+	return resp, nil
+}
+
+// driveURL returns the Graph URL of the drive
+func (p *PulsePointOneDriveProvider) driveURL() string {
+	if p.config.DriveID == "" {
+		return p.config.GraphURL + "/me/drive"
+	}
+	return p.config.GraphURL + "/drives/" + url.PathEscape(p.config.DriveID)
+}
+
+// itemURL addresses an item by remote path
+func (p *PulsePointOneDriveProvider) itemURL(remotePath string) string {
+	return p.driveItemURL(p.drivePath(remotePath))
+}
+
+// driveItemURL addresses an item by path from the drive root
+func (p *PulsePointOneDriveProvider) driveItemURL(drivePath string) string {
+	if drivePath == "/" {
+		return p.driveURL() + "/root"
+	}
+
+	segments := strings.Split(strings.TrimPrefix(drivePath, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return p.driveURL() + "/root:/" + strings.Join(segments, "/") + ":"
+}
+
+// itemIDURL addresses an item by ID
+func (p *PulsePointOneDriveProvider) itemIDURL(id string) string {
+	return p.driveURL() + "/items/" + url.PathEscape(id)
+}
+
+// drivePath maps a remote path to a path from the drive root
+func (p *PulsePointOneDriveProvider) drivePath(remotePath string) string {
+	return path.Join(cleanPath(p.config.Root), cleanPath(remotePath))
+}
+
+// remotePath maps a drive path back to a remote path, or returns "" for
+// paths outside the root. OneDrive paths are case-insensitive.
+func (p *PulsePointOneDriveProvider) remotePath(drivePath string) string {
+	root := cleanPath(p.config.Root)
+	drivePath = cleanPath(drivePath)
+	switch {
+	case root == "/":
+		return drivePath
+	case strings.EqualFold(drivePath, root):
+		return "/"
+	case len(drivePath) > len(root) && strings.EqualFold(drivePath[:len(root)+1], root+"/"):
+		return drivePath[len(root):]
+	default:
+		return ""
+	}
+}
+
+// metadata converts a drive item found at remotePath
+func (p *PulsePointOneDriveProvider) metadata(item *driveItem, remotePath string) *interfaces.Metadata {
+	meta := &interfaces.Metadata{
+		ID:       item.ID,
+		Path:     remotePath,
+		Size:     item.Size,
+		IsFolder: item.Folder != nil,
+		Version:  item.ETag,
+	}
+
+	// fileSystemInfo keeps the times PulsePoint uploaded with
+	modified, created := item.LastModifiedDateTime, item.CreatedDateTime
+	if item.FileSystemInfo != nil {
+		modified, created = item.FileSystemInfo.LastModifiedDateTime, item.FileSystemInfo.CreatedDateTime
+	}
+	meta.ModifiedTime, _ = time.Parse(time.RFC3339, modified)
+	meta.CreatedTime, _ = time.Parse(time.RFC3339, created)
+
+	if meta.IsFolder {
+		meta.MimeType = mimeTypeFolder
+		return meta
+	}
+	if item.File != nil {
+		meta.MimeType = item.File.MimeType
+	}
+	meta.Hash = item.hash()
+	return meta
+}
+
+// fileSystemInfo builds the facet that sets an item's modified time
+func fileSystemInfo(modified time.Time) map[string]string {
+	return map[string]string{"lastModifiedDateTime": modified.UTC().Truncate(time.Second).Format(time.RFC3339)}
+}
+
+// parentDrivePath extracts the drive path from a parentReference path such
+// as /drive/root:/Documents
+func parentDrivePath(reference string) string {
+	if i := strings.Index(reference, "root:"); i >= 0 {
+		reference = reference[i+len("root:"):]
+	}
+	if unescaped, err := url.PathUnescape(reference); err == nil {
+		reference = unescaped
+	}
+	return cleanPath(reference)
+}
+
+// spoolToTemp copies r to a temporary file so its size is known
+func spoolToTemp(r io.Reader) (*os.File, int64, error) {
+	spool, err := os.CreateTemp("", "pulsepoint-onedrive-*")
+	if err != nil {
+		return nil, 0, pperrors.NewFileSystemError("failed to create temporary file", err)
+	}
+	size, err := io.Copy(spool, r)
+	if err == nil {
+		_, err = spool.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		spool.Close()
+		os.Remove(spool.Name())
+		return nil, 0, pperrors.NewFileSystemError("failed to buffer upload", err)
+	}
+	return spool, size, nil
+}
+
+// cleanPath normalises a remote path to a clean absolute path
+func cleanPath(remotePath string) string {
+	return path.Clean("/" + remotePath)
+}
+
+// responseError turns an unsuccessful response into a provider error that
+// keeps the status code and the Graph error code
+func responseError(remotePath string, resp *http.Response) error {
+	var apiErr struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 16*1024))
+	if json.Unmarshal(data, &apiErr) != nil || apiErr.Error.Code == "" {
+		apiErr.Error.Message = strings.TrimSpace(string(data))
+	}
+	message := fmt.Sprintf("Graph request for %s failed: %s %s", remotePath, resp.Status, apiErr.Error.Message)
+
+	var pe *pperrors.PulseError
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		pe = pperrors.NewAuthError(message, nil)
+	case resp.StatusCode == http.StatusNotFound:
+		pe = pperrors.NewProviderError(fmt.Sprintf("file not found: %s", remotePath), nil)
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		pe = pperrors.NewRetryable(pperrors.ProviderError, message, nil)
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			pe.WithContext("retry_after", time.Duration(seconds)*time.Second)
+		}
+	default:
+		pe = pperrors.NewProviderError(message, nil)
+	}
+	pe.StatusCode = resp.StatusCode
+	return pe.WithContext("error_code", apiErr.Error.Code)
+}
+
+// statusCode returns the HTTP status of a Graph error, or 0
+func statusCode(err error) int {
+	if pe, ok := err.(*pperrors.PulseError); ok {
+		return pe.StatusCode
+	}
+	return 0
+}
+
+// driveItem is a Graph driveItem
+type driveItem struct {
+	ID                   string `json:"id"`
+	Name                 string `json:"name"`
+	Size                 int64  `json:"size"`
+	ETag                 string `json:"eTag"`
+	CreatedDateTime      string `json:"createdDateTime"`
+	LastModifiedDateTime string `json:"lastModifiedDateTime"`
+	FileSystemInfo       *struct {
+		CreatedDateTime      string `json:"createdDateTime"`
+		LastModifiedDateTime string `json:"lastModifiedDateTime"`
+	} `json:"fileSystemInfo,omitempty"`
+	File *struct {
+		MimeType string `json:"mimeType"`
+		Hashes   struct {
+			QuickXorHash string `json:"quickXorHash"`
+		} `json:"hashes"`
+	} `json:"file,omitempty"`
+	Folder          *struct{} `json:"folder,omitempty"`
+	Root            *struct{} `json:"root,omitempty"`
+	Deleted         *struct{} `json:"deleted,omitempty"`
+	ParentReference struct {
+		ID   string `json:"id"`
+		Path string `json:"path"`
+	} `json:"parentReference"`
+}
+
+// hash returns the item's QuickXorHash, if OneDrive reported one
+func (i *driveItem) hash() string {
+	if i.File == nil {
+		return ""
+	}
+	return i.File.Hashes.QuickXorHash
+}
+
+// deltaPage is one page of a delta query
+type deltaPage struct {
+	Value     []driveItem `json:"value"`
+	NextLink  string      `json:"@odata.nextLink"`
+	DeltaLink string      `json:"@odata.deltaLink"`
+}
@@ -0,0 +1,632 @@
+package onedrive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	onedriveauth "github.com/pulsepoint/pulsepoint/internal/auth/onedrive"
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"github.com/pulsepoint/pulsepoint/internal/providers/providertest"
+	pperrors "github.com/pulsepoint/pulsepoint/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const rootID = "root-id"
+
+// fakeItem is a file or folder in the fake drive
+type fakeItem struct {
+	id       string
+	name     string
+	parent   string
+	folder   bool
+	deleted  bool
+	data     []byte
+	modified time.Time
+	seq      int // position in the change log
+}
+
+// fakeSession is an upload session in progress
+type fakeSession struct {
+	parent   string
+	name     string
+	modified time.Time
+	data     []byte
+}
+
+// fakeGraph is an in-memory Microsoft Graph drive. Children and delta pages
+// hold two items each so paging is exercised.
+type fakeGraph struct {
+	t        *testing.T
+	url      string
+	mu       sync.Mutex
+	items    map[string]*fakeItem
+	sessions map[string]*fakeSession
+	seq      int
+	nextID   int
+	calls    map[string]int
+}
+
+func newFakeGraph(t *testing.T) *fakeGraph {
+	fake := &fakeGraph{
+		t:        t,
+		items:    map[string]*fakeItem{rootID: {id: rootID, folder: true}},
+		sessions: make(map[string]*fakeSession),
+		calls:    make(map[string]int),
+	}
+	server := httptest.NewServer(http.HandlerFunc(fake.serve))
+	t.Cleanup(server.Close)
+	fake.url = server.URL
+	return fake
+}
+
+func (f *fakeGraph) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/upload/"):
+		// Upload URLs are pre-authenticated
+		assert.Empty(f.t, r.Header.Get("Authorization"))
+		f.uploadChunk(w, r, strings.TrimPrefix(r.URL.Path, "/upload/"))
+		return
+	case strings.HasPrefix(r.URL.Path, "/download/"):
+		item := f.items[strings.TrimPrefix(r.URL.Path, "/download/")]
+		w.Write(item.data)
+		return
+	}
+
+	if r.Header.Get("Authorization") != "Bearer good-token" {
+		f.fail(w, http.StatusUnauthorized, "InvalidAuthenticationToken")
+		return
+	}
+
+	route := strings.TrimPrefix(r.URL.Path, "/v1.0/me/drive")
+	var item, parent *fakeItem
+	var name, rest string
+	switch {
+	case route == "":
+		f.calls["quota"]++
+		f.reply(w, http.StatusOK, map[string]interface{}{
+			"quota": map[string]int64{"total": 4096, "used": 1024, "remaining": 3072},
+		})
+		return
+	case route == "/root/delta":
+		f.delta(w, r)
+		return
+	case strings.HasPrefix(route, "/root:/"):
+		end := strings.LastIndex(route, ":")
+		item, parent, name = f.resolve(route[len("/root:"):end])
+		rest = route[end+1:]
+	case strings.HasPrefix(route, "/root"):
+		item, rest = f.items[rootID], strings.TrimPrefix(route, "/root")
+	case strings.HasPrefix(route, "/items/"):
+		parts := strings.SplitN(strings.TrimPrefix(route, "/items/"), "/", 2)
+		if i, ok := f.items[parts[0]]; ok && !i.deleted {
+			item = i
+		}
+		if len(parts) == 2 {
+			rest = "/" + parts[1]
+		}
+	}
+	f.calls[r.Method+" "+rest]++
+
+	switch {
+	case r.Method == http.MethodPut && rest == "/content":
+		if parent == nil {
+			f.fail(w, http.StatusNotFound, "itemNotFound")
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		f.reply(w, http.StatusCreated, f.view(f.store(item, parent.id, name, data, time.Now()), true))
+	case r.Method == http.MethodPost && rest == "/createUploadSession":
+		if parent == nil {
+			f.fail(w, http.StatusNotFound, "itemNotFound")
+			return
+		}
+		var body struct {
+			Item struct {
+				FileSystemInfo struct {
+					LastModifiedDateTime time.Time `json:"lastModifiedDateTime"`
+				} `json:"fileSystemInfo"`
+			} `json:"item"`
+		}
+		require.NoError(f.t, json.NewDecoder(r.Body).Decode(&body))
+		id := fmt.Sprintf("s%d", len(f.sessions)+1)
+		f.sessions[id] = &fakeSession{parent: parent.id, name: name, modified: body.Item.FileSystemInfo.LastModifiedDateTime}
+		f.reply(w, http.StatusOK, map[string]string{"uploadUrl": f.url + "/upload/" + id})
+	case item == nil:
+		f.fail(w, http.StatusNotFound, "itemNotFound")
+	case r.Method == http.MethodGet && rest == "":
+		f.reply(w, http.StatusOK, f.view(item, true))
+	case r.Method == http.MethodGet && rest == "/content":
+		http.Redirect(w, r, f.url+"/download/"+item.id, http.StatusFound)
+	case r.Method == http.MethodGet && rest == "/children":
+		f.children(w, r, item)
+	case r.Method == http.MethodPost && rest == "/children":
+		var body struct {
+			Name string `json:"name"`
+		}
+		require.NoError(f.t, json.NewDecoder(r.Body).Decode(&body))
+		if f.child(item.id, body.Name) != nil {
+			f.fail(w, http.StatusConflict, "nameAlreadyExists")
+			return
+		}
+		folder := f.store(nil, item.id, body.Name, nil, time.Now())
+		folder.folder = true
+		f.reply(w, http.StatusCreated, f.view(folder, true))
+	case r.Method == http.MethodPatch:
+		f.patch(w, r, item)
+	case r.Method == http.MethodDelete:
+		f.remove(item)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// resolve finds the item at a drive path, or its parent and name if it is missing
+func (f *fakeGraph) resolve(drivePath string) (item, parent *fakeItem, name string) {
+	item = f.items[rootID]
+	for _, segment := range strings.Split(strings.Trim(drivePath, "/"), "/") {
+		if item == nil {
+			return nil, nil, ""
+		}
+		parent, name = item, segment
+		item = f.child(parent.id, segment)
+	}
+	return item, parent, name
+}
+
+func (f *fakeGraph) child(parent, name string) *fakeItem {
+	for _, item := range f.items {
+		if !item.deleted && item.parent == parent && strings.EqualFold(item.name, name) {
+			return item
+		}
+	}
+	return nil
+}
+
+func (f *fakeGraph) store(item *fakeItem, parent, name string, data []byte, modified time.Time) *fakeItem {
+	if item == nil {
+		f.nextID++
+		item = &fakeItem{id: fmt.Sprintf("item-%d", f.nextID), parent: parent, name: name}
+		f.items[item.id] = item
+	}
+	item.data, item.modified = data, modified.UTC().Truncate(time.Second)
+	f.touch(item)
+	return item
+}
+
+func (f *fakeGraph) touch(item *fakeItem) {
+	f.seq++
+	item.seq = f.seq
+}
+
+func (f *fakeGraph) remove(item *fakeItem) {
+	for _, child := range f.items {
+		if child.parent == item.id && !child.deleted {
+			f.remove(child)
+		}
+	}
+	item.deleted = true
+	f.touch(item)
+}
+
+func (f *fakeGraph) patch(w http.ResponseWriter, r *http.Request, item *fakeItem) {
+	var body struct {
+		Name            string `json:"name"`
+		ParentReference struct {
+			ID string `json:"id"`
+		} `json:"parentReference"`
+		FileSystemInfo *struct {
+			LastModifiedDateTime time.Time `json:"lastModifiedDateTime"`
+		} `json:"fileSystemInfo"`
+	}
+	require.NoError(f.t, json.NewDecoder(r.Body).Decode(&body))
+
+	if body.Name != "" {
+		if existing := f.child(body.ParentReference.ID, body.Name); existing != nil && existing != item {
+			f.fail(w, http.StatusConflict, "nameAlreadyExists")
+			return
+		}
+		item.name, item.parent = body.Name, body.ParentReference.ID
+	}
+	if body.FileSystemInfo != nil {
+		item.modified = body.FileSystemInfo.LastModifiedDateTime
+	}
+	f.touch(item)
+	f.reply(w, http.StatusOK, f.view(item, true))
+}
+
+func (f *fakeGraph) uploadChunk(w http.ResponseWriter, r *http.Request, id string) {
+	session, ok := f.sessions[id]
+	if !ok {
+		f.fail(w, http.StatusNotFound, "itemNotFound")
+		return
+	}
+	f.calls["PUT chunk"]++
+
+	var start, end, total int
+	_, err := fmt.Sscanf(r.Header.Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &total)
+	require.NoError(f.t, err)
+	data, _ := io.ReadAll(r.Body)
+	if start != len(session.data) || end-start+1 != len(data) {
+		f.fail(w, http.StatusRequestedRangeNotSatisfiable, "invalidRange")
+		return
+	}
+	if end+1 < total {
+		assert.Zero(f.t, len(data)%(320*1024), "only the last chunk may be a partial unit")
+	}
+
+	session.data = append(session.data, data...)
+	if len(session.data) < total {
+		f.reply(w, http.StatusAccepted, map[string]interface{}{"nextExpectedRanges": []string{fmt.Sprintf("%d-", len(session.data))}})
+		return
+	}
+
+	delete(f.sessions, id)
+	item := f.store(f.child(session.parent, session.name), session.parent, session.name, session.data, session.modified)
+	f.reply(w, http.StatusCreated, f.view(item, true))
+}
+
+func (f *fakeGraph) children(w http.ResponseWriter, r *http.Request, folder *fakeItem) {
+	var ids []string
+	for _, item := range f.items {
+		if item.parent == folder.id && !item.deleted {
+			ids = append(ids, item.id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return f.items[ids[i]].name < f.items[ids[j]].name })
+
+	skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+	page := map[string]interface{}{"value": f.views(ids, skip, true)}
+	if skip+2 < len(ids) {
+		page["@odata.nextLink"] = fmt.Sprintf("%s/v1.0/me/drive/items/%s/children?skip=%d", f.url, folder.id, skip+2)
+	}
+	f.reply(w, http.StatusOK, page)
+}
+
+// delta returns items changed after the token's sequence number, without
+// paths, as Graph does
+func (f *fakeGraph) delta(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	deltaLink := fmt.Sprintf("%s/v1.0/me/drive/root/delta?token=%d", f.url, f.seq)
+	switch token {
+	case "latest":
+		f.reply(w, http.StatusOK, map[string]interface{}{"value": []interface{}{}, "@odata.deltaLink": deltaLink})
+		return
+	case "expired":
+		f.fail(w, http.StatusGone, "resyncRequired")
+		return
+	}
+
+	since, err := strconv.Atoi(token)
+	require.NoError(f.t, err)
+	var ids []string
+	for _, item := range f.items {
+		if item.seq > since {
+			ids = append(ids, item.id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return f.items[ids[i]].seq < f.items[ids[j]].seq })
+
+	skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+	page := map[string]interface{}{"value": f.views(ids, skip, false)}
+	if skip+2 < len(ids) {
+		page["@odata.nextLink"] = fmt.Sprintf("%s/v1.0/me/drive/root/delta?token=%d&skip=%d", f.url, since, skip+2)
+	} else {
+		page["@odata.deltaLink"] = deltaLink
+	}
+	f.reply(w, http.StatusOK, page)
+}
+
+func (f *fakeGraph) views(ids []string, skip int, withPath bool) []interface{} {
+	views := []interface{}{}
+	for i := skip; i < len(ids) && i < skip+2; i++ {
+		views = append(views, f.view(f.items[ids[i]], withPath))
+	}
+	return views
+}
+
+// view renders an item as a driveItem
+func (f *fakeGraph) view(item *fakeItem, withPath bool) map[string]interface{} {
+	view := map[string]interface{}{
+		"id":   item.id,
+		"name": item.name,
+		"eTag": fmt.Sprintf("\"%s,%d\"", item.id, item.seq),
+		"size": len(item.data),
+		"fileSystemInfo": map[string]string{
+			"createdDateTime":      "2024-01-01T00:00:00Z",
+			"lastModifiedDateTime": item.modified.Format(time.RFC3339),
+		},
+	}
+	if item.id == rootID {
+		view["root"] = map[string]interface{}{}
+	}
+	if item.deleted {
+		view["deleted"] = map[string]string{"state": "deleted"}
+	}
+	if item.folder {
+		view["folder"] = map[string]int{"childCount": 0}
+	} else {
+		hash, _ := QuickXorHash(bytes.NewReader(item.data))
+		view["file"] = map[string]interface{}{
+			"mimeType": "application/octet-stream",
+			"hashes":   map[string]string{"quickXorHash": hash},
+		}
+	}
+
+	if item.parent != "" {
+		reference := map[string]string{"id": item.parent}
+		if withPath {
+			reference["path"] = "/drive/root:" + f.pathOf(item.parent)
+		}
+		view["parentReference"] = reference
+	}
+	return view
+}
+
+func (f *fakeGraph) pathOf(id string) string {
+	if id == rootID {
+		return ""
+	}
+	item := f.items[id]
+	return f.pathOf(item.parent) + "/" + item.name
+}
+
+func (f *fakeGraph) reply(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func (f *fakeGraph) fail(w http.ResponseWriter, status int, code string) {
+	f.reply(w, status, map[string]interface{}{"error": map[string]string{"code": code, "message": code}})
+}
+
+func newTestProvider(t *testing.T, fake *fakeGraph, chunkSize int64) *PulsePointOneDriveProvider {
+	auth, err := onedriveauth.NewPulsePointOneDriveAuth(&onedriveauth.Config{
+		ClientID:  "client-id",
+		TokenFile: filepath.Join(t.TempDir(), "onedrive_token.json"),
+		LoginURL:  fake.url,
+	})
+	require.NoError(t, err)
+	require.NoError(t, auth.StoreToken(&interfaces.AuthToken{
+		AccessToken:  "good-token",
+		RefreshToken: "refresh",
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}))
+
+	provider, err := NewPulsePointOneDriveProvider(&Config{
+		Root:      "/PulsePoint",
+		ChunkSize: chunkSize,
+		GraphURL:  fake.url + "/v1.0",
+	}, auth)
+	require.NoError(t, err)
+	return provider
+}
+
+func download(t *testing.T, provider *PulsePointOneDriveProvider, remotePath string) string {
+	file, err := provider.Download(context.Background(), remotePath)
+	require.NoError(t, err)
+	defer file.Content.(io.Closer).Close()
+	content, err := io.ReadAll(file.Content)
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestOneDriveProviderRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeGraph(t)
+	provider := newTestProvider(t, fake, 0)
+
+	modTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, provider.Upload(ctx, &interfaces.File{
+		Path:         "/Docs/Reports/q1 #1.txt",
+		Content:      strings.NewReader("quarterly"),
+		ModifiedTime: modTime,
+	}))
+	for _, name := range []string{"a.md", "b.md"} {
+		require.NoError(t, provider.Upload(ctx, &interfaces.File{
+			Path:    "/Docs/" + name,
+			Content: strings.NewReader(name),
+		}))
+	}
+
+	// The root and both folders are created once, not for every upload
+	assert.Equal(t, 3, fake.calls["POST /children"])
+	assert.Zero(t, fake.calls["PUT chunk"])
+
+	files, err := provider.List(ctx, "/Docs")
+	require.NoError(t, err)
+	var paths []string
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	assert.Equal(t, []string{"/Docs/Reports", "/Docs/a.md", "/Docs/b.md"}, paths)
+	assert.True(t, files[0].IsFolder)
+
+	meta, err := provider.GetMetadata(ctx, "/Docs/Reports/q1 #1.txt")
+	require.NoError(t, err)
+	expected, err := QuickXorHash(strings.NewReader("quarterly"))
+	require.NoError(t, err)
+	assert.Equal(t, expected, meta.Hash)
+	assert.Equal(t, int64(9), meta.Size)
+	assert.True(t, modTime.Equal(meta.ModifiedTime))
+	assert.Equal(t, "quarterly", download(t, provider, "/Docs/Reports/q1 #1.txt"))
+
+	// Moves never overwrite, so the provider replaces the file itself
+	require.NoError(t, provider.Move(ctx, "/Docs/a.md", "/Docs/b.md"))
+	assert.Equal(t, "a.md", download(t, provider, "/Docs/b.md"))
+	require.NoError(t, provider.Move(ctx, "/Docs/Reports", "/Archive/2024"))
+	assert.Equal(t, "quarterly", download(t, provider, "/Archive/2024/q1 #1.txt"))
+
+	require.NoError(t, provider.Delete(ctx, "/Docs"))
+	_, err = provider.GetMetadata(ctx, "/Docs/b.md")
+	assert.True(t, pperrors.IsNotFoundError(err))
+	assert.NoError(t, provider.Delete(ctx, "/Docs"), "deleting a missing path succeeds")
+
+	// Uploading into a deleted folder creates it again
+	require.NoError(t, provider.Upload(ctx, &interfaces.File{
+		Path:    "/Docs/c.md",
+		Content: strings.NewReader("c"),
+	}))
+
+	quota, err := provider.GetQuota(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3072), quota.Available)
+}
+
+func TestOneDriveProviderUploadSession(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeGraph(t)
+	provider := newTestProvider(t, fake, chunkUnit)
+
+	data := bytes.Repeat([]byte("0123456789abcdef"), (simpleUploadLimit+100*1024)/16)
+	localPath := filepath.Join(t.TempDir(), "backup.tar")
+	require.NoError(t, os.WriteFile(localPath, data, 0644))
+	modTime := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+
+	require.NoError(t, provider.Upload(ctx, &interfaces.File{
+		Path:         "/backup.tar",
+		LocalPath:    localPath,
+		ModifiedTime: modTime,
+	}))
+	chunks := (len(data) + chunkUnit - 1) / chunkUnit
+	assert.Equal(t, chunks, fake.calls["PUT chunk"])
+
+	meta, err := provider.GetMetadata(ctx, "/backup.tar")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(data)), meta.Size)
+	assert.True(t, modTime.Equal(meta.ModifiedTime))
+
+	// A reader of unknown size is buffered so the session knows the total
+	require.NoError(t, provider.Upload(ctx, &interfaces.File{
+		Path:    "/backup.tar",
+		Content: io.MultiReader(bytes.NewReader(data), strings.NewReader("tail")),
+	}))
+	assert.Equal(t, string(data)+"tail", download(t, provider, "/backup.tar"))
+}
+
+func TestOneDriveProviderDeltaChanges(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeGraph(t)
+	provider := newTestProvider(t, fake, 0)
+
+	require.NoError(t, provider.Upload(ctx, &interfaces.File{Path: "/Docs/old.txt", Content: strings.NewReader("old")}))
+	token, err := provider.GetStartPageToken(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, provider.Upload(ctx, &interfaces.File{Path: "/Docs/new.txt", Content: strings.NewReader("new")}))
+	require.NoError(t, provider.Move(ctx, "/Docs", "/Papers"))
+	require.NoError(t, provider.Delete(ctx, "/Papers/old.txt"))
+
+	// A change outside the root folder
+	fake.mu.Lock()
+	fake.store(nil, rootID, "elsewhere.txt", []byte("x"), time.Now())
+	fake.mu.Unlock()
+
+	// Start from a fresh provider so folder paths have to be looked up
+	provider = newTestProvider(t, fake, 0)
+	var changes []interfaces.RemoteChange
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 10)
+		page, err := provider.ListChanges(ctx, token)
+		require.NoError(t, err)
+		changes = append(changes, page.Changes...)
+		if page.NextPageToken == "" {
+			token = page.NewStartPageToken
+			break
+		}
+		token = page.NextPageToken
+	}
+
+	byPath := make(map[string]interfaces.RemoteChange)
+	var removed []string
+	for _, c := range changes {
+		if c.Removed {
+			removed = append(removed, c.FileID)
+			continue
+		}
+		byPath[c.Path] = c
+	}
+	require.Contains(t, byPath, "/Papers/new.txt")
+	expected, _ := QuickXorHash(strings.NewReader("new"))
+	assert.Equal(t, expected, byPath["/Papers/new.txt"].Hash)
+	assert.True(t, byPath["/Papers"].IsFolder)
+	assert.Contains(t, byPath, "", "changes outside the root have no path")
+	assert.Len(t, removed, 1)
+
+	// Nothing changed since the last delta link
+	page, err := provider.ListChanges(ctx, token)
+	require.NoError(t, err)
+	assert.Empty(t, page.Changes)
+
+	// An expired delta link resumes from the current state
+	page, err = provider.ListChanges(ctx, fake.url+"/v1.0/me/drive/root/delta?token=expired")
+	require.NoError(t, err)
+	assert.Empty(t, page.Changes)
+	assert.NotEmpty(t, page.NewStartPageToken)
+}
+
+// naiveQuickXor is a bit-by-bit reference implementation of QuickXorHash
+func naiveQuickXor(data []byte) []byte {
+	var bits [quickXorWidth]byte
+	for i, b := range data {
+		start := i * quickXorShift
+		for bit := 0; bit < 8; bit++ {
+			bits[(start+bit)%quickXorWidth] ^= (b >> bit) & 1
+		}
+	}
+	out := make([]byte, quickXorWidth/8)
+	for i, bit := range bits {
+		out[i/8] |= bit << (i % 8)
+	}
+	length := uint64(len(data))
+	for i := 0; i < 8; i++ {
+		out[len(out)-8+i] ^= byte(length >> (8 * i))
+	}
+	return out
+}
+
+func TestQuickXorHash(t *testing.T) {
+	hash, err := QuickXorHash(strings.NewReader("J"))
+	require.NoError(t, err)
+	assert.Equal(t, "SgAAAAAAAAAAAAAAAQAAAAAAAAA=", hash)
+
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i*7 + i/3)
+	}
+
+	// Split writes must match one large write and the reference
+	h := newQuickXorHash()
+	for _, chunk := range [][]byte{data[:1], data[1:160], data[160:161], data[161:]} {
+		h.Write(chunk)
+	}
+	assert.Equal(t, naiveQuickXor(data), h.Sum(nil))
+
+	h.Reset()
+	h.Write(data[:0])
+	assert.Equal(t, naiveQuickXor(nil), h.Sum(nil))
+}
+
+func TestOneDriveProviderConformance(t *testing.T) {
+	providertest.Run(t, func(t *testing.T) interfaces.CloudProvider {
+		return newTestProvider(t, newFakeGraph(t), 0)
+	})
+}
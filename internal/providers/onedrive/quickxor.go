@@ -0,0 +1,91 @@
+package onedrive
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"hash"
+	"io"
+)
+
+const (
+	// quickXorWidth is the width of the QuickXorHash in bits
+	quickXorWidth = 160
+
+	// quickXorShift is how far each byte is shifted from the previous one
+	quickXorShift = 11
+)
+
+// quickXorHash implements the OneDrive QuickXorHash. Every input byte is
+// XORed into a 160-bit circular buffer, 11 bits further along than the byte
+// before it, and the input length is XORed into the last 8 bytes at the end.
+type quickXorHash struct {
+	data   [3]uint64 // 64 + 64 + 32 bits
+	shift  int       // bit position of the next byte
+	length uint64
+}
+
+func newQuickXorHash() hash.Hash {
+	return &quickXorHash{}
+}
+
+// Write adds data to the hash
+func (h *quickXorHash) Write(p []byte) (int, error) {
+	for _, b := range p {
+		cell, offset := h.shift/64, h.shift%64
+		bits := 64
+		if cell == len(h.data)-1 {
+			bits = quickXorWidth % 64
+		}
+
+		h.data[cell] ^= uint64(b) << offset
+		if offset > bits-8 {
+			// The byte spills over into the next cell, wrapping at the end
+			next := (cell + 1) % len(h.data)
+			h.data[next] ^= uint64(b) >> (bits - offset)
+		}
+
+		h.shift = (h.shift + quickXorShift) % quickXorWidth
+	}
+	h.length += uint64(len(p))
+	return len(p), nil
+}
+
+// Sum appends the hash to b without changing the state
+func (h *quickXorHash) Sum(b []byte) []byte {
+	var out [quickXorWidth / 8]byte
+	binary.LittleEndian.PutUint64(out[0:], h.data[0])
+	binary.LittleEndian.PutUint64(out[8:], h.data[1])
+	binary.LittleEndian.PutUint32(out[16:], uint32(h.data[2]))
+
+	var length [8]byte
+	binary.LittleEndian.PutUint64(length[:], h.length)
+	for i, c := range length {
+		out[len(out)-len(length)+i] ^= c
+	}
+	return append(b, out[:]...)
+}
+
+// Reset resets the hash to its initial state
+func (h *quickXorHash) Reset() {
+	*h = quickXorHash{}
+}
+
+// Size returns the number of bytes Sum returns
+func (h *quickXorHash) Size() int {
+	return quickXorWidth / 8
+}
+
+// BlockSize returns the hash's underlying block size
+func (h *quickXorHash) BlockSize() int {
+	return 64
+}
+
+// QuickXorHash computes the base64 QuickXorHash of r, the form OneDrive
+// reports and Metadata.Hash carries
+func QuickXorHash(r io.Reader) (string, error) {
+	h := newQuickXorHash()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,287 @@
+// Package providertest checks CloudProvider implementations against the
+// contract the sync strategies rely on
+package providertest
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	pperrors "github.com/pulsepoint/pulsepoint/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Run checks the provider returned by newProvider against the CloudProvider
+// contract. Every subtest gets a new provider with an empty root.
+//
+// The contract is:
+//   - Paths are slash-separated and relative to the provider's root, so
+//     "a/b" and "/a/b" are the same file. Returned paths are absolute.
+//   - Upload creates missing parent folders and replaces existing files.
+//   - Download and GetMetadata fail with a not-found error, as reported by
+//     pperrors.IsNotFoundError, for paths that do not exist.
+//   - Delete removes files and whole folders, and succeeds for missing paths.
+//   - CreateFolder creates missing parents and succeeds for existing folders.
+//   - Move creates the destination's parents and replaces a destination file.
+//   - List returns the direct children of a folder, folders included.
+//   - GetMetadata, List and Download agree on a file's size, and on its hash
+//     where they report one.
+//   - Calls fail once their context is cancelled.
+func Run(t *testing.T, newProvider func(t *testing.T) interfaces.CloudProvider) {
+	t.Run("UploadDownloadRoundTrip", func(t *testing.T) {
+		testRoundTrip(t, newProvider(t))
+	})
+	t.Run("CreateFolderNested", func(t *testing.T) {
+		testCreateFolder(t, newProvider(t))
+	})
+	t.Run("MoveAcrossFolders", func(t *testing.T) {
+		testMove(t, newProvider(t))
+	})
+	t.Run("DeleteIsIdempotent", func(t *testing.T) {
+		testDelete(t, newProvider(t))
+	})
+	t.Run("ListEmptyAndDeepFolders", func(t *testing.T) {
+		testList(t, newProvider(t))
+	})
+	t.Run("MetadataConsistency", func(t *testing.T) {
+		testMetadata(t, newProvider(t))
+	})
+	t.Run("ContextCancellation", func(t *testing.T) {
+		testCancellation(t, newProvider(t))
+	})
+}
+
+func testRoundTrip(t *testing.T, provider interfaces.CloudProvider) {
+	ctx := context.Background()
+
+	upload(t, provider, "/roundtrip/hello.txt", "hello, world")
+	assertContent(t, provider, "/roundtrip/hello.txt", "hello, world")
+
+	// Relative paths name the same file, and uploads replace it
+	upload(t, provider, "roundtrip/hello.txt", "replaced")
+	assertContent(t, provider, "/roundtrip/hello.txt", "replaced")
+
+	// Files without in-memory content are read from LocalPath
+	binary := string(bytes.Repeat([]byte{0, 1, 2, 0xfe, 0xff}, 1000))
+	localPath := filepath.Join(t.TempDir(), "local.bin")
+	require.NoError(t, os.WriteFile(localPath, []byte(binary), 0644))
+	require.NoError(t, provider.Upload(ctx, &interfaces.File{
+		Path:         "/roundtrip/local.bin",
+		Name:         "local.bin",
+		LocalPath:    localPath,
+		Size:         int64(len(binary)),
+		ModifiedTime: time.Now(),
+	}))
+	assertContent(t, provider, "/roundtrip/local.bin", binary)
+
+	upload(t, provider, "/roundtrip/empty.txt", "")
+	assertContent(t, provider, "/roundtrip/empty.txt", "")
+}
+
+func testCreateFolder(t *testing.T, provider interfaces.CloudProvider) {
+	ctx := context.Background()
+
+	require.NoError(t, provider.CreateFolder(ctx, "/a/b/c"))
+	for _, folder := range []string{"/a", "/a/b", "/a/b/c"} {
+		meta, err := provider.GetMetadata(ctx, folder)
+		require.NoError(t, err, folder)
+		assert.True(t, meta.IsFolder, folder)
+	}
+
+	// Creating an existing folder is not an error
+	require.NoError(t, provider.CreateFolder(ctx, "/a/b/c"))
+	require.NoError(t, provider.CreateFolder(ctx, "a/b"))
+
+	entries := list(t, provider, "/a/b")
+	require.Len(t, entries, 1)
+	assert.Equal(t, "/a/b/c", entries[0].Path)
+	assert.True(t, entries[0].IsFolder)
+}
+
+func testMove(t *testing.T, provider interfaces.CloudProvider) {
+	ctx := context.Background()
+
+	upload(t, provider, "/src/file.txt", "moving")
+	require.NoError(t, provider.Move(ctx, "/src/file.txt", "/dst/sub/renamed.txt"))
+	assertNotFound(t, provider, "/src/file.txt")
+	assertContent(t, provider, "/dst/sub/renamed.txt", "moving")
+
+	// A file at the destination is replaced
+	upload(t, provider, "/src/other.txt", "newer")
+	require.NoError(t, provider.Move(ctx, "src/other.txt", "dst/sub/renamed.txt"))
+	assertNotFound(t, provider, "/src/other.txt")
+	assertContent(t, provider, "/dst/sub/renamed.txt", "newer")
+	assert.Len(t, list(t, provider, "/dst/sub"), 1)
+
+	// Folders move with their contents
+	require.NoError(t, provider.Move(ctx, "/dst/sub", "/moved/sub"))
+	assertNotFound(t, provider, "/dst/sub/renamed.txt")
+	assertContent(t, provider, "/moved/sub/renamed.txt", "newer")
+}
+
+func testDelete(t *testing.T, provider interfaces.CloudProvider) {
+	ctx := context.Background()
+
+	upload(t, provider, "/delete/file.txt", "gone soon")
+	require.NoError(t, provider.Delete(ctx, "/delete/file.txt"))
+	assertNotFound(t, provider, "/delete/file.txt")
+	require.NoError(t, provider.Delete(ctx, "/delete/file.txt"), "deleting again succeeds")
+	require.NoError(t, provider.Delete(ctx, "/never/existed.txt"), "deleting a missing path succeeds")
+
+	// Deleting a folder removes everything beneath it
+	upload(t, provider, "/delete/folder/nested/file.txt", "nested")
+	require.NoError(t, provider.Delete(ctx, "/delete/folder"))
+	assertNotFound(t, provider, "/delete/folder/nested/file.txt")
+	assertNotFound(t, provider, "/delete/folder")
+	assert.Empty(t, list(t, provider, "/delete"))
+}
+
+func testList(t *testing.T, provider interfaces.CloudProvider) {
+	ctx := context.Background()
+
+	require.NoError(t, provider.CreateFolder(ctx, "/empty"))
+	assert.Empty(t, list(t, provider, "/empty"))
+
+	upload(t, provider, "/deep/1/2/3/4/leaf.txt", "leaf")
+	upload(t, provider, "/deep/1/2/sibling.txt", "sibling")
+
+	entries := list(t, provider, "/deep/1/2/3/4")
+	require.Len(t, entries, 1)
+	assert.Equal(t, "/deep/1/2/3/4/leaf.txt", entries[0].Path)
+	assert.Equal(t, int64(4), entries[0].Size)
+	assert.False(t, entries[0].IsFolder)
+
+	// Only direct children are listed
+	paths := make(map[string]bool)
+	for _, entry := range list(t, provider, "deep/1/2") {
+		paths[entry.Path] = entry.IsFolder
+	}
+	assert.Equal(t, map[string]bool{"/deep/1/2/3": true, "/deep/1/2/sibling.txt": false}, paths)
+
+	paths = make(map[string]bool)
+	for _, entry := range list(t, provider, "/") {
+		paths[entry.Path] = entry.IsFolder
+	}
+	assert.Equal(t, map[string]bool{"/deep": true, "/empty": true}, paths)
+}
+
+func testMetadata(t *testing.T, provider interfaces.CloudProvider) {
+	ctx := context.Background()
+
+	upload(t, provider, "/meta/file.txt", "first version")
+	meta, err := provider.GetMetadata(ctx, "/meta/file.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "/meta/file.txt", meta.Path)
+	assert.Equal(t, int64(len("first version")), meta.Size)
+	assert.False(t, meta.IsFolder)
+
+	entries := list(t, provider, "/meta")
+	require.Len(t, entries, 1)
+	assert.Equal(t, meta.Size, entries[0].Size)
+	if entries[0].Hash != "" {
+		assert.Equal(t, meta.Hash, entries[0].Hash)
+	}
+
+	file, err := provider.Download(ctx, "/meta/file.txt")
+	require.NoError(t, err)
+	closeContent(file)
+	assert.Equal(t, meta.Size, file.Size)
+	if file.Hash != "" {
+		assert.Equal(t, meta.Hash, file.Hash)
+	}
+
+	// The hash follows the content
+	upload(t, provider, "/meta/file.txt", "second version!")
+	updated, err := provider.GetMetadata(ctx, "/meta/file.txt")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len("second version!")), updated.Size)
+	if meta.Hash != "" {
+		assert.NotEqual(t, meta.Hash, updated.Hash)
+	}
+
+	assertNotFound(t, provider, "/meta/missing.txt")
+	_, err = provider.Download(ctx, "/meta/missing.txt")
+	assert.True(t, pperrors.IsNotFoundError(err), "download of a missing file: %v", err)
+}
+
+func testCancellation(t *testing.T, provider interfaces.CloudProvider) {
+	upload(t, provider, "/cancel/existing.txt", "existing")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := provider.Upload(ctx, &interfaces.File{
+		Path:    "/cancel/new.txt",
+		Content: bytes.NewReader([]byte("never stored")),
+		Size:    int64(len("never stored")),
+	})
+	assert.Error(t, err, "Upload")
+	_, err = provider.Download(ctx, "/cancel/existing.txt")
+	assert.Error(t, err, "Download")
+	_, err = provider.GetMetadata(ctx, "/cancel/existing.txt")
+	assert.Error(t, err, "GetMetadata")
+	_, err = provider.List(ctx, "/cancel")
+	assert.Error(t, err, "List")
+	assert.Error(t, provider.CreateFolder(ctx, "/cancel/folder"), "CreateFolder")
+	assert.Error(t, provider.Move(ctx, "/cancel/existing.txt", "/cancel/moved.txt"), "Move")
+	assert.Error(t, provider.Delete(ctx, "/cancel/existing.txt"), "Delete")
+
+	// Nothing changed
+	assertNotFound(t, provider, "/cancel/new.txt")
+	assertContent(t, provider, "/cancel/existing.txt", "existing")
+}
+
+// upload stores content at remotePath
+func upload(t *testing.T, provider interfaces.CloudProvider, remotePath, content string) {
+	t.Helper()
+	require.NoError(t, provider.Upload(context.Background(), &interfaces.File{
+		Path:         remotePath,
+		Name:         filepath.Base(remotePath),
+		Content:      bytes.NewReader([]byte(content)),
+		Size:         int64(len(content)),
+		ModifiedTime: time.Now(),
+	}), "upload %s", remotePath)
+}
+
+// list lists a folder
+func list(t *testing.T, provider interfaces.CloudProvider, folder string) []*interfaces.File {
+	t.Helper()
+	entries, err := provider.List(context.Background(), folder)
+	require.NoError(t, err, "list %s", folder)
+	return entries
+}
+
+// assertContent downloads remotePath and compares its content
+func assertContent(t *testing.T, provider interfaces.CloudProvider, remotePath, content string) {
+	t.Helper()
+	file, err := provider.Download(context.Background(), remotePath)
+	require.NoError(t, err, "download %s", remotePath)
+	defer closeContent(file)
+
+	require.NotNil(t, file.Content)
+	data, err := io.ReadAll(file.Content)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data), remotePath)
+	assert.Equal(t, remotePath, file.Path)
+	assert.Equal(t, int64(len(content)), file.Size, remotePath)
+}
+
+// assertNotFound checks that remotePath does not exist
+func assertNotFound(t *testing.T, provider interfaces.CloudProvider, remotePath string) {
+	t.Helper()
+	_, err := provider.GetMetadata(context.Background(), remotePath)
+	assert.True(t, pperrors.IsNotFoundError(err), "%s should not exist: %v", remotePath, err)
+}
+
+// closeContent closes downloaded content that holds resources
+func closeContent(file *interfaces.File) {
+	if closer, ok := file.Content.(io.Closer); ok {
+		closer.Close()
+	}
+}
@@ -0,0 +1,647 @@
+// Package s3 implements an Amazon S3 and S3-compatible provider for PulsePoint
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	pperrors "github.com/pulsepoint/pulsepoint/pkg/errors"
+	pplogger "github.com/pulsepoint/pulsepoint/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// Provider name
+	providerName = "s3"
+
+	// Objects at or above this size are uploaded in parts (64MB)
+	defaultMultipartThreshold = 64 * 1024 * 1024
+
+	// Size of each uploaded part (8MB)
+	defaultPartSize = 8 * 1024 * 1024
+
+	// S3 requires at least 5MB for all but the last part
+	minPartSize = 5 * 1024 * 1024
+
+	// S3 accepts at most this many parts per upload
+	maxParts = 10000
+
+	// Largest object a single copy request can copy (5GB)
+	maxCopySize = 5 * 1024 * 1024 * 1024
+
+	// User metadata key holding the local modification time
+	metaModTime = "Mtime"
+
+	// Content type of the zero-byte objects that mark folders
+	mimeTypeFolder = "application/x-directory"
+)
+
+// PulsePointS3Provider implements CloudProvider for Amazon S3 and
+// S3-compatible services such as MinIO
+type PulsePointS3Provider struct {
+	config *Config
+	client *minio.Client
+	prefix string
+	logger *zap.Logger
+}
+
+// Config holds S3 configuration
+type Config struct {
+	Bucket             string            `json:"bucket"`
+	Region             string            `json:"region"`
+	Endpoint           string            `json:"endpoint"`   // Empty for AWS, e.g. http://localhost:9000 for MinIO
+	PathStyle          bool              `json:"path_style"` // Address the bucket in the path instead of the host name
+	Prefix             string            `json:"prefix"`     // Key prefix that acts as the remote root
+	AccessKeyID        string            `json:"access_key_id"`
+	SecretAccessKey    string            `json:"secret_access_key"`
+	SessionToken       string            `json:"session_token"`
+	MultipartThreshold int64             `json:"multipart_threshold"`
+	PartSize           int64             `json:"part_size"`
+	Transport          http.RoundTripper `json:"-"`
+}
+
+// NewPulsePointS3Provider creates a new S3 provider
+func NewPulsePointS3Provider(config *Config) (*PulsePointS3Provider, error) {
+	if config == nil {
+		return nil, pperrors.NewConfigError("S3 configuration is required", nil)
+	}
+	if config.Bucket == "" {
+		return nil, pperrors.NewConfigError("S3 bucket is required", nil)
+	}
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, pperrors.NewConfigError("S3 access key ID and secret access key are required", nil)
+	}
+
+	// Set defaults
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.MultipartThreshold == 0 {
+		config.MultipartThreshold = defaultMultipartThreshold
+	}
+	if config.PartSize == 0 {
+		config.PartSize = defaultPartSize
+	}
+	if config.PartSize < minPartSize {
+		return nil, pperrors.NewConfigError(fmt.Sprintf("S3 part size must be at least %d bytes", minPartSize), nil)
+	}
+
+	rawEndpoint := config.Endpoint
+	if rawEndpoint == "" {
+		rawEndpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", config.Region)
+	}
+	endpoint, err := url.Parse(rawEndpoint)
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, pperrors.NewConfigError(fmt.Sprintf("invalid S3 endpoint: %s", rawEndpoint), err)
+	}
+
+	lookup := minio.BucketLookupDNS
+	if config.PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+
+	client, err := minio.New(endpoint.Host, &minio.Options{
+		Creds:        credentials.NewStaticV4(config.AccessKeyID, config.SecretAccessKey, config.SessionToken),
+		Secure:       endpoint.Scheme == "https",
+		Transport:    config.Transport,
+		Region:       config.Region,
+		BucketLookup: lookup,
+	})
+	if err != nil {
+		return nil, pperrors.NewConfigError("failed to create S3 client", err)
+	}
+
+	prefix := strings.Trim(config.Prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	provider := &PulsePointS3Provider{
+		config: config,
+		client: client,
+		prefix: prefix,
+		logger: pplogger.Get(),
+	}
+
+	provider.logger.Info("S3 provider initialized",
+		zap.String("bucket", config.Bucket),
+		zap.String("endpoint", endpoint.Host))
+
+	return provider, nil
+}
+
+// Initialize initializes the provider with configuration
+func (p *PulsePointS3Provider) Initialize(config interfaces.ProviderConfig) error {
+	// Already initialized in constructor
+	return nil
+}
+
+// Upload uploads a file to S3, in parts once it reaches the multipart threshold
+func (p *PulsePointS3Provider) Upload(ctx context.Context, file *interfaces.File) error {
+	if file == nil {
+		return pperrors.NewValidationError("file is nil", nil)
+	}
+	if err := p.check(); err != nil {
+		return err
+	}
+
+	p.logger.Debug("Uploading file to S3",
+		zap.String("path", file.Path),
+		zap.Int64("size", file.Size))
+
+	// Prefer in-memory content, otherwise read from the local file
+	reader := file.Content
+	size := file.Size
+	if reader == nil {
+		f, err := os.Open(file.LocalPath)
+		if err != nil {
+			return fmt.Errorf("failed to open local file: %w", err)
+		}
+		defer f.Close()
+		reader = f
+
+		if size == 0 {
+			if info, err := f.Stat(); err == nil {
+				size = info.Size()
+			}
+		}
+	}
+
+	// Content of unknown size is small enough to hold in memory
+	if size == 0 {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return fmt.Errorf("failed to read content: %w", err)
+		}
+		reader = bytes.NewReader(data)
+		size = int64(len(data))
+	}
+
+	opts := minio.PutObjectOptions{
+		ContentType:      file.MimeType,
+		PartSize:         uint64(p.partSize(size)),
+		DisableMultipart: size < p.config.MultipartThreshold,
+	}
+	if !file.ModifiedTime.IsZero() {
+		opts.UserMetadata = map[string]string{metaModTime: file.ModifiedTime.UTC().Format(time.RFC3339Nano)}
+	}
+
+	key := p.objectKey(file.Path)
+	if _, err := p.client.PutObject(ctx, p.config.Bucket, key, reader, size, opts); err != nil {
+		return fmt.Errorf("upload failed: %w", p.wrapError("PUT", key, err))
+	}
+
+	p.logger.Info("File uploaded successfully",
+		zap.String("path", file.Path),
+		zap.String("key", key))
+
+	return nil
+}
+
+// partSize returns the part size for an object, growing it when the
+// configured size would need more parts than S3 allows
+func (p *PulsePointS3Provider) partSize(size int64) int64 {
+	partSize := p.config.PartSize
+	if size/partSize >= maxParts {
+		partSize = size/(maxParts-1) + 1
+	}
+	return partSize
+}
+
+// Download streams a file from S3. The caller must close the returned content.
+func (p *PulsePointS3Provider) Download(ctx context.Context, remotePath string) (*interfaces.File, error) {
+	p.logger.Debug("Downloading file from S3", zap.String("path", remotePath))
+
+	if err := p.check(); err != nil {
+		return nil, err
+	}
+
+	key := p.objectKey(remotePath)
+	object, err := p.client.GetObject(ctx, p.config.Bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("download failed: %w", p.wrapError("GET", key, err))
+	}
+
+	// Stat reads the object details, so a missing object shows up here
+	info, err := object.Stat()
+	if err != nil {
+		object.Close()
+		return nil, fmt.Errorf("download failed: %w", p.wrapError("GET", key, err))
+	}
+
+	meta := p.metadataFromInfo(remotePath, info)
+	return &interfaces.File{
+		ID:           key,
+		Path:         remotePath,
+		Name:         path.Base(remotePath),
+		Size:         meta.Size,
+		Hash:         meta.Hash,
+		ModifiedTime: meta.ModifiedTime,
+		MimeType:     meta.MimeType,
+		Content:      object,
+	}, nil
+}
+
+// Delete deletes a file, or a folder and everything in it, from S3.
+// Deleting a missing path succeeds.
+func (p *PulsePointS3Provider) Delete(ctx context.Context, remotePath string) error {
+	p.logger.Debug("Deleting file from S3", zap.String("path", remotePath))
+
+	if err := p.check(); err != nil {
+		return err
+	}
+
+	key := p.objectKey(remotePath)
+	keys := []string{key}
+	if _, err := p.statObject(ctx, key); err != nil {
+		if !pperrors.IsNotFoundError(err) {
+			return fmt.Errorf("delete failed: %w", err)
+		}
+
+		// Not an object, so delete the folder's contents and marker
+		objects, err := p.listObjects(ctx, p.folderPrefix(remotePath), true)
+		if err != nil {
+			return fmt.Errorf("delete failed: %w", err)
+		}
+		if len(objects) == 0 {
+			p.logger.Debug("File already deleted", zap.String("path", remotePath))
+			return nil
+		}
+		keys = keys[:0]
+		for _, object := range objects {
+			keys = append(keys, object.Key)
+		}
+	}
+
+	for _, k := range keys {
+		if err := p.client.RemoveObject(ctx, p.config.Bucket, k, minio.RemoveObjectOptions{}); err != nil {
+			return fmt.Errorf("delete failed: %w", p.wrapError("DELETE", k, err))
+		}
+	}
+
+	p.logger.Info("File deleted successfully", zap.String("path", remotePath))
+	return nil
+}
+
+// List lists files and folders directly inside a folder
+func (p *PulsePointS3Provider) List(ctx context.Context, folder string) ([]*interfaces.File, error) {
+	p.logger.Debug("Listing files in folder", zap.String("folder", folder))
+
+	if err := p.check(); err != nil {
+		return nil, err
+	}
+
+	prefix := p.folderPrefix(folder)
+	objects, err := p.listObjects(ctx, prefix, false)
+	if err != nil {
+		return nil, fmt.Errorf("list failed: %w", err)
+	}
+
+	var files []*interfaces.File
+	for _, object := range objects {
+		// Skip the folder's own marker
+		if object.Key == prefix {
+			continue
+		}
+
+		remotePath := p.remotePath(object.Key)
+		file := &interfaces.File{
+			ID:           object.Key,
+			Path:         remotePath,
+			Name:         path.Base(remotePath),
+			Size:         object.Size,
+			Hash:         object.ETag,
+			ModifiedTime: object.LastModified,
+			IsFolder:     strings.HasSuffix(object.Key, "/"),
+		}
+		if file.IsFolder {
+			file.MimeType = mimeTypeFolder
+		}
+		files = append(files, file)
+	}
+
+	return files, nil
+}
+
+// GetMetadata gets file metadata from a HEAD request. Folders are found by
+// their marker or by any object under them.
+func (p *PulsePointS3Provider) GetMetadata(ctx context.Context, remotePath string) (*interfaces.Metadata, error) {
+	p.logger.Debug("Getting file metadata", zap.String("path", remotePath))
+
+	if err := p.check(); err != nil {
+		return nil, err
+	}
+
+	key := p.objectKey(remotePath)
+	if key == strings.TrimSuffix(p.prefix, "/") {
+		return p.folderMetadata(remotePath, key), nil
+	}
+
+	info, err := p.statObject(ctx, key)
+	if err == nil {
+		return p.metadataFromInfo(remotePath, info), nil
+	}
+	if !pperrors.IsNotFoundError(err) {
+		return nil, fmt.Errorf("failed to get metadata: %w", err)
+	}
+
+	found, err := p.hasObjects(ctx, key+"/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metadata: %w", err)
+	}
+	if !found {
+		return nil, notFoundError(remotePath)
+	}
+	return p.folderMetadata(remotePath, key), nil
+}
+
+// CreateFolder creates a zero-byte marker object for a folder
+func (p *PulsePointS3Provider) CreateFolder(ctx context.Context, remotePath string) error {
+	p.logger.Debug("Creating folder", zap.String("path", remotePath))
+
+	if err := p.check(); err != nil {
+		return err
+	}
+
+	prefix := p.folderPrefix(remotePath)
+	if prefix == p.prefix {
+		return nil
+	}
+
+	_, err := p.client.PutObject(ctx, p.config.Bucket, prefix, bytes.NewReader(nil), 0, minio.PutObjectOptions{
+		ContentType: mimeTypeFolder,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create folder: %w", p.wrapError("PUT", prefix, err))
+	}
+
+	p.logger.Info("Folder created successfully", zap.String("path", remotePath))
+	return nil
+}
+
+// Move moves a file or folder by copying every object and deleting the originals
+func (p *PulsePointS3Provider) Move(ctx context.Context, sourcePath, destPath string) error {
+	p.logger.Debug("Moving file",
+		zap.String("source", sourcePath),
+		zap.String("destination", destPath))
+
+	if err := p.check(); err != nil {
+		return err
+	}
+
+	sourceKey := p.objectKey(sourcePath)
+	destKey := p.objectKey(destPath)
+
+	type pair struct {
+		from, to string
+		size     int64
+	}
+	var moves []pair
+
+	if info, err := p.statObject(ctx, sourceKey); err == nil {
+		moves = append(moves, pair{from: sourceKey, to: destKey, size: info.Size})
+	} else if !pperrors.IsNotFoundError(err) {
+		return fmt.Errorf("move failed: %w", err)
+	} else {
+		objects, err := p.listObjects(ctx, sourceKey+"/", true)
+		if err != nil {
+			return fmt.Errorf("move failed: %w", err)
+		}
+		if len(objects) == 0 {
+			return notFoundError(sourcePath)
+		}
+		for _, object := range objects {
+			moves = append(moves, pair{
+				from: object.Key,
+				to:   destKey + "/" + strings.TrimPrefix(object.Key, sourceKey+"/"),
+				size: object.Size,
+			})
+		}
+	}
+
+	for _, move := range moves {
+		if err := p.copyObject(ctx, move.from, move.to, move.size); err != nil {
+			return fmt.Errorf("move failed: %w", err)
+		}
+	}
+	for _, move := range moves {
+		if err := p.client.RemoveObject(ctx, p.config.Bucket, move.from, minio.RemoveObjectOptions{}); err != nil {
+			return fmt.Errorf("move failed: %w", p.wrapError("DELETE", move.from, err))
+		}
+	}
+
+	p.logger.Info("File moved successfully",
+		zap.String("source", sourcePath),
+		zap.String("destination", destPath))
+
+	return nil
+}
+
+// copyObject copies an object within the bucket, in parts when it is too
+// large for a single copy
+func (p *PulsePointS3Provider) copyObject(ctx context.Context, sourceKey, destKey string, size int64) error {
+	dest := minio.CopyDestOptions{Bucket: p.config.Bucket, Object: destKey}
+	source := minio.CopySrcOptions{Bucket: p.config.Bucket, Object: sourceKey}
+
+	var err error
+	if size <= maxCopySize {
+		_, err = p.client.CopyObject(ctx, dest, source)
+	} else {
+		_, err = p.client.ComposeObject(ctx, dest, source)
+	}
+	if err != nil {
+		return p.wrapError("COPY", sourceKey, err)
+	}
+	return nil
+}
+
+// GetQuota reports the bytes stored under the prefix. Buckets have no size
+// limit, so Total and Available stay zero.
+func (p *PulsePointS3Provider) GetQuota(ctx context.Context) (*interfaces.QuotaInfo, error) {
+	if err := p.check(); err != nil {
+		return nil, err
+	}
+
+	objects, err := p.listObjects(ctx, p.prefix, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quota: %w", err)
+	}
+
+	quota := &interfaces.QuotaInfo{}
+	for _, object := range objects {
+		quota.Used += object.Size
+	}
+	return quota, nil
+}
+
+// GetProviderName returns the provider name
+func (p *PulsePointS3Provider) GetProviderName() string {
+	return providerName
+}
+
+// IsConnected checks if the provider is connected
+func (p *PulsePointS3Provider) IsConnected() bool {
+	return p.client != nil
+}
+
+// Disconnect closes the connection to the provider
+func (p *PulsePointS3Provider) Disconnect() error {
+	p.client = nil
+	p.logger.Info("Disconnected from S3")
+	return nil
+}
+
+// check fails once the provider is disconnected
+func (p *PulsePointS3Provider) check() error {
+	if p.client == nil {
+		return pperrors.NewProviderError("S3 provider is disconnected", nil)
+	}
+	return nil
+}
+
+// statObject returns the details of an object
+func (p *PulsePointS3Provider) statObject(ctx context.Context, key string) (minio.ObjectInfo, error) {
+	info, err := p.client.StatObject(ctx, p.config.Bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return minio.ObjectInfo{}, p.wrapError("HEAD", key, err)
+	}
+	return info, nil
+}
+
+// listObjects returns the objects under a prefix. Without recursive, folders
+// directly under the prefix are returned as keys ending in a slash.
+func (p *PulsePointS3Provider) listObjects(ctx context.Context, prefix string, recursive bool) ([]minio.ObjectInfo, error) {
+	var objects []minio.ObjectInfo
+	for object := range p.client.ListObjects(ctx, p.config.Bucket, minio.ListObjectsOptions{
+		Prefix:    prefix,
+		Recursive: recursive,
+	}) {
+		if object.Err != nil {
+			return nil, p.wrapError("LIST", prefix, object.Err)
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+// hasObjects reports whether any object exists under a prefix
+func (p *PulsePointS3Provider) hasObjects(ctx context.Context, prefix string) (bool, error) {
+	// Stop the listing after the first result
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for object := range p.client.ListObjects(ctx, p.config.Bucket, minio.ListObjectsOptions{
+		Prefix:    prefix,
+		Recursive: true,
+		MaxKeys:   1,
+	}) {
+		if object.Err != nil {
+			return false, p.wrapError("LIST", prefix, object.Err)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// objectKey maps a remote path to its object key under the prefix
+func (p *PulsePointS3Provider) objectKey(remotePath string) string {
+	clean := strings.TrimPrefix(path.Clean("/"+remotePath), "/")
+	return strings.TrimSuffix(p.prefix+clean, "/")
+}
+
+// folderPrefix returns the key prefix of everything inside a folder
+func (p *PulsePointS3Provider) folderPrefix(remotePath string) string {
+	key := p.objectKey(remotePath)
+	if key == "" {
+		return ""
+	}
+	return key + "/"
+}
+
+// remotePath maps an object key back to a remote path
+func (p *PulsePointS3Provider) remotePath(key string) string {
+	return "/" + strings.TrimSuffix(strings.TrimPrefix(key, p.prefix), "/")
+}
+
+// metadataFromInfo builds metadata from an object's details
+func (p *PulsePointS3Provider) metadataFromInfo(remotePath string, info minio.ObjectInfo) *interfaces.Metadata {
+	// Prefer the local modification time recorded at upload
+	modTime, err := time.Parse(time.RFC3339Nano, info.UserMetadata[metaModTime])
+	if err != nil {
+		modTime = info.LastModified
+	}
+
+	return &interfaces.Metadata{
+		ID:           info.Key,
+		Path:         remotePath,
+		Size:         info.Size,
+		Hash:         info.ETag,
+		ModifiedTime: modTime,
+		MimeType:     info.ContentType,
+		IsFolder:     strings.HasSuffix(info.Key, "/"),
+		Version:      info.VersionID,
+		Attributes: map[string]interface{}{
+			"key":  info.Key,
+			"etag": info.ETag,
+		},
+	}
+}
+
+// folderMetadata returns metadata for a folder, which S3 only knows as a prefix
+func (p *PulsePointS3Provider) folderMetadata(remotePath, key string) *interfaces.Metadata {
+	return &interfaces.Metadata{
+		ID:       key,
+		Path:     remotePath,
+		MimeType: mimeTypeFolder,
+		IsFolder: true,
+		Attributes: map[string]interface{}{
+			"key": key,
+		},
+	}
+}
+
+// wrapError turns a client error into a provider error that keeps the
+// status code. Throttling, server and network errors are retryable.
+func (p *PulsePointS3Provider) wrapError(op, key string, err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	resp := minio.ToErrorResponse(err)
+	if resp.StatusCode == 0 {
+		return pperrors.NewRetryable(pperrors.NetworkError, fmt.Sprintf("%s %s failed", op, key), err)
+	}
+
+	message := fmt.Sprintf("%s %s: %s", op, key, http.StatusText(resp.StatusCode))
+	if resp.Code != "" {
+		message = fmt.Sprintf("%s %s: %s: %s", op, key, resp.Code, resp.Message)
+	}
+
+	var pe *pperrors.PulseError
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		pe = pperrors.NewRetryable(pperrors.ProviderError, message, nil)
+	} else {
+		pe = pperrors.NewProviderError(message, nil)
+	}
+	pe.StatusCode = resp.StatusCode
+	return pe
+}
+
+// notFoundError reports a remote path that does not exist
+func notFoundError(remotePath string) error {
+	err := pperrors.NewProviderError(fmt.Sprintf("file not found: %s", remotePath), nil)
+	err.StatusCode = http.StatusNotFound
+	return err
+}
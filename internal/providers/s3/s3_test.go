@@ -0,0 +1,368 @@
+package s3
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"github.com/pulsepoint/pulsepoint/internal/providers/providertest"
+	pperrors "github.com/pulsepoint/pulsepoint/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeObject is an object stored by fakeS3
+type fakeObject struct {
+	data   []byte
+	header http.Header
+	etag   string
+}
+
+// fakeS3 is an in-memory, path-style S3 stand-in covering the calls the provider makes
+type fakeS3 struct {
+	t       *testing.T
+	bucket  string
+	mu      sync.Mutex
+	objects map[string]*fakeObject
+	uploads map[string]map[int][]byte
+	pageMax int
+	parts   int
+}
+
+func newFakeS3(t *testing.T, bucket string) *fakeS3 {
+	return &fakeS3{
+		t:       t,
+		bucket:  bucket,
+		objects: make(map[string]*fakeObject),
+		uploads: make(map[string]map[int][]byte),
+		pageMax: 1000,
+	}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=test-key/") {
+		f.fail(w, http.StatusForbidden, "AccessDenied")
+		return
+	}
+	if r.Header.Get("X-Amz-Content-Sha256") == "STREAMING-AWS4-HMAC-SHA256-PAYLOAD" {
+		body = decodeChunked(f.t, body)
+	}
+
+	key := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/"+f.bucket), "/")
+	query := r.URL.Query()
+
+	switch {
+	case key == "" && r.Method == http.MethodGet:
+		f.list(w, query)
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		id := fmt.Sprintf("upload-%d", len(f.uploads)+1)
+		f.uploads[id] = make(map[int][]byte)
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>", id)
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		f.uploads[query.Get("uploadId")][number] = body
+		f.parts++
+		w.Header().Set("ETag", fmt.Sprintf(`"part-%d"`, number))
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		parts := f.uploads[query.Get("uploadId")]
+		numbers := make([]int, 0, len(parts))
+		for number := range parts {
+			numbers = append(numbers, number)
+		}
+		sort.Ints(numbers)
+		var data []byte
+		for _, number := range numbers {
+			data = append(data, parts[number]...)
+		}
+		delete(f.uploads, query.Get("uploadId"))
+		f.put(key, data, r.Header)
+		f.objects[key].etag = fmt.Sprintf("%x-%d", md5.Sum(data), len(numbers))
+		fmt.Fprintf(w, "<CompleteMultipartUploadResult><Bucket>%s</Bucket><Key>%s</Key><ETag>\"%s\"</ETag></CompleteMultipartUploadResult>",
+			f.bucket, key, f.objects[key].etag)
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(f.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+		source, _ := url.PathUnescape(strings.TrimPrefix(strings.TrimPrefix(r.Header.Get("X-Amz-Copy-Source"), "/"), f.bucket+"/"))
+		object, ok := f.objects[source]
+		if !ok {
+			f.fail(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		f.put(key, object.data, object.header)
+		fmt.Fprintf(w, "<CopyObjectResult><ETag>\"%s\"</ETag><LastModified>%s</LastModified></CopyObjectResult>",
+			f.objects[key].etag, time.Now().UTC().Format(time.RFC3339))
+	case r.Method == http.MethodPut:
+		f.put(key, body, r.Header)
+		w.Header().Set("ETag", `"`+f.objects[key].etag+`"`)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		object, ok := f.objects[key]
+		if !ok {
+			f.fail(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		for name, values := range object.header {
+			w.Header()[name] = values
+		}
+		w.Header().Set("ETag", `"`+object.etag+`"`)
+		w.Header().Set("Content-Length", strconv.Itoa(len(object.data)))
+		w.Header().Set("Accept-Ranges", "bytes")
+		if r.Method == http.MethodGet {
+			w.Write(object.data)
+		}
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		f.fail(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+func (f *fakeS3) put(key string, data []byte, header http.Header) {
+	stored := http.Header{"Last-Modified": {time.Now().UTC().Format(http.TimeFormat)}}
+	for name, values := range header {
+		if name == "Content-Type" || strings.HasPrefix(name, "X-Amz-Meta-") {
+			stored[name] = values
+		}
+	}
+	f.objects[key] = &fakeObject{data: data, header: stored, etag: fmt.Sprintf("%x", md5.Sum(data))}
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, query map[string][]string) {
+	get := func(name string) string {
+		if values := query[name]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	prefix, delimiter := get("prefix"), get("delimiter")
+	pageMax := f.pageMax
+	if maxKeys, err := strconv.Atoi(get("max-keys")); err == nil {
+		pageMax = maxKeys
+	}
+
+	keys := make([]string, 0, len(f.objects))
+	for key := range f.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var result fakeListResult
+	seen := make(map[string]bool)
+	after := get("continuation-token")
+	for _, key := range keys {
+		if key <= after {
+			continue
+		}
+		if len(result.Contents)+len(result.CommonPrefixes) == pageMax {
+			result.IsTruncated = true
+			break
+		}
+		if delimiter != "" {
+			if i := strings.Index(key[len(prefix):], delimiter); i >= 0 {
+				common := key[:len(prefix)+i+1]
+				if !seen[common] {
+					seen[common] = true
+					result.CommonPrefixes = append(result.CommonPrefixes, fakePrefix{common})
+				}
+				result.NextContinuationToken = common + "\xff"
+				continue
+			}
+		}
+		stored := f.objects[key]
+		result.Contents = append(result.Contents, fakeListObject{Key: key, Size: int64(len(stored.data)), ETag: `"` + stored.etag + `"`, LastModified: time.Now().UTC()})
+		result.NextContinuationToken = key
+	}
+
+	if !result.IsTruncated {
+		result.NextContinuationToken = ""
+	}
+
+	data, err := xml.Marshal(&result)
+	require.NoError(f.t, err)
+	w.Write(data)
+}
+
+// fakeListResult is the ListObjectsV2 response written by fakeS3
+type fakeListResult struct {
+	XMLName               xml.Name         `xml:"ListBucketResult"`
+	IsTruncated           bool             `xml:"IsTruncated"`
+	NextContinuationToken string           `xml:"NextContinuationToken,omitempty"`
+	Contents              []fakeListObject `xml:"Contents"`
+	CommonPrefixes        []fakePrefix     `xml:"CommonPrefixes"`
+}
+
+type fakeListObject struct {
+	Key          string    `xml:"Key"`
+	LastModified time.Time `xml:"LastModified"`
+	ETag         string    `xml:"ETag"`
+	Size         int64     `xml:"Size"`
+}
+
+type fakePrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+// decodeChunked strips the chunk headers of a streaming-signed body
+func decodeChunked(t *testing.T, body []byte) []byte {
+	var data []byte
+	reader := bufio.NewReader(bytes.NewReader(body))
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		size, err := strconv.ParseInt(strings.SplitN(strings.TrimSpace(line), ";", 2)[0], 16, 64)
+		require.NoError(t, err)
+		if size == 0 {
+			return data
+		}
+		chunk := make([]byte, size+2)
+		_, err = io.ReadFull(reader, chunk)
+		require.NoError(t, err)
+		data = append(data, chunk[:size]...)
+	}
+}
+
+func (f *fakeS3) fail(w http.ResponseWriter, status int, code string) {
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, code)
+}
+
+func newTestProvider(t *testing.T, fake *fakeS3, prefix string) *PulsePointS3Provider {
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	provider, err := NewPulsePointS3Provider(&Config{
+		Bucket:             fake.bucket,
+		Endpoint:           server.URL,
+		PathStyle:          true,
+		Prefix:             prefix,
+		AccessKeyID:        "test-key",
+		SecretAccessKey:    "test-secret",
+		MultipartThreshold: 16,
+		PartSize:           minPartSize,
+	})
+	require.NoError(t, err)
+	return provider
+}
+
+func TestS3ProviderRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeS3(t, "backups")
+	provider := newTestProvider(t, fake, "laptop")
+
+	modTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, provider.Upload(ctx, &interfaces.File{
+		Path:         "/Docs/notes file.txt",
+		Size:         5,
+		Content:      strings.NewReader("hello"),
+		ModifiedTime: modTime,
+	}))
+	assert.Contains(t, fake.objects, "laptop/Docs/notes file.txt")
+
+	meta, err := provider.GetMetadata(ctx, "/Docs/notes file.txt")
+	require.NoError(t, err)
+	sum := md5.Sum([]byte("hello"))
+	assert.Equal(t, hex.EncodeToString(sum[:]), meta.Hash)
+	assert.Equal(t, int64(5), meta.Size)
+	assert.True(t, modTime.Equal(meta.ModifiedTime))
+	assert.False(t, meta.IsFolder)
+
+	file, err := provider.Download(ctx, "/Docs/notes file.txt")
+	require.NoError(t, err)
+	content, err := io.ReadAll(file.Content)
+	require.NoError(t, err)
+	file.Content.(io.Closer).Close()
+	assert.Equal(t, "hello", string(content))
+
+	folder, err := provider.GetMetadata(ctx, "/Docs")
+	require.NoError(t, err)
+	assert.True(t, folder.IsFolder)
+
+	require.NoError(t, provider.Move(ctx, "/Docs", "/Archive/Docs"))
+	_, err = provider.GetMetadata(ctx, "/Docs/notes file.txt")
+	assert.True(t, pperrors.IsNotFoundError(err))
+	_, err = provider.GetMetadata(ctx, "/Archive/Docs/notes file.txt")
+	require.NoError(t, err)
+
+	require.NoError(t, provider.Delete(ctx, "/Archive"))
+	assert.Empty(t, fake.objects)
+	assert.NoError(t, provider.Delete(ctx, "/Archive"), "deleting a missing path succeeds")
+}
+
+func TestS3ProviderMultipartUpload(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeS3(t, "backups")
+	provider := newTestProvider(t, fake, "")
+
+	data := bytes.Repeat([]byte("0123456789"), minPartSize/5+1)
+	require.NoError(t, provider.Upload(ctx, &interfaces.File{
+		Path:    "/big.bin",
+		Size:    int64(len(data)),
+		Content: bytes.NewReader(data),
+	}))
+
+	assert.Equal(t, 3, fake.parts)
+	assert.Empty(t, fake.uploads)
+	assert.Equal(t, data, fake.objects["big.bin"].data)
+}
+
+func TestS3ProviderListsFoldersAcrossPages(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeS3(t, "backups")
+	fake.pageMax = 2
+	provider := newTestProvider(t, fake, "")
+
+	require.NoError(t, provider.CreateFolder(ctx, "/Photos"))
+	require.NoError(t, provider.CreateFolder(ctx, "/Photos/2024"))
+	for _, name := range []string{"a.jpg", "b.jpg", "c.jpg", "2024/d.jpg"} {
+		require.NoError(t, provider.Upload(ctx, &interfaces.File{
+			Path:    "/Photos/" + name,
+			Content: strings.NewReader(name),
+		}))
+	}
+
+	files, err := provider.List(ctx, "/Photos")
+	require.NoError(t, err)
+
+	found := make(map[string]bool)
+	for _, file := range files {
+		found[file.Path] = file.IsFolder
+	}
+	assert.Equal(t, map[string]bool{
+		"/Photos/a.jpg": false,
+		"/Photos/b.jpg": false,
+		"/Photos/c.jpg": false,
+		"/Photos/2024":  true,
+	}, found)
+
+	quota, err := provider.GetQuota(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(25), quota.Used)
+}
+
+func TestS3ProviderConformance(t *testing.T) {
+	providertest.Run(t, func(t *testing.T) interfaces.CloudProvider {
+		return newTestProvider(t, newFakeS3(t, "backups"), "pulsepoint")
+	})
+}
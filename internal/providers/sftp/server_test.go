@@ -0,0 +1,250 @@
+package sftp
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// testServer is an SSH server on localhost whose sftp subsystem serves an
+// in-memory file system
+type testServer struct {
+	addr     string
+	hostKey  ssh.PublicKey
+	handlers sftp.Handlers
+}
+
+// newTestServer starts a server that accepts the given client key
+func newTestServer(t *testing.T, clientKey ssh.PublicKey) *testServer {
+	_, hostPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	require.NoError(t, err)
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if conn.User() == "alice" && string(key.Marshal()) == string(clientKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, errors.New("unknown key")
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	server := &testServer{
+		addr:     listener.Addr().String(),
+		hostKey:  hostSigner.PublicKey(),
+		handlers: newMemHandlers(),
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serveConn(conn, config)
+		}
+	}()
+	return server
+}
+
+func (s *testServer) serveConn(conn net.Conn, config *ssh.ServerConfig) {
+	defer conn.Close()
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "session only")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range requests {
+				ok := req.Type == "subsystem" && len(req.Payload) > 4 && string(req.Payload[4:]) == "sftp"
+				req.Reply(ok, nil)
+				if ok {
+					go func() {
+						defer channel.Close()
+						server := sftp.NewRequestServer(channel, s.handlers)
+						defer server.Close()
+						server.Serve()
+					}()
+				}
+			}
+		}()
+	}
+}
+
+// newMemHandlers wraps the in-memory handlers of the sftp package with the
+// parts they leave out: modification times, posix-rename and statvfs
+func newMemHandlers() sftp.Handlers {
+	mem := sftp.InMemHandler()
+	times := &modTimes{times: make(map[string]time.Time)}
+	return sftp.Handlers{
+		FileGet:  mem.FileGet,
+		FilePut:  mem.FilePut,
+		FileCmd:  &memCmder{FileCmder: mem.FileCmd, times: times},
+		FileList: &memLister{FileLister: mem.FileList, times: times},
+	}
+}
+
+// modTimes holds the modification times set by clients
+type modTimes struct {
+	mu    sync.Mutex
+	times map[string]time.Time
+}
+
+func (m *modTimes) get(name string) (time.Time, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.times[name]
+	return t, ok
+}
+
+func (m *modTimes) set(name string, t time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.times[name] = t
+}
+
+// move moves the times of a path and everything below it
+func (m *modTimes) move(oldPath, newPath string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for name, t := range m.times {
+		if name == oldPath || strings.HasPrefix(name, oldPath+"/") {
+			delete(m.times, name)
+			m.times[newPath+strings.TrimPrefix(name, oldPath)] = t
+		}
+	}
+}
+
+func (m *modTimes) remove(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.times, name)
+}
+
+// memCmder records modification times and adds posix-rename and statvfs
+type memCmder struct {
+	sftp.FileCmder
+	times *modTimes
+}
+
+func (c *memCmder) Filecmd(r *sftp.Request) error {
+	if err := c.FileCmder.Filecmd(r); err != nil {
+		return err
+	}
+
+	switch r.Method {
+	case "Setstat":
+		if r.AttrFlags().Acmodtime {
+			c.times.set(r.Filepath, time.Unix(int64(r.Attributes().Mtime), 0))
+		}
+	case "Rename":
+		c.times.move(r.Filepath, r.Target)
+	case "Remove", "Rmdir":
+		c.times.remove(r.Filepath)
+	}
+	return nil
+}
+
+func (c *memCmder) PosixRename(r *sftp.Request) error {
+	if err := c.FileCmder.(sftp.PosixRenameFileCmder).PosixRename(r); err != nil {
+		return err
+	}
+	c.times.move(r.Filepath, r.Target)
+	return nil
+}
+
+// StatVFS reports 4KB blocks: 1000 in total, 400 free, 300 available to users
+func (c *memCmder) StatVFS(r *sftp.Request) (*sftp.StatVFS, error) {
+	return &sftp.StatVFS{Bsize: 4096, Frsize: 4096, Blocks: 1000, Bfree: 400, Bavail: 300, Namemax: 255}, nil
+}
+
+// memLister reports recorded modification times
+type memLister struct {
+	sftp.FileLister
+	times *modTimes
+}
+
+func (l *memLister) Filelist(r *sftp.Request) (sftp.ListerAt, error) {
+	lister, err := l.FileLister.Filelist(r)
+	if err != nil {
+		return nil, err
+	}
+	if r.Method == "List" {
+		return &timedLister{ListerAt: lister, dir: r.Filepath, times: l.times}, nil
+	}
+	return &timedLister{ListerAt: lister, path: r.Filepath, times: l.times}, nil
+}
+
+func (l *memLister) Lstat(r *sftp.Request) (sftp.ListerAt, error) {
+	lister, err := l.FileLister.(sftp.LstatFileLister).Lstat(r)
+	if err != nil {
+		return nil, err
+	}
+	return &timedLister{ListerAt: lister, path: r.Filepath, times: l.times}, nil
+}
+
+// timedLister replaces the modification times of listed entries. Entries of
+// a directory listing are named relative to dir; a stat names the path itself.
+type timedLister struct {
+	sftp.ListerAt
+	dir   string
+	path  string
+	times *modTimes
+}
+
+func (l *timedLister) ListAt(infos []os.FileInfo, offset int64) (int, error) {
+	n, err := l.ListerAt.ListAt(infos, offset)
+	for i := range infos[:n] {
+		name := l.path
+		if l.dir != "" {
+			name = strings.TrimSuffix(l.dir, "/") + "/" + infos[i].Name()
+		}
+		if t, ok := l.times.get(name); ok {
+			infos[i] = timedInfo{FileInfo: infos[i], modTime: t}
+		}
+	}
+	return n, err
+}
+
+// timedInfo is a FileInfo with a replaced modification time
+type timedInfo struct {
+	os.FileInfo
+	modTime time.Time
+}
+
+func (i timedInfo) ModTime() time.Time { return i.modTime }
+
+// withoutPosixRename stops the server advertising posix-rename for the rest
+// of the test
+func withoutPosixRename(t *testing.T) {
+	require.NoError(t, sftp.SetSFTPExtensions("hardlink@openssh.com", extStatVFS))
+	t.Cleanup(func() {
+		sftp.SetSFTPExtensions("hardlink@openssh.com", extPosixRename, extStatVFS)
+	})
+}
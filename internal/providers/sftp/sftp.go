@@ -0,0 +1,741 @@
+// Package sftp implements an SFTP provider for PulsePoint, for any server reachable over SSH
+package sftp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/sftp"
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	pperrors "github.com/pulsepoint/pulsepoint/pkg/errors"
+	pplogger "github.com/pulsepoint/pulsepoint/pkg/logger"
+	"github.com/pulsepoint/pulsepoint/pkg/utils"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	// Provider name
+	providerName = "sftp"
+
+	// MIME type reported for folders
+	mimeTypeFolder = "inode/directory"
+
+	// tempSuffix marks in-progress uploads, which List hides
+	tempSuffix = ".pulsepoint-tmp"
+
+	// Default settings
+	defaultPort    = 22
+	defaultTimeout = 30 * time.Second
+)
+
+// OpenSSH extensions
+const (
+	extPosixRename = "posix-rename@openssh.com"
+	extStatVFS     = "statvfs@openssh.com"
+)
+
+// PulsePointSFTPProvider implements CloudProvider for SFTP servers
+type PulsePointSFTPProvider struct {
+	config    *Config
+	signers   []ssh.Signer
+	hostKeys  ssh.HostKeyCallback
+	logger    *zap.Logger
+	mu        sync.Mutex
+	conn      *ssh.Client
+	client    *sftp.Client
+	closed    chan struct{} // Closed once the client's connection ends
+	connected bool
+}
+
+// Config holds SFTP configuration
+type Config struct {
+	Host                  string              `json:"host"`
+	Port                  int                 `json:"port"`
+	User                  string              `json:"user"`
+	Root                  string              `json:"root"`        // Remote root; relative paths start in the login directory
+	PrivateKeyPath        string              `json:"private_key"` // Empty to use only the SSH agent
+	Passphrase            string              `json:"-"`           // For an encrypted private key
+	UseAgent              bool                `json:"use_agent"`   // Authenticate with the agent at SSH_AUTH_SOCK
+	KnownHostsPath        string              `json:"known_hosts"` // Defaults to ~/.ssh/known_hosts
+	InsecureIgnoreHostKey bool                `json:"insecure_ignore_host_key"`
+	Timeout               time.Duration       `json:"timeout"`
+	HostKeyCallback       ssh.HostKeyCallback `json:"-"` // Overrides known_hosts checking
+}
+
+// NewPulsePointSFTPProvider creates a new SFTP provider. The connection is
+// opened on first use and reopened if it drops.
+func NewPulsePointSFTPProvider(config *Config) (*PulsePointSFTPProvider, error) {
+	if config == nil {
+		return nil, pperrors.NewConfigError("SFTP configuration is required", nil)
+	}
+	if config.Host == "" || config.User == "" {
+		return nil, pperrors.NewConfigError("SFTP host and user are required", nil)
+	}
+	if config.Port == 0 {
+		config.Port = defaultPort
+	}
+	if config.Timeout == 0 {
+		config.Timeout = defaultTimeout
+	}
+
+	var signers []ssh.Signer
+	if config.PrivateKeyPath != "" {
+		signer, err := loadPrivateKey(utils.CleanPath(config.PrivateKeyPath), config.Passphrase)
+		if err != nil {
+			return nil, err
+		}
+		signers = append(signers, signer)
+	}
+	if len(signers) == 0 && !config.UseAgent && os.Getenv("SSH_AUTH_SOCK") != "" {
+		// Fall back to the agent when no key is configured
+		config.UseAgent = true
+	}
+	if len(signers) == 0 && !config.UseAgent {
+		return nil, pperrors.NewConfigError("SFTP needs a private key or an SSH agent", nil)
+	}
+	if config.UseAgent && os.Getenv("SSH_AUTH_SOCK") == "" {
+		return nil, pperrors.NewConfigError("SFTP agent authentication requires SSH_AUTH_SOCK", nil)
+	}
+
+	hostKeys, err := hostKeyCallback(config)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := &PulsePointSFTPProvider{
+		config:    config,
+		signers:   signers,
+		hostKeys:  hostKeys,
+		logger:    pplogger.Get(),
+		connected: true,
+	}
+
+	provider.logger.Info("SFTP provider initialized",
+		zap.String("host", config.Host),
+		zap.Int("port", config.Port),
+		zap.String("root", config.Root))
+
+	return provider, nil
+}
+
+// loadPrivateKey reads and parses a private key file
+func loadPrivateKey(keyPath, passphrase string) (ssh.Signer, error) {
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, pperrors.NewConfigError(fmt.Sprintf("failed to read SSH private key: %s", keyPath), err)
+	}
+
+	var signer ssh.Signer
+	if passphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(data, []byte(passphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey(data)
+	}
+	if err != nil {
+		return nil, pperrors.NewConfigError(fmt.Sprintf("failed to parse SSH private key: %s", keyPath), err)
+	}
+	return signer, nil
+}
+
+// hostKeyCallback verifies the server against known_hosts unless the config
+// supplies a callback or disables checking
+func hostKeyCallback(config *Config) (ssh.HostKeyCallback, error) {
+	if config.HostKeyCallback != nil {
+		return config.HostKeyCallback, nil
+	}
+	if config.InsecureIgnoreHostKey {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+
+	knownHostsPath := config.KnownHostsPath
+	if knownHostsPath == "" {
+		knownHostsPath = "~/.ssh/known_hosts"
+	}
+	callback, err := knownhosts.New(utils.CleanPath(knownHostsPath))
+	if err != nil {
+		return nil, pperrors.NewConfigError(fmt.Sprintf("failed to load known hosts: %s", knownHostsPath), err)
+	}
+	return callback, nil
+}
+
+// Initialize initializes the provider with configuration
+func (p *PulsePointSFTPProvider) Initialize(config interfaces.ProviderConfig) error {
+	// Already initialized in constructor
+	return nil
+}
+
+// Upload streams a file to a temporary name beside its target and renames it
+// into place, so readers never see a partial file
+func (p *PulsePointSFTPProvider) Upload(ctx context.Context, file *interfaces.File) error {
+	if file == nil {
+		return pperrors.NewValidationError("file is nil", nil)
+	}
+
+	p.logger.Debug("Uploading file to SFTP",
+		zap.String("path", file.Path),
+		zap.Int64("size", file.Size))
+
+	c, err := p.session(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := p.CreateFolder(ctx, path.Dir(cleanPath(file.Path))); err != nil {
+		return fmt.Errorf("failed to ensure parent folder: %w", err)
+	}
+
+	// Prefer in-memory content, otherwise read from the local file
+	reader := file.Content
+	if reader == nil {
+		f, err := os.Open(file.LocalPath)
+		if err != nil {
+			return fmt.Errorf("failed to open local file: %w", err)
+		}
+		defer f.Close()
+		reader = f
+	}
+
+	target := p.fullPath(file.Path)
+	temp, err := tempName(target)
+	if err != nil {
+		return pperrors.NewProviderError("failed to name temporary file", err)
+	}
+
+	f, err := c.OpenFile(temp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC|os.O_EXCL)
+	if err != nil {
+		return p.wrapError("upload", file.Path, err)
+	}
+
+	written, err := f.ReadFrom(&contextReader{ctx: ctx, r: reader})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && !file.ModifiedTime.IsZero() {
+		err = c.Chtimes(temp, file.ModifiedTime, file.ModifiedTime)
+	}
+	if err == nil {
+		err = p.replace(c, temp, target)
+	}
+	if err != nil {
+		c.Remove(temp)
+		return p.wrapError("upload", file.Path, err)
+	}
+
+	p.logger.Info("File uploaded successfully",
+		zap.String("path", file.Path),
+		zap.Int64("size", written))
+	return nil
+}
+
+// Download streams a file from the server. The caller must close the returned content.
+func (p *PulsePointSFTPProvider) Download(ctx context.Context, remotePath string) (*interfaces.File, error) {
+	p.logger.Debug("Downloading file from SFTP", zap.String("path", remotePath))
+
+	c, err := p.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := c.Open(p.fullPath(remotePath))
+	if err != nil {
+		return nil, p.wrapError("download", remotePath, err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, p.wrapError("download", remotePath, err)
+	}
+
+	return &interfaces.File{
+		ID:           cleanPath(remotePath),
+		Path:         cleanPath(remotePath),
+		Name:         path.Base(cleanPath(remotePath)),
+		Size:         info.Size(),
+		ModifiedTime: info.ModTime(),
+		Content:      &remoteFile{ctx: ctx, file: f},
+	}, nil
+}
+
+// Delete deletes a file, or a folder and everything in it. Deleting a
+// missing path succeeds.
+func (p *PulsePointSFTPProvider) Delete(ctx context.Context, remotePath string) error {
+	p.logger.Debug("Deleting file from SFTP", zap.String("path", remotePath))
+
+	c, err := p.session(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := p.removeAll(c, p.fullPath(remotePath)); err != nil {
+		err = p.wrapError("delete", remotePath, err)
+		if pperrors.IsNotFoundError(err) {
+			p.logger.Debug("File already deleted", zap.String("path", remotePath))
+			return nil
+		}
+		return err
+	}
+
+	p.logger.Info("File deleted successfully", zap.String("path", remotePath))
+	return nil
+}
+
+// removeAll removes a path and, for a directory, its contents first
+func (p *PulsePointSFTPProvider) removeAll(c *sftp.Client, target string) error {
+	info, err := c.Lstat(target)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return c.Remove(target)
+	}
+
+	entries, err := c.ReadDir(target)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := p.removeAll(c, path.Join(target, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return c.RemoveDirectory(target)
+}
+
+// List lists the files and folders directly inside a folder. Symlinks,
+// devices and in-progress uploads are skipped.
+func (p *PulsePointSFTPProvider) List(ctx context.Context, folder string) ([]*interfaces.File, error) {
+	p.logger.Debug("Listing files in folder", zap.String("folder", folder))
+
+	c, err := p.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := c.ReadDir(p.fullPath(folder))
+	if err != nil {
+		return nil, p.wrapError("list", folder, err)
+	}
+
+	var files []*interfaces.File
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), tempSuffix) || (!entry.IsDir() && !entry.Mode().IsRegular()) {
+			continue
+		}
+		meta := metadata(path.Join(cleanPath(folder), entry.Name()), entry)
+		files = append(files, &interfaces.File{
+			ID:           meta.ID,
+			Path:         meta.Path,
+			Name:         entry.Name(),
+			Size:         meta.Size,
+			MimeType:     meta.MimeType,
+			ModifiedTime: meta.ModifiedTime,
+			IsFolder:     meta.IsFolder,
+			Permissions:  meta.Attributes["mode"].(string),
+		})
+	}
+
+	return files, nil
+}
+
+// GetMetadata gets file metadata. SFTP has no checksums, so Hash is empty.
+func (p *PulsePointSFTPProvider) GetMetadata(ctx context.Context, remotePath string) (*interfaces.Metadata, error) {
+	p.logger.Debug("Getting file metadata", zap.String("path", remotePath))
+
+	c, err := p.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := c.Stat(p.fullPath(remotePath))
+	if err != nil {
+		return nil, p.wrapError("stat", remotePath, err)
+	}
+	return metadata(cleanPath(remotePath), info), nil
+}
+
+// CreateFolder creates a folder and any missing parents, including the root
+func (p *PulsePointSFTPProvider) CreateFolder(ctx context.Context, remotePath string) error {
+	c, err := p.session(ctx)
+	if err != nil {
+		return err
+	}
+
+	target := p.fullPath(remotePath)
+	if info, err := c.Stat(target); err == nil && info.IsDir() {
+		return nil
+	}
+
+	current := ""
+	if strings.HasPrefix(target, "/") {
+		current = "/"
+	}
+	for _, name := range strings.Split(strings.Trim(target, "/"), "/") {
+		if name == "." || name == "" {
+			continue
+		}
+		current = path.Join(current, name)
+		if err := p.mkdir(c, current); err != nil {
+			return p.wrapError("create folder", remotePath, err)
+		}
+	}
+
+	p.logger.Debug("Folder ensured", zap.String("path", remotePath))
+	return nil
+}
+
+// mkdir creates one directory unless a directory already exists there
+func (p *PulsePointSFTPProvider) mkdir(c *sftp.Client, dir string) error {
+	info, err := c.Stat(dir)
+	if err == nil {
+		if info.IsDir() {
+			return nil
+		}
+		return fmt.Errorf("%s exists and is not a folder", dir)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if err := c.Mkdir(dir); err != nil {
+		// Another client may have created it meanwhile
+		if info, statErr := c.Stat(dir); statErr == nil && info.IsDir() {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// Move moves a file or folder, replacing a file at the destination
+func (p *PulsePointSFTPProvider) Move(ctx context.Context, sourcePath, destPath string) error {
+	p.logger.Debug("Moving file",
+		zap.String("source", sourcePath),
+		zap.String("destination", destPath))
+
+	c, err := p.session(ctx)
+	if err != nil {
+		return err
+	}
+
+	source := p.fullPath(sourcePath)
+	if _, err := c.Lstat(source); err != nil {
+		return p.wrapError("move", sourcePath, err)
+	}
+	if err := p.CreateFolder(ctx, path.Dir(cleanPath(destPath))); err != nil {
+		return fmt.Errorf("failed to ensure parent folder: %w", err)
+	}
+
+	if err := p.replace(c, source, p.fullPath(destPath)); err != nil {
+		return p.wrapError("move", sourcePath, err)
+	}
+
+	p.logger.Info("File moved successfully",
+		zap.String("source", sourcePath),
+		zap.String("destination", destPath))
+
+	return nil
+}
+
+// replace renames source over target. Servers without posix-rename cannot
+// overwrite, so an existing target file is removed first.
+func (p *PulsePointSFTPProvider) replace(c *sftp.Client, source, target string) error {
+	if _, ok := c.HasExtension(extPosixRename); ok {
+		return c.PosixRename(source, target)
+	}
+
+	if info, err := c.Lstat(target); err == nil && !info.IsDir() {
+		if err := c.Remove(target); err != nil {
+			return err
+		}
+	}
+	return c.Rename(source, target)
+}
+
+// GetQuota reports the file system holding the root with statvfs. Servers
+// without the statvfs extension report an empty quota.
+func (p *PulsePointSFTPProvider) GetQuota(ctx context.Context) (*interfaces.QuotaInfo, error) {
+	c, err := p.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	quota := &interfaces.QuotaInfo{}
+	if _, ok := c.HasExtension(extStatVFS); !ok {
+		return quota, nil
+	}
+
+	stats, err := c.StatVFS(p.fullPath("/"))
+	if err != nil {
+		return nil, p.wrapError("statvfs", "/", err)
+	}
+
+	blockSize := stats.Frsize
+	if blockSize == 0 {
+		blockSize = stats.Bsize
+	}
+	quota.Total = int64(stats.Blocks * blockSize)
+	quota.Available = int64(stats.Bavail * blockSize)
+	quota.Used = int64((stats.Blocks - stats.Bfree) * blockSize)
+	return quota, nil
+}
+
+// GetProviderName returns the provider name
+func (p *PulsePointSFTPProvider) GetProviderName() string {
+	return providerName
+}
+
+// IsConnected checks if the provider is connected
+func (p *PulsePointSFTPProvider) IsConnected() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.connected
+}
+
+// Disconnect closes the connection to the provider
+func (p *PulsePointSFTPProvider) Disconnect() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closeLocked()
+	p.connected = false
+	p.logger.Info("Disconnected from SFTP")
+	return nil
+}
+
+// session returns a live SFTP session, dialing a new one when there is none
+func (p *PulsePointSFTPProvider) session(ctx context.Context) (*sftp.Client, error) {
+	// Requests do not take a context, so a done one stops them before they start
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.connected {
+		return nil, pperrors.NewProviderError("SFTP provider is disconnected", nil)
+	}
+	if p.client != nil && p.aliveLocked() {
+		return p.client, nil
+	}
+	p.closeLocked()
+
+	addr := net.JoinHostPort(p.config.Host, strconv.Itoa(p.config.Port))
+	conn, err := p.dial(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Concurrent writes keep several requests in flight during uploads
+	c, err := sftp.NewClient(conn, sftp.UseConcurrentWrites(true))
+	if err != nil {
+		conn.Close()
+		return nil, pperrors.NewProviderError("failed to start SFTP session", err)
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		c.Wait()
+		close(closed)
+	}()
+
+	p.conn = conn
+	p.client = c
+	p.closed = closed
+	p.logger.Debug("SFTP session opened", zap.String("addr", addr))
+	return c, nil
+}
+
+// dial connects and authenticates to the server
+func (p *PulsePointSFTPProvider) dial(ctx context.Context, addr string) (*ssh.Client, error) {
+	auth := []ssh.AuthMethod{}
+	if len(p.signers) > 0 {
+		auth = append(auth, ssh.PublicKeys(p.signers...))
+	}
+	if p.config.UseAgent {
+		agentConn, err := net.Dial("unix", os.Getenv("SSH_AUTH_SOCK"))
+		if err != nil {
+			return nil, pperrors.NewAuthError("failed to connect to SSH agent", err)
+		}
+		// The agent is only needed while authenticating
+		defer agentConn.Close()
+		auth = append(auth, ssh.PublicKeysCallback(agent.NewClient(agentConn).Signers))
+	}
+
+	dialer := net.Dialer{Timeout: p.config.Timeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, pperrors.NewRetryable(pperrors.NetworkError, fmt.Sprintf("failed to connect to %s", addr), err)
+	}
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(netConn, addr, &ssh.ClientConfig{
+		User:            p.config.User,
+		Auth:            auth,
+		HostKeyCallback: p.hostKeys,
+		Timeout:         p.config.Timeout,
+	})
+	if err != nil {
+		netConn.Close()
+		var keyErr *knownhosts.KeyError
+		if errors.As(err, &keyErr) || strings.Contains(err.Error(), "unable to authenticate") {
+			return nil, pperrors.NewAuthError(fmt.Sprintf("SSH authentication with %s failed", addr), err)
+		}
+		return nil, pperrors.NewRetryable(pperrors.NetworkError, fmt.Sprintf("SSH handshake with %s failed", addr), err)
+	}
+	return ssh.NewClient(sshConn, chans, reqs), nil
+}
+
+// aliveLocked reports whether the current session is still connected. The
+// caller holds p.mu.
+func (p *PulsePointSFTPProvider) aliveLocked() bool {
+	select {
+	case <-p.closed:
+		return false
+	default:
+		return true
+	}
+}
+
+// closeLocked closes the current connection. The caller holds p.mu.
+func (p *PulsePointSFTPProvider) closeLocked() {
+	if p.client != nil {
+		p.client.Close()
+		p.client = nil
+	}
+	if p.conn != nil {
+		p.conn.Close()
+		p.conn = nil
+	}
+}
+
+// fullPath maps a remote path to a server path under the root
+func (p *PulsePointSFTPProvider) fullPath(remotePath string) string {
+	root := p.config.Root
+	if root == "" {
+		root = "."
+	}
+	return path.Join(root, cleanPath(remotePath))
+}
+
+// wrapError turns a protocol error into a provider error
+func (p *PulsePointSFTPProvider) wrapError(op, remotePath string, err error) error {
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return notFoundError(remotePath)
+	case errors.Is(err, os.ErrPermission):
+		pe := pperrors.NewProviderError(fmt.Sprintf("%s %s: permission denied", op, remotePath), err)
+		pe.StatusCode = 403
+		return pe
+	case errors.Is(err, sftp.ErrSSHFxConnectionLost), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return pperrors.NewRetryable(pperrors.NetworkError, fmt.Sprintf("%s %s: connection lost", op, remotePath), err)
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	default:
+		return pperrors.NewProviderError(fmt.Sprintf("%s %s failed", op, remotePath), err)
+	}
+}
+
+// remoteFile streams an open remote file until its context is done
+type remoteFile struct {
+	ctx    context.Context
+	file   *sftp.File
+	closed bool
+}
+
+// Read reads the next chunk of the file
+func (f *remoteFile) Read(b []byte) (int, error) {
+	if err := f.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return f.file.Read(b)
+}
+
+// Close closes the remote handle
+func (f *remoteFile) Close() error {
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	return f.file.Close()
+}
+
+var _ io.ReadCloser = (*remoteFile)(nil)
+
+// contextReader stops an upload once its context is done
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+// Read reads from the underlying reader unless the context is done
+func (r *contextReader) Read(b []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(b)
+}
+
+// tempName returns a hidden, unique name beside target for an upload in progress
+func tempName(target string) (string, error) {
+	suffix := make([]byte, 6)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	dir, base := path.Split(target)
+	return dir + "." + base + "." + hex.EncodeToString(suffix) + tempSuffix, nil
+}
+
+// metadata converts file attributes to provider metadata
+func metadata(remotePath string, info os.FileInfo) *interfaces.Metadata {
+	mode := uint32(info.Mode().Perm())
+	stat, hasStat := info.Sys().(*sftp.FileStat)
+	if hasStat {
+		mode = stat.Mode & 07777
+	}
+
+	meta := &interfaces.Metadata{
+		ID:           remotePath,
+		Path:         remotePath,
+		Size:         info.Size(),
+		ModifiedTime: info.ModTime(),
+		IsFolder:     info.IsDir(),
+		Attributes: map[string]interface{}{
+			"mode": fmt.Sprintf("%04o", mode),
+		},
+	}
+	if meta.IsFolder {
+		meta.Size = 0
+		meta.MimeType = mimeTypeFolder
+	}
+	if hasStat {
+		meta.Owner = strconv.FormatUint(uint64(stat.UID), 10)
+	}
+	return meta
+}
+
+// cleanPath normalises a remote path to a clean absolute path
+func cleanPath(remotePath string) string {
+	return path.Clean("/" + remotePath)
+}
+
+// notFoundError returns a provider error that pperrors.IsNotFoundError recognises
+func notFoundError(remotePath string) error {
+	err := pperrors.NewProviderError(fmt.Sprintf("file not found: %s", remotePath), nil)
+	err.StatusCode = 404
+	return err
+}
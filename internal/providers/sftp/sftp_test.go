@@ -0,0 +1,207 @@
+package sftp
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"github.com/pulsepoint/pulsepoint/internal/providers/providertest"
+	pperrors "github.com/pulsepoint/pulsepoint/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// testKey generates a client key and writes it to a file
+func testKey(t *testing.T) (ed25519.PrivateKey, ssh.PublicKey, string) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	require.NoError(t, err)
+
+	keyPath := filepath.Join(t.TempDir(), "id_ed25519")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(block), 0o600))
+
+	signer, err := ssh.NewSignerFromKey(priv)
+	require.NoError(t, err)
+	return priv, signer.PublicKey(), keyPath
+}
+
+// knownHostsFile writes a known_hosts file trusting key for addr
+func knownHostsFile(t *testing.T, addr string, key ssh.PublicKey) string {
+	knownHostsPath := filepath.Join(t.TempDir(), "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(addr)}, key)
+	require.NoError(t, os.WriteFile(knownHostsPath, []byte(line+"\n"), 0o600))
+	return knownHostsPath
+}
+
+func newTestProvider(t *testing.T, server *testServer, config *Config) *PulsePointSFTPProvider {
+	host, port, err := net.SplitHostPort(server.addr)
+	require.NoError(t, err)
+	config.Host = host
+	config.Port, err = strconv.Atoi(port)
+	require.NoError(t, err)
+	config.User = "alice"
+	config.Root = "sync"
+	if config.KnownHostsPath == "" {
+		config.KnownHostsPath = knownHostsFile(t, server.addr, server.hostKey)
+	}
+
+	provider, err := NewPulsePointSFTPProvider(config)
+	require.NoError(t, err)
+	t.Cleanup(func() { provider.Disconnect() })
+	return provider
+}
+
+func TestSFTPProviderRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	_, publicKey, keyPath := testKey(t)
+	server := newTestServer(t, publicKey)
+	provider := newTestProvider(t, server, &Config{PrivateKeyPath: keyPath})
+
+	// Large enough to keep several writes in flight
+	large := bytes.Repeat([]byte("0123456789abcdef"), 40*1024)
+	modTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, provider.Upload(ctx, &interfaces.File{
+		Path:         "/Docs/Reports/video.bin",
+		Content:      bytes.NewReader(large),
+		ModifiedTime: modTime,
+	}))
+	require.NoError(t, provider.Upload(ctx, &interfaces.File{
+		Path:    "/Docs/todo.md",
+		Content: strings.NewReader("- ship it"),
+	}))
+
+	files, err := provider.List(ctx, "/Docs")
+	require.NoError(t, err)
+	found := make(map[string]bool)
+	for _, file := range files {
+		found[file.Path] = file.IsFolder
+	}
+	assert.Equal(t, map[string]bool{"/Docs/Reports": true, "/Docs/todo.md": false}, found)
+
+	meta, err := provider.GetMetadata(ctx, "/Docs/Reports/video.bin")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(large)), meta.Size)
+	assert.True(t, modTime.Equal(meta.ModifiedTime))
+	assert.False(t, meta.IsFolder)
+
+	file, err := provider.Download(ctx, "/Docs/Reports/video.bin")
+	require.NoError(t, err)
+	content, err := io.ReadAll(file.Content)
+	require.NoError(t, err)
+	require.NoError(t, file.Content.(io.Closer).Close())
+	assert.Equal(t, large, content)
+
+	require.NoError(t, provider.Move(ctx, "/Docs/Reports", "/Archive/2024/Reports"))
+	_, err = provider.GetMetadata(ctx, "/Docs/Reports/video.bin")
+	assert.True(t, pperrors.IsNotFoundError(err))
+	_, err = provider.GetMetadata(ctx, "/Archive/2024/Reports/video.bin")
+	require.NoError(t, err)
+
+	require.NoError(t, provider.Delete(ctx, "/Archive"))
+	assert.NoError(t, provider.Delete(ctx, "/Archive"), "deleting a missing path succeeds")
+	_, err = provider.GetMetadata(ctx, "/Archive")
+	assert.True(t, pperrors.IsNotFoundError(err))
+
+	quota, err := provider.GetQuota(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &interfaces.QuotaInfo{Used: 600 * 4096, Available: 300 * 4096, Total: 1000 * 4096}, quota)
+}
+
+func TestSFTPProviderReplacesWithoutPosixRename(t *testing.T) {
+	ctx := context.Background()
+	_, publicKey, keyPath := testKey(t)
+	withoutPosixRename(t)
+	server := newTestServer(t, publicKey)
+	provider := newTestProvider(t, server, &Config{PrivateKeyPath: keyPath})
+
+	for _, content := range []string{"first", "second"} {
+		require.NoError(t, provider.Upload(ctx, &interfaces.File{
+			Path:    "/notes.txt",
+			Content: strings.NewReader(content),
+		}))
+	}
+
+	file, err := provider.Download(ctx, "/notes.txt")
+	require.NoError(t, err)
+	data, err := io.ReadAll(file.Content)
+	require.NoError(t, err)
+	require.NoError(t, file.Content.(io.Closer).Close())
+	assert.Equal(t, "second", string(data))
+
+	// No temporary files are left behind
+	lister, err := server.handlers.FileList.Filelist(sftp.NewRequest("List", "/sync"))
+	require.NoError(t, err)
+	entries := make([]os.FileInfo, 10)
+	n, _ := lister.ListAt(entries, 0)
+	assert.Equal(t, 1, n)
+}
+
+func TestSFTPProviderAuthenticatesWithAgent(t *testing.T) {
+	priv, publicKey, _ := testKey(t)
+	server := newTestServer(t, publicKey)
+
+	keyring := agent.NewKeyring()
+	require.NoError(t, keyring.Add(agent.AddedKey{PrivateKey: priv}))
+
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go agent.ServeAgent(keyring, conn)
+		}
+	}()
+	t.Setenv("SSH_AUTH_SOCK", socket)
+
+	provider := newTestProvider(t, server, &Config{UseAgent: true})
+	require.NoError(t, provider.CreateFolder(context.Background(), "/empty"))
+
+	files, err := provider.List(context.Background(), "/")
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.True(t, files[0].IsFolder)
+}
+
+func TestSFTPProviderRejectsUnknownHostKey(t *testing.T) {
+	_, publicKey, keyPath := testKey(t)
+	server := newTestServer(t, publicKey)
+
+	// Trust a different key for the server's address
+	_, otherKey, _ := testKey(t)
+	provider := newTestProvider(t, server, &Config{
+		PrivateKeyPath: keyPath,
+		KnownHostsPath: knownHostsFile(t, server.addr, otherKey),
+	})
+
+	_, err := provider.List(context.Background(), "/")
+	require.Error(t, err)
+	assert.True(t, pperrors.IsAuthError(err))
+}
+
+func TestSFTPProviderConformance(t *testing.T) {
+	providertest.Run(t, func(t *testing.T) interfaces.CloudProvider {
+		_, publicKey, keyPath := testKey(t)
+		return newTestProvider(t, newTestServer(t, publicKey), &Config{PrivateKeyPath: keyPath})
+	})
+}
@@ -0,0 +1,635 @@
+// Package webdav implements a WebDAV provider for PulsePoint, for servers such as Nextcloud
+package webdav
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	pperrors "github.com/pulsepoint/pulsepoint/pkg/errors"
+	pplogger "github.com/pulsepoint/pulsepoint/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// Provider name
+	providerName = "webdav"
+
+	// MIME type reported for collections
+	mimeTypeFolder = "httpd/unix-directory"
+
+	// Properties requested for files and folders. The ownCloud namespace adds
+	// checksums and file IDs on Nextcloud and ownCloud servers.
+	propfindFiles = `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:" xmlns:oc="http://owncloud.org/ns">
+  <d:prop>
+    <d:resourcetype/>
+    <d:getcontentlength/>
+    <d:getlastmodified/>
+    <d:creationdate/>
+    <d:getcontenttype/>
+    <d:getetag/>
+    <oc:fileid/>
+    <oc:checksums/>
+  </d:prop>
+</d:propfind>`
+
+	// Properties requested for GetQuota (RFC 4331)
+	propfindQuota = `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:">
+  <d:prop>
+    <d:quota-used-bytes/>
+    <d:quota-available-bytes/>
+  </d:prop>
+</d:propfind>`
+)
+
+// PulsePointWebDAVProvider implements CloudProvider for WebDAV servers
+type PulsePointWebDAVProvider struct {
+	config  *Config
+	client  *http.Client
+	baseURL *url.URL
+	logger  *zap.Logger
+
+	// folders caches the folders known to exist, so uploads skip MKCOL
+	foldersMu sync.Mutex
+	folders   map[string]bool
+}
+
+// Config holds WebDAV configuration
+type Config struct {
+	URL        string       `json:"url"`  // e.g. https://cloud.example.com/remote.php/dav/files/alice/
+	Root       string       `json:"root"` // Folder under URL that acts as the remote root
+	Username   string       `json:"username"`
+	Password   string       `json:"password"` // An app password on Nextcloud
+	HTTPClient *http.Client `json:"-"`
+}
+
+// NewPulsePointWebDAVProvider creates a new WebDAV provider
+func NewPulsePointWebDAVProvider(config *Config) (*PulsePointWebDAVProvider, error) {
+	if config == nil {
+		return nil, pperrors.NewConfigError("WebDAV configuration is required", nil)
+	}
+
+	baseURL, err := url.Parse(config.URL)
+	if err != nil || baseURL.Scheme == "" || baseURL.Host == "" {
+		return nil, pperrors.NewConfigError(fmt.Sprintf("invalid WebDAV URL: %s", config.URL), err)
+	}
+	baseURL.Path = path.Join("/", baseURL.Path, config.Root)
+	baseURL.RawPath = ""
+
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{}
+	}
+
+	provider := &PulsePointWebDAVProvider{
+		config:  config,
+		client:  client,
+		baseURL: baseURL,
+		logger:  pplogger.Get(),
+		folders: make(map[string]bool),
+	}
+
+	provider.logger.Info("WebDAV provider initialized",
+		zap.String("url", baseURL.Redacted()))
+
+	return provider, nil
+}
+
+// Initialize initializes the provider with configuration
+func (p *PulsePointWebDAVProvider) Initialize(config interfaces.ProviderConfig) error {
+	// Already initialized in constructor
+	return nil
+}
+
+// Upload streams a file to the server with PUT, creating missing parent folders
+func (p *PulsePointWebDAVProvider) Upload(ctx context.Context, file *interfaces.File) error {
+	if file == nil {
+		return pperrors.NewValidationError("file is nil", nil)
+	}
+
+	p.logger.Debug("Uploading file to WebDAV",
+		zap.String("path", file.Path),
+		zap.Int64("size", file.Size))
+
+	if err := p.CreateFolder(ctx, path.Dir(cleanPath(file.Path))); err != nil {
+		return fmt.Errorf("failed to ensure parent folder: %w", err)
+	}
+
+	// Prefer in-memory content, otherwise read from the local file
+	reader := file.Content
+	size := int64(-1)
+	if reader == nil {
+		f, err := os.Open(file.LocalPath)
+		if err != nil {
+			return fmt.Errorf("failed to open local file: %w", err)
+		}
+		defer f.Close()
+		reader = f
+
+		if info, err := f.Stat(); err == nil {
+			size = info.Size()
+		}
+	} else if file.Size > 0 {
+		size = file.Size
+	}
+
+	header := http.Header{}
+	if file.MimeType != "" {
+		header.Set("Content-Type", file.MimeType)
+	}
+	if !file.ModifiedTime.IsZero() {
+		// Nextcloud and ownCloud keep this as the file's modification time
+		header.Set("X-OC-Mtime", strconv.FormatInt(file.ModifiedTime.Unix(), 10))
+	}
+
+	resp, err := p.request(ctx, http.MethodPut, file.Path, header, reader, size)
+	if err != nil {
+		return fmt.Errorf("upload failed: %w", err)
+	}
+	resp.Body.Close()
+
+	p.logger.Info("File uploaded successfully", zap.String("path", file.Path))
+	return nil
+}
+
+// Download streams a file from the server. The caller must close the returned content.
+func (p *PulsePointWebDAVProvider) Download(ctx context.Context, remotePath string) (*interfaces.File, error) {
+	p.logger.Debug("Downloading file from WebDAV", zap.String("path", remotePath))
+
+	resp, err := p.request(ctx, http.MethodGet, remotePath, nil, nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return &interfaces.File{
+		Path:         cleanPath(remotePath),
+		Name:         path.Base(cleanPath(remotePath)),
+		Size:         resp.ContentLength,
+		Hash:         strings.Trim(resp.Header.Get("ETag"), `"`),
+		ModifiedTime: modTime,
+		MimeType:     resp.Header.Get("Content-Type"),
+		Content:      resp.Body,
+	}, nil
+}
+
+// Delete deletes a file or folder. Deleting a missing path succeeds.
+func (p *PulsePointWebDAVProvider) Delete(ctx context.Context, remotePath string) error {
+	p.logger.Debug("Deleting file from WebDAV", zap.String("path", remotePath))
+
+	resp, err := p.request(ctx, http.MethodDelete, remotePath, nil, nil, 0)
+	if pperrors.IsNotFoundError(err) {
+		p.logger.Debug("File already deleted", zap.String("path", remotePath))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("delete failed: %w", err)
+	}
+	resp.Body.Close()
+	p.forgetFolders(remotePath)
+
+	p.logger.Info("File deleted successfully", zap.String("path", remotePath))
+	return nil
+}
+
+// List lists files and folders directly inside a folder
+func (p *PulsePointWebDAVProvider) List(ctx context.Context, folder string) ([]*interfaces.File, error) {
+	p.logger.Debug("Listing files in folder", zap.String("folder", folder))
+
+	entries, err := p.propfind(ctx, folder, "1", propfindFiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	self := cleanPath(folder)
+	var files []*interfaces.File
+	for _, entry := range entries {
+		if entry.path == self {
+			continue
+		}
+		meta := entry.metadata()
+		files = append(files, &interfaces.File{
+			ID:           meta.ID,
+			Path:         meta.Path,
+			Name:         path.Base(meta.Path),
+			Size:         meta.Size,
+			Hash:         meta.Hash,
+			MimeType:     meta.MimeType,
+			ModifiedTime: meta.ModifiedTime,
+			CreatedTime:  meta.CreatedTime,
+			IsFolder:     meta.IsFolder,
+		})
+	}
+
+	return files, nil
+}
+
+// GetMetadata gets file metadata with a depth 0 PROPFIND
+func (p *PulsePointWebDAVProvider) GetMetadata(ctx context.Context, remotePath string) (*interfaces.Metadata, error) {
+	p.logger.Debug("Getting file metadata", zap.String("path", remotePath))
+
+	entries, err := p.propfind(ctx, remotePath, "0", propfindFiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metadata: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, notFoundError(remotePath)
+	}
+	return entries[0].metadata(), nil
+}
+
+// CreateFolder creates a folder and any missing parents with MKCOL
+func (p *PulsePointWebDAVProvider) CreateFolder(ctx context.Context, remotePath string) error {
+	if err := p.createRoot(ctx); err != nil {
+		return err
+	}
+
+	target := cleanPath(remotePath)
+	if target == "/" {
+		return nil
+	}
+
+	// Walk down from the root, creating what is missing
+	current := ""
+	for _, name := range strings.Split(strings.TrimPrefix(target, "/"), "/") {
+		current += "/" + name
+		if err := p.mkcol(ctx, current, p.resourceURL(current)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createRoot creates the configured root folder under the URL if it is missing
+func (p *PulsePointWebDAVProvider) createRoot(ctx context.Context) error {
+	root := strings.TrimPrefix(cleanPath(p.config.Root), "/")
+	if root == "" {
+		return nil
+	}
+
+	u := *p.baseURL
+	u.Path = strings.TrimSuffix(u.Path, "/"+root)
+	current := ""
+	for _, name := range strings.Split(root, "/") {
+		current += "/" + name
+		u.Path += "/" + name
+		if err := p.mkcol(ctx, "root:"+current, &u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mkcol creates one folder unless it is known to exist. key names the
+// folder in the cache.
+func (p *PulsePointWebDAVProvider) mkcol(ctx context.Context, key string, target *url.URL) error {
+	if p.folderKnown(key) {
+		return nil
+	}
+
+	resp, err := p.send(ctx, "MKCOL", target, key, nil, nil, 0)
+	if err == nil {
+		resp.Body.Close()
+		p.logger.Debug("Folder created", zap.String("path", key))
+	} else if pe, ok := err.(*pperrors.PulseError); !ok || pe.StatusCode != http.StatusMethodNotAllowed {
+		// 405 means the folder already exists
+		return fmt.Errorf("failed to create folder: %w", err)
+	}
+
+	p.rememberFolder(key)
+	return nil
+}
+
+// folderKnown reports whether a folder is known to exist
+func (p *PulsePointWebDAVProvider) folderKnown(remotePath string) bool {
+	p.foldersMu.Lock()
+	defer p.foldersMu.Unlock()
+	return p.folders[remotePath]
+}
+
+// rememberFolder records that a folder exists
+func (p *PulsePointWebDAVProvider) rememberFolder(remotePath string) {
+	p.foldersMu.Lock()
+	defer p.foldersMu.Unlock()
+	p.folders[remotePath] = true
+}
+
+// forgetFolders drops a path and everything under it from the folder cache
+func (p *PulsePointWebDAVProvider) forgetFolders(remotePath string) {
+	target := cleanPath(remotePath)
+
+	p.foldersMu.Lock()
+	defer p.foldersMu.Unlock()
+	for folder := range p.folders {
+		if folder == target || strings.HasPrefix(folder, target+"/") {
+			delete(p.folders, folder)
+		}
+	}
+}
+
+// Move moves a file or folder with MOVE, replacing anything at the destination
+func (p *PulsePointWebDAVProvider) Move(ctx context.Context, sourcePath, destPath string) error {
+	p.logger.Debug("Moving file",
+		zap.String("source", sourcePath),
+		zap.String("destination", destPath))
+
+	if err := p.CreateFolder(ctx, path.Dir(cleanPath(destPath))); err != nil {
+		return fmt.Errorf("failed to ensure parent folder: %w", err)
+	}
+
+	header := http.Header{}
+	header.Set("Destination", p.resourceURL(destPath).String())
+	header.Set("Overwrite", "T")
+
+	resp, err := p.request(ctx, "MOVE", sourcePath, header, nil, 0)
+	if err != nil {
+		return fmt.Errorf("move failed: %w", err)
+	}
+	resp.Body.Close()
+	p.forgetFolders(sourcePath)
+
+	p.logger.Info("File moved successfully",
+		zap.String("source", sourcePath),
+		zap.String("destination", destPath))
+
+	return nil
+}
+
+// GetQuota reads the RFC 4331 quota properties of the root. Servers that
+// report no limit leave Total and Available at zero.
+func (p *PulsePointWebDAVProvider) GetQuota(ctx context.Context) (*interfaces.QuotaInfo, error) {
+	entries, err := p.propfind(ctx, "/", "0", propfindQuota)
+	if err != nil {
+		return nil, err
+	}
+
+	quota := &interfaces.QuotaInfo{}
+	if len(entries) == 0 {
+		return quota, nil
+	}
+
+	quota.Used, _ = strconv.ParseInt(entries[0].prop.QuotaUsed, 10, 64)
+
+	// Negative values mean the quota is unknown or unlimited
+	if available, err := strconv.ParseInt(entries[0].prop.QuotaAvailable, 10, 64); err == nil && available >= 0 {
+		quota.Available = available
+		quota.Total = quota.Used + available
+	}
+	return quota, nil
+}
+
+// GetProviderName returns the provider name
+func (p *PulsePointWebDAVProvider) GetProviderName() string {
+	return providerName
+}
+
+// IsConnected checks if the provider is connected
+func (p *PulsePointWebDAVProvider) IsConnected() bool {
+	return p.client != nil
+}
+
+// Disconnect closes the connection to the provider
+func (p *PulsePointWebDAVProvider) Disconnect() error {
+	if p.client != nil {
+		p.client.CloseIdleConnections()
+	}
+	p.client = nil
+	p.logger.Info("Disconnected from WebDAV")
+	return nil
+}
+
+// propfind runs a PROPFIND and returns the entries with the properties that were found
+func (p *PulsePointWebDAVProvider) propfind(ctx context.Context, remotePath, depth, body string) ([]*entry, error) {
+	header := http.Header{}
+	header.Set("Depth", depth)
+	header.Set("Content-Type", "application/xml; charset=utf-8")
+
+	resp, err := p.request(ctx, "PROPFIND", remotePath, header, strings.NewReader(body), int64(len(body)))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result multistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, pperrors.NewProviderError("failed to decode PROPFIND response", err)
+	}
+
+	entries := make([]*entry, 0, len(result.Responses))
+	for _, response := range result.Responses {
+		remote, ok := p.remotePath(response.Href)
+		if !ok {
+			continue
+		}
+
+		found := &entry{path: remote}
+		for _, propstat := range response.Propstats {
+			if strings.Contains(propstat.Status, " 200 ") {
+				found.prop = propstat.Prop
+			}
+		}
+		entries = append(entries, found)
+	}
+	return entries, nil
+}
+
+// request sends an authenticated request for a remote path. size is the body
+// length, or -1 when unknown. Responses outside 2xx are returned as errors.
+func (p *PulsePointWebDAVProvider) request(ctx context.Context, method, remotePath string, header http.Header, body io.Reader, size int64) (*http.Response, error) {
+	return p.send(ctx, method, p.resourceURL(remotePath), remotePath, header, body, size)
+}
+
+// send sends an authenticated request to a URL; remotePath names it in errors
+func (p *PulsePointWebDAVProvider) send(ctx context.Context, method string, target *url.URL, remotePath string, header http.Header, body io.Reader, size int64) (*http.Response, error) {
+	if p.client == nil {
+		return nil, pperrors.NewProviderError("WebDAV provider is disconnected", nil)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return nil, pperrors.NewProviderError("failed to build request", err)
+	}
+	if body != nil && size >= 0 {
+		req.ContentLength = size
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if p.config.Username != "" {
+		req.SetBasicAuth(p.config.Username, p.config.Password)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, pperrors.NewRetryable(pperrors.NetworkError, fmt.Sprintf("%s %s failed", method, remotePath), err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		return nil, responseError(method, remotePath, resp)
+	}
+	return resp, nil
+}
+
+// resourceURL returns the URL of a remote path under the root
+func (p *PulsePointWebDAVProvider) resourceURL(remotePath string) *url.URL {
+	u := *p.baseURL
+	u.Path = path.Join(u.Path, cleanPath(remotePath))
+	if strings.HasSuffix(remotePath, "/") && u.Path != "/" {
+		u.Path += "/"
+	}
+	return &u
+}
+
+// remotePath maps an href from a multistatus response back to a remote path
+func (p *PulsePointWebDAVProvider) remotePath(href string) (string, bool) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return "", false
+	}
+
+	root := strings.TrimSuffix(p.baseURL.Path, "/")
+	target := strings.TrimSuffix(u.Path, "/")
+	if target == root {
+		return "/", true
+	}
+	if !strings.HasPrefix(target, root+"/") {
+		return "", false
+	}
+	return cleanPath(strings.TrimPrefix(target, root)), true
+}
+
+// cleanPath normalises a remote path to a clean absolute path
+func cleanPath(remotePath string) string {
+	return path.Clean("/" + remotePath)
+}
+
+// responseError turns an unsuccessful response into a provider error that
+// keeps the status code. Throttling, locks and server errors are retryable.
+func responseError(method, remotePath string, resp *http.Response) error {
+	message := fmt.Sprintf("%s %s: %s", method, remotePath, resp.Status)
+	if data, err := io.ReadAll(io.LimitReader(resp.Body, 4*1024)); err == nil {
+		if detail := serverMessage(data); detail != "" {
+			message += ": " + detail
+		}
+	}
+
+	var err *pperrors.PulseError
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode == http.StatusLocked, resp.StatusCode >= 500:
+		err = pperrors.NewRetryable(pperrors.ProviderError, message, nil)
+	case resp.StatusCode == http.StatusUnauthorized, resp.StatusCode == http.StatusForbidden:
+		err = pperrors.NewAuthError(message, nil)
+	default:
+		err = pperrors.NewProviderError(message, nil)
+	}
+	err.StatusCode = resp.StatusCode
+	return err
+}
+
+// serverMessage extracts the message of a Sabre/Nextcloud error body, if any
+func serverMessage(data []byte) string {
+	var body struct {
+		Message string `xml:"http://sabredav.org/ns message"`
+	}
+	if xml.NewDecoder(bytes.NewReader(data)).Decode(&body) != nil {
+		return ""
+	}
+	return body.Message
+}
+
+// notFoundError reports a remote path that does not exist
+func notFoundError(remotePath string) error {
+	err := pperrors.NewProviderError(fmt.Sprintf("file not found: %s", remotePath), nil)
+	err.StatusCode = http.StatusNotFound
+	return err
+}
+
+// entry is one resource from a PROPFIND response
+type entry struct {
+	path string
+	prop prop
+}
+
+// metadata converts the entry's properties to provider metadata
+func (e *entry) metadata() *interfaces.Metadata {
+	size, _ := strconv.ParseInt(e.prop.ContentLength, 10, 64)
+	modTime, _ := http.ParseTime(e.prop.LastModified)
+	createdTime, _ := time.Parse(time.RFC3339, e.prop.CreationDate)
+	etag := strings.Trim(e.prop.ETag, `"`)
+	isFolder := e.prop.ResourceType.Collection != nil
+
+	// Prefer a content checksum, which unlike the ETag matches local hashes
+	hash := etag
+	for _, checksum := range strings.Fields(e.prop.Checksums) {
+		if value, ok := strings.CutPrefix(strings.ToUpper(checksum), "MD5:"); ok {
+			hash = strings.ToLower(value)
+		}
+	}
+
+	mimeType := e.prop.ContentType
+	if isFolder {
+		mimeType = mimeTypeFolder
+		hash = ""
+	}
+
+	id := e.prop.FileID
+	if id == "" {
+		id = e.path
+	}
+
+	return &interfaces.Metadata{
+		ID:           id,
+		Path:         e.path,
+		Size:         size,
+		Hash:         hash,
+		ModifiedTime: modTime,
+		CreatedTime:  createdTime,
+		MimeType:     mimeType,
+		IsFolder:     isFolder,
+		Version:      etag,
+		Attributes: map[string]interface{}{
+			"etag": etag,
+		},
+	}
+}
+
+// multistatus is a 207 Multi-Status response body
+type multistatus struct {
+	Responses []struct {
+		Href      string `xml:"DAV: href"`
+		Propstats []struct {
+			Prop   prop   `xml:"DAV: prop"`
+			Status string `xml:"DAV: status"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+}
+
+// prop holds the properties PulsePoint asks for
+type prop struct {
+	ResourceType struct {
+		Collection *struct{} `xml:"DAV: collection"`
+	} `xml:"DAV: resourcetype"`
+	ContentLength  string `xml:"DAV: getcontentlength"`
+	LastModified   string `xml:"DAV: getlastmodified"`
+	CreationDate   string `xml:"DAV: creationdate"`
+	ContentType    string `xml:"DAV: getcontenttype"`
+	ETag           string `xml:"DAV: getetag"`
+	FileID         string `xml:"http://owncloud.org/ns fileid"`
+	Checksums      string `xml:"http://owncloud.org/ns checksums>checksum"`
+	QuotaUsed      string `xml:"DAV: quota-used-bytes"`
+	QuotaAvailable string `xml:"DAV: quota-available-bytes"`
+}
@@ -0,0 +1,144 @@
+package webdav
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"github.com/pulsepoint/pulsepoint/internal/providers/providertest"
+	pperrors "github.com/pulsepoint/pulsepoint/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/webdav"
+)
+
+const testPrefix = "/remote.php/dav/files/alice"
+
+// newTestServer starts an in-memory WebDAV server behind basic auth. The
+// x/net/webdav handler has no quota properties, so those are answered here.
+func newTestServer(t *testing.T) *httptest.Server {
+	handler := &webdav.Handler{
+		Prefix:     testPrefix,
+		FileSystem: webdav.NewMemFS(),
+		LockSystem: webdav.NewMemLS(),
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "alice" || pass != "app-password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if r.Method == "PROPFIND" {
+			body, _ := io.ReadAll(r.Body)
+			if strings.Contains(string(body), "quota-used-bytes") {
+				w.WriteHeader(http.StatusMultiStatus)
+				fmt.Fprintf(w, `<d:multistatus xmlns:d="DAV:"><d:response><d:href>%s/Sync/</d:href>
+<d:propstat><d:prop><d:quota-used-bytes>1024</d:quota-used-bytes><d:quota-available-bytes>3072</d:quota-available-bytes></d:prop>
+<d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response></d:multistatus>`, testPrefix)
+				return
+			}
+			r.Body = io.NopCloser(strings.NewReader(string(body)))
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestProvider(t *testing.T, server *httptest.Server, password string) *PulsePointWebDAVProvider {
+	provider, err := NewPulsePointWebDAVProvider(&Config{
+		URL:      server.URL + testPrefix + "/",
+		Root:     "Sync",
+		Username: "alice",
+		Password: password,
+	})
+	require.NoError(t, err)
+	return provider
+}
+
+func TestWebDAVProviderRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	provider := newTestProvider(t, newTestServer(t), "app-password")
+
+	require.NoError(t, provider.Upload(ctx, &interfaces.File{
+		Path:    "/Docs/Reports/q1 summary.txt",
+		Content: strings.NewReader("quarterly"),
+		Size:    9,
+	}))
+	require.NoError(t, provider.Upload(ctx, &interfaces.File{
+		Path:    "/Docs/todo.md",
+		Content: strings.NewReader("- ship it"),
+	}))
+
+	files, err := provider.List(ctx, "/Docs")
+	require.NoError(t, err)
+	found := make(map[string]bool)
+	for _, file := range files {
+		found[file.Path] = file.IsFolder
+	}
+	assert.Equal(t, map[string]bool{"/Docs/Reports": true, "/Docs/todo.md": false}, found)
+
+	meta, err := provider.GetMetadata(ctx, "/Docs/Reports/q1 summary.txt")
+	require.NoError(t, err)
+	assert.Equal(t, int64(9), meta.Size)
+	assert.NotEmpty(t, meta.Hash)
+	assert.False(t, meta.IsFolder)
+	assert.WithinDuration(t, time.Now(), meta.ModifiedTime, time.Minute)
+
+	file, err := provider.Download(ctx, "/Docs/Reports/q1 summary.txt")
+	require.NoError(t, err)
+	content, err := io.ReadAll(file.Content)
+	require.NoError(t, err)
+	file.Content.(io.Closer).Close()
+	assert.Equal(t, "quarterly", string(content))
+
+	require.NoError(t, provider.Move(ctx, "/Docs/Reports", "/Archive/2024/Reports"))
+	_, err = provider.GetMetadata(ctx, "/Docs/Reports/q1 summary.txt")
+	assert.True(t, pperrors.IsNotFoundError(err))
+	_, err = provider.GetMetadata(ctx, "/Archive/2024/Reports/q1 summary.txt")
+	require.NoError(t, err)
+
+	// Deleting a folder must not leave it cached as existing
+	require.NoError(t, provider.Delete(ctx, "/Archive"))
+	assert.NoError(t, provider.Delete(ctx, "/Archive"), "deleting a missing path succeeds")
+	require.NoError(t, provider.Upload(ctx, &interfaces.File{
+		Path:    "/Archive/2024/again.txt",
+		Content: strings.NewReader("again"),
+	}))
+
+	quota, err := provider.GetQuota(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &interfaces.QuotaInfo{Used: 1024, Available: 3072, Total: 4096}, quota)
+}
+
+func TestWebDAVProviderReportsAuthFailures(t *testing.T) {
+	provider := newTestProvider(t, newTestServer(t), "wrong")
+
+	_, err := provider.List(context.Background(), "/")
+	require.Error(t, err)
+	assert.True(t, pperrors.IsAuthError(err))
+}
+
+func TestEntryPrefersMD5Checksum(t *testing.T) {
+	e := &entry{path: "/a.txt", prop: prop{
+		ETag:      `"abc123"`,
+		Checksums: "SHA1:da39a3ee5e6b4b0d3255bfef95601890afd80709 MD5:D41D8CD98F00B204E9800998ECF8427E",
+	}}
+
+	meta := e.metadata()
+	assert.Equal(t, "d41d8cd98f00b204e9800998ecf8427e", meta.Hash)
+	assert.Equal(t, "abc123", meta.Version)
+}
+
+func TestWebDAVProviderConformance(t *testing.T) {
+	providertest.Run(t, func(t *testing.T) interfaces.CloudProvider {
+		return newTestProvider(t, newTestServer(t), "app-password")
+	})
+}
@@ -1,13 +1,16 @@
 package strategies
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"path"
 	"path/filepath"
 	"time"
 
 	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
 	pperrors "github.com/pulsepoint/pulsepoint/pkg/errors"
+	"github.com/pulsepoint/pulsepoint/pkg/utils"
 	"go.uber.org/zap"
 )
 
@@ -73,7 +76,7 @@ func (s *PulsePointBackupStrategy) Sync(
 
 	// Process each change
 	for _, change := range changes {
-		if err := s.processChange(ctx, change, backupTimestamp, result); err != nil {
+		if err := s.processChange(ctx, source, destination, change, backupTimestamp, result); err != nil {
 			s.logger.Error("Failed to process change",
 				zap.String("path", change.Path),
 				zap.String("type", string(change.Type)),
@@ -109,28 +112,36 @@ func (s *PulsePointBackupStrategy) Sync(
 // processChange processes a single change event
 func (s *PulsePointBackupStrategy) processChange(
 	ctx context.Context,
+	source, destination string,
 	change interfaces.ChangeEvent,
 	backupTimestamp string,
 	result *interfaces.SyncResult,
 ) error {
 	result.FilesProcessed++
 
+	remotePath := utils.RemotePath(source, destination, change.Path)
+
 	switch change.Type {
 	case interfaces.ChangeTypeCreate, interfaces.ChangeTypeModify:
-		return s.backupFile(ctx, change, backupTimestamp, result)
+		return s.backupFile(ctx, change, remotePath, backupTimestamp, result)
 
 	case interfaces.ChangeTypeDelete:
 		// In backup mode, we mark files as deleted but don't remove them
-		return s.markDeleted(ctx, change, backupTimestamp, result)
+		return s.markDeleted(ctx, remotePath, backupTimestamp, result)
 
-	case interfaces.ChangeTypeRename:
+	case interfaces.ChangeTypeRename, interfaces.ChangeTypeMove:
+		// Without the old path the watcher only saw the file leave
+		if change.OldPath == "" {
+			return s.markDeleted(ctx, remotePath, backupTimestamp, result)
+		}
 		// In backup mode, keep both old and new paths
 		// Mark old path as moved
-		if err := s.markMoved(ctx, change.OldPath, change.Path, backupTimestamp, result); err != nil {
+		oldRemotePath := utils.RemotePath(source, destination, change.OldPath)
+		if err := s.markMoved(ctx, oldRemotePath, remotePath, backupTimestamp, result); err != nil {
 			return err
 		}
 		// Upload new file
-		return s.backupFile(ctx, change, backupTimestamp, result)
+		return s.backupFile(ctx, change, remotePath, backupTimestamp, result)
 
 	default:
 		s.logger.Warn("Unknown change type",
@@ -146,9 +157,25 @@ func (s *PulsePointBackupStrategy) processChange(
 func (s *PulsePointBackupStrategy) backupFile(
 	ctx context.Context,
 	change interfaces.ChangeEvent,
+	remotePath string,
 	backupTimestamp string,
 	result *interfaces.SyncResult,
 ) error {
+	// Directories only need to exist remotely
+	if change.IsDir {
+		if change.Type == interfaces.ChangeTypeModify {
+			result.FilesSkipped++
+			return nil
+		}
+		if err := s.provider.CreateFolder(ctx, remotePath); err != nil {
+			return pperrors.NewSyncError(
+				fmt.Sprintf("failed to create folder %s", remotePath),
+				err,
+			)
+		}
+		return nil
+	}
+
 	// Check file size limit
 	if s.config.MaxFileSize > 0 && change.Size > s.config.MaxFileSize {
 		s.logger.Debug("File exceeds size limit, skipping",
@@ -161,28 +188,28 @@ func (s *PulsePointBackupStrategy) backupFile(
 	}
 
 	// Generate versioned path if file already exists
-	remotePath := change.Path
 	if s.config.VersionControl {
 		// Check if file exists remotely
-		metadata, err := s.provider.GetMetadata(ctx, change.Path)
+		metadata, err := s.provider.GetMetadata(ctx, remotePath)
 		if err == nil && metadata != nil {
 			// File exists, create versioned backup
-			remotePath = s.generateVersionedPath(change.Path, backupTimestamp)
+			versionedPath := s.generateVersionedPath(remotePath, backupTimestamp)
 			s.logger.Debug("Creating versioned backup",
-				zap.String("original", change.Path),
-				zap.String("versioned", remotePath),
+				zap.String("original", remotePath),
+				zap.String("versioned", versionedPath),
 			)
+			remotePath = versionedPath
 		}
 	}
 
 	// Create file model
 	file := &interfaces.File{
 		Path:         remotePath,
+		Name:         path.Base(remotePath),
 		Size:         change.Size,
 		Hash:         change.Hash,
 		ModifiedTime: time.Unix(0, change.Timestamp),
-		IsFolder:     change.IsDir,
-		// Note: Metadata field doesn't exist in interfaces.File
+		LocalPath:    change.Path,
 	}
 
 	// Upload to provider
@@ -208,35 +235,36 @@ func (s *PulsePointBackupStrategy) backupFile(
 // markDeleted marks a file as deleted without removing it
 func (s *PulsePointBackupStrategy) markDeleted(
 	ctx context.Context,
-	change interfaces.ChangeEvent,
+	remotePath string,
 	backupTimestamp string,
 	result *interfaces.SyncResult,
 ) error {
 	// Create a deletion marker file
-	markerPath := fmt.Sprintf("%s.deleted_%s", change.Path, backupTimestamp)
+	markerPath := fmt.Sprintf("%s.deleted_%s", remotePath, backupTimestamp)
 
 	markerFile := &interfaces.File{
 		Path:         markerPath,
+		Name:         path.Base(markerPath),
 		Size:         0,
 		ModifiedTime: time.Now(),
 		IsFolder:     false,
+		Content:      bytes.NewReader(nil),
 	}
 
 	// Upload deletion marker
 	if err := s.provider.Upload(ctx, markerFile); err != nil {
 		s.logger.Warn("Failed to create deletion marker",
-			zap.String("path", change.Path),
+			zap.String("path", remotePath),
 			zap.Error(err),
 		)
 		// Not a fatal error, continue
 	}
 
 	s.logger.Debug("File marked as deleted",
-		zap.String("path", change.Path),
+		zap.String("path", remotePath),
 		zap.String("marker", markerPath),
 	)
 
-	result.FilesProcessed++
 	return nil
 }
 
@@ -252,9 +280,11 @@ func (s *PulsePointBackupStrategy) markMoved(
 
 	markerFile := &interfaces.File{
 		Path:         markerPath,
+		Name:         path.Base(markerPath),
 		Size:         0,
 		ModifiedTime: time.Now(),
 		IsFolder:     false,
+		Content:      bytes.NewReader(nil),
 	}
 
 	// Upload move marker
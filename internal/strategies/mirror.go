@@ -3,13 +3,23 @@ package strategies
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"github.com/pulsepoint/pulsepoint/internal/watchers/ignore"
 	pperrors "github.com/pulsepoint/pulsepoint/pkg/errors"
+	"github.com/pulsepoint/pulsepoint/pkg/utils"
 	"go.uber.org/zap"
 )
 
+// defaultMaxDeletePercent is the largest share of remote files a cleanup
+// deletes before it assumes something is wrong with the source
+const defaultMaxDeletePercent = 50
+
 // PulsePointMirrorStrategy implements mirror synchronization
 // Mirror sync makes the destination an exact copy of the source (deletes extra files)
 type PulsePointMirrorStrategy struct {
@@ -29,14 +39,12 @@ func NewPulsePointMirrorStrategy(
 			ConflictResolution: interfaces.ResolutionKeepLocal,
 			IgnorePatterns:     []string{},
 			MaxFileSize:        0,     // No limit
-			PreserveDeleted:    false, // Mirror always deletes extra files
+			PreserveDeleted:    false, // Mirror deletes extra files
+			MaxDeletePercent:   defaultMaxDeletePercent,
 			VersionControl:     false,
 		}
 	}
 
-	// Force preserve_deleted to false for mirror sync
-	config.PreserveDeleted = false
-
 	return &PulsePointMirrorStrategy{
 		provider: provider,
 		logger:   logger.With(zap.String("strategy", "mirror")),
@@ -68,7 +76,7 @@ func (s *PulsePointMirrorStrategy) Sync(
 
 	// First, sync all local changes to remote
 	for _, change := range changes {
-		if err := s.processChange(ctx, change, result); err != nil {
+		if err := s.processChange(ctx, source, destination, change, result); err != nil {
 			s.logger.Error("Failed to process change",
 				zap.String("path", change.Path),
 				zap.String("type", string(change.Type)),
@@ -86,12 +94,8 @@ func (s *PulsePointMirrorStrategy) Sync(
 		}
 	}
 
-	// Then, remove any remote files that don't exist locally
-	if err := s.cleanupRemote(ctx, source, result); err != nil {
-		s.logger.Error("Failed to cleanup remote files", zap.Error(err))
-		result.Success = false
-	}
-
+	// Remote files with no local counterpart are only removed by CleanupRemote,
+	// which walks both trees and is too costly for every batch of changes
 	result.EndTime = time.Now().UnixNano()
 
 	s.logger.Info("Mirror sync completed",
@@ -109,6 +113,7 @@ func (s *PulsePointMirrorStrategy) Sync(
 // processChange processes a single change event
 func (s *PulsePointMirrorStrategy) processChange(
 	ctx context.Context,
+	source, destination string,
 	change interfaces.ChangeEvent,
 	result *interfaces.SyncResult,
 ) error {
@@ -116,21 +121,33 @@ func (s *PulsePointMirrorStrategy) processChange(
 
 	switch change.Type {
 	case interfaces.ChangeTypeCreate, interfaces.ChangeTypeModify:
-		return s.uploadFile(ctx, change, result)
+		return s.uploadFile(ctx, source, destination, change, result)
 
 	case interfaces.ChangeTypeDelete:
 		// Always delete in mirror mode
-		return s.deleteFile(ctx, change, result)
-
-	case interfaces.ChangeTypeRename:
-		// Handle rename as delete old + create new
-		if err := s.deleteFile(ctx, interfaces.ChangeEvent{
-			Type: interfaces.ChangeTypeDelete,
-			Path: change.OldPath,
-		}, result); err != nil {
+		return s.deleteFile(ctx, source, destination, change.Path, result)
+
+	case interfaces.ChangeTypeRename, interfaces.ChangeTypeMove:
+		// Without the old path the watcher only saw the file leave
+		if change.OldPath == "" {
+			return s.deleteFile(ctx, source, destination, change.Path, result)
+		}
+
+		oldRemotePath := utils.RemotePath(source, destination, change.OldPath)
+		newRemotePath := utils.RemotePath(source, destination, change.Path)
+		if err := s.provider.Move(ctx, oldRemotePath, newRemotePath); err == nil {
+			s.logger.Debug("File moved successfully",
+				zap.String("from", oldRemotePath),
+				zap.String("to", newRemotePath),
+			)
+			return nil
+		}
+
+		// Fall back to delete old + create new
+		if err := s.deleteFile(ctx, source, destination, change.OldPath, result); err != nil {
 			return err
 		}
-		return s.uploadFile(ctx, change, result)
+		return s.uploadFile(ctx, source, destination, change, result)
 
 	default:
 		s.logger.Warn("Unknown change type",
@@ -145,9 +162,27 @@ func (s *PulsePointMirrorStrategy) processChange(
 // uploadFile uploads a file to the remote
 func (s *PulsePointMirrorStrategy) uploadFile(
 	ctx context.Context,
+	source, destination string,
 	change interfaces.ChangeEvent,
 	result *interfaces.SyncResult,
 ) error {
+	remotePath := utils.RemotePath(source, destination, change.Path)
+
+	// Directories only need to exist remotely
+	if change.IsDir {
+		if change.Type == interfaces.ChangeTypeModify {
+			result.FilesSkipped++
+			return nil
+		}
+		if err := s.provider.CreateFolder(ctx, remotePath); err != nil {
+			return pperrors.NewSyncError(
+				fmt.Sprintf("failed to create folder %s", remotePath),
+				err,
+			)
+		}
+		return nil
+	}
+
 	// Check file size limit
 	if s.config.MaxFileSize > 0 && change.Size > s.config.MaxFileSize {
 		s.logger.Debug("File exceeds size limit, skipping",
@@ -161,11 +196,12 @@ func (s *PulsePointMirrorStrategy) uploadFile(
 
 	// Create file model
 	file := &interfaces.File{
-		Path:         change.Path,
+		Path:         remotePath,
+		Name:         filepath.Base(change.Path),
 		Size:         change.Size,
 		Hash:         change.Hash,
 		ModifiedTime: time.Unix(0, change.Timestamp),
-		IsFolder:     change.IsDir,
+		LocalPath:    change.Path,
 	}
 
 	// Upload to provider
@@ -181,6 +217,7 @@ func (s *PulsePointMirrorStrategy) uploadFile(
 
 	s.logger.Debug("File uploaded successfully",
 		zap.String("path", change.Path),
+		zap.String("remote_path", remotePath),
 		zap.Int64("size", change.Size),
 	)
 
@@ -190,21 +227,24 @@ func (s *PulsePointMirrorStrategy) uploadFile(
 // deleteFile deletes a file from the remote
 func (s *PulsePointMirrorStrategy) deleteFile(
 	ctx context.Context,
-	change interfaces.ChangeEvent,
+	source, destination string,
+	localPath string,
 	result *interfaces.SyncResult,
 ) error {
+	remotePath := utils.RemotePath(source, destination, localPath)
+
 	// Delete from provider
-	if err := s.provider.Delete(ctx, change.Path); err != nil {
+	if err := s.provider.Delete(ctx, remotePath); err != nil {
 		// If file doesn't exist, it's not an error in mirror mode
 		if pperrors.IsNotFoundError(err) {
 			s.logger.Debug("File already deleted from remote",
-				zap.String("path", change.Path),
+				zap.String("path", localPath),
 			)
 			return nil
 		}
 
 		return pperrors.NewSyncError(
-			fmt.Sprintf("failed to delete %s", change.Path),
+			fmt.Sprintf("failed to delete %s", localPath),
 			err,
 		)
 	}
@@ -212,38 +252,246 @@ func (s *PulsePointMirrorStrategy) deleteFile(
 	result.FilesDeleted++
 
 	s.logger.Debug("File deleted successfully",
-		zap.String("path", change.Path),
+		zap.String("path", localPath),
+		zap.String("remote_path", remotePath),
 	)
 
 	return nil
 }
 
-// cleanupRemote removes remote files that don't exist locally
+// CleanupRemote removes remote files that don't exist locally by comparing
+// the full remote tree against a walk of the source. It is meant for full and
+// startup syncs; Sync only applies the changes it is given.
+func (s *PulsePointMirrorStrategy) CleanupRemote(
+	ctx context.Context,
+	source, destination string,
+) (*interfaces.SyncResult, error) {
+	result := &interfaces.SyncResult{
+		StartTime: time.Now().UnixNano(),
+		Success:   true,
+	}
+
+	if err := s.cleanupRemote(ctx, source, destination, result); err != nil {
+		return nil, err
+	}
+
+	result.EndTime = time.Now().UnixNano()
+	return result, nil
+}
+
+// cleanupRemote deletes the remote extras into result
 func (s *PulsePointMirrorStrategy) cleanupRemote(
 	ctx context.Context,
-	localPath string,
+	source, destination string,
 	result *interfaces.SyncResult,
 ) error {
+	if s.config.PreserveDeleted {
+		s.logger.Debug("Preserving deleted files, skipping remote cleanup")
+		return nil
+	}
+
 	s.logger.Info("Cleaning up remote files not in source")
 
-	// List all remote files
-	remoteFiles, err := s.provider.List(ctx, "/")
+	matcher := s.ignoreMatcher(source)
+
+	// A missing source must never look like an empty one
+	localPaths, err := s.walkLocal(source, matcher)
+	if err != nil {
+		return pperrors.NewSyncError("failed to walk local files", err)
+	}
+
+	remoteFiles, err := s.listRemote(ctx, source, destination, matcher)
 	if err != nil {
 		return pperrors.NewSyncError("failed to list remote files", err)
 	}
 
-	// TODO: Compare with local files and delete extras
-	// This requires access to local file system which should be
-	// injected or passed as a parameter
+	// Collect remote entries with no local counterpart
+	var extras []*interfaces.File
+	fileCount, deleteCount := 0, 0
+	for _, file := range remoteFiles {
+		if !file.IsFolder {
+			fileCount++
+		}
+		if localPaths[s.relativePath(source, destination, file.Path)] {
+			continue
+		}
+		extras = append(extras, file)
+		if !file.IsFolder {
+			deleteCount++
+		}
+	}
 
-	// For now, we'll just log the count
-	s.logger.Info("Remote cleanup check completed",
-		zap.Int("remote_files", len(remoteFiles)),
+	if len(extras) == 0 {
+		s.logger.Info("Remote cleanup check completed",
+			zap.Int("remote_files", fileCount),
+		)
+		return nil
+	}
+
+	limit := s.config.MaxDeletePercent
+	if limit <= 0 {
+		limit = defaultMaxDeletePercent
+	}
+	if deleteCount*100 > fileCount*limit {
+		return pperrors.NewSyncError(fmt.Sprintf(
+			"refusing to delete %d of %d remote files, more than the %d%% limit",
+			deleteCount, fileCount, limit,
+		), nil)
+	}
+
+	// Files go first, then folders from the deepest up, so each file is counted
+	sort.SliceStable(extras, func(i, j int) bool {
+		if extras[i].IsFolder != extras[j].IsFolder {
+			return !extras[i].IsFolder
+		}
+		return strings.Count(extras[i].Path, "/") > strings.Count(extras[j].Path, "/")
+	})
+
+	for _, file := range extras {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := s.provider.Delete(ctx, file.Path); err != nil && !pperrors.IsNotFoundError(err) {
+			s.logger.Error("Failed to delete extra remote file",
+				zap.String("remote_path", file.Path),
+				zap.Error(err),
+			)
+			result.Errors = append(result.Errors, interfaces.SyncError{
+				Path:      file.Path,
+				Operation: string(interfaces.ChangeTypeDelete),
+				Message:   err.Error(),
+				Timestamp: time.Now().UnixNano(),
+			})
+			result.Success = false
+			continue
+		}
+
+		if !file.IsFolder {
+			result.FilesDeleted++
+		}
+
+		s.logger.Debug("Extra remote file deleted",
+			zap.String("remote_path", file.Path),
+			zap.Bool("folder", file.IsFolder),
+		)
+	}
+
+	s.logger.Info("Remote cleanup completed",
+		zap.Int("remote_files", fileCount),
+		zap.Int("deleted", deleteCount),
 	)
 
 	return nil
 }
 
+// ignoreMatcher builds the matcher for paths cleanup must leave alone, from
+// the configured patterns and the source's ignore file
+func (s *PulsePointMirrorStrategy) ignoreMatcher(source string) *ignore.PulsePointIgnoreMatcher {
+	matcher := ignore.NewPulsePointIgnoreMatcher()
+	matcher.AddPatterns(s.config.IgnorePatterns)
+
+	for _, name := range []string{".pulseignore", ".gitignore"} {
+		ignoreFile := filepath.Join(source, name)
+		if utils.PathExists(ignoreFile) {
+			if err := matcher.LoadFromFile(ignoreFile); err != nil {
+				s.logger.Warn("Failed to load ignore file",
+					zap.String("path", ignoreFile),
+					zap.Error(err),
+				)
+			}
+			break
+		}
+	}
+
+	return matcher
+}
+
+// walkLocal returns the relative paths of everything under source that is not ignored
+func (s *PulsePointMirrorStrategy) walkLocal(
+	source string,
+	matcher *ignore.PulsePointIgnoreMatcher,
+) (map[string]bool, error) {
+	paths := make(map[string]bool)
+
+	err := filepath.WalkDir(source, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == source {
+			return nil
+		}
+
+		rel, err := filepath.Rel(source, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if matcher.ShouldIgnore(rel, entry.IsDir()) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		paths[rel] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return paths, nil
+}
+
+// listRemote lists the remote tree under destination, leaving out ignored paths
+func (s *PulsePointMirrorStrategy) listRemote(
+	ctx context.Context,
+	source, destination string,
+	matcher *ignore.PulsePointIgnoreMatcher,
+) ([]*interfaces.File, error) {
+	var files []*interfaces.File
+	seen := make(map[string]bool)
+
+	folders := []string{destination}
+	for len(folders) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		folder := folders[0]
+		folders = folders[1:]
+
+		entries, err := s.provider.List(ctx, folder)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			if seen[entry.Path] || matcher.ShouldIgnore(s.relativePath(source, destination, entry.Path), entry.IsFolder) {
+				continue
+			}
+			seen[entry.Path] = true
+			files = append(files, entry)
+			if entry.IsFolder {
+				folders = append(folders, entry.Path)
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// relativePath returns a remote path relative to the mirrored root
+func (s *PulsePointMirrorStrategy) relativePath(source, destination, remotePath string) string {
+	rel, err := filepath.Rel(source, utils.LocalPath(source, destination, remotePath))
+	if err != nil {
+		return remotePath
+	}
+	return filepath.ToSlash(rel)
+}
+
 // ResolveConflict handles conflict resolution
 func (s *PulsePointMirrorStrategy) ResolveConflict(
 	ctx context.Context,
@@ -3,10 +3,12 @@ package strategies
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"time"
 
 	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
 	pperrors "github.com/pulsepoint/pulsepoint/pkg/errors"
+	"github.com/pulsepoint/pulsepoint/pkg/utils"
 	"go.uber.org/zap"
 )
 
@@ -64,7 +66,7 @@ func (s *PulsePointOneWayStrategy) Sync(
 
 	// Process each change
 	for _, change := range changes {
-		if err := s.processChange(ctx, change, result); err != nil {
+		if err := s.processChange(ctx, source, destination, change, result); err != nil {
 			s.logger.Error("Failed to process change",
 				zap.String("path", change.Path),
 				zap.String("type", string(change.Type)),
@@ -99,6 +101,7 @@ func (s *PulsePointOneWayStrategy) Sync(
 // processChange processes a single change event
 func (s *PulsePointOneWayStrategy) processChange(
 	ctx context.Context,
+	source, destination string,
 	change interfaces.ChangeEvent,
 	result *interfaces.SyncResult,
 ) error {
@@ -106,7 +109,7 @@ func (s *PulsePointOneWayStrategy) processChange(
 
 	switch change.Type {
 	case interfaces.ChangeTypeCreate, interfaces.ChangeTypeModify:
-		return s.uploadFile(ctx, change, result)
+		return s.uploadFile(ctx, source, destination, change, result)
 
 	case interfaces.ChangeTypeDelete:
 		if s.config.PreserveDeleted {
@@ -116,17 +119,19 @@ func (s *PulsePointOneWayStrategy) processChange(
 			result.FilesSkipped++
 			return nil
 		}
-		return s.deleteFile(ctx, change, result)
-
-	case interfaces.ChangeTypeRename:
-		// Handle rename as delete old + create new
-		if err := s.deleteFile(ctx, interfaces.ChangeEvent{
-			Type: interfaces.ChangeTypeDelete,
-			Path: change.OldPath,
-		}, result); err != nil {
-			return err
+		return s.deleteFile(ctx, source, destination, change, result)
+
+	case interfaces.ChangeTypeRename, interfaces.ChangeTypeMove:
+		// Without the old path the watcher only saw the file leave, so
+		// treat it as a delete; the new name arrives as its own create
+		if change.OldPath == "" {
+			if s.config.PreserveDeleted {
+				result.FilesSkipped++
+				return nil
+			}
+			return s.deleteFile(ctx, source, destination, change, result)
 		}
-		return s.uploadFile(ctx, change, result)
+		return s.moveFile(ctx, source, destination, change, result)
 
 	default:
 		s.logger.Warn("Unknown change type",
@@ -141,9 +146,27 @@ func (s *PulsePointOneWayStrategy) processChange(
 // uploadFile uploads a file to the remote
 func (s *PulsePointOneWayStrategy) uploadFile(
 	ctx context.Context,
+	source, destination string,
 	change interfaces.ChangeEvent,
 	result *interfaces.SyncResult,
 ) error {
+	remotePath := utils.RemotePath(source, destination, change.Path)
+
+	// Directories only need to exist remotely
+	if change.IsDir {
+		if change.Type == interfaces.ChangeTypeModify {
+			result.FilesSkipped++
+			return nil
+		}
+		if err := s.provider.CreateFolder(ctx, remotePath); err != nil {
+			return pperrors.NewSyncError(
+				fmt.Sprintf("failed to create folder %s", remotePath),
+				err,
+			)
+		}
+		return nil
+	}
+
 	// Check file size limit
 	if s.config.MaxFileSize > 0 && change.Size > s.config.MaxFileSize {
 		s.logger.Debug("File exceeds size limit, skipping",
@@ -157,11 +180,12 @@ func (s *PulsePointOneWayStrategy) uploadFile(
 
 	// Create file model
 	file := &interfaces.File{
-		Path:         change.Path,
+		Path:         remotePath,
+		Name:         filepath.Base(change.Path),
 		Size:         change.Size,
 		Hash:         change.Hash,
 		ModifiedTime: time.Unix(0, change.Timestamp),
-		IsFolder:     change.IsDir,
+		LocalPath:    change.Path,
 	}
 
 	// Upload to provider
@@ -177,6 +201,7 @@ func (s *PulsePointOneWayStrategy) uploadFile(
 
 	s.logger.Debug("File uploaded successfully",
 		zap.String("path", change.Path),
+		zap.String("remote_path", remotePath),
 		zap.Int64("size", change.Size),
 	)
 
@@ -186,11 +211,14 @@ func (s *PulsePointOneWayStrategy) uploadFile(
 // deleteFile deletes a file from the remote
 func (s *PulsePointOneWayStrategy) deleteFile(
 	ctx context.Context,
+	source, destination string,
 	change interfaces.ChangeEvent,
 	result *interfaces.SyncResult,
 ) error {
+	remotePath := utils.RemotePath(source, destination, change.Path)
+
 	// Delete from provider
-	if err := s.provider.Delete(ctx, change.Path); err != nil {
+	if err := s.provider.Delete(ctx, remotePath); err != nil {
 		return pperrors.NewSyncError(
 			fmt.Sprintf("failed to delete %s", change.Path),
 			err,
@@ -201,6 +229,35 @@ func (s *PulsePointOneWayStrategy) deleteFile(
 
 	s.logger.Debug("File deleted successfully",
 		zap.String("path", change.Path),
+		zap.String("remote_path", remotePath),
+	)
+
+	return nil
+}
+
+// moveFile moves or renames a file on the remote
+func (s *PulsePointOneWayStrategy) moveFile(
+	ctx context.Context,
+	source, destination string,
+	change interfaces.ChangeEvent,
+	result *interfaces.SyncResult,
+) error {
+	oldRemotePath := utils.RemotePath(source, destination, change.OldPath)
+	newRemotePath := utils.RemotePath(source, destination, change.Path)
+
+	if err := s.provider.Move(ctx, oldRemotePath, newRemotePath); err != nil {
+		// The old file may never have reached the remote; upload the new one instead
+		s.logger.Debug("Remote move failed, uploading instead",
+			zap.String("from", oldRemotePath),
+			zap.String("to", newRemotePath),
+			zap.Error(err),
+		)
+		return s.uploadFile(ctx, source, destination, change, result)
+	}
+
+	s.logger.Debug("File moved successfully",
+		zap.String("from", oldRemotePath),
+		zap.String("to", newRemotePath),
 	)
 
 	return nil
@@ -0,0 +1,1053 @@
+package strategies
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	pperrors "github.com/pulsepoint/pulsepoint/pkg/errors"
+	"github.com/pulsepoint/pulsepoint/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// PulsePointTwoWayStrategy implements bidirectional synchronization
+// The last synced file state of each path is the common ancestor used to
+// decide which side changed
+type PulsePointTwoWayStrategy struct {
+	provider     interfaces.CloudProvider
+	stateManager interfaces.StateManager
+	logger       *zap.Logger
+	config       interfaces.StrategyConfig
+	resolver     interfaces.ConflictResolver
+}
+
+// metadataMergedRemoteHash records, for a merge left with conflict markers,
+// the remote hash the merge was made against
+const metadataMergedRemoteHash = "merged_remote_hash"
+
+// twoWaySides describes both sides of a path relative to its common ancestor
+type twoWaySides struct {
+	localPath     string
+	remotePath    string
+	localHash     string
+	localExists   bool
+	localChanged  bool
+	remote        *interfaces.Metadata
+	remoteChanged bool
+	ancestor      *interfaces.FileState
+}
+
+// NewPulsePointTwoWayStrategy creates a new two-way sync strategy
+func NewPulsePointTwoWayStrategy(
+	provider interfaces.CloudProvider,
+	stateManager interfaces.StateManager,
+	logger *zap.Logger,
+	config *interfaces.StrategyConfig,
+) *PulsePointTwoWayStrategy {
+	if config == nil {
+		config = &interfaces.StrategyConfig{
+			ConflictResolution: interfaces.ResolutionKeepBoth,
+			IgnorePatterns:     []string{},
+			MaxFileSize:        0, // No limit
+			PreserveDeleted:    false,
+			VersionControl:     false,
+		}
+	}
+
+	return &PulsePointTwoWayStrategy{
+		provider:     provider,
+		stateManager: stateManager,
+		logger:       logger.With(zap.String("strategy", "twoway")),
+		config:       *config,
+	}
+}
+
+// SetConflictResolver hands conflict decisions to a resolver, which is
+// needed for the merge and interactive resolutions
+func (s *PulsePointTwoWayStrategy) SetConflictResolver(resolver interfaces.ConflictResolver) {
+	s.resolver = resolver
+}
+
+// Name returns the strategy name
+func (s *PulsePointTwoWayStrategy) Name() string {
+	return "two-way"
+}
+
+// OwnsFileState reports that the strategy records file state itself
+func (s *PulsePointTwoWayStrategy) OwnsFileState() bool {
+	return s.stateManager != nil
+}
+
+// Sync performs two-way synchronization between local and remote
+func (s *PulsePointTwoWayStrategy) Sync(
+	ctx context.Context,
+	source, destination string,
+	changes []interfaces.ChangeEvent,
+) (*interfaces.SyncResult, error) {
+	s.logger.Info("Starting two-way sync",
+		zap.String("source", source),
+		zap.String("destination", destination),
+		zap.Int("changes", len(changes)),
+	)
+
+	result := &interfaces.SyncResult{
+		StartTime: time.Now().UnixNano(),
+		Success:   true,
+	}
+
+	// Process each change
+	for _, change := range changes {
+		if err := s.processChange(ctx, source, destination, change, result); err != nil {
+			s.logger.Error("Failed to process change",
+				zap.String("path", change.Path),
+				zap.String("type", string(change.Type)),
+				zap.Error(err),
+			)
+
+			result.Errors = append(result.Errors, interfaces.SyncError{
+				Path:      change.Path,
+				Operation: string(change.Type),
+				Message:   err.Error(),
+				Timestamp: time.Now().UnixNano(),
+			})
+
+			result.Success = false
+		}
+	}
+
+	result.EndTime = time.Now().UnixNano()
+
+	s.logger.Info("Two-way sync completed",
+		zap.Int("processed", result.FilesProcessed),
+		zap.Int("uploaded", result.FilesUploaded),
+		zap.Int("downloaded", result.FilesDownloaded),
+		zap.Int("deleted", result.FilesDeleted),
+		zap.Int("skipped", result.FilesSkipped),
+		zap.Int("conflicts", len(result.Conflicts)),
+		zap.Int64("bytes", result.BytesTransferred),
+		zap.Bool("success", result.Success),
+	)
+
+	return result, nil
+}
+
+// processChange processes a single change event from either side
+func (s *PulsePointTwoWayStrategy) processChange(
+	ctx context.Context,
+	source, destination string,
+	change interfaces.ChangeEvent,
+	result *interfaces.SyncResult,
+) error {
+	result.FilesProcessed++
+
+	if change.Source == "remote" {
+		return s.processRemoteChange(ctx, source, destination, change, result)
+	}
+	return s.processLocalChange(ctx, source, destination, change, result)
+}
+
+// processLocalChange handles a change reported by the local watcher
+func (s *PulsePointTwoWayStrategy) processLocalChange(
+	ctx context.Context,
+	source, destination string,
+	change interfaces.ChangeEvent,
+	result *interfaces.SyncResult,
+) error {
+	localPath := change.Path
+	remotePath := utils.RemotePath(source, destination, localPath)
+
+	if change.IsDir {
+		return s.syncLocalFolder(ctx, change, remotePath, result)
+	}
+
+	switch change.Type {
+	case interfaces.ChangeTypeCreate, interfaces.ChangeTypeModify:
+		sides := s.inspect(ctx, localPath, remotePath)
+		sides.localExists = true
+		sides.localHash = change.Hash
+		sides.localChanged = sides.ancestor == nil || change.Hash == "" || change.Hash != sides.ancestor.LocalHash
+		return s.reconcile(ctx, sides, result)
+
+	case interfaces.ChangeTypeDelete:
+		return s.reconcileLocalDelete(ctx, localPath, remotePath, result)
+
+	case interfaces.ChangeTypeRename, interfaces.ChangeTypeMove:
+		// Without the old path the watcher only saw the file leave
+		if change.OldPath == "" {
+			return s.reconcileLocalDelete(ctx, localPath, remotePath, result)
+		}
+		return s.moveRemote(ctx, source, destination, change, result)
+
+	default:
+		s.logger.Warn("Unknown change type",
+			zap.String("type", string(change.Type)),
+			zap.String("path", change.Path),
+		)
+		result.FilesSkipped++
+		return nil
+	}
+}
+
+// processRemoteChange handles a change reported by the remote provider.
+// The event path is the remote path.
+func (s *PulsePointTwoWayStrategy) processRemoteChange(
+	ctx context.Context,
+	source, destination string,
+	change interfaces.ChangeEvent,
+	result *interfaces.SyncResult,
+) error {
+	remotePath := change.Path
+	localPath := utils.LocalPath(source, destination, remotePath)
+
+	if change.IsDir {
+		return s.syncRemoteFolder(change, localPath, result)
+	}
+
+	if change.Type == interfaces.ChangeTypeRename || change.Type == interfaces.ChangeTypeMove {
+		if change.OldPath != "" {
+			oldLocalPath := utils.LocalPath(source, destination, change.OldPath)
+			if moved, err := s.moveLocal(ctx, oldLocalPath, localPath, change.OldPath, remotePath, result); moved || err != nil {
+				return err
+			}
+		}
+	}
+
+	sides := s.inspect(ctx, localPath, remotePath)
+	s.inspectLocal(&sides)
+	return s.reconcile(ctx, sides, result)
+}
+
+// reconcileLocalDelete handles a local file that no longer exists
+func (s *PulsePointTwoWayStrategy) reconcileLocalDelete(
+	ctx context.Context,
+	localPath, remotePath string,
+	result *interfaces.SyncResult,
+) error {
+	sides := s.inspect(ctx, localPath, remotePath)
+	sides.localExists = false
+	sides.localChanged = sides.ancestor != nil
+	return s.reconcile(ctx, sides, result)
+}
+
+// reconcile decides the direction for a path and applies it
+func (s *PulsePointTwoWayStrategy) reconcile(
+	ctx context.Context,
+	sides twoWaySides,
+	result *interfaces.SyncResult,
+) error {
+	if merged, pending := s.pendingMerge(sides); pending {
+		// Syncing the markers would merge them into the file again
+		if utils.HasMergeMarkers(sides.localPath) {
+			s.logger.Debug("Merge still has conflict markers, skipping",
+				zap.String("path", sides.localPath),
+			)
+			result.FilesSkipped++
+			return nil
+		}
+
+		// Editing the markers out settles the conflict; only a remote edit
+		// made since the merge is still news
+		sides.localChanged = true
+		sides.remoteChanged = sides.remote == nil || sides.remote.Hash != merged
+	}
+
+	switch {
+	case !sides.localChanged && !sides.remoteChanged:
+		result.FilesSkipped++
+		return nil
+
+	case sides.localChanged && !sides.remoteChanged:
+		return s.push(ctx, sides, result)
+
+	case !sides.localChanged && sides.remoteChanged:
+		return s.pull(ctx, sides, result)
+	}
+
+	// Both sides changed since the last sync
+	if !sides.localExists && sides.remote == nil {
+		// Deleted on both sides
+		s.deleteState(ctx, sides.localPath)
+		result.FilesSkipped++
+		return nil
+	}
+
+	if sides.localExists && sides.remote != nil && s.sameContent(sides) {
+		// Same edit on both sides
+		s.recordSynced(ctx, sides)
+		result.FilesSkipped++
+		return nil
+	}
+
+	return s.handleConflict(ctx, sides, result)
+}
+
+// push makes the remote match the local side
+func (s *PulsePointTwoWayStrategy) push(
+	ctx context.Context,
+	sides twoWaySides,
+	result *interfaces.SyncResult,
+) error {
+	if !sides.localExists {
+		if s.config.PreserveDeleted {
+			result.FilesSkipped++
+			return nil
+		}
+		if sides.remote != nil {
+			if err := s.provider.Delete(ctx, sides.remotePath); err != nil && !pperrors.IsNotFoundError(err) {
+				return pperrors.NewSyncError(
+					fmt.Sprintf("failed to delete %s", sides.remotePath),
+					err,
+				)
+			}
+			result.FilesDeleted++
+		}
+		s.deleteState(ctx, sides.localPath)
+		return nil
+	}
+
+	info, err := os.Stat(sides.localPath)
+	if err != nil {
+		return pperrors.NewSyncError(
+			fmt.Sprintf("failed to stat %s", sides.localPath),
+			err,
+		)
+	}
+
+	// Check file size limit
+	if s.config.MaxFileSize > 0 && info.Size() > s.config.MaxFileSize {
+		s.logger.Debug("File exceeds size limit, skipping",
+			zap.String("path", sides.localPath),
+			zap.Int64("size", info.Size()),
+			zap.Int64("limit", s.config.MaxFileSize),
+		)
+		result.FilesSkipped++
+		return nil
+	}
+
+	file := &interfaces.File{
+		Path:         sides.remotePath,
+		Name:         filepath.Base(sides.localPath),
+		Size:         info.Size(),
+		ModifiedTime: info.ModTime(),
+		LocalPath:    sides.localPath,
+	}
+
+	if err := s.provider.Upload(ctx, file); err != nil {
+		return pperrors.NewSyncError(
+			fmt.Sprintf("failed to upload %s", sides.localPath),
+			err,
+		)
+	}
+
+	result.FilesUploaded++
+	result.BytesTransferred += info.Size()
+
+	s.logger.Debug("File uploaded successfully",
+		zap.String("path", sides.localPath),
+		zap.String("remote_path", sides.remotePath),
+		zap.Int64("size", info.Size()),
+	)
+
+	s.recordSynced(ctx, sides)
+	return nil
+}
+
+// pull makes the local side match the remote
+func (s *PulsePointTwoWayStrategy) pull(
+	ctx context.Context,
+	sides twoWaySides,
+	result *interfaces.SyncResult,
+) error {
+	if sides.remote == nil {
+		if s.config.PreserveDeleted {
+			result.FilesSkipped++
+			return nil
+		}
+		if err := os.Remove(sides.localPath); err != nil && !os.IsNotExist(err) {
+			return pperrors.NewSyncError(
+				fmt.Sprintf("failed to delete %s", sides.localPath),
+				err,
+			)
+		}
+		result.FilesDeleted++
+		s.deleteState(ctx, sides.localPath)
+		return nil
+	}
+
+	written, err := s.download(ctx, sides.remotePath, sides.localPath, sides.remote.ModifiedTime)
+	if err != nil {
+		return err
+	}
+
+	result.FilesDownloaded++
+	result.BytesTransferred += written
+
+	s.logger.Debug("File downloaded successfully",
+		zap.String("path", sides.localPath),
+		zap.String("remote_path", sides.remotePath),
+		zap.Int64("size", written),
+	)
+
+	sides.localExists = true
+	sides.localHash = ""
+	s.recordSynced(ctx, sides)
+	return nil
+}
+
+// handleConflict raises a conflict and applies the configured resolution
+func (s *PulsePointTwoWayStrategy) handleConflict(
+	ctx context.Context,
+	sides twoWaySides,
+	result *interfaces.SyncResult,
+) error {
+	conflict := interfaces.Conflict{
+		Path:       sides.localPath,
+		Type:       interfaces.ConflictTypeModified,
+		DetectedAt: time.Now().UnixNano(),
+	}
+	if !sides.localExists || sides.remote == nil {
+		conflict.Type = interfaces.ConflictTypeDeleted
+	}
+	if sides.localExists {
+		conflict.LocalFile = &interfaces.File{
+			Path:      sides.localPath,
+			Name:      filepath.Base(sides.localPath),
+			LocalPath: sides.localPath,
+			Hash:      sides.localHash,
+		}
+		if info, err := os.Stat(sides.localPath); err == nil {
+			conflict.LocalFile.Size = info.Size()
+			conflict.LocalFile.ModifiedTime = info.ModTime()
+		}
+	}
+	if sides.remote != nil {
+		conflict.RemoteFile = &interfaces.File{
+			ID:           sides.remote.ID,
+			Path:         sides.remotePath,
+			Name:         filepath.Base(sides.remotePath),
+			Size:         sides.remote.Size,
+			Hash:         sides.remote.Hash,
+			ModifiedTime: sides.remote.ModifiedTime,
+			RemoteID:     sides.remote.ID,
+		}
+	}
+
+	s.logger.Warn("Conflict detected",
+		zap.String("path", sides.localPath),
+		zap.String("type", string(conflict.Type)),
+	)
+
+	// Merging and showing a diff need the remote content and the ancestor
+	if s.needsContent(conflict) {
+		if sides.ancestor != nil && sides.ancestor.LocalHash != "" {
+			conflict.BaseFile = &interfaces.File{
+				Path:         sides.localPath,
+				Hash:         sides.ancestor.LocalHash,
+				ModifiedTime: sides.ancestor.LastSyncTime,
+			}
+		}
+
+		remoteCopy := filepath.Join(filepath.Dir(sides.localPath), "."+filepath.Base(sides.localPath)+".remote.tmp")
+		if _, err := s.download(ctx, sides.remotePath, remoteCopy, sides.remote.ModifiedTime); err == nil {
+			conflict.RemoteFile.LocalPath = remoteCopy
+			defer os.Remove(remoteCopy)
+		} else {
+			s.logger.Warn("Failed to fetch remote version of conflict",
+				zap.String("path", sides.remotePath),
+				zap.Error(err),
+			)
+		}
+	}
+
+	resolution, err := s.ResolveConflict(ctx, &conflict)
+	if err != nil {
+		return err
+	}
+	conflict.Resolution = *resolution
+	result.Conflicts = append(result.Conflicts, conflict)
+
+	switch resolution.Strategy {
+	case interfaces.ResolutionKeepLocal:
+		return s.push(ctx, sides, result)
+
+	case interfaces.ResolutionKeepRemote:
+		return s.pull(ctx, sides, result)
+
+	case interfaces.ResolutionKeepBoth:
+		// With one side deleted, keeping both means keeping the survivor
+		if !sides.localExists {
+			return s.pull(ctx, sides, result)
+		}
+		if sides.remote == nil {
+			return s.push(ctx, sides, result)
+		}
+
+		// The resolver may have saved the remote version already
+		if utils.PathExists(resolution.BackupPath) {
+			return s.push(ctx, sides, result)
+		}
+
+		// Save the remote version beside the local one, then push local
+		written, err := s.download(ctx, sides.remotePath, resolution.BackupPath, sides.remote.ModifiedTime)
+		if err != nil {
+			return err
+		}
+		result.FilesDownloaded++
+		result.BytesTransferred += written
+		return s.push(ctx, sides, result)
+
+	case interfaces.ResolutionMerge:
+		// Hunks left with conflict markers need a person to finish the merge;
+		// the path is skipped until the markers are gone
+		if len(resolution.MergeConflicts) > 0 {
+			s.recordMergeConflict(ctx, sides)
+			result.FilesSkipped++
+			return nil
+		}
+
+		// The local file now holds the merged content
+		sides.localHash = ""
+		return s.push(ctx, sides, result)
+
+	default:
+		// Leave both sides untouched until the conflict is resolved
+		s.recordConflict(ctx, sides)
+		result.FilesSkipped++
+		return nil
+	}
+}
+
+// needsContent checks whether the resolver needs file contents for a conflict
+func (s *PulsePointTwoWayStrategy) needsContent(conflict interfaces.Conflict) bool {
+	if s.resolver == nil || conflict.LocalFile == nil || conflict.RemoteFile == nil {
+		return false
+	}
+
+	switch s.config.ConflictResolution {
+	case interfaces.ResolutionMerge, interfaces.ResolutionInteractive:
+		return utils.IsMergeable(conflict.Path)
+	default:
+		return false
+	}
+}
+
+// moveRemote applies a local rename to the remote
+func (s *PulsePointTwoWayStrategy) moveRemote(
+	ctx context.Context,
+	source, destination string,
+	change interfaces.ChangeEvent,
+	result *interfaces.SyncResult,
+) error {
+	oldRemotePath := utils.RemotePath(source, destination, change.OldPath)
+	newRemotePath := utils.RemotePath(source, destination, change.Path)
+
+	old := s.inspect(ctx, change.OldPath, oldRemotePath)
+	if old.remote != nil && !old.remoteChanged {
+		if err := s.provider.Move(ctx, oldRemotePath, newRemotePath); err == nil {
+			s.logger.Debug("File moved successfully",
+				zap.String("from", oldRemotePath),
+				zap.String("to", newRemotePath),
+			)
+			s.deleteState(ctx, change.OldPath)
+			s.recordSynced(ctx, twoWaySides{
+				localPath:   change.Path,
+				remotePath:  newRemotePath,
+				localHash:   change.Hash,
+				localExists: true,
+				ancestor:    old.ancestor,
+			})
+			return nil
+		}
+	}
+
+	// The remote copy changed or is missing; keep it and sync the new path
+	sides := s.inspect(ctx, change.Path, newRemotePath)
+	sides.localExists = true
+	sides.localHash = change.Hash
+	sides.localChanged = true
+	return s.reconcile(ctx, sides, result)
+}
+
+// moveLocal applies a remote rename to the local side. It reports false when
+// the local file changed or is missing and the new path must be synced instead.
+func (s *PulsePointTwoWayStrategy) moveLocal(
+	ctx context.Context,
+	oldLocalPath, newLocalPath string,
+	oldRemotePath, newRemotePath string,
+	result *interfaces.SyncResult,
+) (bool, error) {
+	old := s.inspect(ctx, oldLocalPath, oldRemotePath)
+	s.inspectLocal(&old)
+	if !old.localExists || old.localChanged || utils.PathExists(newLocalPath) {
+		return false, nil
+	}
+
+	if err := utils.EnsureDir(filepath.Dir(newLocalPath)); err != nil {
+		return false, pperrors.NewSyncError(
+			fmt.Sprintf("failed to create directory for %s", newLocalPath),
+			err,
+		)
+	}
+	if err := os.Rename(oldLocalPath, newLocalPath); err != nil {
+		return false, pperrors.NewSyncError(
+			fmt.Sprintf("failed to move %s", oldLocalPath),
+			err,
+		)
+	}
+
+	s.logger.Debug("File moved locally",
+		zap.String("from", oldLocalPath),
+		zap.String("to", newLocalPath),
+	)
+
+	s.deleteState(ctx, oldLocalPath)
+	s.recordSynced(ctx, twoWaySides{
+		localPath:   newLocalPath,
+		remotePath:  newRemotePath,
+		localHash:   old.localHash,
+		localExists: true,
+		ancestor:    old.ancestor,
+	})
+	return true, nil
+}
+
+// syncLocalFolder mirrors a local folder change to the remote
+func (s *PulsePointTwoWayStrategy) syncLocalFolder(
+	ctx context.Context,
+	change interfaces.ChangeEvent,
+	remotePath string,
+	result *interfaces.SyncResult,
+) error {
+	switch change.Type {
+	case interfaces.ChangeTypeCreate:
+		if err := s.provider.CreateFolder(ctx, remotePath); err != nil {
+			return pperrors.NewSyncError(
+				fmt.Sprintf("failed to create folder %s", remotePath),
+				err,
+			)
+		}
+		return nil
+
+	case interfaces.ChangeTypeDelete, interfaces.ChangeTypeRename, interfaces.ChangeTypeMove:
+		if s.config.PreserveDeleted {
+			result.FilesSkipped++
+			return nil
+		}
+		// Only remove remote folders that have nothing left in them
+		children, err := s.provider.List(ctx, remotePath)
+		if err != nil || len(children) > 0 {
+			result.FilesSkipped++
+			return nil
+		}
+		if err := s.provider.Delete(ctx, remotePath); err != nil && !pperrors.IsNotFoundError(err) {
+			return pperrors.NewSyncError(
+				fmt.Sprintf("failed to delete folder %s", remotePath),
+				err,
+			)
+		}
+		result.FilesDeleted++
+		return nil
+
+	default:
+		result.FilesSkipped++
+		return nil
+	}
+}
+
+// syncRemoteFolder mirrors a remote folder change to the local side
+func (s *PulsePointTwoWayStrategy) syncRemoteFolder(
+	change interfaces.ChangeEvent,
+	localPath string,
+	result *interfaces.SyncResult,
+) error {
+	switch change.Type {
+	case interfaces.ChangeTypeCreate:
+		if err := utils.EnsureDir(localPath); err != nil {
+			return pperrors.NewSyncError(
+				fmt.Sprintf("failed to create folder %s", localPath),
+				err,
+			)
+		}
+		return nil
+
+	case interfaces.ChangeTypeDelete:
+		if s.config.PreserveDeleted {
+			result.FilesSkipped++
+			return nil
+		}
+		// os.Remove refuses non-empty folders, which keeps unsynced local files
+		if err := os.Remove(localPath); err == nil {
+			result.FilesDeleted++
+		} else {
+			result.FilesSkipped++
+		}
+		return nil
+
+	default:
+		result.FilesSkipped++
+		return nil
+	}
+}
+
+// inspect loads the common ancestor and the remote side of a path
+func (s *PulsePointTwoWayStrategy) inspect(ctx context.Context, localPath, remotePath string) twoWaySides {
+	sides := twoWaySides{
+		localPath:  localPath,
+		remotePath: remotePath,
+	}
+
+	if s.stateManager != nil {
+		if state, err := s.stateManager.GetFileState(ctx, localPath); err == nil {
+			sides.ancestor = state
+		}
+	}
+
+	if meta, err := s.provider.GetMetadata(ctx, remotePath); err == nil && meta != nil && !meta.IsFolder {
+		sides.remote = meta
+	}
+
+	sides.remoteChanged = s.remoteChanged(sides.ancestor, sides.remote)
+	return sides
+}
+
+// inspectLocal fills in the local side of a path by hashing the file on disk
+func (s *PulsePointTwoWayStrategy) inspectLocal(sides *twoWaySides) {
+	info, err := os.Stat(sides.localPath)
+	sides.localExists = err == nil && !info.IsDir()
+
+	if !sides.localExists {
+		sides.localChanged = sides.ancestor != nil && sides.ancestor.LocalHash != ""
+		return
+	}
+	if sides.ancestor == nil {
+		sides.localChanged = true
+		return
+	}
+
+	hash, err := utils.HashFileWith(sides.localPath, s.hashAlgorithm(sides.ancestor))
+	if err != nil {
+		sides.localChanged = true
+		return
+	}
+	sides.localHash = hash
+	sides.localChanged = hash != sides.ancestor.LocalHash
+}
+
+// remoteChanged checks whether the remote side differs from the ancestor
+func (s *PulsePointTwoWayStrategy) remoteChanged(ancestor *interfaces.FileState, remote *interfaces.Metadata) bool {
+	if ancestor == nil {
+		return remote != nil
+	}
+	if remote == nil {
+		// Only a deletion if the remote copy existed at the last sync
+		return ancestor.RemoteHash != "" || ancestor.RemoteID != ""
+	}
+	if ancestor.RemoteHash != "" && remote.Hash != "" {
+		return remote.Hash != ancestor.RemoteHash
+	}
+	if !ancestor.RemoteModTime.IsZero() {
+		return remote.ModifiedTime.After(ancestor.RemoteModTime)
+	}
+	return remote.ModifiedTime.After(ancestor.LastSyncTime)
+}
+
+// sameContent checks whether the local file matches the remote checksum
+func (s *PulsePointTwoWayStrategy) sameContent(sides twoWaySides) bool {
+	remote := sides.remote
+
+	// Some providers record the local hash the remote file was uploaded from
+	if recorded, ok := remote.Attributes[interfaces.AttributeLocalHash].(string); ok && recorded != "" {
+		if sides.localHash == recorded {
+			return true
+		}
+		algorithm, _ := remote.Attributes[interfaces.AttributeHashAlgorithm].(string)
+		hash, err := utils.HashFileWith(sides.localPath, algorithm)
+		if err == nil && hash == recorded {
+			return true
+		}
+	}
+
+	// Providers report MD5 checksums
+	if len(remote.Hash) != 32 {
+		return false
+	}
+	hash, err := utils.FileHash(sides.localPath)
+	return err == nil && hash == remote.Hash
+}
+
+// download writes a remote file to a local path atomically
+func (s *PulsePointTwoWayStrategy) download(
+	ctx context.Context,
+	remotePath, localPath string,
+	modTime time.Time,
+) (int64, error) {
+	// Providers that write the file themselves can resume partial downloads
+	if downloader, ok := s.provider.(interfaces.FileDownloader); ok {
+		file, err := downloader.DownloadFile(ctx, remotePath, localPath)
+		if err != nil {
+			return 0, pperrors.NewSyncError(
+				fmt.Sprintf("failed to download %s", remotePath),
+				err,
+			)
+		}
+		if !modTime.IsZero() {
+			_ = os.Chtimes(localPath, modTime, modTime)
+		}
+		return file.Size, nil
+	}
+
+	file, err := s.provider.Download(ctx, remotePath)
+	if err != nil {
+		return 0, pperrors.NewSyncError(
+			fmt.Sprintf("failed to download %s", remotePath),
+			err,
+		)
+	}
+	if closer, ok := file.Content.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	dir := filepath.Dir(localPath)
+	if err := utils.EnsureDir(dir); err != nil {
+		return 0, pperrors.NewSyncError(
+			fmt.Sprintf("failed to create directory %s", dir),
+			err,
+		)
+	}
+
+	// The watchers never sync hidden .tmp names, see ignore.IsTempName
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(localPath)+".*.tmp")
+	if err != nil {
+		return 0, pperrors.NewSyncError("failed to create temp file", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, file.Content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, pperrors.NewSyncError(
+			fmt.Sprintf("failed to write %s", localPath),
+			err,
+		)
+	}
+
+	if !modTime.IsZero() {
+		_ = os.Chtimes(tmp.Name(), modTime, modTime)
+	}
+
+	if err := os.Rename(tmp.Name(), localPath); err != nil {
+		return 0, pperrors.NewSyncError(
+			fmt.Sprintf("failed to replace %s", localPath),
+			err,
+		)
+	}
+
+	return written, nil
+}
+
+// recordSynced stores the new common ancestor for a path
+func (s *PulsePointTwoWayStrategy) recordSynced(ctx context.Context, sides twoWaySides) {
+	if s.stateManager == nil {
+		return
+	}
+
+	state := s.newState(sides)
+	delete(state.Metadata, metadataMergedRemoteHash)
+	state.Status = interfaces.FileSyncStatusSynced
+	state.LastSyncTime = time.Now()
+	state.LastError = ""
+	state.RetryCount = 0
+	state.Version++
+
+	if info, err := os.Stat(sides.localPath); err == nil {
+		state.Size = info.Size()
+		state.LocalModTime = info.ModTime()
+	}
+
+	state.LocalHash = sides.localHash
+	if state.LocalHash == "" {
+		if hash, err := utils.HashFileWith(sides.localPath, s.hashAlgorithm(sides.ancestor)); err == nil {
+			state.LocalHash = hash
+		}
+	}
+
+	if meta, err := s.provider.GetMetadata(ctx, sides.remotePath); err == nil && meta != nil {
+		state.RemoteHash = meta.Hash
+		state.RemoteModTime = meta.ModifiedTime
+		state.RemoteID = meta.ID
+	}
+
+	s.saveState(ctx, state)
+}
+
+// recordConflict marks a path as conflicted without moving its ancestor
+func (s *PulsePointTwoWayStrategy) recordConflict(ctx context.Context, sides twoWaySides) {
+	if s.stateManager == nil {
+		return
+	}
+
+	state := s.newState(sides)
+	delete(state.Metadata, metadataMergedRemoteHash)
+	state.Status = interfaces.FileSyncStatusConflict
+	s.saveState(ctx, state)
+}
+
+// recordMergeConflict marks a path as conflicted by a merge that left
+// conflict markers in the local file
+func (s *PulsePointTwoWayStrategy) recordMergeConflict(ctx context.Context, sides twoWaySides) {
+	if s.stateManager == nil {
+		return
+	}
+
+	state := s.newState(sides)
+	state.Status = interfaces.FileSyncStatusConflict
+	state.Metadata[metadataMergedRemoteHash] = sides.remote.Hash
+	s.saveState(ctx, state)
+}
+
+// pendingMerge reports whether the local file holds a merge left with
+// conflict markers, and the remote hash it was merged against
+func (s *PulsePointTwoWayStrategy) pendingMerge(sides twoWaySides) (string, bool) {
+	if !sides.localExists || sides.ancestor == nil || sides.ancestor.Status != interfaces.FileSyncStatusConflict {
+		return "", false
+	}
+	merged, ok := sides.ancestor.Metadata[metadataMergedRemoteHash].(string)
+	return merged, ok
+}
+
+// newState copies the ancestor so it can be updated
+func (s *PulsePointTwoWayStrategy) newState(sides twoWaySides) *interfaces.FileState {
+	state := &interfaces.FileState{Path: sides.localPath}
+	if sides.ancestor != nil {
+		copied := *sides.ancestor
+		copied.Path = sides.localPath
+		state = &copied
+	}
+
+	metadata := make(map[string]interface{}, len(state.Metadata)+1)
+	for k, v := range state.Metadata {
+		metadata[k] = v
+	}
+	metadata["remote_path"] = sides.remotePath
+	state.Metadata = metadata
+
+	return state
+}
+
+// saveState writes a file state, logging failures
+func (s *PulsePointTwoWayStrategy) saveState(ctx context.Context, state *interfaces.FileState) {
+	if err := s.stateManager.UpdateFileState(ctx, state); err != nil {
+		s.logger.Warn("Failed to update file state",
+			zap.String("path", state.Path),
+			zap.Error(err),
+		)
+	}
+}
+
+// deleteState removes the stored ancestor for a path
+func (s *PulsePointTwoWayStrategy) deleteState(ctx context.Context, localPath string) {
+	if s.stateManager == nil {
+		return
+	}
+	if err := s.stateManager.DeleteFileState(ctx, localPath); err != nil {
+		s.logger.Debug("Failed to delete file state",
+			zap.String("path", localPath),
+			zap.Error(err),
+		)
+	}
+}
+
+// hashAlgorithm picks the algorithm the ancestor's local hash was made with
+func (s *PulsePointTwoWayStrategy) hashAlgorithm(ancestor *interfaces.FileState) string {
+	if ancestor != nil && len(ancestor.LocalHash) == 32 {
+		return "md5"
+	}
+	if algorithm, ok := s.config.CustomSettings["hash_algorithm"].(string); ok {
+		return algorithm
+	}
+	return "sha256"
+}
+
+// ResolveConflict handles conflict resolution
+func (s *PulsePointTwoWayStrategy) ResolveConflict(
+	ctx context.Context,
+	conflict *interfaces.Conflict,
+) (*interfaces.ConflictResolution, error) {
+	if s.resolver != nil {
+		return s.resolver.ResolveConflict(ctx, conflict)
+	}
+
+	resolution := &interfaces.ConflictResolution{
+		Strategy:   s.config.ConflictResolution,
+		ResolvedAt: time.Now().UnixNano(),
+		Manual:     false,
+	}
+
+	switch resolution.Strategy {
+	case interfaces.ResolutionKeepLocal:
+		resolution.Winner = "local"
+	case interfaces.ResolutionKeepRemote:
+		resolution.Winner = "remote"
+	case interfaces.ResolutionKeepBoth:
+		dir := filepath.Dir(conflict.Path)
+		base := filepath.Base(conflict.Path)
+		ext := filepath.Ext(base)
+		nameWithoutExt := base[:len(base)-len(ext)]
+		timestamp := time.Now().Format("20060102_150405")
+
+		resolution.ResolvedPath = conflict.Path
+		resolution.BackupPath = filepath.Join(dir, fmt.Sprintf("%s_remote_%s%s", nameWithoutExt, timestamp, ext))
+	}
+
+	s.logger.Debug("Conflict resolved",
+		zap.String("path", conflict.Path),
+		zap.String("type", string(conflict.Type)),
+		zap.String("strategy", string(resolution.Strategy)),
+	)
+
+	return resolution, nil
+}
+
+// ValidateSync validates if sync can be performed
+func (s *PulsePointTwoWayStrategy) ValidateSync(
+	ctx context.Context,
+	source, destination string,
+) error {
+	// Check if source exists and is accessible
+	if source == "" {
+		return pperrors.NewValidationError("source path is required", nil)
+	}
+
+	// Check if provider is initialized
+	if s.provider == nil {
+		return pperrors.NewValidationError("cloud provider not initialized", nil)
+	}
+
+	// The file state is the common ancestor for every decision
+	if s.stateManager == nil {
+		return pperrors.NewValidationError("two-way sync requires a state manager", nil)
+	}
+
+	return nil
+}
+
+// GetDirection returns the sync direction
+func (s *PulsePointTwoWayStrategy) GetDirection() interfaces.SyncDirection {
+	return interfaces.SyncDirectionTwoWay
+}
+
+// SupportsResume checks if the strategy supports resuming interrupted syncs
+func (s *PulsePointTwoWayStrategy) SupportsResume() bool {
+	return true
+}
+
+// GetConfiguration returns the strategy configuration
+func (s *PulsePointTwoWayStrategy) GetConfiguration() interfaces.StrategyConfig {
+	return s.config
+}
@@ -0,0 +1,386 @@
+package strategies
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"github.com/pulsepoint/pulsepoint/internal/providers/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memStateManager keeps file state in memory. Methods the strategy does not
+// use are left to the embedded nil interface.
+type memStateManager struct {
+	interfaces.StateManager
+	states map[string]*interfaces.FileState
+}
+
+func newMemStateManager() *memStateManager {
+	return &memStateManager{states: make(map[string]*interfaces.FileState)}
+}
+
+func (m *memStateManager) GetFileState(ctx context.Context, path string) (*interfaces.FileState, error) {
+	state, ok := m.states[path]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	copied := *state
+	return &copied, nil
+}
+
+func (m *memStateManager) UpdateFileState(ctx context.Context, state *interfaces.FileState) error {
+	copied := *state
+	m.states[state.Path] = &copied
+	return nil
+}
+
+func (m *memStateManager) DeleteFileState(ctx context.Context, path string) error {
+	delete(m.states, path)
+	return nil
+}
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func md5Hex(content string) string {
+	sum := md5.Sum([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// twoWayFixture is a local folder and a mock remote that were last synced
+// holding "v1" at notes.txt
+type twoWayFixture struct {
+	strategy  *PulsePointTwoWayStrategy
+	provider  *mock.MockDriveProvider
+	states    *memStateManager
+	localRoot string
+	localPath string
+}
+
+const twoWayRemotePath = "/remote/notes.txt"
+
+func newTwoWayFixture(t *testing.T, resolution interfaces.ResolutionStrategy) *twoWayFixture {
+	ctx := context.Background()
+	f := &twoWayFixture{
+		provider:  mock.NewMockDriveProvider(),
+		states:    newMemStateManager(),
+		localRoot: t.TempDir(),
+	}
+	require.NoError(t, f.provider.Initialize(interfaces.ProviderConfig{}))
+	f.localPath = filepath.Join(f.localRoot, "notes.txt")
+
+	f.writeLocal(t, "v1")
+	f.writeRemote(t, "v1")
+	f.states.states[f.localPath] = &interfaces.FileState{
+		Path:         f.localPath,
+		LocalHash:    sha256Hex("v1"),
+		RemoteHash:   md5Hex("v1"),
+		RemoteID:     "file-" + twoWayRemotePath,
+		Status:       interfaces.FileSyncStatusSynced,
+		LastSyncTime: time.Now(),
+		Metadata:     map[string]interface{}{"remote_path": twoWayRemotePath},
+	}
+
+	f.strategy = NewPulsePointTwoWayStrategy(f.provider, f.states, zap.NewNop(), &interfaces.StrategyConfig{
+		ConflictResolution: resolution,
+		CustomSettings:     map[string]interface{}{"hash_algorithm": "sha256"},
+	})
+	require.NoError(t, f.strategy.ValidateSync(ctx, f.localRoot, "/remote"))
+	return f
+}
+
+func (f *twoWayFixture) writeLocal(t *testing.T, content string) {
+	require.NoError(t, os.WriteFile(f.localPath, []byte(content), 0644))
+}
+
+func (f *twoWayFixture) writeRemote(t *testing.T, content string) {
+	require.NoError(t, f.provider.Upload(context.Background(), &interfaces.File{
+		Path:    twoWayRemotePath,
+		Content: strings.NewReader(content),
+	}))
+}
+
+// local returns the local content, or "" when the file is gone
+func (f *twoWayFixture) local(t *testing.T) string {
+	data, err := os.ReadFile(f.localPath)
+	if os.IsNotExist(err) {
+		return ""
+	}
+	require.NoError(t, err)
+	return string(data)
+}
+
+// remote returns the remote content, or "" when the file is gone
+func (f *twoWayFixture) remote(t *testing.T) string {
+	file, err := f.provider.Download(context.Background(), twoWayRemotePath)
+	if err != nil {
+		return ""
+	}
+	data := new(strings.Builder)
+	_, err = io.Copy(data, file.Content)
+	require.NoError(t, err)
+	return data.String()
+}
+
+func TestTwoWayStrategySync(t *testing.T) {
+	tests := []struct {
+		name       string
+		resolution interfaces.ResolutionStrategy
+		setup      func(t *testing.T, f *twoWayFixture) interfaces.ChangeEvent
+
+		wantLocal    string
+		wantRemote   string
+		wantResult   interfaces.SyncResult
+		wantConflict interfaces.ConflictType
+		wantState    interfaces.FileSyncStatus // empty when the state is removed
+	}{
+		{
+			name: "pull remote modify",
+			setup: func(t *testing.T, f *twoWayFixture) interfaces.ChangeEvent {
+				f.writeRemote(t, "v2 remote")
+				return interfaces.ChangeEvent{Type: interfaces.ChangeTypeModify, Path: twoWayRemotePath, Source: "remote"}
+			},
+			wantLocal:  "v2 remote",
+			wantRemote: "v2 remote",
+			wantResult: interfaces.SyncResult{FilesDownloaded: 1},
+			wantState:  interfaces.FileSyncStatusSynced,
+		},
+		{
+			name: "push local modify",
+			setup: func(t *testing.T, f *twoWayFixture) interfaces.ChangeEvent {
+				f.writeLocal(t, "v2 local")
+				return interfaces.ChangeEvent{Type: interfaces.ChangeTypeModify, Path: f.localPath, Hash: sha256Hex("v2 local")}
+			},
+			wantLocal:  "v2 local",
+			wantRemote: "v2 local",
+			wantResult: interfaces.SyncResult{FilesUploaded: 1},
+			wantState:  interfaces.FileSyncStatusSynced,
+		},
+		{
+			name: "unchanged local hash is skipped",
+			setup: func(t *testing.T, f *twoWayFixture) interfaces.ChangeEvent {
+				return interfaces.ChangeEvent{Type: interfaces.ChangeTypeModify, Path: f.localPath, Hash: sha256Hex("v1")}
+			},
+			wantLocal:  "v1",
+			wantRemote: "v1",
+			wantResult: interfaces.SyncResult{FilesSkipped: 1},
+			wantState:  interfaces.FileSyncStatusSynced,
+		},
+		{
+			name: "remote delete",
+			setup: func(t *testing.T, f *twoWayFixture) interfaces.ChangeEvent {
+				require.NoError(t, f.provider.Delete(context.Background(), twoWayRemotePath))
+				return interfaces.ChangeEvent{Type: interfaces.ChangeTypeDelete, Path: twoWayRemotePath, Source: "remote"}
+			},
+			wantResult: interfaces.SyncResult{FilesDeleted: 1},
+		},
+		{
+			name: "local delete",
+			setup: func(t *testing.T, f *twoWayFixture) interfaces.ChangeEvent {
+				require.NoError(t, os.Remove(f.localPath))
+				return interfaces.ChangeEvent{Type: interfaces.ChangeTypeDelete, Path: f.localPath}
+			},
+			wantResult: interfaces.SyncResult{FilesDeleted: 1},
+		},
+		{
+			name: "deleted on both sides",
+			setup: func(t *testing.T, f *twoWayFixture) interfaces.ChangeEvent {
+				require.NoError(t, os.Remove(f.localPath))
+				require.NoError(t, f.provider.Delete(context.Background(), twoWayRemotePath))
+				return interfaces.ChangeEvent{Type: interfaces.ChangeTypeDelete, Path: f.localPath}
+			},
+			wantResult: interfaces.SyncResult{FilesSkipped: 1},
+		},
+		{
+			name:       "local delete against remote modify is a conflict",
+			resolution: interfaces.ResolutionSkip,
+			setup: func(t *testing.T, f *twoWayFixture) interfaces.ChangeEvent {
+				require.NoError(t, os.Remove(f.localPath))
+				f.writeRemote(t, "v2 remote")
+				return interfaces.ChangeEvent{Type: interfaces.ChangeTypeDelete, Path: f.localPath}
+			},
+			wantRemote:   "v2 remote",
+			wantResult:   interfaces.SyncResult{FilesSkipped: 1},
+			wantConflict: interfaces.ConflictTypeDeleted,
+			wantState:    interfaces.FileSyncStatusConflict,
+		},
+		{
+			name:       "remote delete against local modify keeps the local edit",
+			resolution: interfaces.ResolutionKeepBoth,
+			setup: func(t *testing.T, f *twoWayFixture) interfaces.ChangeEvent {
+				f.writeLocal(t, "v2 local")
+				require.NoError(t, f.provider.Delete(context.Background(), twoWayRemotePath))
+				return interfaces.ChangeEvent{Type: interfaces.ChangeTypeDelete, Path: twoWayRemotePath, Source: "remote"}
+			},
+			wantLocal:    "v2 local",
+			wantRemote:   "v2 local",
+			wantResult:   interfaces.SyncResult{FilesUploaded: 1},
+			wantConflict: interfaces.ConflictTypeDeleted,
+			wantState:    interfaces.FileSyncStatusSynced,
+		},
+		{
+			name:       "modified on both sides takes the configured side",
+			resolution: interfaces.ResolutionKeepRemote,
+			setup: func(t *testing.T, f *twoWayFixture) interfaces.ChangeEvent {
+				f.writeLocal(t, "v2 local")
+				f.writeRemote(t, "v2 remote")
+				return interfaces.ChangeEvent{Type: interfaces.ChangeTypeModify, Path: f.localPath, Hash: sha256Hex("v2 local")}
+			},
+			wantLocal:    "v2 remote",
+			wantRemote:   "v2 remote",
+			wantResult:   interfaces.SyncResult{FilesDownloaded: 1},
+			wantConflict: interfaces.ConflictTypeModified,
+			wantState:    interfaces.FileSyncStatusSynced,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolution := tt.resolution
+			if resolution == "" {
+				resolution = interfaces.ResolutionSkip
+			}
+			f := newTwoWayFixture(t, resolution)
+			change := tt.setup(t, f)
+
+			result, err := f.strategy.Sync(context.Background(), f.localRoot, "/remote", []interfaces.ChangeEvent{change})
+			require.NoError(t, err)
+			require.Empty(t, result.Errors)
+
+			assert.Equal(t, tt.wantLocal, f.local(t))
+			assert.Equal(t, tt.wantRemote, f.remote(t))
+			assert.Equal(t, tt.wantResult.FilesUploaded, result.FilesUploaded, "uploaded")
+			assert.Equal(t, tt.wantResult.FilesDownloaded, result.FilesDownloaded, "downloaded")
+			assert.Equal(t, tt.wantResult.FilesDeleted, result.FilesDeleted, "deleted")
+			assert.Equal(t, tt.wantResult.FilesSkipped, result.FilesSkipped, "skipped")
+
+			if tt.wantConflict == "" {
+				assert.Empty(t, result.Conflicts)
+			} else {
+				require.Len(t, result.Conflicts, 1)
+				assert.Equal(t, tt.wantConflict, result.Conflicts[0].Type)
+			}
+
+			state, ok := f.states.states[f.localPath]
+			if tt.wantState == "" {
+				assert.False(t, ok, "state is removed")
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, tt.wantState, state.Status)
+			if tt.wantState == interfaces.FileSyncStatusSynced {
+				assert.Equal(t, sha256Hex(tt.wantLocal), state.LocalHash, "the new ancestor holds the local hash")
+				assert.Equal(t, md5Hex(tt.wantRemote), state.RemoteHash, "the new ancestor holds the remote hash")
+			}
+		})
+	}
+}
+
+func TestTwoWayStrategyRemoteChanged(t *testing.T) {
+	synced := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name     string
+		ancestor *interfaces.FileState
+		remote   *interfaces.Metadata
+		want     bool
+	}{
+		{
+			name: "never synced and missing",
+		},
+		{
+			name:   "never synced and present",
+			remote: &interfaces.Metadata{Hash: "a"},
+			want:   true,
+		},
+		{
+			name:     "same remote hash",
+			ancestor: &interfaces.FileState{RemoteHash: "a", LastSyncTime: synced},
+			remote:   &interfaces.Metadata{Hash: "a", ModifiedTime: time.Now()},
+		},
+		{
+			name:     "different remote hash",
+			ancestor: &interfaces.FileState{RemoteHash: "a", LastSyncTime: synced},
+			remote:   &interfaces.Metadata{Hash: "b", ModifiedTime: synced.Add(-time.Hour)},
+			want:     true,
+		},
+		{
+			name:     "remote deleted after sync",
+			ancestor: &interfaces.FileState{RemoteHash: "a"},
+			want:     true,
+		},
+		{
+			name:     "remote never uploaded",
+			ancestor: &interfaces.FileState{LocalHash: "a"},
+		},
+		{
+			name:     "no hash, modified after recorded time",
+			ancestor: &interfaces.FileState{RemoteModTime: synced, LastSyncTime: time.Now()},
+			remote:   &interfaces.Metadata{ModifiedTime: synced.Add(time.Minute)},
+			want:     true,
+		},
+		{
+			name:     "no hash, recorded time unchanged",
+			ancestor: &interfaces.FileState{RemoteModTime: synced},
+			remote:   &interfaces.Metadata{ModifiedTime: synced},
+		},
+		{
+			name:     "no hash or recorded time, modified after last sync",
+			ancestor: &interfaces.FileState{LastSyncTime: synced},
+			remote:   &interfaces.Metadata{ModifiedTime: time.Now()},
+			want:     true,
+		},
+		{
+			name:     "no hash or recorded time, modified before last sync",
+			ancestor: &interfaces.FileState{LastSyncTime: synced},
+			remote:   &interfaces.Metadata{ModifiedTime: synced.Add(-time.Minute)},
+		},
+	}
+
+	strategy := NewPulsePointTwoWayStrategy(mock.NewMockDriveProvider(), newMemStateManager(), zap.NewNop(), nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, strategy.remoteChanged(tt.ancestor, tt.remote))
+		})
+	}
+}
+
+func TestTwoWayStrategyLocalChanged(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.txt")
+	require.NoError(t, os.WriteFile(path, []byte("v1"), 0644))
+
+	tests := []struct {
+		name     string
+		ancestor *interfaces.FileState
+		want     bool
+	}{
+		{name: "never synced", want: true},
+		{name: "same sha256", ancestor: &interfaces.FileState{LocalHash: sha256Hex("v1")}},
+		{name: "same md5 from an older sync", ancestor: &interfaces.FileState{LocalHash: md5Hex("v1")}},
+		{name: "different hash", ancestor: &interfaces.FileState{LocalHash: sha256Hex("v0")}, want: true},
+	}
+
+	strategy := NewPulsePointTwoWayStrategy(mock.NewMockDriveProvider(), newMemStateManager(), zap.NewNop(), nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sides := twoWaySides{localPath: path, ancestor: tt.ancestor}
+			strategy.inspectLocal(&sides)
+			assert.True(t, sides.localExists)
+			assert.Equal(t, tt.want, sides.localChanged)
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package sync
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	pperrors "github.com/pulsepoint/pulsepoint/pkg/errors"
+	"github.com/pulsepoint/pulsepoint/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// DefaultMaxBaseSize is the largest file kept as a merge base
+const DefaultMaxBaseSize = 10 * 1024 * 1024 // 10MB
+
+// PulsePointBaseStore keeps the last synced content of mergeable files,
+// addressed by content hash, so conflicts can be merged against an ancestor
+type PulsePointBaseStore struct {
+	dir         string
+	maxFileSize int64
+	logger      *zap.Logger
+
+	// collectMu keeps collection from removing a base while the file state
+	// referencing it is being saved
+	collectMu sync.RWMutex
+}
+
+// NewPulsePointBaseStore creates a base store rooted at dir
+func NewPulsePointBaseStore(dir string, maxFileSize int64, logger *zap.Logger) *PulsePointBaseStore {
+	if maxFileSize <= 0 {
+		maxFileSize = DefaultMaxBaseSize
+	}
+
+	return &PulsePointBaseStore{
+		dir:         dir,
+		maxFileSize: maxFileSize,
+		logger:      logger.With(zap.String("component", "base_store")),
+	}
+}
+
+// Put stores the content of a local file under its hash. Files that are not
+// mergeable, too large, or no longer match the hash are skipped.
+func (s *PulsePointBaseStore) Put(ctx context.Context, localPath, contentHash string) error {
+	if contentHash == "" || !utils.IsMergeable(localPath) || s.Has(contentHash) {
+		return nil
+	}
+
+	hasher := newContentHasher(contentHash)
+	if hasher == nil {
+		return nil
+	}
+
+	source, err := os.Open(localPath)
+	if err != nil {
+		return pperrors.NewFileSystemError(fmt.Sprintf("failed to open %s", localPath), err)
+	}
+	defer source.Close()
+
+	info, err := source.Stat()
+	if err != nil {
+		return pperrors.NewFileSystemError(fmt.Sprintf("failed to stat %s", localPath), err)
+	}
+	if info.Size() > s.maxFileSize {
+		return nil
+	}
+
+	target := s.Path(contentHash)
+	if err := utils.EnsureDir(filepath.Dir(target)); err != nil {
+		return pperrors.NewFileSystemError("failed to create base store directory", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".base.*.tmp")
+	if err != nil {
+		return pperrors.NewFileSystemError("failed to create base file", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(io.MultiWriter(tmp, hasher), source)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return pperrors.NewFileSystemError("failed to write base file", err)
+	}
+
+	// The file changed after it was hashed; its synced content is gone
+	if hex.EncodeToString(hasher.Sum(nil)) != contentHash {
+		s.logger.Debug("Skipping merge base for changed file", zap.String("path", localPath))
+		return nil
+	}
+
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return pperrors.NewFileSystemError("failed to store base file", err)
+	}
+
+	s.logger.Debug("Stored merge base",
+		zap.String("path", localPath),
+		zap.String("hash", contentHash),
+	)
+
+	return nil
+}
+
+// Open returns the stored content for a hash
+func (s *PulsePointBaseStore) Open(contentHash string) (io.ReadCloser, error) {
+	file, err := os.Open(s.Path(contentHash))
+	if err != nil {
+		return nil, pperrors.NewFileSystemError(fmt.Sprintf("merge base %s not found", contentHash), err)
+	}
+	return file, nil
+}
+
+// Has reports whether content for a hash is stored
+func (s *PulsePointBaseStore) Has(contentHash string) bool {
+	return contentHash != "" && utils.PathExists(s.Path(contentHash))
+}
+
+// Path returns where the content for a hash is stored
+func (s *PulsePointBaseStore) Path(contentHash string) string {
+	if len(contentHash) < 2 {
+		return filepath.Join(s.dir, contentHash)
+	}
+	return filepath.Join(s.dir, contentHash[:2], contentHash)
+}
+
+// GarbageCollect removes stored content whose hash is not in keep
+func (s *PulsePointBaseStore) GarbageCollect(ctx context.Context, keep map[string]bool) (int, error) {
+	removed := 0
+
+	err := filepath.WalkDir(s.dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if entry.IsDir() || keep[entry.Name()] {
+			return nil
+		}
+
+		if err := os.Remove(path); err != nil {
+			s.logger.Warn("Failed to remove merge base",
+				zap.String("path", path),
+				zap.Error(err),
+			)
+			return nil
+		}
+		removed++
+		return nil
+	})
+	if err != nil {
+		return removed, pperrors.NewFileSystemError("failed to collect merge bases", err)
+	}
+
+	return removed, nil
+}
+
+// newContentHasher picks the hash function matching a hex digest's length
+func newContentHasher(contentHash string) hash.Hash {
+	switch len(contentHash) {
+	case md5.Size * 2:
+		return md5.New()
+	case sha256.Size * 2:
+		return sha256.New()
+	default:
+		return nil
+	}
+}
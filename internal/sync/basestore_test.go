@@ -0,0 +1,70 @@
+package sync
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pulsepoint/pulsepoint/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestBaseStoreKeepsSyncedContent(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store := NewPulsePointBaseStore(filepath.Join(dir, "bases"), 0, zap.NewNop())
+
+	localPath := filepath.Join(dir, "notes.md")
+	require.NoError(t, os.WriteFile(localPath, []byte("# Notes\n"), 0600))
+	hash, err := utils.FileSHA256(localPath)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put(ctx, localPath, hash))
+	require.True(t, store.Has(hash))
+
+	reader, err := store.Open(hash)
+	require.NoError(t, err)
+	content, err := io.ReadAll(reader)
+	reader.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "# Notes\n", string(content))
+
+	removed, err := store.GarbageCollect(ctx, map[string]bool{hash: true})
+	require.NoError(t, err)
+	assert.Equal(t, 0, removed)
+
+	removed, err = store.GarbageCollect(ctx, map[string]bool{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.False(t, store.Has(hash))
+}
+
+func TestBaseStoreSkipsUnsuitableFiles(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store := NewPulsePointBaseStore(filepath.Join(dir, "bases"), 4, zap.NewNop())
+
+	binary := filepath.Join(dir, "photo.jpg")
+	require.NoError(t, os.WriteFile(binary, []byte("jpg"), 0600))
+	binaryHash, err := utils.FileSHA256(binary)
+	require.NoError(t, err)
+
+	large := filepath.Join(dir, "large.txt")
+	require.NoError(t, os.WriteFile(large, []byte("too large"), 0600))
+	largeHash, err := utils.FileSHA256(large)
+	require.NoError(t, err)
+
+	changed := filepath.Join(dir, "changed.txt")
+	require.NoError(t, os.WriteFile(changed, []byte("new"), 0600))
+
+	require.NoError(t, store.Put(ctx, binary, binaryHash))
+	require.NoError(t, store.Put(ctx, large, largeHash))
+	require.NoError(t, store.Put(ctx, changed, largeHash))
+
+	assert.False(t, store.Has(binaryHash))
+	assert.False(t, store.Has(largeHash))
+}
@@ -0,0 +1,252 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	pperrors "github.com/pulsepoint/pulsepoint/pkg/errors"
+	"github.com/pulsepoint/pulsepoint/pkg/models"
+	"github.com/pulsepoint/pulsepoint/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// PulsePointBatchSyncer applies batches of watcher events through a sync strategy
+// and records the outcome of every event in the file state store
+type PulsePointBatchSyncer struct {
+	strategy     interfaces.SyncStrategy
+	stateManager interfaces.StateManager
+	provider     interfaces.CloudProvider
+	localRoot    string
+	remoteRoot   string
+	logger       *zap.Logger
+}
+
+// fileStateOwner is implemented by strategies that keep file state up to date themselves
+type fileStateOwner interface {
+	OwnsFileState() bool
+}
+
+// remoteCleaner is implemented by strategies that delete remote files missing locally
+type remoteCleaner interface {
+	CleanupRemote(ctx context.Context, source, destination string) (*interfaces.SyncResult, error)
+}
+
+// NewPulsePointBatchSyncer creates a new batch syncer.
+// The provider is optional and only used to record remote metadata.
+func NewPulsePointBatchSyncer(
+	strategy interfaces.SyncStrategy,
+	stateManager interfaces.StateManager,
+	provider interfaces.CloudProvider,
+	localRoot, remoteRoot string,
+	logger *zap.Logger,
+) *PulsePointBatchSyncer {
+	if remoteRoot == "" {
+		remoteRoot = "/"
+	}
+
+	return &PulsePointBatchSyncer{
+		strategy:     strategy,
+		stateManager: stateManager,
+		provider:     provider,
+		localRoot:    localRoot,
+		remoteRoot:   remoteRoot,
+		logger:       logger.With(zap.String("component", "batch_syncer")),
+	}
+}
+
+// SyncBatch runs a batch of events through the strategy. Each event is marked
+// completed or failed so the caller can decide what to retry.
+func (b *PulsePointBatchSyncer) SyncBatch(ctx context.Context, events []*models.ChangeEvent) (*interfaces.SyncResult, error) {
+	if b.strategy == nil {
+		return nil, pperrors.NewValidationError("sync strategy not configured", nil)
+	}
+
+	changes := make([]interfaces.ChangeEvent, 0, len(events))
+	pending := make([]*models.ChangeEvent, 0, len(events))
+	for _, event := range events {
+		// Permission-only changes have nothing to transfer
+		if event.Type == models.ChangeTypeChmod {
+			event.MarkProcessed()
+			continue
+		}
+
+		changes = append(changes, toStrategyEvent(event))
+		pending = append(pending, event)
+	}
+
+	if len(changes) == 0 {
+		return &interfaces.SyncResult{Success: true}, nil
+	}
+
+	result, err := b.strategy.Sync(ctx, b.localRoot, b.remoteRoot, changes)
+	if err != nil {
+		for _, event := range pending {
+			event.SetError(err)
+		}
+		return result, pperrors.NewSyncError("batch sync failed", err)
+	}
+
+	failures := make(map[string]string, len(result.Errors))
+	for _, syncErr := range result.Errors {
+		failures[syncErr.Path] = syncErr.Message
+	}
+
+	for _, event := range pending {
+		if msg, failed := failures[event.Path]; failed {
+			event.SetError(errors.New(msg))
+			b.recordFailure(ctx, event)
+			continue
+		}
+
+		event.MarkProcessed()
+		if !b.strategyOwnsState() {
+			b.recordSuccess(ctx, event)
+		}
+	}
+
+	return result, nil
+}
+
+// CleanupRemote removes remote files the pair no longer has locally, for
+// strategies that mirror the local tree. It walks both trees, so it belongs to
+// full and startup syncs rather than to every batch.
+func (b *PulsePointBatchSyncer) CleanupRemote(ctx context.Context) (*interfaces.SyncResult, error) {
+	cleaner, ok := b.strategy.(remoteCleaner)
+	if !ok {
+		return &interfaces.SyncResult{Success: true}, nil
+	}
+
+	result, err := cleaner.CleanupRemote(ctx, b.localRoot, b.remoteRoot)
+	if err != nil {
+		return nil, pperrors.NewSyncError("remote cleanup failed", err)
+	}
+	return result, nil
+}
+
+// toStrategyEvent converts a queued change to the event a strategy syncs
+func toStrategyEvent(event *models.ChangeEvent) interfaces.ChangeEvent {
+	return interfaces.ChangeEvent{
+		Type:      interfaces.ChangeType(event.Type),
+		Path:      event.Path,
+		OldPath:   event.OldPath,
+		Timestamp: event.Timestamp.UnixNano(),
+		Size:      event.Size,
+		Hash:      event.Hash,
+		IsDir:     event.IsDir,
+		Source:    event.Source,
+	}
+}
+
+// strategyOwnsState reports whether the strategy records file state itself
+func (b *PulsePointBatchSyncer) strategyOwnsState() bool {
+	owner, ok := b.strategy.(fileStateOwner)
+	return ok && owner.OwnsFileState()
+}
+
+// recordSuccess updates file state after an event was synced
+func (b *PulsePointBatchSyncer) recordSuccess(ctx context.Context, event *models.ChangeEvent) {
+	if b.stateManager == nil {
+		return
+	}
+
+	path := b.localPath(event, event.Path)
+	switch {
+	case event.IsDelete():
+		b.deleteState(ctx, path)
+		return
+	case event.IsRenameOrMove():
+		if event.OldPath == "" {
+			b.deleteState(ctx, path)
+			return
+		}
+		b.deleteState(ctx, b.localPath(event, event.OldPath))
+	}
+
+	if event.IsDir {
+		return
+	}
+
+	state := b.loadState(ctx, path)
+	if event.Source != "remote" {
+		// A remote event's hash is the provider's, not the local file's
+		state.LocalHash = event.Hash
+		state.LocalModTime = event.Timestamp
+	}
+	state.Size = event.Size
+	state.Status = interfaces.FileSyncStatusSynced
+	state.LastSyncTime = time.Now()
+	state.LastError = ""
+	state.RetryCount = 0
+	state.Version++
+
+	remotePath := utils.RemotePath(b.localRoot, b.remoteRoot, path)
+	state.Metadata["remote_path"] = remotePath
+
+	if b.provider != nil {
+		if meta, err := b.provider.GetMetadata(ctx, remotePath); err == nil && meta != nil {
+			state.RemoteHash = meta.Hash
+			state.RemoteModTime = meta.ModifiedTime
+			state.RemoteID = meta.ID
+		}
+	}
+
+	if err := b.stateManager.UpdateFileState(ctx, state); err != nil {
+		b.logger.Warn("Failed to update file state",
+			zap.String("path", path),
+			zap.Error(err),
+		)
+	}
+}
+
+// recordFailure stores the error for an event that could not be synced
+func (b *PulsePointBatchSyncer) recordFailure(ctx context.Context, event *models.ChangeEvent) {
+	if b.stateManager == nil {
+		return
+	}
+
+	path := b.localPath(event, event.Path)
+	state := b.loadState(ctx, path)
+	state.Status = interfaces.FileSyncStatusError
+	state.LastError = event.Error
+	state.RetryCount = event.Retries
+
+	if err := b.stateManager.UpdateFileState(ctx, state); err != nil {
+		b.logger.Warn("Failed to update file state",
+			zap.String("path", event.Path),
+			zap.Error(err),
+		)
+	}
+}
+
+// localPath maps a path from an event to the local path file state is
+// keyed by, which for remote events means translating the remote path
+func (b *PulsePointBatchSyncer) localPath(event *models.ChangeEvent, path string) string {
+	if event.Source == "remote" {
+		return utils.LocalPath(b.localRoot, b.remoteRoot, path)
+	}
+	return path
+}
+
+// loadState returns the stored state for a path, or a fresh one
+func (b *PulsePointBatchSyncer) loadState(ctx context.Context, path string) *interfaces.FileState {
+	state, err := b.stateManager.GetFileState(ctx, path)
+	if err != nil || state == nil {
+		state = &interfaces.FileState{Path: path}
+	}
+	if state.Metadata == nil {
+		state.Metadata = make(map[string]interface{})
+	}
+	return state
+}
+
+// deleteState removes the stored state for a path
+func (b *PulsePointBatchSyncer) deleteState(ctx context.Context, path string) {
+	if err := b.stateManager.DeleteFileState(ctx, path); err != nil {
+		b.logger.Debug("Failed to delete file state",
+			zap.String("path", path),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,158 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"github.com/pulsepoint/pulsepoint/internal/strategies"
+	"github.com/pulsepoint/pulsepoint/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestBatchSyncerUploadsToRemoteRoot(t *testing.T) {
+	mockProvider := new(MockProvider)
+	mockStateManager := new(MockStateManager)
+
+	mockProvider.On("Upload", mock.Anything, mock.MatchedBy(func(f *interfaces.File) bool {
+		return f.Path == "/Backup/docs/a.txt" && f.LocalPath == "/home/user/sync/docs/a.txt"
+	})).Return(nil)
+	mockProvider.On("GetMetadata", mock.Anything, "/Backup/docs/a.txt").
+		Return(&interfaces.Metadata{ID: "remote-1", Hash: "abc"}, nil)
+	mockStateManager.On("GetFileState", mock.Anything, "/home/user/sync/docs/a.txt").Return(nil, nil)
+	mockStateManager.On("UpdateFileState", mock.Anything, mock.MatchedBy(func(s *interfaces.FileState) bool {
+		return s.Status == interfaces.FileSyncStatusSynced && s.RemoteID == "remote-1" && s.Version == 1
+	})).Return(nil)
+
+	strategy := strategies.NewPulsePointOneWayStrategy(mockProvider, zap.NewNop(), nil)
+	syncer := NewPulsePointBatchSyncer(strategy, mockStateManager, mockProvider, "/home/user/sync", "Backup", zap.NewNop())
+
+	event := models.NewChangeEvent(models.ChangeTypeCreate, "/home/user/sync/docs/a.txt")
+	event.Hash = "abc"
+	event.Timestamp = time.Now()
+
+	result, err := syncer.SyncBatch(context.Background(), []*models.ChangeEvent{event})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, result.FilesUploaded)
+	assert.Equal(t, models.EventStatusCompleted, event.Status)
+	mockProvider.AssertExpectations(t)
+	mockStateManager.AssertExpectations(t)
+}
+
+func TestBatchSyncerMarksFailedEvents(t *testing.T) {
+	mockProvider := new(MockProvider)
+	mockStateManager := new(MockStateManager)
+
+	mockProvider.On("Delete", mock.Anything, "/gone.txt").Return(errors.New("network down"))
+	mockStateManager.On("GetFileState", mock.Anything, "/local/gone.txt").Return(nil, nil)
+	mockStateManager.On("UpdateFileState", mock.Anything, mock.MatchedBy(func(s *interfaces.FileState) bool {
+		return s.Status == interfaces.FileSyncStatusError && s.RetryCount == 1
+	})).Return(nil)
+
+	strategy := strategies.NewPulsePointOneWayStrategy(mockProvider, zap.NewNop(), nil)
+	syncer := NewPulsePointBatchSyncer(strategy, mockStateManager, mockProvider, "/local", "", zap.NewNop())
+
+	event := models.NewChangeEvent(models.ChangeTypeDelete, "/local/gone.txt")
+
+	result, err := syncer.SyncBatch(context.Background(), []*models.ChangeEvent{event})
+	require.NoError(t, err)
+
+	assert.Len(t, result.Errors, 1)
+	assert.Equal(t, models.EventStatusFailed, event.Status)
+	assert.True(t, event.CanRetry(3))
+	mockStateManager.AssertExpectations(t)
+}
+
+func TestBatchSyncerRecordsRemoteEventsByLocalPath(t *testing.T) {
+	mockProvider := new(MockProvider)
+	mockStateManager := new(MockStateManager)
+	mockStrategy := new(MockStrategy)
+
+	mockStrategy.On("Sync", mock.Anything, "/local", "/Backup", mock.Anything).Return(&interfaces.SyncResult{Success: true}, nil)
+	mockProvider.On("GetMetadata", mock.Anything, "/Backup/docs/a.txt").
+		Return(&interfaces.Metadata{ID: "remote-1", Hash: "md5-v2"}, nil)
+	mockStateManager.On("GetFileState", mock.Anything, "/local/docs/a.txt").
+		Return(&interfaces.FileState{Path: "/local/docs/a.txt", LocalHash: "sha-v1"}, nil)
+	mockStateManager.On("UpdateFileState", mock.Anything, mock.MatchedBy(func(s *interfaces.FileState) bool {
+		return s.Path == "/local/docs/a.txt" && s.RemoteHash == "md5-v2" && s.LocalHash == "sha-v1" &&
+			s.Metadata["remote_path"] == "/Backup/docs/a.txt"
+	})).Return(nil)
+
+	syncer := NewPulsePointBatchSyncer(mockStrategy, mockStateManager, mockProvider, "/local", "/Backup", zap.NewNop())
+
+	event := models.NewChangeEvent(models.ChangeTypeModify, "/Backup/docs/a.txt")
+	event.Source = "remote"
+	event.Hash = "md5-v2"
+
+	_, err := syncer.SyncBatch(context.Background(), []*models.ChangeEvent{event})
+	require.NoError(t, err)
+
+	assert.Equal(t, models.EventStatusCompleted, event.Status)
+	mockStateManager.AssertExpectations(t)
+}
+
+func TestBatchSyncerMovesRenamedFiles(t *testing.T) {
+	mockProvider := new(MockProvider)
+	mockStateManager := new(MockStateManager)
+
+	mockProvider.On("Move", mock.Anything, "/old.txt", "/new.txt").Return(nil)
+	mockProvider.On("GetMetadata", mock.Anything, "/new.txt").Return(nil, errors.New("not found"))
+	mockStateManager.On("DeleteFileState", mock.Anything, "/local/old.txt").Return(nil)
+	mockStateManager.On("GetFileState", mock.Anything, "/local/new.txt").Return(nil, nil)
+	mockStateManager.On("UpdateFileState", mock.Anything, mock.Anything).Return(nil)
+
+	strategy := strategies.NewPulsePointOneWayStrategy(mockProvider, zap.NewNop(), nil)
+	syncer := NewPulsePointBatchSyncer(strategy, mockStateManager, mockProvider, "/local", "/", zap.NewNop())
+
+	event := models.NewChangeEvent(models.ChangeTypeRename, "/local/new.txt")
+	event.OldPath = "/local/old.txt"
+
+	_, err := syncer.SyncBatch(context.Background(), []*models.ChangeEvent{event})
+	require.NoError(t, err)
+
+	assert.Equal(t, models.EventStatusCompleted, event.Status)
+	mockProvider.AssertNotCalled(t, "Upload", mock.Anything, mock.Anything)
+	mockProvider.AssertExpectations(t)
+}
+
+func TestBatchSyncerTwoWayRaisesConflict(t *testing.T) {
+	mockProvider := new(MockProvider)
+	mockStateManager := new(MockStateManager)
+
+	ancestor := &interfaces.FileState{
+		Path:       "/local/notes.txt",
+		LocalHash:  "local-v1",
+		RemoteHash: "remote-v1",
+		RemoteID:   "remote-1",
+	}
+	mockStateManager.On("GetFileState", mock.Anything, "/local/notes.txt").Return(ancestor, nil)
+	mockProvider.On("GetMetadata", mock.Anything, "/notes.txt").
+		Return(&interfaces.Metadata{ID: "remote-1", Hash: "remote-v2"}, nil)
+	mockStateManager.On("UpdateFileState", mock.Anything, mock.MatchedBy(func(s *interfaces.FileState) bool {
+		return s.Status == interfaces.FileSyncStatusConflict && s.LocalHash == "local-v1"
+	})).Return(nil)
+
+	strategy := strategies.NewPulsePointTwoWayStrategy(mockProvider, mockStateManager, zap.NewNop(), &interfaces.StrategyConfig{
+		ConflictResolution: interfaces.ResolutionSkip,
+	})
+	syncer := NewPulsePointBatchSyncer(strategy, mockStateManager, mockProvider, "/local", "/", zap.NewNop())
+
+	event := models.NewChangeEvent(models.ChangeTypeModify, "/local/notes.txt")
+	event.Hash = "local-v2"
+
+	result, err := syncer.SyncBatch(context.Background(), []*models.ChangeEvent{event})
+	require.NoError(t, err)
+
+	require.Len(t, result.Conflicts, 1)
+	assert.Equal(t, interfaces.ConflictTypeModified, result.Conflicts[0].Type)
+	assert.Equal(t, 0, result.FilesUploaded)
+	assert.Equal(t, models.EventStatusCompleted, event.Status)
+	mockProvider.AssertNotCalled(t, "Upload", mock.Anything, mock.Anything)
+	mockStateManager.AssertExpectations(t)
+}
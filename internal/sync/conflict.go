@@ -1,21 +1,42 @@
 package sync
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"github.com/pulsepoint/pulsepoint/internal/database/repositories"
 	pperrors "github.com/pulsepoint/pulsepoint/pkg/errors"
+	"github.com/pulsepoint/pulsepoint/pkg/models"
+	"github.com/pulsepoint/pulsepoint/pkg/utils"
 	"go.uber.org/zap"
 )
 
+// maxDiffLines limits the diff shown for a conflict
+const maxDiffLines = 200
+
 // PulsePointConflictResolver handles conflict resolution during sync
 type PulsePointConflictResolver struct {
 	logger   *zap.Logger
 	strategy interfaces.ResolutionStrategy
 	config   *ConflictResolverConfig
+
+	// Source of remote versions that were not fetched with the conflict
+	provider interfaces.CloudProvider
+
+	// Interactive resolution
+	prompter     ConflictPrompter
+	conflictRepo *repositories.ConflictRepository
+	baseStore    *PulsePointBaseStore
+	remembered   map[string]interfaces.ResolutionStrategy // Answers applied to all similar conflicts
+	rememberMu   sync.Mutex
 }
 
 // ConflictResolverConfig holds configuration for conflict resolution
@@ -59,12 +80,34 @@ func NewPulsePointConflictResolver(
 	}
 
 	return &PulsePointConflictResolver{
-		logger:   logger.With(zap.String("component", "conflict_resolver")),
-		strategy: strategy,
-		config:   config,
+		logger:     logger.With(zap.String("component", "conflict_resolver")),
+		strategy:   strategy,
+		config:     config,
+		remembered: make(map[string]interfaces.ResolutionStrategy),
 	}
 }
 
+// SetProvider sets the provider remote versions are downloaded from
+func (r *PulsePointConflictResolver) SetProvider(provider interfaces.CloudProvider) {
+	r.provider = provider
+}
+
+// SetPrompter sets the prompter used for interactive resolution
+func (r *PulsePointConflictResolver) SetPrompter(prompter ConflictPrompter) {
+	r.prompter = prompter
+}
+
+// SetConflictRepository sets where skipped and unfinished conflicts are
+// recorded, and where interactive conflicts wait when nobody can be prompted
+func (r *PulsePointConflictResolver) SetConflictRepository(repo *repositories.ConflictRepository) {
+	r.conflictRepo = repo
+}
+
+// SetBaseStore sets the store merge bases are read from
+func (r *PulsePointConflictResolver) SetBaseStore(store *PulsePointBaseStore) {
+	r.baseStore = store
+}
+
 // ResolveConflict resolves a single conflict
 func (r *PulsePointConflictResolver) ResolveConflict(
 	ctx context.Context,
@@ -78,7 +121,26 @@ func (r *PulsePointConflictResolver) ResolveConflict(
 	// Determine resolution strategy
 	strategy := r.determineStrategy(conflict)
 
-	// Apply resolution based on strategy
+	resolution, err := r.applyStrategy(ctx, conflict, strategy)
+	if err != nil {
+		return nil, pperrors.NewSyncError("conflict resolution failed", err)
+	}
+
+	r.logger.Info("Conflict resolved",
+		zap.String("path", conflict.Path),
+		zap.String("strategy", string(resolution.Strategy)),
+		zap.String("winner", resolution.Winner),
+	)
+
+	return resolution, nil
+}
+
+// applyStrategy resolves a conflict with the given strategy
+func (r *PulsePointConflictResolver) applyStrategy(
+	ctx context.Context,
+	conflict *interfaces.Conflict,
+	strategy interfaces.ResolutionStrategy,
+) (*interfaces.ConflictResolution, error) {
 	var resolution *interfaces.ConflictResolution
 	var err error
 
@@ -102,17 +164,7 @@ func (r *PulsePointConflictResolver) ResolveConflict(
 		)
 	}
 
-	if err != nil {
-		return nil, pperrors.NewSyncError("conflict resolution failed", err)
-	}
-
-	r.logger.Info("Conflict resolved",
-		zap.String("path", conflict.Path),
-		zap.String("strategy", string(resolution.Strategy)),
-		zap.String("winner", resolution.Winner),
-	)
-
-	return resolution, nil
+	return resolution, err
 }
 
 // ResolveMultiple resolves multiple conflicts
@@ -169,7 +221,10 @@ func (r *PulsePointConflictResolver) determineStrategy(conflict *interfaces.Conf
 		}
 	}
 
-	// Use default strategy
+	// Use the chosen strategy; the default only covers unanswered prompts
+	if r.strategy != "" {
+		return r.strategy
+	}
 	return r.config.DefaultStrategy
 }
 
@@ -222,8 +277,8 @@ func (r *PulsePointConflictResolver) keepLocal(ctx context.Context, conflict *in
 	}
 
 	// Backup remote version if configured
-	if r.config.BackupConflicts {
-		backupPath, err := r.backupFile(conflict.Path, conflict.RemoteFile, "remote")
+	if r.config.BackupConflicts && conflict.RemoteFile != nil {
+		backupPath, err := r.backupFile(ctx, conflict, conflict.RemoteFile, "remote")
 		if err != nil {
 			r.logger.Warn("Failed to backup remote file",
 				zap.String("path", conflict.Path),
@@ -247,8 +302,8 @@ func (r *PulsePointConflictResolver) keepRemote(ctx context.Context, conflict *i
 	}
 
 	// Backup local version if configured
-	if r.config.BackupConflicts {
-		backupPath, err := r.backupFile(conflict.Path, conflict.LocalFile, "local")
+	if r.config.BackupConflicts && conflict.LocalFile != nil {
+		backupPath, err := r.backupFile(ctx, conflict, conflict.LocalFile, "local")
 		if err != nil {
 			r.logger.Warn("Failed to backup local file",
 				zap.String("path", conflict.Path),
@@ -262,30 +317,32 @@ func (r *PulsePointConflictResolver) keepRemote(ctx context.Context, conflict *i
 	return resolution, nil
 }
 
-// keepBoth keeps both versions with rename
+// keepBoth keeps the local version in place and saves the remote version
+// beside it under a new name
 func (r *PulsePointConflictResolver) keepBoth(ctx context.Context, conflict *interfaces.Conflict) (*interfaces.ConflictResolution, error) {
-	// Generate new names for both files
-	timestamp := time.Now().Format("20060102_150405")
-	baseName := filepath.Base(conflict.Path)
-	dir := filepath.Dir(conflict.Path)
-	ext := filepath.Ext(baseName)
-	nameWithoutExt := baseName[:len(baseName)-len(ext)]
-
-	localNewPath := filepath.Join(dir, fmt.Sprintf("%s_local_%s%s", nameWithoutExt, timestamp, ext))
-	remoteNewPath := filepath.Join(dir, fmt.Sprintf("%s_remote_%s%s", nameWithoutExt, timestamp, ext))
-
 	resolution := &interfaces.ConflictResolution{
 		Strategy:     interfaces.ResolutionKeepBoth,
-		ResolvedPath: localNewPath,  // Path for local version
-		BackupPath:   remoteNewPath, // Path for remote version
+		ResolvedPath: conflict.Path,
 		ResolvedAt:   time.Now().UnixNano(),
 		Manual:       false,
 	}
 
+	// With one side deleted there is only one version to keep
+	if conflict.LocalFile == nil || conflict.RemoteFile == nil {
+		return resolution, nil
+	}
+
+	backupPath, err := r.saveVersion(ctx, conflict.Path, conflict.RemoteFile, "remote", filepath.Dir(conflict.Path))
+	if err != nil {
+		return nil, pperrors.NewSyncError("failed to keep remote version", err)
+	}
+	resolution.BackupPath = backupPath
+
 	return resolution, nil
 }
 
-// merge attempts to merge changes (for text files)
+// merge performs a three-way merge of text files against their common ancestor.
+// Hunks changed differently on both sides are written with conflict markers.
 func (r *PulsePointConflictResolver) merge(ctx context.Context, conflict *interfaces.Conflict) (*interfaces.ConflictResolution, error) {
 	// Check if file is mergeable (text file)
 	if !r.isMergeable(conflict.Path) {
@@ -293,17 +350,82 @@ func (r *PulsePointConflictResolver) merge(ctx context.Context, conflict *interf
 		return r.keepBoth(ctx, conflict)
 	}
 
-	// TODO: Implement actual merge logic using three-way merge
-	// For now, fall back to keep both
-	r.logger.Warn("Merge not yet implemented, falling back to keep both",
-		zap.String("path", conflict.Path),
-	)
+	r.fillBase(conflict)
+	if conflict.BaseFile == nil || conflict.LocalFile == nil || conflict.RemoteFile == nil {
+		r.logger.Warn("No merge base available, falling back to keep both",
+			zap.String("path", conflict.Path),
+		)
+		return r.keepBoth(ctx, conflict)
+	}
+
+	base, err := readConflictVersion(conflict.BaseFile)
+	if err != nil {
+		return nil, pperrors.NewSyncError("failed to read merge base", err)
+	}
+	local, err := readConflictVersion(conflict.LocalFile)
+	if err != nil {
+		return nil, pperrors.NewSyncError("failed to read local version", err)
+	}
+	remote, err := readConflictVersion(conflict.RemoteFile)
+	if err != nil {
+		return nil, pperrors.NewSyncError("failed to read remote version", err)
+	}
+
+	// Binary content slipped past the extension check
+	if bytes.IndexByte(base, 0) >= 0 || bytes.IndexByte(local, 0) >= 0 || bytes.IndexByte(remote, 0) >= 0 {
+		return r.keepBoth(ctx, conflict)
+	}
+
+	merged, ok := mergeText(string(base), string(local), string(remote))
+	if !ok {
+		r.logger.Warn("File too large to merge, falling back to keep both",
+			zap.String("path", conflict.Path),
+		)
+		return r.keepBoth(ctx, conflict)
+	}
+
+	mergedPath := conflict.LocalFile.LocalPath
+	if mergedPath == "" {
+		mergedPath = conflict.Path
+	}
+	if err := writeMergedFile(mergedPath, []byte(merged.Content)); err != nil {
+		return nil, pperrors.NewSyncError("failed to write merged file", err)
+	}
+
+	if len(merged.Conflicts) > 0 {
+		r.logger.Warn("Merge left conflicting hunks",
+			zap.String("path", conflict.Path),
+			zap.Strings("hunks", merged.Conflicts),
+		)
+
+		// The markers need a person; keep the conflict until they are edited out
+		if r.conflictRepo != nil {
+			description := fmt.Sprintf("Merge left %d conflicting hunks in %s", len(merged.Conflicts), mergedPath)
+			if err := r.record(conflict, description); err != nil {
+				return nil, err
+			}
+		}
+	}
 
-	return r.keepBoth(ctx, conflict)
+	return &interfaces.ConflictResolution{
+		Strategy:       interfaces.ResolutionMerge,
+		Winner:         "merged",
+		ResolvedPath:   mergedPath,
+		MergedPath:     mergedPath,
+		MergeBase:      conflict.BaseFile.Hash,
+		MergeConflicts: merged.Conflicts,
+		ResolvedAt:     time.Now().UnixNano(),
+		Manual:         false,
+	}, nil
 }
 
-// skip skips the conflicted file
+// skip skips the conflicted file. With a conflict repository the conflict is
+// recorded so it can be resolved later with the conflicts command.
 func (r *PulsePointConflictResolver) skip(ctx context.Context, conflict *interfaces.Conflict) (*interfaces.ConflictResolution, error) {
+	if r.conflictRepo != nil {
+		return r.park(conflict)
+	}
+
 	resolution := &interfaces.ConflictResolution{
 		Strategy:   interfaces.ResolutionSkip,
 		ResolvedAt: time.Now().UnixNano(),
@@ -313,54 +435,449 @@ func (r *PulsePointConflictResolver) skip(ctx context.Context, conflict *interfa
 	return resolution, nil
 }
 
-// interactive prompts user for resolution
+// interactive asks the user how to resolve the conflict. Without a prompter
+// the conflict is parked for later, or resolved with the default strategy.
 func (r *PulsePointConflictResolver) interactive(ctx context.Context, conflict *interfaces.Conflict) (*interfaces.ConflictResolution, error) {
-	// TODO: Implement interactive resolution
-	// For now, fall back to default strategy
-	r.logger.Warn("Interactive resolution not yet implemented, using default strategy",
+	if strategy, ok := r.rememberedStrategy(conflict); ok {
+		return r.applyManual(ctx, conflict, strategy)
+	}
+
+	if r.prompter == nil {
+		if r.conflictRepo != nil {
+			return r.park(conflict)
+		}
+		return r.fallback(ctx, conflict, "no terminal available")
+	}
+
+	promptCtx := ctx
+	if r.config.InteractiveTimeout > 0 {
+		var cancel context.CancelFunc
+		promptCtx, cancel = context.WithTimeout(ctx, r.config.InteractiveTimeout)
+		defer cancel()
+	}
+
+	answer, err := r.prompter.Prompt(promptCtx, conflict, r.describeConflict(conflict))
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return r.fallback(ctx, conflict, err.Error())
+	}
+
+	if answer.ApplyToAll {
+		r.rememberStrategy(conflict, answer.Strategy)
+	}
+
+	return r.applyManual(ctx, conflict, answer.Strategy)
+}
+
+// applyManual applies a strategy chosen by the user
+func (r *PulsePointConflictResolver) applyManual(
+	ctx context.Context,
+	conflict *interfaces.Conflict,
+	strategy interfaces.ResolutionStrategy,
+) (*interfaces.ConflictResolution, error) {
+	resolution, err := r.applyStrategy(ctx, conflict, strategy)
+	if err != nil {
+		return nil, err
+	}
+	resolution.Manual = true
+	return resolution, nil
+}
+
+// fallback resolves an unanswered conflict with the default strategy
+func (r *PulsePointConflictResolver) fallback(
+	ctx context.Context,
+	conflict *interfaces.Conflict,
+	reason string,
+) (*interfaces.ConflictResolution, error) {
+	strategy := r.config.DefaultStrategy
+	if strategy == interfaces.ResolutionInteractive || strategy == "" {
+		strategy = interfaces.ResolutionSkip
+	}
+
+	r.logger.Warn("No interactive answer, using default strategy",
 		zap.String("path", conflict.Path),
-		zap.String("default_strategy", string(r.config.DefaultStrategy)),
+		zap.String("reason", reason),
+		zap.String("default_strategy", string(strategy)),
 	)
 
-	r.strategy = r.config.DefaultStrategy
-	return r.ResolveConflict(ctx, conflict)
+	return r.applyStrategy(ctx, conflict, strategy)
 }
 
-// backupFile creates a backup of a file
-func (r *PulsePointConflictResolver) backupFile(originalPath string, file *interfaces.File, suffix string) (string, error) {
-	timestamp := time.Now().Format("20060102_150405")
-	baseName := filepath.Base(originalPath)
-	backupName := fmt.Sprintf("%s_%s_%s", baseName, suffix, timestamp)
-	backupPath := filepath.Join(r.config.BackupDir, backupName)
+// park records the conflict and leaves both sides untouched until the user decides
+func (r *PulsePointConflictResolver) park(conflict *interfaces.Conflict) (*interfaces.ConflictResolution, error) {
+	if err := r.record(conflict, "Waiting for the user to resolve"); err != nil {
+		return nil, err
+	}
 
-	// TODO: Actually copy/download the file to backup location
-	// For now, just return the path
-	return backupPath, nil
+	return &interfaces.ConflictResolution{
+		Strategy:   interfaces.ResolutionSkip,
+		ResolvedAt: time.Now().UnixNano(),
+		Manual:     false,
+	}, nil
 }
 
-// isMergeable checks if a file can be merged
-func (r *PulsePointConflictResolver) isMergeable(path string) bool {
-	if !r.config.MergeTextFiles {
-		return false
+// record stores the conflict so it can be resolved later with the conflicts command
+func (r *PulsePointConflictResolver) record(conflict *interfaces.Conflict, description string) error {
+	parked := models.NewConflict(
+		conflict.Path,
+		models.ConflictType(conflict.Type),
+		toModelFile(conflict.LocalFile),
+		toModelFile(conflict.RemoteFile),
+	)
+
+	// Replace an earlier unresolved record for the same path
+	isNew := true
+	if existing, err := r.conflictRepo.GetByPath(conflict.Path); err == nil {
+		for _, previous := range existing {
+			if previous.ResolutionStatus != models.ResolutionStatusResolved {
+				parked = previous
+				isNew = false
+				parked.LocalFile = toModelFile(conflict.LocalFile)
+				parked.RemoteFile = toModelFile(conflict.RemoteFile)
+				break
+			}
+		}
+	}
+
+	parked.BaseFile = toModelFile(conflict.BaseFile)
+	parked.Severity = models.ConflictSeverityHigh
+	parked.UserRequired = true
+	parked.ResolutionStatus = models.ResolutionStatusDeferred
+	parked.Description = description
+	parked.AddHistory("parked until resolved by the user")
+
+	var err error
+	if isNew {
+		err = r.conflictRepo.Create(parked)
+	} else {
+		err = r.conflictRepo.Update(parked)
+	}
+	if err != nil {
+		return pperrors.NewDatabaseError("failed to park conflict", err)
+	}
+
+	r.logger.Info("Conflict parked for later resolution",
+		zap.String("path", conflict.Path),
+		zap.String("id", parked.ID),
+	)
+
+	return nil
+}
+
+// rememberedStrategy returns an answer the user applied to all similar conflicts
+func (r *PulsePointConflictResolver) rememberedStrategy(conflict *interfaces.Conflict) (interfaces.ResolutionStrategy, bool) {
+	r.rememberMu.Lock()
+	defer r.rememberMu.Unlock()
+
+	strategy, ok := r.remembered[similarConflictKey(conflict)]
+	return strategy, ok
+}
+
+// rememberStrategy applies an answer to all later similar conflicts
+func (r *PulsePointConflictResolver) rememberStrategy(conflict *interfaces.Conflict, strategy interfaces.ResolutionStrategy) {
+	r.rememberMu.Lock()
+	defer r.rememberMu.Unlock()
+
+	r.remembered[similarConflictKey(conflict)] = strategy
+}
+
+// similarConflictKey groups conflicts of the same type on the same kind of file
+func similarConflictKey(conflict *interfaces.Conflict) string {
+	return string(conflict.Type) + ":" + strings.ToLower(filepath.Ext(conflict.Path))
+}
+
+// describeConflict shows both sides of a conflict and, for text files, how they differ
+func (r *PulsePointConflictResolver) describeConflict(conflict *interfaces.Conflict) string {
+	var out strings.Builder
+
+	describeSide := func(label string, file *interfaces.File) {
+		if file == nil {
+			fmt.Fprintf(&out, "  %-7s deleted\n", label+":")
+			return
+		}
+		fmt.Fprintf(&out, "  %-7s %s, modified %s, hash %s\n",
+			label+":",
+			utils.FormatBytes(file.Size),
+			file.ModifiedTime.Format("2006-01-02 15:04:05"),
+			shortHash(file.Hash),
+		)
+	}
+	describeSide("local", conflict.LocalFile)
+	describeSide("remote", conflict.RemoteFile)
+
+	if conflict.LocalFile == nil || conflict.RemoteFile == nil || !utils.IsMergeable(conflict.Path) {
+		return out.String()
+	}
+
+	local, err := readConflictVersion(conflict.LocalFile)
+	if err != nil {
+		return out.String()
+	}
+	remote, err := readConflictVersion(conflict.RemoteFile)
+	if err != nil {
+		fmt.Fprintf(&out, "  (remote content not available for diff)\n")
+		return out.String()
+	}
+
+	diff, ok := unifiedDiff("remote", "local", string(remote), string(local))
+	if !ok {
+		fmt.Fprintf(&out, "  (files too large to diff)\n")
+		return out.String()
+	}
+
+	lines := strings.SplitAfter(diff, "\n")
+	if len(lines) > maxDiffLines {
+		diff = strings.Join(lines[:maxDiffLines], "") + fmt.Sprintf("... %d more lines\n", len(lines)-maxDiffLines)
+	}
+	out.WriteString(diff)
+
+	return out.String()
+}
+
+// fillBase points the merge base at the stored ancestor content
+func (r *PulsePointConflictResolver) fillBase(conflict *interfaces.Conflict) {
+	base := conflict.BaseFile
+	if base == nil || base.Content != nil || base.LocalPath != "" || r.baseStore == nil {
+		return
+	}
+	if r.baseStore.Has(base.Hash) {
+		base.LocalPath = r.baseStore.Path(base.Hash)
+	} else {
+		conflict.BaseFile = nil
+	}
+}
+
+// shortHash abbreviates a hash for display
+func shortHash(hash string) string {
+	if hash == "" {
+		return "unknown"
+	}
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
+
+// toModelFile converts a provider file to its stored form
+func toModelFile(file *interfaces.File) *models.File {
+	if file == nil {
+		return nil
+	}
+
+	return &models.File{
+		ID:           file.ID,
+		Path:         file.Path,
+		Name:         file.Name,
+		RemoteID:     file.RemoteID,
+		ParentID:     file.ParentID,
+		Size:         file.Size,
+		Hash:         file.Hash,
+		MimeType:     file.MimeType,
+		IsFolder:     file.IsFolder,
+		ModifiedTime: file.ModifiedTime,
+		CreatedTime:  file.CreatedTime,
+		LocalPath:    file.LocalPath,
+		Permissions:  file.Permissions,
+	}
+}
+
+// backupFile saves the losing side of a conflict into the backup directory
+// before the winning side overwrites it
+func (r *PulsePointConflictResolver) backupFile(
+	ctx context.Context,
+	conflict *interfaces.Conflict,
+	file *interfaces.File,
+	side string,
+) (string, error) {
+	return r.saveVersion(ctx, conflict.Path, file, side, r.backupDir(conflict.Path))
+}
+
+// backupDir returns where backups of a conflicted file go. A relative
+// BackupDir is taken relative to the file's directory.
+func (r *PulsePointConflictResolver) backupDir(originalPath string) string {
+	switch dir := r.config.BackupDir; {
+	case dir == "":
+		return filepath.Dir(originalPath)
+	case filepath.IsAbs(dir):
+		return dir
+	default:
+		return filepath.Join(filepath.Dir(originalPath), dir)
+	}
+}
+
+// saveVersion copies one side of a conflict into dir under a name that does
+// not collide with existing files and returns the new path
+func (r *PulsePointConflictResolver) saveVersion(
+	ctx context.Context,
+	originalPath string,
+	file *interfaces.File,
+	side, dir string,
+) (string, error) {
+	content, err := r.openVersion(ctx, file, side)
+	if err != nil {
+		return "", err
+	}
+	defer content.Close()
+
+	if err := utils.EnsureDir(dir); err != nil {
+		return "", pperrors.NewFileSystemError(fmt.Sprintf("failed to create %s", dir), err)
+	}
+
+	// The copy only appears under its final name once complete
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(originalPath)+".*.tmp")
+	if err != nil {
+		return "", pperrors.NewFileSystemError("failed to create temp file", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", pperrors.NewFileSystemError(fmt.Sprintf("failed to copy %s version", side), err)
+	}
+	if !file.ModifiedTime.IsZero() {
+		_ = os.Chtimes(tmp.Name(), file.ModifiedTime, file.ModifiedTime)
 	}
 
-	// Check file extension
-	ext := filepath.Ext(path)
-	mergeableExts := []string{
-		".txt", ".md", ".json", ".xml", ".yaml", ".yml",
-		".go", ".js", ".ts", ".py", ".java", ".c", ".cpp", ".h",
-		".html", ".css", ".scss", ".less",
-		".sh", ".bash", ".zsh",
-		".conf", ".config", ".ini",
+	target, err := reserveVersionPath(dir, originalPath, side)
+	if err != nil {
+		return "", err
 	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		os.Remove(target)
+		return "", pperrors.NewFileSystemError(fmt.Sprintf("failed to save %s", target), err)
+	}
+
+	r.logger.Info("Saved conflicting version",
+		zap.String("path", originalPath),
+		zap.String("side", side),
+		zap.String("copy", target),
+	)
+
+	return target, nil
+}
 
-	for _, mergeableExt := range mergeableExts {
-		if ext == mergeableExt {
-			return true
+// openVersion opens the content of one side of a conflict. The remote side is
+// downloaded unless its content or a local copy of it was already fetched.
+func (r *PulsePointConflictResolver) openVersion(
+	ctx context.Context,
+	file *interfaces.File,
+	side string,
+) (io.ReadCloser, error) {
+	if file.Content != nil {
+		data, err := readConflictVersion(file)
+		if err != nil {
+			return nil, pperrors.NewFileSystemError(fmt.Sprintf("failed to read %s version", side), err)
+		}
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	if file.LocalPath != "" || side == "local" {
+		path := file.LocalPath
+		if path == "" {
+			path = file.Path
+		}
+		source, err := os.Open(path)
+		if err != nil {
+			return nil, pperrors.NewFileSystemError(fmt.Sprintf("failed to open %s", path), err)
 		}
+		return source, nil
+	}
+
+	if r.provider == nil {
+		return nil, pperrors.NewSyncError(fmt.Sprintf("no provider to download %s", file.Path), nil)
+	}
+	downloaded, err := r.provider.Download(ctx, file.Path)
+	if err != nil {
+		return nil, pperrors.NewProviderError(fmt.Sprintf("failed to download %s", file.Path), err)
+	}
+	if closer, ok := downloaded.Content.(io.ReadCloser); ok {
+		return closer, nil
+	}
+	return io.NopCloser(downloaded.Content), nil
+}
+
+// reserveVersionPath claims a name for a saved version, built from the
+// original name, the side and a timestamp, with a counter when it is taken
+func reserveVersionPath(dir, originalPath, side string) (string, error) {
+	baseName := filepath.Base(originalPath)
+	ext := filepath.Ext(baseName)
+	stem := fmt.Sprintf("%s_%s_%s", baseName[:len(baseName)-len(ext)], side, time.Now().Format("20060102_150405"))
+
+	for n := 1; n <= 1000; n++ {
+		name := stem + ext
+		if n > 1 {
+			name = fmt.Sprintf("%s_%d%s", stem, n, ext)
+		}
+
+		path := filepath.Join(dir, name)
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			file.Close()
+			return path, nil
+		}
+		if !os.IsExist(err) {
+			return "", pperrors.NewFileSystemError(fmt.Sprintf("failed to create %s", path), err)
+		}
+	}
+
+	return "", pperrors.NewFileSystemError(fmt.Sprintf("no free name for a copy of %s", originalPath), nil)
+}
+
+// readConflictVersion reads the content of one side of a conflict
+func readConflictVersion(file *interfaces.File) ([]byte, error) {
+	if file.Content != nil {
+		data, err := io.ReadAll(file.Content)
+		if err != nil {
+			return nil, err
+		}
+		// Keep the content readable for later steps
+		file.Content = bytes.NewReader(data)
+		return data, nil
+	}
+	if file.LocalPath != "" {
+		return os.ReadFile(file.LocalPath)
+	}
+	return nil, fmt.Errorf("no content available for %s", file.Path)
+}
+
+// writeMergedFile replaces path with the merged content through a temp file
+func writeMergedFile(path string, content []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// isMergeable checks if a file can be merged
+func (r *PulsePointConflictResolver) isMergeable(path string) bool {
+	if !r.config.MergeTextFiles {
+		return false
 	}
 
-	return false
+	return utils.IsMergeable(path)
 }
 
 // GetStatistics returns conflict resolution statistics
@@ -0,0 +1,236 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"github.com/pulsepoint/pulsepoint/internal/database"
+	"github.com/pulsepoint/pulsepoint/internal/database/repositories"
+	"github.com/pulsepoint/pulsepoint/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// stubPrompter answers prompts with a fixed choice, or blocks until the deadline
+type stubPrompter struct {
+	answer *PromptAnswer
+	calls  int
+}
+
+func (p *stubPrompter) Prompt(ctx context.Context, conflict *interfaces.Conflict, details string) (*PromptAnswer, error) {
+	p.calls++
+	if p.answer == nil {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return p.answer, nil
+}
+
+func newInteractiveResolver(timeout time.Duration) *PulsePointConflictResolver {
+	return NewPulsePointConflictResolver(zap.NewNop(), interfaces.ResolutionInteractive, &ConflictResolverConfig{
+		DefaultStrategy:    interfaces.ResolutionInteractive,
+		InteractiveTimeout: timeout,
+	})
+}
+
+func TestInteractiveResolutionAppliesToSimilarConflicts(t *testing.T) {
+	resolver := newInteractiveResolver(time.Second)
+	prompter := &stubPrompter{answer: &PromptAnswer{Strategy: interfaces.ResolutionKeepRemote, ApplyToAll: true}}
+	resolver.SetPrompter(prompter)
+
+	first := &interfaces.Conflict{Path: "/sync/a.txt", Type: interfaces.ConflictTypeModified}
+	second := &interfaces.Conflict{Path: "/sync/b.txt", Type: interfaces.ConflictTypeModified}
+
+	resolution, err := resolver.ResolveConflict(context.Background(), first)
+	require.NoError(t, err)
+	assert.Equal(t, interfaces.ResolutionKeepRemote, resolution.Strategy)
+	assert.True(t, resolution.Manual)
+
+	resolution, err = resolver.ResolveConflict(context.Background(), second)
+	require.NoError(t, err)
+	assert.Equal(t, interfaces.ResolutionKeepRemote, resolution.Strategy)
+	assert.Equal(t, 1, prompter.calls)
+}
+
+func TestInteractiveResolutionTimesOut(t *testing.T) {
+	resolver := newInteractiveResolver(10 * time.Millisecond)
+	resolver.SetPrompter(&stubPrompter{})
+
+	resolution, err := resolver.ResolveConflict(context.Background(), &interfaces.Conflict{
+		Path: "/sync/a.txt",
+		Type: interfaces.ConflictTypeModified,
+	})
+	require.NoError(t, err)
+
+	// The default strategy is interactive itself, so the conflict is skipped
+	assert.Equal(t, interfaces.ResolutionSkip, resolution.Strategy)
+	assert.False(t, resolution.Manual)
+}
+
+func newTestConflictRepository(t *testing.T) *repositories.ConflictRepository {
+	options := database.DefaultOptions()
+	options.Path = filepath.Join(t.TempDir(), "test.db")
+	db, err := database.NewManager(options)
+	require.NoError(t, err)
+	require.NoError(t, db.Open())
+	t.Cleanup(func() { db.Close() })
+
+	return repositories.NewConflictRepository(db)
+}
+
+func TestInteractiveResolutionParksWithoutTerminal(t *testing.T) {
+	repo := newTestConflictRepository(t)
+	resolver := newInteractiveResolver(time.Second)
+	resolver.SetConflictRepository(repo)
+
+	conflict := &interfaces.Conflict{
+		Path:       "/sync/a.txt",
+		Type:       interfaces.ConflictTypeModified,
+		LocalFile:  &interfaces.File{Path: "/sync/a.txt", Hash: "local"},
+		RemoteFile: &interfaces.File{Path: "/a.txt", Hash: "remote"},
+	}
+
+	resolution, err := resolver.ResolveConflict(context.Background(), conflict)
+	require.NoError(t, err)
+	assert.Equal(t, interfaces.ResolutionSkip, resolution.Strategy)
+
+	// Parking the same path again updates the existing record
+	_, err = resolver.ResolveConflict(context.Background(), conflict)
+	require.NoError(t, err)
+
+	parked, err := repo.ListUnresolved()
+	require.NoError(t, err)
+	require.Len(t, parked, 1)
+	assert.Equal(t, models.ResolutionStatusDeferred, parked[0].ResolutionStatus)
+	assert.True(t, parked[0].UserRequired)
+	assert.Equal(t, "remote", parked[0].RemoteFile.Hash)
+}
+
+func TestResolutionUsesChosenStrategyOverDefault(t *testing.T) {
+	// The default only applies to interactive prompts nobody answers
+	resolver := NewPulsePointConflictResolver(zap.NewNop(), interfaces.ResolutionSkip, &ConflictResolverConfig{
+		DefaultStrategy: interfaces.ResolutionKeepRemote,
+	})
+
+	resolution, err := resolver.ResolveConflict(context.Background(), &interfaces.Conflict{
+		Path: "/sync/a.txt",
+		Type: interfaces.ConflictTypeModified,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, interfaces.ResolutionSkip, resolution.Strategy)
+}
+
+func TestSkippedConflictsAreRecorded(t *testing.T) {
+	repo := newTestConflictRepository(t)
+	resolver := NewPulsePointConflictResolver(zap.NewNop(), interfaces.ResolutionSkip, &ConflictResolverConfig{
+		DefaultStrategy: interfaces.ResolutionSkip,
+	})
+	resolver.SetConflictRepository(repo)
+
+	resolution, err := resolver.ResolveConflict(context.Background(), &interfaces.Conflict{
+		Path: "/sync/a.txt",
+		Type: interfaces.ConflictTypeDeleted,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, interfaces.ResolutionSkip, resolution.Strategy)
+
+	recorded, err := repo.ListUnresolved()
+	require.NoError(t, err)
+	require.Len(t, recorded, 1)
+	assert.Equal(t, models.ConflictTypeDeleteModify, recorded[0].Type)
+}
+
+func TestKeepBothSavesRemoteVersionBeside(t *testing.T) {
+	dir := t.TempDir()
+	localPath := filepath.Join(dir, "notes.txt")
+	remoteCopy := filepath.Join(dir, ".notes.txt.remote.tmp")
+	require.NoError(t, os.WriteFile(localPath, []byte("local\n"), 0600))
+	require.NoError(t, os.WriteFile(remoteCopy, []byte("remote\n"), 0600))
+
+	resolver := NewPulsePointConflictResolver(zap.NewNop(), interfaces.ResolutionKeepBoth, &ConflictResolverConfig{
+		DefaultStrategy: interfaces.ResolutionKeepBoth,
+	})
+	conflict := &interfaces.Conflict{
+		Path:       localPath,
+		Type:       interfaces.ConflictTypeModified,
+		LocalFile:  &interfaces.File{Path: localPath, LocalPath: localPath},
+		RemoteFile: &interfaces.File{Path: "/notes.txt", LocalPath: remoteCopy},
+	}
+
+	first, err := resolver.ResolveConflict(context.Background(), conflict)
+	require.NoError(t, err)
+	second, err := resolver.ResolveConflict(context.Background(), conflict)
+	require.NoError(t, err)
+
+	// Copies saved within the same second get distinct names
+	assert.NotEqual(t, first.BackupPath, second.BackupPath)
+	for _, backupPath := range []string{first.BackupPath, second.BackupPath} {
+		assert.Equal(t, dir, filepath.Dir(backupPath))
+		content, err := os.ReadFile(backupPath)
+		require.NoError(t, err)
+		assert.Equal(t, "remote\n", string(content))
+	}
+}
+
+func TestKeepBothUsesFetchedRemoteContent(t *testing.T) {
+	dir := t.TempDir()
+	localPath := filepath.Join(dir, "notes.txt")
+	require.NoError(t, os.WriteFile(localPath, []byte("local\n"), 0600))
+
+	// No provider is set, so the remote version cannot be downloaded again
+	resolver := NewPulsePointConflictResolver(zap.NewNop(), interfaces.ResolutionKeepBoth, &ConflictResolverConfig{
+		DefaultStrategy: interfaces.ResolutionKeepBoth,
+	})
+	conflict := &interfaces.Conflict{
+		Path:       localPath,
+		Type:       interfaces.ConflictTypeModified,
+		LocalFile:  &interfaces.File{Path: localPath, LocalPath: localPath},
+		RemoteFile: &interfaces.File{Path: "/notes.txt", Content: strings.NewReader("remote\n")},
+	}
+
+	for i := 0; i < 2; i++ {
+		resolution, err := resolver.ResolveConflict(context.Background(), conflict)
+		require.NoError(t, err)
+		content, err := os.ReadFile(resolution.BackupPath)
+		require.NoError(t, err)
+		assert.Equal(t, "remote\n", string(content))
+	}
+}
+
+func TestKeepRemoteBacksUpLocalVersion(t *testing.T) {
+	dir := t.TempDir()
+	localPath := filepath.Join(dir, "notes.txt")
+	require.NoError(t, os.WriteFile(localPath, []byte("local\n"), 0600))
+
+	resolver := NewPulsePointConflictResolver(zap.NewNop(), interfaces.ResolutionKeepRemote, &ConflictResolverConfig{
+		DefaultStrategy: interfaces.ResolutionKeepRemote,
+		BackupConflicts: true,
+		BackupDir:       ".conflicts",
+	})
+
+	resolution, err := resolver.ResolveConflict(context.Background(), &interfaces.Conflict{
+		Path:       localPath,
+		Type:       interfaces.ConflictTypeModified,
+		LocalFile:  &interfaces.File{Path: localPath},
+		RemoteFile: &interfaces.File{Path: "/notes.txt"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, filepath.Join(dir, ".conflicts"), filepath.Dir(resolution.BackupPath))
+	content, err := os.ReadFile(resolution.BackupPath)
+	require.NoError(t, err)
+	assert.Equal(t, "local\n", string(content))
+}
+
+func TestUnifiedDiffShowsChangedLines(t *testing.T) {
+	diff, ok := unifiedDiff("remote", "local", "a\nb\nc\n", "a\nB\nc\n")
+	require.True(t, ok)
+
+	assert.Equal(t, "--- remote\n+++ local\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n", diff)
+}
@@ -0,0 +1,122 @@
+package sync
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// diffOp is one line of an edit script
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// unifiedDiff renders a unified diff from oldText to newText. It returns an
+// empty string when the texts are equal and false when they are too large to diff.
+func unifiedDiff(oldName, newName, oldText, newText string) (string, bool) {
+	a := splitLines(oldText)
+	b := splitLines(newText)
+
+	match, ok := matchLines(a, b)
+	if !ok {
+		return "", false
+	}
+
+	// Build the edit script from the line matching
+	var ops []diffOp
+	j := 0
+	for i, line := range a {
+		if match[i] < 0 {
+			ops = append(ops, diffOp{'-', line})
+			continue
+		}
+		for ; j < match[i]; j++ {
+			ops = append(ops, diffOp{'+', b[j]})
+		}
+		ops = append(ops, diffOp{' ', line})
+		j++
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+
+	var out strings.Builder
+	oldLine, newLine := 1, 1
+	for start := 0; start < len(ops); {
+		// Find the next change
+		for start < len(ops) && ops[start].kind == ' ' {
+			oldLine++
+			newLine++
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		// Extend the hunk while changes are close together
+		hunkStart := start - diffContext
+		if hunkStart < 0 {
+			hunkStart = 0
+		}
+		end := start
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == ' ' {
+				run++
+			}
+			if run == len(ops) || run-end > 2*diffContext {
+				break
+			}
+			end = run
+		}
+		hunkEnd := end + diffContext
+		if hunkEnd > len(ops) {
+			hunkEnd = len(ops)
+		}
+
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+		}
+
+		hunkOld := oldLine - (start - hunkStart)
+		hunkNew := newLine - (start - hunkStart)
+		oldCount, newCount := 0, 0
+		for _, op := range ops[hunkStart:hunkEnd] {
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", hunkOld, oldCount, hunkNew, newCount)
+
+		for _, op := range ops[hunkStart:hunkEnd] {
+			out.WriteByte(op.kind)
+			out.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		// Advance the line counters past the hunk
+		for _, op := range ops[start:hunkEnd] {
+			if op.kind != '+' {
+				oldLine++
+			}
+			if op.kind != '-' {
+				newLine++
+			}
+		}
+		start = hunkEnd
+	}
+
+	return out.String(), true
+}
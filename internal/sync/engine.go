@@ -2,6 +2,7 @@ package sync
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"sync"
 	"time"
@@ -10,9 +11,12 @@ import (
 	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
 	"github.com/pulsepoint/pulsepoint/internal/database"
 	"github.com/pulsepoint/pulsepoint/internal/providers"
+	"github.com/pulsepoint/pulsepoint/internal/watchers/ignore"
+	"github.com/pulsepoint/pulsepoint/internal/watchers/queue"
 	pperrors "github.com/pulsepoint/pulsepoint/pkg/errors"
 	pplogger "github.com/pulsepoint/pulsepoint/pkg/logger"
 	"github.com/pulsepoint/pulsepoint/pkg/models"
+	"github.com/pulsepoint/pulsepoint/pkg/utils"
 	"go.uber.org/zap"
 )
 
@@ -28,6 +32,9 @@ type PulsePointEngine struct {
 
 	// Pipeline components
 	pipeline *PulsePointPipeline
+	changes  ChangeSource
+	ignores  map[string]*ignore.PulsePointIgnoreMatcher // Keyed by local folder
+	pairs    map[string]*pathSync                       // Keyed by local folder
 
 	// Configuration
 	config *EngineConfig
@@ -44,8 +51,29 @@ type PulsePointEngine struct {
 	metrics *SyncMetrics
 }
 
+// ChangeSource holds changes waiting for the next sync
+type ChangeSource interface {
+	Add(event *models.ChangeEvent) error
+	Drain(max int) []*models.ChangeEvent
+	Requeue(events []*models.ChangeEvent)
+}
+
+// pathSync is the strategy and state manager one folder pair syncs with
+type pathSync struct {
+	strategy     interfaces.SyncStrategy
+	stateManager interfaces.StateManager
+}
+
+// changeSourceStopper is implemented by change sources that persist on shutdown
+type changeSourceStopper interface {
+	Stop() error
+}
+
 // EngineConfig holds configuration for the sync engine
 type EngineConfig struct {
+	// Folder pairs to sync
+	Paths []SyncPath `json:"paths"`
+
 	// Sync settings
 	SyncInterval       time.Duration `json:"sync_interval"`
 	BatchSize          int           `json:"batch_size"`
@@ -123,11 +151,29 @@ func NewPulsePointEngine(
 		stopChan:     make(chan struct{}),
 		pauseChan:    make(chan struct{}),
 		metrics:      &SyncMetrics{StartTime: time.Now()},
+		ignores:      make(map[string]*ignore.PulsePointIgnoreMatcher),
+		pairs:        make(map[string]*pathSync),
+	}
+
+	for i := range config.Paths {
+		syncPath := &config.Paths[i]
+		engine.ignores[syncPath.Local] = syncPath.IgnoreMatcher(config.IgnorePatterns)
 	}
 
 	// Initialize pipeline
 	engine.pipeline = NewPulsePointPipeline(engine)
 
+	// Changes wait in the persistent queue until the next sync
+	if db != nil && db.DB != nil {
+		changeQueue, err := queue.NewPulsePointChangeQueue(db.DB, queue.QueueConfig{
+			BatchSize: config.BatchSize,
+		})
+		if err != nil {
+			return nil, pperrors.NewDatabaseError("failed to load change queue", err)
+		}
+		engine.changes = changeQueue
+	}
+
 	// Load existing state
 	if err := engine.loadState(); err != nil {
 		logger.Warn("Failed to load existing state", zap.Error(err))
@@ -154,7 +200,11 @@ func (e *PulsePointEngine) Start(ctx context.Context) error {
 	)
 
 	// Start file watcher
-	if err := e.watcher.Start(ctx, []string{"."}); err != nil {
+	var roots []string
+	for _, syncPath := range e.enabledPaths() {
+		roots = append(roots, syncPath.Local)
+	}
+	if err := e.watcher.Start(ctx, roots); err != nil {
 		e.mu.Lock()
 		e.isRunning = false
 		e.mu.Unlock()
@@ -193,6 +243,13 @@ func (e *PulsePointEngine) Stop() error {
 		e.logger.Error("Failed to stop file watcher", zap.Error(err))
 	}
 
+	// Keep changes that were not synced for the next run
+	if stopper, ok := e.changes.(changeSourceStopper); ok {
+		if err := stopper.Stop(); err != nil {
+			e.logger.Error("Failed to save pending changes", zap.Error(err))
+		}
+	}
+
 	// Update state
 	e.mu.Lock()
 	e.isRunning = false
@@ -294,6 +351,150 @@ func (e *PulsePointEngine) Sync(ctx context.Context) (*interfaces.SyncResult, er
 	return result, nil
 }
 
+// CleanupRemote removes remote files that no longer exist locally from every
+// enabled folder pair, for strategies that mirror the local tree. Callers run
+// it on full syncs only.
+func (e *PulsePointEngine) CleanupRemote(ctx context.Context) (*interfaces.SyncResult, error) {
+	result := &interfaces.SyncResult{}
+	for _, syncPath := range e.enabledPaths() {
+		syncer := NewPulsePointBatchSyncer(e.strategyFor(syncPath), e.stateManagerFor(syncPath), e.provider, syncPath.Local, syncPath.RemoteRoot(), e.logger)
+		cleaned, err := syncer.CleanupRemote(ctx)
+		if err != nil {
+			return nil, pperrors.NewSyncError(fmt.Sprintf("cleanup of %s failed", syncPath.Name), err)
+		}
+		mergeSyncResult(result, cleaned)
+	}
+
+	result.Success = len(result.Errors) == 0
+	return result, nil
+}
+
+// SetChangeSource replaces the queue the engine takes changes from
+func (e *PulsePointEngine) SetChangeSource(source ChangeSource) {
+	e.changes = source
+}
+
+// Enqueue adds changes found outside the watcher, such as a reconciliation scan
+func (e *PulsePointEngine) Enqueue(events []*models.ChangeEvent) error {
+	if e.changes == nil {
+		return pperrors.NewSyncError("no change queue configured", nil)
+	}
+
+	for _, event := range events {
+		if err := e.changes.Add(event); err != nil {
+			return pperrors.NewSyncError("failed to queue change", err)
+		}
+	}
+	return nil
+}
+
+// takeChanges removes all pending changes from the queue
+func (e *PulsePointEngine) takeChanges() []*models.ChangeEvent {
+	if e.changes == nil {
+		return nil
+	}
+	return e.changes.Drain(0)
+}
+
+// requeueFailed puts failed changes back in the queue while they have retries left
+func (e *PulsePointEngine) requeueFailed(events []*models.ChangeEvent) {
+	if e.changes == nil {
+		return
+	}
+
+	var retry []*models.ChangeEvent
+	for _, event := range events {
+		if event.CanRetry(e.config.RetryAttempts) {
+			retry = append(retry, event)
+		} else if event.Status == models.EventStatusFailed {
+			e.logger.Error("Giving up on change after repeated failures",
+				zap.String("path", event.Path),
+				zap.Int("retries", event.Retries),
+				zap.String("error", event.Error),
+			)
+		}
+	}
+	e.changes.Requeue(retry)
+}
+
+// requeueUnfinished puts back every change a failed sync did not complete
+func (e *PulsePointEngine) requeueUnfinished(events []*models.ChangeEvent) {
+	if e.changes == nil {
+		return
+	}
+
+	var unfinished []*models.ChangeEvent
+	for _, event := range events {
+		if event.Status != models.EventStatusCompleted {
+			event.Status = models.EventStatusPending
+			unfinished = append(unfinished, event)
+		}
+	}
+	e.changes.Requeue(unfinished)
+}
+
+// SetPathStrategy makes the folder pair at local sync with its own strategy and
+// state manager, such as one scoped to the pair's namespace. Pairs without one
+// use the engine's.
+func (e *PulsePointEngine) SetPathStrategy(local string, strategy interfaces.SyncStrategy, stateManager interfaces.StateManager) {
+	e.pairs[local] = &pathSync{strategy: strategy, stateManager: stateManager}
+}
+
+// SetIgnoreMatcher replaces the ignore rules of the folder pair at local, for
+// callers that also load the pair's ignore file
+func (e *PulsePointEngine) SetIgnoreMatcher(local string, matcher *ignore.PulsePointIgnoreMatcher) {
+	e.ignores[local] = matcher
+}
+
+// strategyFor returns the strategy a folder pair syncs with
+func (e *PulsePointEngine) strategyFor(syncPath *SyncPath) interfaces.SyncStrategy {
+	if syncPath != nil {
+		if pair, ok := e.pairs[syncPath.Local]; ok {
+			return pair.strategy
+		}
+	}
+	return e.strategy
+}
+
+// stateManagerFor returns the state manager of a folder pair
+func (e *PulsePointEngine) stateManagerFor(syncPath *SyncPath) interfaces.StateManager {
+	if syncPath != nil {
+		if pair, ok := e.pairs[syncPath.Local]; ok {
+			return pair.stateManager
+		}
+	}
+	return e.stateManager
+}
+
+// enabledPaths returns the folder pairs that are switched on
+func (e *PulsePointEngine) enabledPaths() []*SyncPath {
+	var paths []*SyncPath
+	for i := range e.config.Paths {
+		if e.config.Paths[i].Enabled {
+			paths = append(paths, &e.config.Paths[i])
+		}
+	}
+	return paths
+}
+
+// syncPathFor returns the enabled folder pair a change belongs to
+func (e *PulsePointEngine) syncPathFor(target, source string) *SyncPath {
+	return MatchSyncPath(e.enabledPaths(), target, source)
+}
+
+// remotePath returns where a file was last synced to on the provider
+func (e *PulsePointEngine) remotePath(state *interfaces.FileState) string {
+	if remotePath, ok := state.Metadata["remote_path"].(string); ok && remotePath != "" {
+		return remotePath
+	}
+
+	syncPath := e.syncPathFor(state.Path, "local")
+	if syncPath == nil {
+		return ""
+	}
+	return utils.RemotePath(syncPath.Local, syncPath.RemoteRoot(), state.Path)
+}
+
 // GetStatus returns the current engine status
 func (e *PulsePointEngine) GetStatus() (*EngineStatus, error) {
 	e.mu.RLock()
@@ -379,13 +580,37 @@ func (e *PulsePointEngine) handleFileChange(ctx context.Context, event interface
 		return
 	}
 
-	e.logger.Info("File change detected",
+	e.logger.Debug("File change detected",
 		zap.String("path", event.Path),
 		zap.String("type", string(event.Type)),
 	)
 
-	// TODO: Implement actual file change handling
-	// This would typically trigger a sync for the changed file
+	if e.changes == nil {
+		e.logger.Warn("No change queue configured, dropping change", zap.String("path", event.Path))
+		return
+	}
+
+	// The change is synced by the next scheduled or manual sync
+	if err := e.changes.Add(convertChangeEvent(event)); err != nil {
+		e.logger.Error("Failed to queue change",
+			zap.String("path", event.Path),
+			zap.Error(err),
+		)
+	}
+}
+
+// convertChangeEvent converts a watcher event to a queued change
+func convertChangeEvent(event interfaces.ChangeEvent) *models.ChangeEvent {
+	change := models.NewChangeEvent(models.ChangeType(event.Type), event.Path)
+	if event.Source != "" {
+		change.Source = event.Source
+	}
+	change.OldPath = event.OldPath
+	change.Timestamp = time.Unix(event.Timestamp, 0)
+	change.Size = event.Size
+	change.Hash = event.Hash
+	change.IsDir = event.IsDir
+	return change
 }
 
 // loadState loads the sync state from storage
@@ -2,13 +2,17 @@ package sync
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"github.com/pulsepoint/pulsepoint/internal/watchers/ignore"
+	"github.com/pulsepoint/pulsepoint/pkg/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 )
 
 // Mock implementations for testing
@@ -562,3 +566,285 @@ func TestEngineConfiguration(t *testing.T) {
 	assert.Equal(t, config.MaxFileSize, engine.config.MaxFileSize)
 	assert.Equal(t, config.IgnorePatterns, engine.config.IgnorePatterns)
 }
+
+// sliceChangeSource is an in-memory change source
+type sliceChangeSource struct {
+	events []*models.ChangeEvent
+}
+
+func (s *sliceChangeSource) Add(event *models.ChangeEvent) error {
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *sliceChangeSource) Drain(max int) []*models.ChangeEvent {
+	events := s.events
+	s.events = nil
+	return events
+}
+
+func (s *sliceChangeSource) Requeue(events []*models.ChangeEvent) {
+	s.events = append(s.events, events...)
+}
+
+func TestEngineSyncsQueuedChangesPerPath(t *testing.T) {
+	mockProvider := new(MockProvider)
+	mockWatcher := new(MockWatcher)
+	mockStrategy := new(MockStrategy)
+	mockStateManager := new(MockStateManager)
+
+	mockStateManager.On("LoadState", mock.Anything).Return(nil, nil).Once()
+	mockStateManager.On("GetFileState", mock.Anything, mock.Anything).Return(nil, nil)
+	mockStateManager.On("UpdateFileState", mock.Anything, mock.Anything).Return(nil)
+	mockProvider.On("GetMetadata", mock.Anything, mock.Anything).Return(&interfaces.Metadata{Hash: "r1"}, nil)
+	mockStrategy.On("Sync", mock.Anything, "/sync", "/Backup", mock.MatchedBy(func(changes []interfaces.ChangeEvent) bool {
+		return len(changes) == 2
+	})).Return(&interfaces.SyncResult{
+		FilesUploaded: 1,
+		Errors:        []interfaces.SyncError{{Path: "/sync/b.txt", Message: "network down"}},
+	}, nil)
+	mockStrategy.On("Sync", mock.Anything, "/work", "/Work", mock.MatchedBy(func(changes []interfaces.ChangeEvent) bool {
+		return len(changes) == 1 && changes[0].Path == "/work/c.txt"
+	})).Return(&interfaces.SyncResult{FilesUploaded: 1}, nil)
+
+	engine, err := NewPulsePointEngine(
+		mockProvider,
+		mockWatcher,
+		mockStrategy,
+		mockStateManager,
+		nil,
+		&EngineConfig{
+			Paths: []SyncPath{
+				{Name: "sync", Local: "/sync", Remote: "/Backup", Recursive: true, Enabled: true},
+				{Name: "work", Local: "/work", Remote: "/Work", Enabled: true, Ignore: []string{"*.log"}},
+				{Name: "old", Local: "/old", Remote: "/Old", Recursive: true},
+			},
+			RetryAttempts:  3,
+			IgnorePatterns: []string{"*.tmp"},
+		},
+	)
+	require.NoError(t, err)
+
+	source := &sliceChangeSource{}
+	engine.SetChangeSource(source)
+	require.NoError(t, engine.Enqueue([]*models.ChangeEvent{
+		models.NewChangeEvent(models.ChangeTypeModify, "/sync/a.txt"),
+		models.NewChangeEvent(models.ChangeTypeCreate, "/sync/b.txt"),
+		models.NewChangeEvent(models.ChangeTypeCreate, "/sync/scratch.tmp"),
+		models.NewChangeEvent(models.ChangeTypeCreate, "/work/c.txt"),
+		models.NewChangeEvent(models.ChangeTypeCreate, "/work/build.log"),
+		models.NewChangeEvent(models.ChangeTypeCreate, "/work/sub/d.txt"),
+		models.NewChangeEvent(models.ChangeTypeCreate, "/old/e.txt"),
+	}))
+
+	result, err := engine.pipeline.Execute(context.Background(), engine.createTransaction(interfaces.TransactionTypeFullSync))
+	require.NoError(t, err)
+
+	// Ignored files, subfolders of non-recursive pairs and disabled pairs are left out
+	assert.Equal(t, 2, result.FilesUploaded)
+	assert.Len(t, result.Errors, 1)
+	assert.False(t, result.Success)
+
+	// Only the failed change waits for the next sync
+	require.Len(t, source.events, 1)
+	assert.Equal(t, "/sync/b.txt", source.events[0].Path)
+	assert.Equal(t, 1, source.events[0].Retries)
+	mockStrategy.AssertExpectations(t)
+}
+
+func TestPipelineReportsFlaggedConflicts(t *testing.T) {
+	mockProvider := new(MockProvider)
+	mockWatcher := new(MockWatcher)
+	mockStrategy := new(MockStrategy)
+	mockStateManager := new(MockStateManager)
+
+	// a.txt changed locally and remotely since it was last synced
+	mockStateManager.On("LoadState", mock.Anything).Return(nil, nil).Once()
+	mockStateManager.On("GetFileState", mock.Anything, "/sync/a.txt").Return(&interfaces.FileState{
+		Path:       "/sync/a.txt",
+		LocalHash:  "l0",
+		RemoteHash: "r0",
+		Status:     interfaces.FileSyncStatusSynced,
+	}, nil)
+	mockStateManager.On("GetFileState", mock.Anything, mock.Anything).Return(nil, nil)
+	mockStateManager.On("UpdateFileState", mock.Anything, mock.Anything).Return(nil)
+	mockProvider.On("GetMetadata", mock.Anything, "/Backup/a.txt").Return(&interfaces.Metadata{Hash: "r1"}, nil)
+	mockProvider.On("GetMetadata", mock.Anything, mock.Anything).Return(&interfaces.Metadata{Hash: "r2"}, nil)
+	mockStrategy.On("Name").Return("one-way")
+	mockStrategy.On("GetDirection").Return(interfaces.SyncDirectionOneWay)
+	mockStrategy.On("ResolveConflict", mock.Anything, mock.MatchedBy(func(conflict *interfaces.Conflict) bool {
+		return conflict.Path == "/sync/a.txt"
+	})).Return(&interfaces.ConflictResolution{Strategy: interfaces.ResolutionSkip}, nil).Once()
+	mockStrategy.On("Sync", mock.Anything, "/sync", "/Backup", mock.MatchedBy(func(changes []interfaces.ChangeEvent) bool {
+		return len(changes) == 1 && changes[0].Path == "/sync/b.txt"
+	})).Return(&interfaces.SyncResult{FilesUploaded: 1}, nil).Once()
+
+	engine, err := NewPulsePointEngine(
+		mockProvider,
+		mockWatcher,
+		mockStrategy,
+		mockStateManager,
+		nil,
+		&EngineConfig{
+			Paths:         []SyncPath{{Name: "sync", Local: "/sync", Remote: "/Backup", Recursive: true, Enabled: true}},
+			RetryAttempts: 3,
+		},
+	)
+	require.NoError(t, err)
+
+	source := &sliceChangeSource{}
+	engine.SetChangeSource(source)
+	conflicted := models.NewChangeEvent(models.ChangeTypeModify, "/sync/a.txt")
+	conflicted.Hash = "l1"
+	require.NoError(t, engine.Enqueue([]*models.ChangeEvent{
+		conflicted,
+		models.NewChangeEvent(models.ChangeTypeCreate, "/sync/b.txt"),
+	}))
+
+	result, err := engine.pipeline.Execute(context.Background(), engine.createTransaction(interfaces.TransactionTypeFullSync))
+	require.NoError(t, err)
+
+	// The conflicted file is left alone and reported
+	assert.Equal(t, 1, result.FilesUploaded)
+	assert.Equal(t, 1, result.FilesSkipped)
+	require.Len(t, result.Conflicts, 1)
+	assert.Equal(t, "/sync/a.txt", result.Conflicts[0].Path)
+	assert.Equal(t, interfaces.ConflictTypeModified, result.Conflicts[0].Type)
+	assert.Equal(t, interfaces.ResolutionSkip, result.Conflicts[0].Resolution.Strategy)
+	assert.Equal(t, "r1", result.Conflicts[0].RemoteFile.Hash)
+	assert.False(t, result.Success)
+	assert.Equal(t, models.EventStatusSkipped, conflicted.Status)
+	mockStrategy.AssertExpectations(t)
+}
+
+// failingPhase is an idempotent phase that always fails
+type failingPhase struct {
+	calls int
+}
+
+func (p *failingPhase) Name() string {
+	return "failing"
+}
+
+func (p *failingPhase) Execute(ctx context.Context, input *PipelineInput) (*PipelineOutput, error) {
+	p.calls++
+	return nil, errors.New("temporarily unavailable")
+}
+
+func (p *failingPhase) Validate(input *PipelineInput) error {
+	return nil
+}
+
+func (p *failingPhase) Idempotent() bool {
+	return true
+}
+
+func TestPipelineRetryWaitStopsOnCancel(t *testing.T) {
+	phase := &failingPhase{}
+	pipeline := &PulsePointPipeline{
+		logger: zap.NewNop(),
+		phases: []PipelinePhase{phase},
+		config: &PipelineConfig{MaxRetries: 3, RetryDelay: time.Hour},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := pipeline.runPhases(ctx, &PipelineInput{Transaction: &interfaces.SyncTransaction{ID: "tx"}})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, 1, phase.calls)
+}
+
+func TestPipelineDoesNotRetryExecution(t *testing.T) {
+	mockProvider := new(MockProvider)
+	mockWatcher := new(MockWatcher)
+	mockStrategy := new(MockStrategy)
+	mockStateManager := new(MockStateManager)
+
+	mockStateManager.On("LoadState", mock.Anything).Return(nil, nil).Once()
+	mockStateManager.On("GetFileState", mock.Anything, mock.Anything).Return(nil, nil)
+	mockStrategy.On("Sync", mock.Anything, "/sync", "/Backup", mock.Anything).
+		Return(nil, errors.New("connection reset"))
+
+	engine, err := NewPulsePointEngine(
+		mockProvider,
+		mockWatcher,
+		mockStrategy,
+		mockStateManager,
+		nil,
+		&EngineConfig{
+			Paths:         []SyncPath{{Name: "sync", Local: "/sync", Remote: "/Backup", Recursive: true, Enabled: true}},
+			RetryAttempts: 3,
+		},
+	)
+	require.NoError(t, err)
+	engine.pipeline.config.RetryDelay = time.Millisecond
+
+	source := &sliceChangeSource{}
+	engine.SetChangeSource(source)
+	require.NoError(t, engine.Enqueue([]*models.ChangeEvent{
+		models.NewChangeEvent(models.ChangeTypeCreate, "/sync/a.txt"),
+	}))
+
+	_, err = engine.pipeline.Execute(context.Background(), engine.createTransaction(interfaces.TransactionTypeFullSync))
+	require.Error(t, err)
+
+	// Uploads may have partly happened, so the change waits for the next sync
+	mockStrategy.AssertNumberOfCalls(t, "Sync", 1)
+	require.Len(t, source.events, 1)
+	assert.Equal(t, "/sync/a.txt", source.events[0].Path)
+}
+
+func TestEngineSyncsPairsWithTheirOwnStrategy(t *testing.T) {
+	mockProvider := new(MockProvider)
+	mockWatcher := new(MockWatcher)
+	defaultStrategy := new(MockStrategy)
+	pairStrategy := new(MockStrategy)
+	mockStateManager := new(MockStateManager)
+	pairState := new(MockStateManager)
+
+	mockStateManager.On("LoadState", mock.Anything).Return(nil, nil).Once()
+	pairState.On("GetFileState", mock.Anything, mock.Anything).Return(nil, nil)
+	pairState.On("UpdateFileState", mock.Anything, mock.Anything).Return(nil)
+	mockProvider.On("GetMetadata", mock.Anything, mock.Anything).Return(&interfaces.Metadata{Hash: "r1"}, nil)
+	pairStrategy.On("Sync", mock.Anything, "/sync", "/Backup", mock.MatchedBy(func(changes []interfaces.ChangeEvent) bool {
+		return len(changes) == 1 && changes[0].Path == "/sync/a.txt"
+	})).Return(&interfaces.SyncResult{FilesUploaded: 1}, nil).Once()
+
+	engine, err := NewPulsePointEngine(
+		mockProvider,
+		mockWatcher,
+		defaultStrategy,
+		mockStateManager,
+		nil,
+		&EngineConfig{
+			Paths:         []SyncPath{{Name: "sync", Local: "/sync", Remote: "/Backup", Recursive: true, Enabled: true}},
+			RetryAttempts: 3,
+		},
+	)
+	require.NoError(t, err)
+
+	matcher := ignore.NewPulsePointIgnoreMatcher()
+	matcher.AddPatterns([]string{"*.log"})
+	engine.SetPathStrategy("/sync", pairStrategy, pairState)
+	engine.SetIgnoreMatcher("/sync", matcher)
+
+	source := &sliceChangeSource{}
+	engine.SetChangeSource(source)
+	require.NoError(t, engine.Enqueue([]*models.ChangeEvent{
+		models.NewChangeEvent(models.ChangeTypeCreate, "/sync/a.txt"),
+		models.NewChangeEvent(models.ChangeTypeCreate, "/sync/build.log"),
+	}))
+
+	result, err := engine.pipeline.Execute(context.Background(), engine.createTransaction(interfaces.TransactionTypeFullSync))
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.FilesUploaded)
+
+	pairStrategy.AssertExpectations(t)
+	pairState.AssertCalled(t, "UpdateFileState", mock.Anything, mock.Anything)
+	defaultStrategy.AssertNotCalled(t, "Sync", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockStateManager.AssertNotCalled(t, "UpdateFileState", mock.Anything, mock.Anything)
+}
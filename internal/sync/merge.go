@@ -0,0 +1,183 @@
+package sync
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pulsepoint/pulsepoint/pkg/utils"
+)
+
+// Conflict markers written around hunks that cannot be merged automatically
+const (
+	mergeMarkerLocal  = utils.MergeMarkerLocal
+	mergeMarkerBase   = "||||||| base"
+	mergeMarkerSep    = "======="
+	mergeMarkerRemote = utils.MergeMarkerRemote
+)
+
+// maxMergeCells bounds the LCS table so huge files fall back to keep-both
+const maxMergeCells = 16 * 1024 * 1024
+
+// mergeResult is the outcome of a three-way text merge
+type mergeResult struct {
+	Content   string
+	Conflicts []string // Merged line ranges written with conflict markers
+}
+
+// mergeText performs a line-based diff3 merge of local and remote against base.
+// It returns false when the inputs are too large to diff.
+func mergeText(base, local, remote string) (*mergeResult, bool) {
+	o := splitLines(base)
+	a := splitLines(local)
+	b := splitLines(remote)
+
+	matchA, ok := matchLines(o, a)
+	if !ok {
+		return nil, false
+	}
+	matchB, ok := matchLines(o, b)
+	if !ok {
+		return nil, false
+	}
+
+	var out []string
+	result := &mergeResult{}
+	po, pa, pb := 0, 0, 0
+
+	for po < len(o) || pa < len(a) || pb < len(b) {
+		// Stable region: the next base line is kept at the same place on both sides
+		if po < len(o) && matchA[po] == pa && matchB[po] == pb {
+			out = append(out, o[po])
+			po, pa, pb = po+1, pa+1, pb+1
+			continue
+		}
+
+		// Unstable region: runs up to the next base line both sides kept
+		next := po
+		for next < len(o) && (matchA[next] < 0 || matchB[next] < 0) {
+			next++
+		}
+		endA, endB := len(a), len(b)
+		if next < len(o) {
+			endA, endB = matchA[next], matchB[next]
+		}
+
+		chunkO, chunkA, chunkB := o[po:next], a[pa:endA], b[pb:endB]
+		switch {
+		case equalLines(chunkA, chunkO):
+			out = append(out, chunkB...)
+		case equalLines(chunkB, chunkO), equalLines(chunkA, chunkB):
+			out = append(out, chunkA...)
+		default:
+			start := len(out) + 1
+			out = append(out, mergeMarkerLocal+"\n")
+			out = appendTerminated(out, chunkA)
+			out = append(out, mergeMarkerBase+"\n")
+			out = appendTerminated(out, chunkO)
+			out = append(out, mergeMarkerSep+"\n")
+			out = appendTerminated(out, chunkB)
+			out = append(out, mergeMarkerRemote+"\n")
+			result.Conflicts = append(result.Conflicts, fmt.Sprintf("lines %d-%d", start, len(out)))
+		}
+
+		po, pa, pb = next, endA, endB
+	}
+
+	result.Content = strings.Join(out, "")
+	return result, true
+}
+
+// matchLines maps each base line to its index in other along a longest common
+// subsequence, or -1 when the line was removed. Common prefix and suffix are
+// matched directly to keep the table small.
+func matchLines(base, other []string) ([]int, bool) {
+	match := make([]int, len(base))
+	for i := range match {
+		match[i] = -1
+	}
+
+	prefix := 0
+	for prefix < len(base) && prefix < len(other) && base[prefix] == other[prefix] {
+		match[prefix] = prefix
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(base)-prefix && suffix < len(other)-prefix &&
+		base[len(base)-1-suffix] == other[len(other)-1-suffix] {
+		match[len(base)-1-suffix] = len(other) - 1 - suffix
+		suffix++
+	}
+
+	x := base[prefix : len(base)-suffix]
+	y := other[prefix : len(other)-suffix]
+	if len(x) == 0 || len(y) == 0 {
+		return match, true
+	}
+	if (len(x)+1)*(len(y)+1) > maxMergeCells {
+		return nil, false
+	}
+
+	// lengths[i][j] is the LCS length of x[i:] and y[j:]
+	width := len(y) + 1
+	lengths := make([]int32, (len(x)+1)*width)
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			switch {
+			case x[i] == y[j]:
+				lengths[i*width+j] = lengths[(i+1)*width+j+1] + 1
+			case lengths[(i+1)*width+j] >= lengths[i*width+j+1]:
+				lengths[i*width+j] = lengths[(i+1)*width+j]
+			default:
+				lengths[i*width+j] = lengths[i*width+j+1]
+			}
+		}
+	}
+
+	for i, j := 0, 0; i < len(x) && j < len(y); {
+		switch {
+		case x[i] == y[j]:
+			match[prefix+i] = prefix + j
+			i++
+			j++
+		case lengths[(i+1)*width+j] >= lengths[i*width+j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	return match, true
+}
+
+// splitLines splits text into lines that keep their line endings
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// equalLines reports whether two line slices are identical
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// appendTerminated appends lines, making sure the last one ends with a newline
+// so a following conflict marker starts on its own line
+func appendTerminated(out, lines []string) []string {
+	out = append(out, lines...)
+	if n := len(out); n > 0 && !strings.HasSuffix(out[n-1], "\n") {
+		out[n-1] += "\n"
+	}
+	return out
+}
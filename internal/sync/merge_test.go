@@ -0,0 +1,196 @@
+package sync
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"github.com/pulsepoint/pulsepoint/internal/database"
+	"github.com/pulsepoint/pulsepoint/internal/providers/mock"
+	"github.com/pulsepoint/pulsepoint/internal/strategies"
+	"github.com/pulsepoint/pulsepoint/pkg/models"
+	"github.com/pulsepoint/pulsepoint/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestMergeTextCombinesSeparateEdits(t *testing.T) {
+	base := "a\nb\nc\nd\ne\n"
+	local := "a\nB\nc\nd\ne\n"
+	remote := "a\nb\nc\nd\nE\nf\n"
+
+	merged, ok := mergeText(base, local, remote)
+	require.True(t, ok)
+
+	assert.Equal(t, "a\nB\nc\nd\nE\nf\n", merged.Content)
+	assert.Empty(t, merged.Conflicts)
+}
+
+func TestMergeTextMarksOverlappingEdits(t *testing.T) {
+	base := "name: app\nport: 80\n"
+	local := "name: app\nport: 8080\n"
+	remote := "name: app\nport: 9090\n"
+
+	merged, ok := mergeText(base, local, remote)
+	require.True(t, ok)
+
+	expected := strings.Join([]string{
+		"name: app",
+		mergeMarkerLocal,
+		"port: 8080",
+		mergeMarkerBase,
+		"port: 80",
+		mergeMarkerSep,
+		"port: 9090",
+		mergeMarkerRemote,
+		"",
+	}, "\n")
+	assert.Equal(t, expected, merged.Content)
+	assert.Equal(t, []string{"lines 2-8"}, merged.Conflicts)
+}
+
+func TestMergeTextTakesIdenticalChangesOnce(t *testing.T) {
+	merged, ok := mergeText("x\n", "x\ny\n", "x\ny\n")
+	require.True(t, ok)
+
+	assert.Equal(t, "x\ny\n", merged.Content)
+	assert.Empty(t, merged.Conflicts)
+}
+
+func TestConflictResolverMergeWritesLocalFile(t *testing.T) {
+	dir := t.TempDir()
+	localPath := filepath.Join(dir, "notes.md")
+	require.NoError(t, os.WriteFile(localPath, []byte("# Notes\nlocal line\n\nend\n"), 0600))
+
+	resolver := NewPulsePointConflictResolver(zap.NewNop(), interfaces.ResolutionMerge, &ConflictResolverConfig{
+		DefaultStrategy: interfaces.ResolutionMerge,
+		MergeTextFiles:  true,
+	})
+
+	conflict := &interfaces.Conflict{
+		Path:       localPath,
+		Type:       interfaces.ConflictTypeModified,
+		LocalFile:  &interfaces.File{Path: localPath, LocalPath: localPath},
+		RemoteFile: &interfaces.File{Path: "/notes.md", Content: strings.NewReader("# Notes\n\nend\nremote line\n")},
+		BaseFile:   &interfaces.File{Path: "/notes.md", Hash: "base-hash", Content: strings.NewReader("# Notes\n\nend\n")},
+	}
+
+	resolution, err := resolver.ResolveConflict(context.Background(), conflict)
+	require.NoError(t, err)
+
+	assert.Equal(t, interfaces.ResolutionMerge, resolution.Strategy)
+	assert.Equal(t, "merged", resolution.Winner)
+	assert.Equal(t, "base-hash", resolution.MergeBase)
+	assert.Empty(t, resolution.MergeConflicts)
+
+	content, err := os.ReadFile(localPath)
+	require.NoError(t, err)
+	assert.Equal(t, "# Notes\nlocal line\n\nend\nremote line\n", string(content))
+}
+
+func TestMergeWithConflictMarkersIsNotMergedAgain(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	localRoot := filepath.Join(dir, "sync")
+	localPath := filepath.Join(localRoot, "notes.md")
+	require.NoError(t, os.MkdirAll(localRoot, 0755))
+
+	options := database.DefaultOptions()
+	options.Path = filepath.Join(dir, "test.db")
+	db, err := database.NewManager(options)
+	require.NoError(t, err)
+	require.NoError(t, db.Open())
+	t.Cleanup(func() { db.Close() })
+
+	stateManager := NewPulsePointStateManager(db, zap.NewNop(), nil)
+	require.NoError(t, stateManager.Initialize(options.Path))
+	stateManager.SetBaseStore(NewPulsePointBaseStore(filepath.Join(dir, "bases"), 0, zap.NewNop()))
+
+	provider := mock.NewMockDriveProvider()
+	require.NoError(t, provider.Initialize(interfaces.ProviderConfig{}))
+	upload := func(content string) {
+		require.NoError(t, provider.Upload(ctx, &interfaces.File{Path: "/notes.md", Content: strings.NewReader(content)}))
+	}
+
+	// Both sides hold the same version after the last sync
+	base := "# Notes\nshared line\n"
+	require.NoError(t, os.WriteFile(localPath, []byte(base), 0644))
+	upload(base)
+	localHash, err := utils.FileSHA256(localPath)
+	require.NoError(t, err)
+	remote, err := provider.GetMetadata(ctx, "/notes.md")
+	require.NoError(t, err)
+	require.NoError(t, stateManager.UpdateFileState(ctx, &interfaces.FileState{
+		Path:       localPath,
+		LocalHash:  localHash,
+		RemoteHash: remote.Hash,
+		RemoteID:   remote.ID,
+		Status:     interfaces.FileSyncStatusSynced,
+		Metadata:   map[string]interface{}{"remote_path": "/notes.md"},
+	}))
+
+	resolver := NewPulsePointConflictResolver(zap.NewNop(), interfaces.ResolutionMerge, &ConflictResolverConfig{
+		DefaultStrategy: interfaces.ResolutionMerge,
+		MergeTextFiles:  true,
+	})
+	resolver.SetProvider(provider)
+	resolver.SetBaseStore(stateManager.BaseStore())
+	strategy := strategies.NewPulsePointTwoWayStrategy(provider, stateManager, zap.NewNop(), &interfaces.StrategyConfig{
+		ConflictResolution: interfaces.ResolutionMerge,
+	})
+	strategy.SetConflictResolver(resolver)
+	syncer := NewPulsePointBatchSyncer(strategy, stateManager, provider, localRoot, "/", zap.NewNop())
+
+	localEdit := func(content string) []*models.ChangeEvent {
+		require.NoError(t, os.WriteFile(localPath, []byte(content), 0644))
+		hash, err := utils.FileSHA256(localPath)
+		require.NoError(t, err)
+		event := models.NewChangeEvent(models.ChangeTypeModify, localPath)
+		event.Hash = hash
+		return []*models.ChangeEvent{event}
+	}
+
+	// The same line changed on both sides leaves markers in the local file
+	upload("# Notes\nremote line\n")
+	_, err = syncer.SyncBatch(ctx, localEdit("# Notes\nlocal line\n"))
+	require.NoError(t, err)
+	merged, err := os.ReadFile(localPath)
+	require.NoError(t, err)
+	require.True(t, utils.HasMergeMarkers(localPath))
+	state, err := stateManager.GetFileState(ctx, localPath)
+	require.NoError(t, err)
+	assert.Equal(t, interfaces.FileSyncStatusConflict, state.Status)
+
+	// The watcher reports the merge's own write; the markers are left alone
+	hash, err := utils.FileSHA256(localPath)
+	require.NoError(t, err)
+	event := models.NewChangeEvent(models.ChangeTypeModify, localPath)
+	event.Hash = hash
+	result, err := syncer.SyncBatch(ctx, []*models.ChangeEvent{event})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.FilesSkipped)
+	assert.Empty(t, result.Conflicts)
+	again, err := os.ReadFile(localPath)
+	require.NoError(t, err)
+	assert.Equal(t, string(merged), string(again), "markers are not merged again")
+	assert.Equal(t, 1, strings.Count(string(again), utils.MergeMarkerLocal))
+
+	// Editing the markers out settles the conflict in favour of the edit
+	result, err = syncer.SyncBatch(ctx, localEdit("# Notes\nlocal line\nremote line\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.FilesUploaded)
+	assert.Empty(t, result.Conflicts)
+	file, err := provider.Download(ctx, "/notes.md")
+	require.NoError(t, err)
+	content, err := io.ReadAll(file.Content)
+	require.NoError(t, err)
+	assert.Equal(t, "# Notes\nlocal line\nremote line\n", string(content))
+	state, err = stateManager.GetFileState(ctx, localPath)
+	require.NoError(t, err)
+	assert.Equal(t, interfaces.FileSyncStatusSynced, state.Status)
+}
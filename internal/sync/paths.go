@@ -0,0 +1,88 @@
+package sync
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pulsepoint/pulsepoint/internal/watchers/ignore"
+)
+
+// SyncPath pairs a local folder with the remote folder it syncs to
+type SyncPath struct {
+	Name      string   `json:"name" mapstructure:"name"`
+	Local     string   `json:"local" mapstructure:"local"`
+	Remote    string   `json:"remote" mapstructure:"remote"`
+	Recursive bool     `json:"recursive" mapstructure:"recursive"`
+	Enabled   bool     `json:"enabled" mapstructure:"enabled"`
+	Ignore    []string `json:"ignore" mapstructure:"ignore"`
+	Strategy  string   `json:"strategy" mapstructure:"strategy"` // Empty uses the default strategy
+}
+
+// RemoteRoot returns the remote folder, defaulting to the provider root
+func (p *SyncPath) RemoteRoot() string {
+	if p.Remote == "" {
+		return "/"
+	}
+	return path.Join("/", p.Remote)
+}
+
+// Relative returns a path relative to this pair's local or remote folder,
+// slash-separated, and whether the path lies inside it at all
+func (p *SyncPath) Relative(target, source string) (string, bool) {
+	if source == "remote" {
+		root := p.RemoteRoot()
+		target = path.Join("/", target)
+		if root == "/" {
+			return strings.TrimPrefix(target, "/"), true
+		}
+		if target == root {
+			return ".", true
+		}
+		if !strings.HasPrefix(target, root+"/") {
+			return "", false
+		}
+		return strings.TrimPrefix(target, root+"/"), true
+	}
+
+	rel, err := filepath.Rel(p.Local, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// Contains reports whether a change belongs to this pair. Changes in
+// subfolders only belong to recursive pairs.
+func (p *SyncPath) Contains(target, source string) bool {
+	rel, ok := p.Relative(target, source)
+	if !ok {
+		return false
+	}
+	return p.Recursive || !strings.Contains(rel, "/")
+}
+
+// IgnoreMatcher builds the ignore rules for this pair on top of the shared ones
+func (p *SyncPath) IgnoreMatcher(shared []string) *ignore.PulsePointIgnoreMatcher {
+	matcher := ignore.NewPulsePointIgnoreMatcher()
+	matcher.AddPatterns(shared)
+	matcher.AddPatterns(p.Ignore)
+	return matcher
+}
+
+// MatchSyncPath returns the folder pair a change belongs to, preferring the
+// most specific one when pairs are nested
+func MatchSyncPath(paths []*SyncPath, target, source string) *SyncPath {
+	var best *SyncPath
+	bestDepth := -1
+	for _, syncPath := range paths {
+		rel, ok := syncPath.Relative(target, source)
+		if !ok {
+			continue
+		}
+		if depth := len(target) - len(rel); depth > bestDepth {
+			best, bestDepth = syncPath, depth
+		}
+	}
+	return best
+}
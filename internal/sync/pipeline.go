@@ -3,7 +3,6 @@ package sync
 import (
 	"context"
 	"fmt"
-	"path/filepath"
 	"sync"
 	"time"
 
@@ -30,6 +29,7 @@ type PipelinePhase interface {
 	Name() string
 	Execute(ctx context.Context, input *PipelineInput) (*PipelineOutput, error)
 	Validate(input *PipelineInput) error
+	Idempotent() bool // Safe to run again after a failed attempt
 }
 
 // PipelineConfig holds pipeline configuration
@@ -45,7 +45,9 @@ type PipelineConfig struct {
 type PipelineInput struct {
 	Transaction *interfaces.SyncTransaction
 	Changes     []interfaces.ChangeEvent
+	Events      []*models.ChangeEvent // Queued changes behind Changes, in the same order
 	FileStates  map[string]*models.FileState
+	Conflicts   []interfaces.Conflict // Conflicts found by earlier phases
 	Metadata    map[string]interface{}
 }
 
@@ -98,75 +100,84 @@ func (p *PulsePointPipeline) Execute(ctx context.Context, transaction *interface
 		zap.String("type", string(transaction.Type)),
 	)
 
-	// Initialize pipeline input
+	// Take the changes queued since the last sync
+	events := p.engine.takeChanges()
+
 	input := &PipelineInput{
 		Transaction: transaction,
-		Changes:     []interfaces.ChangeEvent{},
+		Changes:     make([]interfaces.ChangeEvent, 0, len(events)),
+		Events:      events,
 		FileStates:  make(map[string]*models.FileState),
 		Metadata:    make(map[string]interface{}),
 	}
+	for _, event := range events {
+		input.Changes = append(input.Changes, toStrategyEvent(event))
+	}
 
-	// Get changes from watcher
-	// TODO: Implement GetChanges method or use a different approach
-	changes := []*models.ChangeEvent{} // Placeholder for now
-	for _, change := range changes {
-		input.Changes = append(input.Changes, interfaces.ChangeEvent{
-			Type:      interfaces.ChangeType(change.Type),
-			Path:      change.Path,
-			OldPath:   change.OldPath,
-			Timestamp: change.Timestamp.UnixNano(),
-			Size:      change.Size,
-			Hash:      change.Hash,
-			IsDir:     change.IsDir,
-		})
+	if err := p.runPhases(ctx, input); err != nil {
+		// Nothing was lost; unfinished changes wait for the next sync
+		p.engine.requeueUnfinished(input.Events)
+		return nil, err
 	}
+	p.engine.requeueFailed(input.Events)
+
+	// Create sync result from what the strategy did
+	result := &interfaces.SyncResult{}
+	if executed, ok := input.Metadata["execution_result"].(*interfaces.SyncResult); ok {
+		*result = *executed
+	}
+	rejected, _ := input.Metadata["rejected_count"].(int)
+	result.StartTime = startTime.UnixNano()
+	result.EndTime = time.Now().UnixNano()
+	result.FilesSkipped += rejected
+	result.Success = rejected == 0 && len(result.Errors) == 0 && len(result.Conflicts) == 0
+
+	// Update transaction
+	transaction.EndTime = time.Now()
+	transaction.BytesTransferred = result.BytesTransferred
+	transaction.Result = result
+
+	p.logger.Info("Sync pipeline completed",
+		zap.String("transaction_id", transaction.ID),
+		zap.Int("changes", len(events)),
+		zap.Int("files_processed", result.FilesProcessed),
+		zap.Int64("bytes_transferred", result.BytesTransferred),
+		zap.Bool("success", result.Success),
+		zap.Duration("duration", time.Duration(result.EndTime-result.StartTime)),
+	)
+
+	return result, nil
+}
 
-	// Execute each phase
-	var output *PipelineOutput
+// runPhases runs every phase over the input, in order
+func (p *PulsePointPipeline) runPhases(ctx context.Context, input *PipelineInput) error {
 	for _, phase := range p.phases {
 		p.logger.Info("Executing pipeline phase",
 			zap.String("phase", phase.Name()),
-			zap.String("transaction_id", transaction.ID),
+			zap.String("transaction_id", input.Transaction.ID),
 		)
 
 		// Validate input if enabled
 		if p.config.EnableValidation {
 			if err := phase.Validate(input); err != nil {
-				return nil, pperrors.NewSyncError(
+				return pperrors.NewSyncError(
 					fmt.Sprintf("validation failed for phase %s", phase.Name()),
 					err,
 				)
 			}
 		}
 
-		// Execute phase with retries
-		var err error
-		for retry := 0; retry <= p.config.MaxRetries; retry++ {
-			output, err = phase.Execute(ctx, input)
-			if err == nil {
-				break
-			}
-
-			if retry < p.config.MaxRetries {
-				p.logger.Warn("Phase execution failed, retrying",
-					zap.String("phase", phase.Name()),
-					zap.Int("retry", retry+1),
-					zap.Error(err),
-				)
-				time.Sleep(p.config.RetryDelay)
-			}
-		}
-
+		output, err := p.executePhase(ctx, phase, input)
 		if err != nil {
-			return nil, pperrors.NewSyncError(
-				fmt.Sprintf("phase %s failed after %d retries", phase.Name(), p.config.MaxRetries),
-				err,
-			)
+			return err
 		}
 
 		// Update input for next phase
 		if output != nil {
 			input.Metadata = output.Metadata
+			if output.Conflicts != nil {
+				input.Conflicts = output.Conflicts
+			}
 			// Add processed files to file states
 			for _, file := range output.ProcessedFiles {
 				state := models.NewFileState(file.Path)
@@ -176,34 +187,41 @@ func (p *PulsePointPipeline) Execute(ctx context.Context, transaction *interface
 		}
 	}
 
-	// Create sync result
-	result := &interfaces.SyncResult{
-		StartTime:        startTime.UnixNano(),
-		EndTime:          time.Now().UnixNano(),
-		FilesProcessed:   len(output.ProcessedFiles),
-		FilesUploaded:    0, // Will be updated by execution phase
-		FilesDownloaded:  0, // Will be updated by execution phase
-		FilesDeleted:     0, // Will be updated by execution phase
-		FilesSkipped:     len(output.FailedFiles),
-		BytesTransferred: output.BytesTransferred,
-		Conflicts:        output.Conflicts,
-		Success:          len(output.FailedFiles) == 0 && len(output.Conflicts) == 0,
+	return nil
+}
+
+// executePhase runs a phase, retrying idempotent ones until they succeed, the
+// retries run out or ctx is done
+func (p *PulsePointPipeline) executePhase(ctx context.Context, phase PipelinePhase, input *PipelineInput) (*PipelineOutput, error) {
+	retries := 0
+	if phase.Idempotent() {
+		retries = p.config.MaxRetries
 	}
 
-	// Update transaction
-	transaction.EndTime = time.Now()
-	transaction.BytesTransferred = output.BytesTransferred
-	transaction.Result = result
+	for attempt := 1; ; attempt++ {
+		output, err := phase.Execute(ctx, input)
+		if err == nil {
+			return output, nil
+		}
+		if attempt > retries || ctx.Err() != nil {
+			return nil, pperrors.NewSyncError(
+				fmt.Sprintf("phase %s failed after %d attempts", phase.Name(), attempt),
+				err,
+			)
+		}
 
-	p.logger.Info("Sync pipeline completed",
-		zap.String("transaction_id", transaction.ID),
-		zap.Int("files_processed", result.FilesProcessed),
-		zap.Int64("bytes_transferred", result.BytesTransferred),
-		zap.Bool("success", result.Success),
-		zap.Duration("duration", time.Duration(result.EndTime-result.StartTime)),
-	)
+		p.logger.Warn("Phase execution failed, retrying",
+			zap.String("phase", phase.Name()),
+			zap.Int("retry", attempt),
+			zap.Error(err),
+		)
 
-	return result, nil
+		select {
+		case <-ctx.Done():
+			return nil, pperrors.NewSyncError(fmt.Sprintf("phase %s cancelled before retrying", phase.Name()), ctx.Err())
+		case <-time.After(p.config.RetryDelay):
+		}
+	}
 }
 
 // CollectionPhase collects files that need to be synced
@@ -237,10 +255,32 @@ func (p *CollectionPhase) Execute(ctx context.Context, input *PipelineInput) (*P
 		Metadata:       make(map[string]interface{}),
 	}
 
-	// Process each change event
-	for _, change := range input.Changes {
-		// Skip directories for now
+	// Keep the changes that pass the filters, together with their queued events
+	changes := make([]interfaces.ChangeEvent, 0, len(input.Changes))
+	events := make([]*models.ChangeEvent, 0, len(input.Events))
+	rejected := 0
+
+	for i, change := range input.Changes {
+		event := input.Events[i]
+
+		syncPath := p.engine.syncPathFor(change.Path, change.Source)
+		if syncPath == nil {
+			p.logger.Warn("Change outside configured sync paths", zap.String("path", change.Path))
+			event.MarkProcessed()
+			continue
+		}
+
+		// Check if file should be ignored
+		if p.shouldIgnore(syncPath, change) {
+			p.logger.Debug("Ignoring file", zap.String("path", change.Path))
+			event.MarkProcessed()
+			continue
+		}
+
+		// Directories are synced but not collected as files
 		if change.IsDir {
+			changes = append(changes, change)
+			events = append(events, event)
 			continue
 		}
 
@@ -253,12 +293,6 @@ func (p *CollectionPhase) Execute(ctx context.Context, input *PipelineInput) (*P
 			IsFolder:     change.IsDir,
 		}
 
-		// Check if file should be ignored
-		if p.shouldIgnore(file.Path) {
-			p.logger.Debug("Ignoring file", zap.String("path", file.Path))
-			continue
-		}
-
 		// Check file size limit
 		if p.engine.config.MaxFileSize > 0 && file.Size > p.engine.config.MaxFileSize {
 			p.logger.Warn("File exceeds size limit",
@@ -266,40 +300,68 @@ func (p *CollectionPhase) Execute(ctx context.Context, input *PipelineInput) (*P
 				zap.Int64("size", file.Size),
 				zap.Int64("limit", p.engine.config.MaxFileSize),
 			)
+			event.SetError(fmt.Errorf("file exceeds size limit of %d bytes", p.engine.config.MaxFileSize))
 			output.FailedFiles = append(output.FailedFiles, file)
+			rejected++
 			continue
 		}
 
-		output.ProcessedFiles = append(output.ProcessedFiles, file)
+		changes = append(changes, change)
+		events = append(events, event)
+
+		// Remote changes are inspected by the strategy against the local copy
+		if change.Source != "remote" {
+			output.ProcessedFiles = append(output.ProcessedFiles, file)
+		}
 	}
 
+	input.Changes = changes
+	input.Events = events
+
 	p.logger.Info("File collection completed",
 		zap.Int("collected", len(output.ProcessedFiles)),
 		zap.Int("failed", len(output.FailedFiles)),
 	)
 
 	output.Metadata["collection_count"] = len(output.ProcessedFiles)
+	output.Metadata["rejected_count"] = rejected
 	return output, nil
 }
 
+// Idempotent reports that collection can run again; it only filters the input
+func (p *CollectionPhase) Idempotent() bool {
+	return true
+}
+
 // Validate validates the input for collection phase
 func (p *CollectionPhase) Validate(input *PipelineInput) error {
 	if input.Transaction == nil {
 		return fmt.Errorf("transaction is required")
 	}
+	if len(input.Events) != len(input.Changes) {
+		return fmt.Errorf("every change needs its queued event")
+	}
 	return nil
 }
 
-// shouldIgnore checks if a file should be ignored
-func (p *CollectionPhase) shouldIgnore(path string) bool {
-	// Check ignore patterns
-	for _, pattern := range p.engine.config.IgnorePatterns {
-		// Simple pattern matching (can be enhanced with glob patterns)
-		if matched, _ := filepath.Match(pattern, filepath.Base(path)); matched {
-			return true
-		}
+// shouldIgnore checks if a change is excluded by its folder pair
+func (p *CollectionPhase) shouldIgnore(syncPath *SyncPath, change interfaces.ChangeEvent) bool {
+	if !syncPath.Contains(change.Path, change.Source) {
+		// Inside a subfolder of a non-recursive pair
+		return true
 	}
-	return false
+
+	matcher := p.engine.ignores[syncPath.Local]
+	if matcher == nil {
+		return false
+	}
+	rel, _ := syncPath.Relative(change.Path, change.Source)
+	return matcher.ShouldIgnore(rel, change.IsDir)
+}
+
+// baseStoreOwner is implemented by state managers that keep merge bases
+type baseStoreOwner interface {
+	BaseStore() *PulsePointBaseStore
 }
 
 // AnalysisPhase analyzes files and detects conflicts
@@ -334,19 +396,37 @@ func (p *AnalysisPhase) Execute(ctx context.Context, input *PipelineInput) (*Pip
 		Metadata:       input.Metadata,
 	}
 
-	// Analyze each file
+	// A conflict is a file changed on both sides since it was last synced
 	for path, state := range input.FileStates {
-		// Get remote metadata
-		remoteMeta, err := p.engine.provider.GetMetadata(ctx, path)
+		syncPath := p.engine.syncPathFor(path, "local")
+		stateManager := p.engine.stateManagerFor(syncPath)
+		stored, err := stateManager.GetFileState(ctx, path)
+		if err != nil || stored == nil || stored.Status != interfaces.FileSyncStatusSynced {
+			// Never synced, nothing to compare against
+			continue
+		}
+		state.RemoteHash = stored.RemoteHash
+		state.RemoteModTime = stored.RemoteModTime
+		state.LastSyncTime = stored.LastSyncTime
+
+		remotePath := p.engine.remotePath(stored)
+		if remotePath == "" {
+			continue
+		}
+		remoteMeta, err := p.engine.provider.GetMetadata(ctx, remotePath)
 		if err != nil {
 			// File doesn't exist remotely, mark for upload
 			state.Status = models.FileSyncStatusPending
 			continue
 		}
 
-		// Check for conflicts
-		if state.LocalHash != "" && remoteMeta.Hash != "" && state.LocalHash != remoteMeta.Hash {
-			// Both files modified - conflict detected
+		localChanged := state.LocalHash == "" || state.LocalHash != stored.LocalHash
+		remoteChanged := stored.RemoteHash != "" && remoteMeta.Hash != "" && remoteMeta.Hash != stored.RemoteHash
+		if recorded, ok := remoteMeta.Attributes[interfaces.AttributeLocalHash].(string); ok && recorded == state.LocalHash {
+			// The remote copy was uploaded from this very content
+			remoteChanged = false
+		}
+		if localChanged && remoteChanged {
 			conflict := interfaces.Conflict{
 				Path: path,
 				Type: interfaces.ConflictTypeModified,
@@ -357,15 +437,22 @@ func (p *AnalysisPhase) Execute(ctx context.Context, input *PipelineInput) (*Pip
 					Size:         state.LocalSize,
 				},
 				RemoteFile: &interfaces.File{
-					Path:         remoteMeta.Path,
+					Path:         remotePath,
 					Hash:         remoteMeta.Hash,
 					ModifiedTime: remoteMeta.ModifiedTime,
 					Size:         remoteMeta.Size,
 				},
+				BaseFile:   p.mergeBase(stateManager, path, stored),
 				DetectedAt: time.Now().UnixNano(),
 			}
 			output.Conflicts = append(output.Conflicts, conflict)
 			state.SetConflict(string(interfaces.ConflictTypeModified))
+
+			// The strategy decides what happens to it
+			p.logger.Warn("File changed on both sides since last sync",
+				zap.String("path", path),
+				zap.String("strategy", p.engine.strategyFor(syncPath).Name()),
+			)
 		}
 	}
 
@@ -377,6 +464,26 @@ func (p *AnalysisPhase) Execute(ctx context.Context, input *PipelineInput) (*Pip
 	return output, nil
 }
 
+// mergeBase returns the last synced version of a file, if one was kept
+func (p *AnalysisPhase) mergeBase(stateManager interfaces.StateManager, path string, stored *interfaces.FileState) *interfaces.File {
+	owner, ok := stateManager.(baseStoreOwner)
+	if !ok || owner.BaseStore() == nil || !owner.BaseStore().Has(stored.LocalHash) {
+		return nil
+	}
+
+	return &interfaces.File{
+		Path:         path,
+		Hash:         stored.LocalHash,
+		ModifiedTime: stored.LastSyncTime,
+		LocalPath:    owner.BaseStore().Path(stored.LocalHash),
+	}
+}
+
+// Idempotent reports that analysis can run again; it only reads state
+func (p *AnalysisPhase) Idempotent() bool {
+	return true
+}
+
 // Validate validates the input for analysis phase
 func (p *AnalysisPhase) Validate(input *PipelineInput) error {
 	return nil
@@ -405,15 +512,56 @@ func (p *ExecutionPhase) Name() string {
 func (p *ExecutionPhase) Execute(ctx context.Context, input *PipelineInput) (*PipelineOutput, error) {
 	p.logger.Info("Starting sync execution")
 
-	// Use the sync strategy to perform the sync
-	result, err := p.engine.strategy.Sync(ctx,
-		p.engine.config.IgnorePatterns[0], // source path (simplified for now)
-		"remote://",                       // destination (simplified)
-		input.Changes,
-	)
+	// Each folder pair is synced with its own roots. The batch syncer runs the
+	// strategy and records the outcome of each change.
+	result := &interfaces.SyncResult{}
+	flagged := p.resolveConflicts(ctx, input)
+	for _, syncPath := range p.engine.enabledPaths() {
+		var events []*models.ChangeEvent
+		for _, event := range input.Events {
+			// Changes finished by an earlier attempt are not synced again
+			if event.Status == models.EventStatusCompleted {
+				continue
+			}
+			if conflict, ok := flagged[event.Path]; ok && !keepsLocal(conflict) {
+				p.logger.Warn("Skipping conflicted file",
+					zap.String("path", event.Path),
+					zap.String("resolution", string(conflict.Resolution.Strategy)),
+				)
+				event.Status = models.EventStatusSkipped
+				event.Processed = true
+				result.FilesSkipped++
+				continue
+			}
+			if p.engine.syncPathFor(event.Path, event.Source) == syncPath {
+				events = append(events, event)
+			}
+		}
+		if len(events) == 0 {
+			continue
+		}
 
-	if err != nil {
-		return nil, pperrors.NewSyncError("sync execution failed", err)
+		syncer := NewPulsePointBatchSyncer(
+			p.engine.strategyFor(syncPath),
+			p.engine.stateManagerFor(syncPath),
+			p.engine.provider,
+			syncPath.Local,
+			syncPath.RemoteRoot(),
+			p.logger,
+		)
+		batch, err := syncer.SyncBatch(ctx, events)
+		if err != nil {
+			return nil, pperrors.NewSyncError(fmt.Sprintf("sync of %s failed", syncPath.Name), err)
+		}
+		mergeSyncResult(result, batch)
+	}
+	addFlaggedConflicts(result, input.Conflicts, flagged)
+
+	// Synced files are checked by the verification phase
+	for _, event := range input.Events {
+		if state, ok := input.FileStates[event.Path]; ok && event.Status == models.EventStatusCompleted && !event.IsDelete() {
+			state.Status = models.FileSyncStatusSynced
+		}
 	}
 
 	output := &PipelineOutput{
@@ -436,11 +584,89 @@ func (p *ExecutionPhase) Execute(ctx context.Context, input *PipelineInput) (*Pi
 	return output, nil
 }
 
+// resolveConflicts asks the strategy how to settle the conflicts found by the
+// analysis phase. Two-way strategies detect and resolve conflicts themselves
+// while syncing, so their changes are passed through unchanged.
+func (p *ExecutionPhase) resolveConflicts(ctx context.Context, input *PipelineInput) map[string]*interfaces.Conflict {
+	flagged := make(map[string]*interfaces.Conflict, len(input.Conflicts))
+
+	for i := range input.Conflicts {
+		conflict := &input.Conflicts[i]
+		strategy := p.engine.strategyFor(p.engine.syncPathFor(conflict.Path, "local"))
+		if strategy.GetDirection() == interfaces.SyncDirectionTwoWay {
+			continue
+		}
+		flagged[conflict.Path] = conflict
+		if conflict.Resolution.Strategy != "" {
+			// Settled by an earlier attempt
+			continue
+		}
+
+		resolution, err := strategy.ResolveConflict(ctx, conflict)
+		if err != nil {
+			p.logger.Warn("Failed to resolve conflict",
+				zap.String("path", conflict.Path),
+				zap.Error(err),
+			)
+			continue
+		}
+		conflict.Resolution = *resolution
+	}
+	return flagged
+}
+
+// keepsLocal reports whether a conflict was resolved in favour of the local file,
+// which is what syncing the change does
+func keepsLocal(conflict *interfaces.Conflict) bool {
+	return conflict.Resolution.Strategy == interfaces.ResolutionKeepLocal
+}
+
+// addFlaggedConflicts reports the conflicts found by the analysis phase. A
+// conflict the strategy reported itself for the same path takes precedence.
+func addFlaggedConflicts(result *interfaces.SyncResult, conflicts []interfaces.Conflict, flagged map[string]*interfaces.Conflict) {
+	reported := make(map[string]bool, len(result.Conflicts))
+	for _, conflict := range result.Conflicts {
+		reported[conflict.Path] = true
+	}
+
+	for _, conflict := range conflicts {
+		if reported[conflict.Path] {
+			continue
+		}
+		if resolved, ok := flagged[conflict.Path]; ok {
+			conflict = *resolved
+		}
+		result.Conflicts = append(result.Conflicts, conflict)
+	}
+}
+
+// Idempotent reports that execution must not run again within a sync. Its
+// uploads and deletes may have partly happened; unfinished changes are
+// requeued for the next sync instead.
+func (p *ExecutionPhase) Idempotent() bool {
+	return false
+}
+
 // Validate validates the input for execution phase
 func (p *ExecutionPhase) Validate(input *PipelineInput) error {
+	if len(input.Changes) > 0 && len(p.engine.enabledPaths()) == 0 {
+		return fmt.Errorf("no sync paths configured")
+	}
 	return nil
 }
 
+// mergeSyncResult adds the outcome of one folder pair to the total
+func mergeSyncResult(total, result *interfaces.SyncResult) {
+	total.FilesProcessed += result.FilesProcessed
+	total.FilesUploaded += result.FilesUploaded
+	total.FilesDownloaded += result.FilesDownloaded
+	total.FilesDeleted += result.FilesDeleted
+	total.FilesSkipped += result.FilesSkipped
+	total.BytesTransferred += result.BytesTransferred
+	total.Errors = append(total.Errors, result.Errors...)
+	total.Conflicts = append(total.Conflicts, result.Conflicts...)
+}
+
 // VerificationPhase verifies the sync results
 type VerificationPhase struct {
 	engine *PulsePointEngine
@@ -481,14 +707,26 @@ func (p *VerificationPhase) Execute(ctx context.Context, input *PipelineInput) (
 		}
 
 		wg.Add(1)
-		go func(filePath string, fileState *models.FileState) {
+		go func(filePath string) {
 			defer wg.Done()
 
+			stateManager := p.engine.stateManagerFor(p.engine.syncPathFor(filePath, "local"))
+			stored, err := stateManager.GetFileState(ctx, filePath)
+			if err != nil || stored == nil {
+				// Strategies that sync nothing for a change leave no state
+				return
+			}
+
 			// Get remote metadata to verify
-			remoteMeta, err := p.engine.provider.GetMetadata(ctx, filePath)
+			remotePath := p.engine.remotePath(stored)
+			if remotePath == "" {
+				return
+			}
+			remoteMeta, err := p.engine.provider.GetMetadata(ctx, remotePath)
 			if err != nil {
 				p.logger.Error("Failed to verify file",
 					zap.String("path", filePath),
+					zap.String("remote_path", remotePath),
 					zap.Error(err),
 				)
 				mu.Lock()
@@ -497,18 +735,18 @@ func (p *VerificationPhase) Execute(ctx context.Context, input *PipelineInput) (
 				return
 			}
 
-			// Verify hash matches
-			if fileState.LocalHash != remoteMeta.Hash {
+			// The remote copy must be the one recorded at sync time
+			if stored.RemoteHash != "" && remoteMeta.Hash != "" && stored.RemoteHash != remoteMeta.Hash {
 				p.logger.Error("Hash mismatch after sync",
 					zap.String("path", filePath),
-					zap.String("local_hash", fileState.LocalHash),
+					zap.String("recorded_hash", stored.RemoteHash),
 					zap.String("remote_hash", remoteMeta.Hash),
 				)
 				mu.Lock()
 				verifyErrors++
 				mu.Unlock()
 			}
-		}(path, state)
+		}(path)
 	}
 
 	wg.Wait()
@@ -525,6 +763,11 @@ func (p *VerificationPhase) Execute(ctx context.Context, input *PipelineInput) (
 	return output, nil
 }
 
+// Idempotent reports that verification can run again; it only reads state
+func (p *VerificationPhase) Idempotent() bool {
+	return true
+}
+
 // Validate validates the input for verification phase
 func (p *VerificationPhase) Validate(input *PipelineInput) error {
 	return nil
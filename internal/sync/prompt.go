@@ -0,0 +1,105 @@
+package sync
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+)
+
+// ConflictPrompter asks the user how a conflict should be resolved
+type ConflictPrompter interface {
+	Prompt(ctx context.Context, conflict *interfaces.Conflict, details string) (*PromptAnswer, error)
+}
+
+// PromptAnswer is the user's choice for a conflict
+type PromptAnswer struct {
+	Strategy   interfaces.ResolutionStrategy
+	ApplyToAll bool // Use the same choice for similar conflicts
+}
+
+// promptChoices maps answers to resolution strategies
+var promptChoices = map[string]interfaces.ResolutionStrategy{
+	"l": interfaces.ResolutionKeepLocal,
+	"r": interfaces.ResolutionKeepRemote,
+	"b": interfaces.ResolutionKeepBoth,
+	"m": interfaces.ResolutionMerge,
+	"s": interfaces.ResolutionSkip,
+}
+
+// PulsePointTerminalPrompter prompts for conflict resolution on a terminal
+type PulsePointTerminalPrompter struct {
+	in    io.Reader
+	out   io.Writer
+	lines chan string
+	once  sync.Once
+	mu    sync.Mutex
+}
+
+// NewPulsePointTerminalPrompter creates a prompter reading answers from in
+func NewPulsePointTerminalPrompter(in io.Reader, out io.Writer) *PulsePointTerminalPrompter {
+	return &PulsePointTerminalPrompter{
+		in:    in,
+		out:   out,
+		lines: make(chan string),
+	}
+}
+
+// Prompt shows the conflict and waits for an answer until ctx is done.
+// An upper-case answer applies the choice to all similar conflicts.
+func (p *PulsePointTerminalPrompter) Prompt(
+	ctx context.Context,
+	conflict *interfaces.Conflict,
+	details string,
+) (*PromptAnswer, error) {
+	// One prompt at a time; batches may report several conflicts
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Input is read by a single goroutine so a timed out prompt does not
+	// swallow the answer to the next one
+	p.once.Do(func() {
+		go p.readLines()
+	})
+
+	fmt.Fprintf(p.out, "\n⚔️  Conflict: %s (%s)\n", conflict.Path, conflict.Type)
+	fmt.Fprint(p.out, details)
+
+	for {
+		fmt.Fprint(p.out, "Resolve with [l]ocal, [r]emote, [b]oth, [m]erge, [s]kip (upper-case applies to all similar): ")
+
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(p.out)
+			return nil, ctx.Err()
+
+		case line, ok := <-p.lines:
+			if !ok {
+				return nil, io.EOF
+			}
+
+			answer := strings.TrimSpace(line)
+			if strategy, found := promptChoices[strings.ToLower(answer)]; found {
+				return &PromptAnswer{
+					Strategy:   strategy,
+					ApplyToAll: answer != strings.ToLower(answer),
+				}, nil
+			}
+			fmt.Fprintf(p.out, "Unknown choice %q\n", answer)
+		}
+	}
+}
+
+// readLines feeds input lines to waiting prompts
+func (p *PulsePointTerminalPrompter) readLines() {
+	defer close(p.lines)
+
+	scanner := bufio.NewScanner(p.in)
+	for scanner.Scan() {
+		p.lines <- scanner.Text()
+	}
+}
@@ -0,0 +1,447 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"github.com/pulsepoint/pulsepoint/internal/watchers/ignore"
+	pperrors "github.com/pulsepoint/pulsepoint/pkg/errors"
+	"github.com/pulsepoint/pulsepoint/pkg/models"
+	"github.com/pulsepoint/pulsepoint/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// PulsePointReconciler compares the local tree, the remote tree and the stored
+// file state to find changes made while nothing was watching
+type PulsePointReconciler struct {
+	provider     interfaces.CloudProvider
+	stateManager interfaces.StateManager
+	localRoot    string
+	remoteRoot   string
+	ignore       *ignore.PulsePointIgnoreMatcher
+	config       *ReconcilerConfig
+	logger       *zap.Logger
+}
+
+// ReconcilerConfig holds configuration for a reconciliation scan
+type ReconcilerConfig struct {
+	IgnorePatterns []string `json:"ignore_patterns"`
+	IgnoreFile     string   `json:"ignore_file"`
+	HashAlgorithm  string   `json:"hash_algorithm"`
+	IncludeRemote  bool     `json:"include_remote"` // Report remote changes, for strategies that pull
+	Recursive      bool     `json:"recursive"`      // Descend into subfolders of both roots
+}
+
+// ReconcileStats summarizes a reconciliation scan
+type ReconcileStats struct {
+	LocalFiles  int `json:"local_files"`
+	RemoteFiles int `json:"remote_files"`
+	Created     int `json:"created"`
+	Modified    int `json:"modified"`
+	Deleted     int `json:"deleted"`
+}
+
+// NewPulsePointReconciler creates a reconciler for one local and remote root
+func NewPulsePointReconciler(
+	provider interfaces.CloudProvider,
+	stateManager interfaces.StateManager,
+	localRoot, remoteRoot string,
+	logger *zap.Logger,
+	config *ReconcilerConfig,
+) *PulsePointReconciler {
+	if config == nil {
+		config = &ReconcilerConfig{
+			HashAlgorithm: "sha256",
+			IncludeRemote: false,
+			Recursive:     true,
+		}
+	}
+	if remoteRoot == "" {
+		remoteRoot = "/"
+	}
+
+	matcher := ignore.NewPulsePointIgnoreMatcher()
+	matcher.AddPatterns(config.IgnorePatterns)
+	if config.IgnoreFile != "" {
+		if err := matcher.LoadFromFile(config.IgnoreFile); err != nil {
+			logger.Warn("Failed to load ignore file",
+				zap.String("file", config.IgnoreFile),
+				zap.Error(err),
+			)
+		}
+	}
+
+	return &PulsePointReconciler{
+		provider:     provider,
+		stateManager: stateManager,
+		localRoot:    localRoot,
+		remoteRoot:   remoteRoot,
+		ignore:       matcher,
+		config:       config,
+		logger:       logger.With(zap.String("component", "reconciler")),
+	}
+}
+
+// Scan walks both trees and returns synthetic change events for everything
+// that differs from the stored file state. Each path gets at most one event;
+// a local change wins over a remote one, since two-way sync inspects both
+// sides of a path whichever side reported it.
+func (r *PulsePointReconciler) Scan(ctx context.Context) ([]*models.ChangeEvent, *ReconcileStats, error) {
+	r.logger.Info("Starting reconciliation scan",
+		zap.String("local_root", r.localRoot),
+		zap.String("remote_root", r.remoteRoot),
+		zap.Bool("include_remote", r.config.IncludeRemote),
+	)
+
+	stats := &ReconcileStats{}
+
+	states, err := r.loadStates(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// A missing root must never look like an empty one
+	localFiles, err := r.walkLocal(ctx)
+	if err != nil {
+		return nil, nil, pperrors.NewFileSystemError(fmt.Sprintf("failed to walk %s", r.localRoot), err)
+	}
+	stats.LocalFiles = len(localFiles)
+
+	var events []*models.ChangeEvent
+	changed := make(map[string]bool)
+
+	// Local files that are new or differ from their last synced state
+	for path, info := range localFiles {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		state := states[path]
+		if state != nil && !r.localChanged(path, info, state) {
+			continue
+		}
+
+		changeType := models.ChangeTypeModify
+		if state == nil {
+			changeType = models.ChangeTypeCreate
+		}
+		events = append(events, r.localEvent(changeType, path, info, state))
+		changed[path] = true
+	}
+
+	// Synced files that are gone locally
+	for path := range states {
+		if _, exists := localFiles[path]; exists {
+			continue
+		}
+		if r.ignore.ShouldIgnore(r.relativeLocal(path), false) {
+			continue
+		}
+
+		events = append(events, r.newEvent(models.ChangeTypeDelete, "local", path))
+		changed[path] = true
+	}
+
+	if r.config.IncludeRemote {
+		remoteEvents, remoteCount, err := r.scanRemote(ctx, states, localFiles, changed)
+		if err != nil {
+			return nil, nil, err
+		}
+		stats.RemoteFiles = remoteCount
+		events = append(events, remoteEvents...)
+	}
+
+	for _, event := range events {
+		switch event.Type {
+		case models.ChangeTypeCreate:
+			stats.Created++
+		case models.ChangeTypeModify:
+			stats.Modified++
+		case models.ChangeTypeDelete:
+			stats.Deleted++
+		}
+	}
+
+	r.logger.Info("Reconciliation scan completed",
+		zap.Int("local_files", stats.LocalFiles),
+		zap.Int("remote_files", stats.RemoteFiles),
+		zap.Int("created", stats.Created),
+		zap.Int("modified", stats.Modified),
+		zap.Int("deleted", stats.Deleted),
+	)
+
+	return events, stats, nil
+}
+
+// scanRemote reports remote files that are new, changed or gone since their
+// last synced state, skipping paths that already have a local event
+func (r *PulsePointReconciler) scanRemote(
+	ctx context.Context,
+	states map[string]*interfaces.FileState,
+	localFiles map[string]os.FileInfo,
+	changed map[string]bool,
+) ([]*models.ChangeEvent, int, error) {
+	remoteFiles, err := r.listRemote(ctx)
+	if err != nil {
+		return nil, 0, pperrors.NewProviderError("failed to list remote files", err)
+	}
+
+	var events []*models.ChangeEvent
+	seen := make(map[string]bool, len(remoteFiles))
+
+	for _, file := range remoteFiles {
+		localPath := utils.LocalPath(r.localRoot, r.remoteRoot, file.Path)
+		seen[localPath] = true
+		if changed[localPath] {
+			continue
+		}
+
+		state := states[localPath]
+		switch {
+		case state == nil:
+			// New on the remote side, or already present on both without state
+			if _, exists := localFiles[localPath]; exists {
+				continue
+			}
+			events = append(events, r.remoteEvent(models.ChangeTypeCreate, file))
+
+		case r.remoteChanged(state, file):
+			events = append(events, r.remoteEvent(models.ChangeTypeModify, file))
+
+		default:
+			continue
+		}
+		changed[localPath] = true
+	}
+
+	// Synced files that are gone remotely
+	for path, state := range states {
+		if seen[path] || changed[path] {
+			continue
+		}
+		if _, exists := localFiles[path]; !exists {
+			continue
+		}
+
+		remotePath, _ := state.Metadata["remote_path"].(string)
+		if remotePath == "" {
+			remotePath = utils.RemotePath(r.localRoot, r.remoteRoot, path)
+		}
+		events = append(events, r.newEvent(models.ChangeTypeDelete, "remote", remotePath))
+		changed[path] = true
+	}
+
+	return events, len(remoteFiles), nil
+}
+
+// loadStates returns the stored states under the local root, keyed by path
+func (r *PulsePointReconciler) loadStates(ctx context.Context) (map[string]*interfaces.FileState, error) {
+	states, err := r.stateManager.ListFileStates(ctx)
+	if err != nil {
+		return nil, pperrors.NewDatabaseError("failed to list file states", err)
+	}
+
+	byPath := make(map[string]*interfaces.FileState, len(states))
+	for _, state := range states {
+		if r.inScope(state.Path) {
+			byPath[state.Path] = state
+		}
+	}
+	return byPath, nil
+}
+
+// inScope checks whether a local path belongs to the pair, which for a
+// non-recursive pair means sitting directly in the local root
+func (r *PulsePointReconciler) inScope(path string) bool {
+	if path == r.localRoot {
+		return true
+	}
+	if !strings.HasPrefix(path, r.localRoot+string(filepath.Separator)) {
+		return false
+	}
+	return r.config.Recursive || filepath.Dir(path) == r.localRoot
+}
+
+// walkLocal returns every file under the local root that is not ignored,
+// leaving out subfolders unless the scan is recursive
+func (r *PulsePointReconciler) walkLocal(ctx context.Context) (map[string]os.FileInfo, error) {
+	files := make(map[string]os.FileInfo)
+
+	err := filepath.WalkDir(r.localRoot, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if path == r.localRoot {
+			return nil
+		}
+
+		if r.ignore.ShouldIgnore(r.relativeLocal(path), entry.IsDir()) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() {
+			if !r.config.Recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			// Removed during the walk
+			return nil
+		}
+		files[path] = info
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// listRemote lists every file under the remote root that is not ignored,
+// leaving out subfolders unless the scan is recursive
+func (r *PulsePointReconciler) listRemote(ctx context.Context) ([]*interfaces.File, error) {
+	var files []*interfaces.File
+	seen := make(map[string]bool)
+
+	folders := []string{r.remoteRoot}
+	for len(folders) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		folder := folders[0]
+		folders = folders[1:]
+
+		entries, err := r.provider.List(ctx, folder)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			if seen[entry.Path] {
+				continue
+			}
+			seen[entry.Path] = true
+
+			rel := r.relativeLocal(utils.LocalPath(r.localRoot, r.remoteRoot, entry.Path))
+			if r.ignore.ShouldIgnore(rel, entry.IsFolder) {
+				continue
+			}
+
+			if entry.IsFolder {
+				if r.config.Recursive {
+					folders = append(folders, entry.Path)
+				}
+			} else {
+				files = append(files, entry)
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// localChanged checks a local file against its last synced state. The
+// content is only hashed when size or modification time moved.
+func (r *PulsePointReconciler) localChanged(path string, info os.FileInfo, state *interfaces.FileState) bool {
+	if state.Status != interfaces.FileSyncStatusSynced {
+		return true
+	}
+	if info.Size() == state.Size && info.ModTime().Equal(state.LocalModTime) {
+		return false
+	}
+	if state.LocalHash == "" {
+		return true
+	}
+
+	hash, err := utils.HashFileWith(path, r.hashAlgorithm(state.LocalHash))
+	if err != nil {
+		return true
+	}
+	return hash != state.LocalHash
+}
+
+// remoteChanged checks a remote file against its last synced state
+func (r *PulsePointReconciler) remoteChanged(state *interfaces.FileState, file *interfaces.File) bool {
+	if state.RemoteHash != "" && file.Hash != "" {
+		return state.RemoteHash != file.Hash
+	}
+	return !state.RemoteModTime.IsZero() && !file.ModifiedTime.Equal(state.RemoteModTime)
+}
+
+// localEvent builds an event for a local file, with its hash when known
+func (r *PulsePointReconciler) localEvent(
+	changeType models.ChangeType,
+	path string,
+	info os.FileInfo,
+	state *interfaces.FileState,
+) *models.ChangeEvent {
+	event := r.newEvent(changeType, "local", path)
+	event.Timestamp = info.ModTime()
+	event.Size = info.Size()
+
+	algorithm := r.config.HashAlgorithm
+	if state != nil && state.LocalHash != "" {
+		algorithm = r.hashAlgorithm(state.LocalHash)
+	}
+	if hash, err := utils.HashFileWith(path, algorithm); err == nil {
+		event.Hash = hash
+	}
+
+	return event
+}
+
+// remoteEvent builds an event for a remote file
+func (r *PulsePointReconciler) remoteEvent(changeType models.ChangeType, file *interfaces.File) *models.ChangeEvent {
+	event := r.newEvent(changeType, "remote", file.Path)
+	if !file.ModifiedTime.IsZero() {
+		event.Timestamp = file.ModifiedTime
+	}
+	event.Size = file.Size
+	event.Hash = file.Hash
+	return event
+}
+
+// newEvent builds a synthetic change event
+func (r *PulsePointReconciler) newEvent(changeType models.ChangeType, source, path string) *models.ChangeEvent {
+	event := models.NewChangeEvent(changeType, path)
+	event.Source = source
+	event.Metadata["reconciled"] = true
+	return event
+}
+
+// relativeLocal returns a local path relative to the root, for ignore matching
+func (r *PulsePointReconciler) relativeLocal(path string) string {
+	rel, err := filepath.Rel(r.localRoot, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// hashAlgorithm picks the algorithm that produced a stored hash
+func (r *PulsePointReconciler) hashAlgorithm(storedHash string) string {
+	if len(storedHash) == 32 {
+		return "md5"
+	}
+	if storedHash == "" && r.config.HashAlgorithm != "" {
+		return r.config.HashAlgorithm
+	}
+	return "sha256"
+}
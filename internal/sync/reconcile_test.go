@@ -0,0 +1,139 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"github.com/pulsepoint/pulsepoint/pkg/models"
+	"github.com/pulsepoint/pulsepoint/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestReconcilerFindsChangesSinceLastSync(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) (string, os.FileInfo) {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		return path, info
+	}
+	synced := func(path string, info os.FileInfo, remoteHash string) *interfaces.FileState {
+		hash, err := utils.HashFileWith(path, "sha256")
+		require.NoError(t, err)
+		return &interfaces.FileState{
+			Path:         path,
+			LocalHash:    hash,
+			RemoteHash:   remoteHash,
+			LocalModTime: info.ModTime(),
+			Size:         info.Size(),
+			Status:       interfaces.FileSyncStatusSynced,
+		}
+	}
+
+	unchangedPath, unchangedInfo := write("unchanged.txt", "same")
+	editedPath, editedInfo := write("edited.txt", "before")
+	keptPath, keptInfo := write("kept.txt", "kept")
+	newPath, _ := write("new.txt", "new")
+	write("scratch.tmp", "ignored")
+
+	editedState := synced(editedPath, editedInfo, "r-edited")
+	require.NoError(t, os.WriteFile(editedPath, []byte("after the edit"), 0600))
+
+	states := []*interfaces.FileState{
+		synced(unchangedPath, unchangedInfo, "r-unchanged"),
+		editedState,
+		synced(keptPath, keptInfo, "r-kept"),
+		{Path: filepath.Join(root, "gone.txt"), Status: interfaces.FileSyncStatusSynced},
+	}
+
+	mockProvider := new(MockProvider)
+	mockStateManager := new(MockStateManager)
+	mockStateManager.On("ListFileStates", mock.Anything).Return(states, nil)
+	mockProvider.On("List", mock.Anything, "/Sync").Return([]*interfaces.File{
+		{Path: "/Sync/unchanged.txt", Hash: "r-changed"},
+		{Path: "/Sync/edited.txt", Hash: "r-edited"},
+		{Path: "/Sync/remote-new.txt", Hash: "r-new"},
+	}, nil)
+
+	reconciler := NewPulsePointReconciler(mockProvider, mockStateManager, root, "/Sync", zap.NewNop(), &ReconcilerConfig{
+		IgnorePatterns: []string{"*.tmp"},
+		HashAlgorithm:  "sha256",
+		IncludeRemote:  true,
+		Recursive:      true,
+	})
+
+	events, stats, err := reconciler.Scan(context.Background())
+	require.NoError(t, err)
+
+	found := make(map[string]*models.ChangeEvent)
+	for _, event := range events {
+		found[event.Source+":"+event.Path] = event
+	}
+
+	require.Len(t, events, 6)
+	assert.Equal(t, models.ChangeTypeCreate, found["local:"+newPath].Type)
+	assert.Equal(t, models.ChangeTypeModify, found["local:"+editedPath].Type)
+	assert.Equal(t, models.ChangeTypeDelete, found["local:"+filepath.Join(root, "gone.txt")].Type)
+	assert.Equal(t, models.ChangeTypeModify, found["remote:/Sync/unchanged.txt"].Type)
+	assert.Equal(t, models.ChangeTypeCreate, found["remote:/Sync/remote-new.txt"].Type)
+	assert.Equal(t, models.ChangeTypeDelete, found["remote:/Sync/kept.txt"].Type)
+
+	assert.Equal(t, 2, stats.Created)
+	assert.Equal(t, 2, stats.Modified)
+	assert.Equal(t, 2, stats.Deleted)
+}
+
+func TestReconcilerStaysOutOfSubfoldersWhenNotRecursive(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "sub"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "top.txt"), []byte("top"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "sub", "nested.txt"), []byte("nested"), 0600))
+
+	// A synced file inside the subfolder is not the pair's to delete
+	mockStateManager := new(MockStateManager)
+	mockStateManager.On("ListFileStates", mock.Anything).Return([]*interfaces.FileState{
+		{Path: filepath.Join(root, "sub", "old.txt"), Status: interfaces.FileSyncStatusSynced},
+	}, nil)
+	mockProvider := new(MockProvider)
+	mockProvider.On("List", mock.Anything, "/Sync").Return([]*interfaces.File{
+		{Path: "/Sync/remote.txt", Hash: "r1"},
+		{Path: "/Sync/folder", IsFolder: true},
+	}, nil)
+
+	reconciler := NewPulsePointReconciler(mockProvider, mockStateManager, root, "/Sync", zap.NewNop(), &ReconcilerConfig{
+		HashAlgorithm: "sha256",
+		IncludeRemote: true,
+		Recursive:     false,
+	})
+
+	events, _, err := reconciler.Scan(context.Background())
+	require.NoError(t, err)
+
+	paths := make([]string, 0, len(events))
+	for _, event := range events {
+		paths = append(paths, event.Source+":"+event.Path)
+	}
+	assert.ElementsMatch(t, []string{"local:" + filepath.Join(root, "top.txt"), "remote:/Sync/remote.txt"}, paths)
+
+	// The remote subfolder is never listed
+	mockProvider.AssertNotCalled(t, "List", mock.Anything, "/Sync/folder")
+}
+
+func TestReconcilerFailsOnMissingRoot(t *testing.T) {
+	mockStateManager := new(MockStateManager)
+	mockStateManager.On("ListFileStates", mock.Anything).Return([]*interfaces.FileState{}, nil)
+
+	reconciler := NewPulsePointReconciler(new(MockProvider), mockStateManager,
+		filepath.Join(t.TempDir(), "missing"), "/", zap.NewNop(), nil)
+
+	// An unreadable root must not turn every synced file into a delete
+	_, _, err := reconciler.Scan(context.Background())
+	assert.Error(t, err)
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
@@ -16,9 +18,14 @@ import (
 
 // PulsePointStateManager manages sync state persistence
 type PulsePointStateManager struct {
-	db     *database.DB
-	logger *zap.Logger
-	config *StateManagerConfig
+	db        *database.DB
+	logger    *zap.Logger
+	config    *StateManagerConfig
+	baseStore *PulsePointBaseStore
+
+	// namespace and root scope a state manager to one folder pair
+	namespace string
+	root      string
 }
 
 // StateManagerConfig holds configuration for state management
@@ -50,6 +57,51 @@ func NewPulsePointStateManager(db *database.DB, logger *zap.Logger, config *Stat
 	}
 }
 
+// WithNamespace returns a state manager that shares this one's database but
+// keeps its own sync state and only lists file states under root. File states
+// stay keyed by absolute local path, so unscoped managers still see them.
+func (m *PulsePointStateManager) WithNamespace(namespace, root string) *PulsePointStateManager {
+	return &PulsePointStateManager{
+		db:        m.db,
+		logger:    m.logger.With(zap.String("namespace", namespace)),
+		config:    m.config,
+		baseStore: m.baseStore,
+		namespace: namespace,
+		root:      filepath.Clean(root),
+	}
+}
+
+// Namespace returns the namespace of a scoped state manager, or "" if unscoped
+func (m *PulsePointStateManager) Namespace() string {
+	return m.namespace
+}
+
+// stateKey returns the key of the sync state record for this namespace
+func (m *PulsePointStateManager) stateKey() []byte {
+	if m.namespace == "" {
+		return []byte("current")
+	}
+	return []byte("current:" + m.namespace)
+}
+
+// inScope reports whether a file state path belongs to this manager's root
+func (m *PulsePointStateManager) inScope(path string) bool {
+	if m.root == "" {
+		return true
+	}
+	return path == m.root || strings.HasPrefix(path, m.root+string(filepath.Separator))
+}
+
+// SetBaseStore enables keeping merge bases for files as they are synced
+func (m *PulsePointStateManager) SetBaseStore(store *PulsePointBaseStore) {
+	m.baseStore = store
+}
+
+// BaseStore returns the merge base store, or nil if none is configured
+func (m *PulsePointStateManager) BaseStore() *PulsePointBaseStore {
+	return m.baseStore
+}
+
 // Initialize sets up the state manager
 func (m *PulsePointStateManager) Initialize(dbPath string) error {
 	m.logger.Info("Initializing state manager", zap.String("db_path", dbPath))
@@ -124,7 +176,7 @@ func (m *PulsePointStateManager) SaveState(ctx context.Context, state *interface
 		if bucket == nil {
 			return fmt.Errorf("state bucket not found")
 		}
-		return bucket.Put([]byte("current"), data)
+		return bucket.Put(m.stateKey(), data)
 	})
 
 	if err != nil {
@@ -145,7 +197,7 @@ func (m *PulsePointStateManager) LoadState(ctx context.Context) (*interfaces.Syn
 		if bucket == nil {
 			return fmt.Errorf("state bucket not found")
 		}
-		data = bucket.Get([]byte("current"))
+		data = bucket.Get(m.stateKey())
 		return nil
 	})
 
@@ -192,6 +244,12 @@ func (m *PulsePointStateManager) UpdateFileState(ctx context.Context, file *inte
 
 	m.logger.Debug("Updating file state", zap.String("path", file.Path))
 
+	// The state and its base are saved together as far as collection sees
+	if m.baseStore != nil {
+		m.baseStore.collectMu.RLock()
+		defer m.baseStore.collectMu.RUnlock()
+	}
+
 	// Convert to model file state
 	modelFile := &models.FileState{
 		Path:          file.Path,
@@ -226,6 +284,16 @@ func (m *PulsePointStateManager) UpdateFileState(ctx context.Context, file *inte
 		return pperrors.NewDatabaseError("failed to update file state", err)
 	}
 
+	// Keep the synced content as the ancestor for later merges
+	if m.baseStore != nil && file.Status == interfaces.FileSyncStatusSynced {
+		if err := m.baseStore.Put(ctx, file.Path, file.LocalHash); err != nil {
+			m.logger.Warn("Failed to store merge base",
+				zap.String("path", file.Path),
+				zap.Error(err),
+			)
+		}
+	}
+
 	return nil
 }
 
@@ -295,9 +363,14 @@ func (m *PulsePointStateManager) DeleteFileState(ctx context.Context, path strin
 	return nil
 }
 
-// ListFileStates lists all file states
+// ListFileStates lists all file states, or those under the root of a scoped manager
 func (m *PulsePointStateManager) ListFileStates(ctx context.Context) ([]*interfaces.FileState, error) {
-	m.logger.Debug("Listing all file states")
+	return m.listFileStates(ctx, m.inScope)
+}
+
+// listFileStates lists the file states whose path passes the filter
+func (m *PulsePointStateManager) listFileStates(ctx context.Context, include func(path string) bool) ([]*interfaces.FileState, error) {
+	m.logger.Debug("Listing file states")
 
 	var states []*interfaces.FileState
 
@@ -316,6 +389,9 @@ func (m *PulsePointStateManager) ListFileStates(ctx context.Context) ([]*interfa
 				)
 				return nil // Continue iteration
 			}
+			if !include(modelFile.Path) {
+				return nil
+			}
 
 			fileState := &interfaces.FileState{
 				Path:          modelFile.Path,
@@ -494,6 +570,37 @@ func (m *PulsePointStateManager) Cleanup(ctx context.Context, before time.Time)
 	}
 
 	m.logger.Info("Cleanup completed", zap.Int("deleted", deleted))
+
+	if m.baseStore != nil {
+		return m.collectBases(ctx)
+	}
+	return nil
+}
+
+// collectBases removes merge bases no longer referenced by any file state
+func (m *PulsePointStateManager) collectBases(ctx context.Context) error {
+	m.baseStore.collectMu.Lock()
+	defer m.baseStore.collectMu.Unlock()
+
+	// Bases are shared by every namespace, so look at every file state
+	states, err := m.listFileStates(ctx, func(string) bool { return true })
+	if err != nil {
+		return err
+	}
+
+	keep := make(map[string]bool, len(states))
+	for _, state := range states {
+		if state.LocalHash != "" {
+			keep[state.LocalHash] = true
+		}
+	}
+
+	removed, err := m.baseStore.GarbageCollect(ctx, keep)
+	if err != nil {
+		return err
+	}
+
+	m.logger.Info("Merge bases collected", zap.Int("removed", removed))
 	return nil
 }
 
@@ -0,0 +1,110 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"github.com/pulsepoint/pulsepoint/internal/database"
+	"github.com/pulsepoint/pulsepoint/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestStateNamespacesSplitOneDatabase(t *testing.T) {
+	ctx := context.Background()
+	options := database.DefaultOptions()
+	options.Path = filepath.Join(t.TempDir(), "test.db")
+	db, err := database.NewManager(options)
+	require.NoError(t, err)
+	require.NoError(t, db.Open())
+	t.Cleanup(func() { db.Close() })
+
+	shared := NewPulsePointStateManager(db, zap.NewNop(), nil)
+	require.NoError(t, shared.Initialize(options.Path))
+	docs := shared.WithNamespace("docs", "/home/me/Documents")
+	projects := shared.WithNamespace("projects", "/home/me/Projects")
+
+	require.NoError(t, docs.UpdateFileState(ctx, &interfaces.FileState{Path: "/home/me/Documents/a.txt"}))
+	require.NoError(t, projects.UpdateFileState(ctx, &interfaces.FileState{Path: "/home/me/Projects/b.txt"}))
+	require.NoError(t, projects.UpdateFileState(ctx, &interfaces.FileState{Path: "/home/me/Projects-old/c.txt"}))
+
+	states, err := docs.ListFileStates(ctx)
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	assert.Equal(t, "/home/me/Documents/a.txt", states[0].Path)
+
+	states, err = projects.ListFileStates(ctx)
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	assert.Equal(t, "/home/me/Projects/b.txt", states[0].Path)
+
+	// The unscoped manager still sees every pair's files
+	states, err = shared.ListFileStates(ctx)
+	require.NoError(t, err)
+	assert.Len(t, states, 3)
+
+	require.NoError(t, docs.SaveState(ctx, &interfaces.SyncState{TotalFiles: 1}))
+	require.NoError(t, projects.SaveState(ctx, &interfaces.SyncState{TotalFiles: 2}))
+
+	state, err := docs.LoadState(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, state.TotalFiles)
+
+	state, err = shared.LoadState(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, state)
+}
+
+// newBaseStateManager returns a state manager that keeps merge bases, and a
+// function that writes a local file and saves it as synced
+func newBaseStateManager(t *testing.T) (*PulsePointStateManager, string, func(name, content string) string) {
+	dir := t.TempDir()
+	options := database.DefaultOptions()
+	options.Path = filepath.Join(dir, "test.db")
+	db, err := database.NewManager(options)
+	require.NoError(t, err)
+	require.NoError(t, db.Open())
+	t.Cleanup(func() { db.Close() })
+
+	stateManager := NewPulsePointStateManager(db, zap.NewNop(), nil)
+	require.NoError(t, stateManager.Initialize(options.Path))
+	stateManager.SetBaseStore(NewPulsePointBaseStore(filepath.Join(dir, "bases"), 0, zap.NewNop()))
+
+	synced := func(name, content string) string {
+		localPath := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(localPath, []byte(content), 0644))
+		hash, err := utils.FileSHA256(localPath)
+		require.NoError(t, err)
+		require.NoError(t, stateManager.UpdateFileState(context.Background(), &interfaces.FileState{
+			Path:      localPath,
+			LocalHash: hash,
+			Status:    interfaces.FileSyncStatusSynced,
+		}))
+		return hash
+	}
+	return stateManager, dir, synced
+}
+
+func TestCleanupRemovesUnreferencedBases(t *testing.T) {
+	ctx := context.Background()
+	stateManager, dir, synced := newBaseStateManager(t)
+	store := stateManager.BaseStore()
+
+	oldNotes := synced("notes.md", "# Notes\nv1\n")
+	newNotes := synced("notes.md", "# Notes\nv2\n")
+	todo := synced("todo.md", "- [ ] write tests\n")
+	require.True(t, store.Has(oldNotes))
+	require.True(t, store.Has(todo))
+
+	require.NoError(t, stateManager.DeleteFileState(ctx, filepath.Join(dir, "todo.md")))
+	require.NoError(t, stateManager.Cleanup(ctx, time.Now()))
+
+	assert.False(t, store.Has(oldNotes), "base of an updated state is removed")
+	assert.False(t, store.Has(todo), "base of a deleted state is removed")
+	assert.True(t, store.Has(newNotes), "base of the current state is kept")
+}
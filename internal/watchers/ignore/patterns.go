@@ -199,5 +199,11 @@ func (m *PulsePointIgnoreMatcher) pulsePointIsDefaultIgnored(name string) bool {
 		}
 	}
 
+	return IsTempName(name)
+}
+
+// IsTempName reports whether name is one of the temporary files written next
+// to a file while it is downloaded or replaced: a hidden name ending in .tmp
+func IsTempName(name string) bool {
 	return strings.HasPrefix(name, ".") && strings.HasSuffix(name, ".tmp")
 }
@@ -15,6 +15,7 @@ import (
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"github.com/pulsepoint/pulsepoint/internal/watchers/ignore"
 	"github.com/pulsepoint/pulsepoint/pkg/logger"
 	"go.uber.org/zap"
 )
@@ -441,9 +442,9 @@ func (pw *PulsePointWatcher) pulsePointShouldIgnore(path string) bool {
 		}
 	}
 
-	// Always ignore common system files
+	// Always ignore common system files and our own temp files
 	base := filepath.Base(path)
-	if base == ".DS_Store" || base == "Thumbs.db" || base == ".git" || strings.HasPrefix(base, "~") {
+	if base == ".DS_Store" || base == "Thumbs.db" || base == ".git" || strings.HasPrefix(base, "~") || ignore.IsTempName(base) {
 		return true
 	}
 
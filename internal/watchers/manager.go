@@ -20,10 +20,12 @@ import (
 // PulsePointWatcherManager manages file watching and change processing
 type PulsePointWatcherManager struct {
 	watcher       interfaces.FileWatcher
+	remoteWatcher interfaces.FileWatcher
 	changeQueue   *queue.PulsePointChangeQueue
 	ignoreMatcher *ignore.PulsePointIgnoreMatcher
 	db            *bbolt.DB
 	syncHandler   func([]*models.ChangeEvent) error
+	maxRetries    int
 	ctx           context.Context
 	cancel        context.CancelFunc
 	wg            sync.WaitGroup
@@ -32,6 +34,12 @@ type PulsePointWatcherManager struct {
 	runningMu     sync.RWMutex
 }
 
+// eventSinker is implemented by watchers that can hand over changes and wait
+// for them to be stored, such as the remote watcher
+type eventSinker interface {
+	SetEventSink(sink func([]interfaces.ChangeEvent) error)
+}
+
 // ManagerConfig contains configuration for the watcher manager
 type ManagerConfig struct {
 	DebouncePeriod time.Duration                     // Debounce period for file events
@@ -41,6 +49,8 @@ type ManagerConfig struct {
 	FlushInterval  time.Duration                     // Interval to flush changes
 	SyncHandler    func([]*models.ChangeEvent) error // Handler for processing changes
 	IgnoreFile     string                            // Path to ignore file (e.g., .gitignore)
+	MaxRetries     int                               // Attempts before a failed event is dropped
+	RemoteWatcher  interfaces.FileWatcher            // Optional watcher for changes made on the provider
 }
 
 // NewPulsePointWatcherManager creates a new watcher manager
@@ -61,6 +71,9 @@ func NewPulsePointWatcherManager(db *bbolt.DB, config ManagerConfig) (*PulsePoin
 	if config.FlushInterval == 0 {
 		config.FlushInterval = 5 * time.Second
 	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = 3
+	}
 
 	// Create file watcher
 	watcher, err := local.NewPulsePointWatcher(config.DebouncePeriod, config.HashAlgorithm)
@@ -83,9 +96,11 @@ func NewPulsePointWatcherManager(db *bbolt.DB, config ManagerConfig) (*PulsePoin
 
 	manager := &PulsePointWatcherManager{
 		watcher:       watcher,
+		remoteWatcher: config.RemoteWatcher,
 		ignoreMatcher: ignoreMatcher,
 		db:            db,
 		syncHandler:   config.SyncHandler,
+		maxRetries:    config.MaxRetries,
 		ctx:           ctx,
 		cancel:        cancel,
 		logger:        logger.Get(),
@@ -106,6 +121,11 @@ func NewPulsePointWatcherManager(db *bbolt.DB, config ManagerConfig) (*PulsePoin
 	}
 	manager.changeQueue = changeQueue
 
+	// The remote watcher only moves past changes once they are queued on disk
+	if sinker, ok := config.RemoteWatcher.(eventSinker); ok {
+		sinker.SetEventSink(manager.pulsePointEnqueueRemote)
+	}
+
 	// Set ignore patterns on the watcher
 	watcher.SetIgnorePatterns(ignoreMatcher.GetPatterns())
 
@@ -126,15 +146,30 @@ func (m *PulsePointWatcherManager) Start() error {
 		return fmt.Errorf("failed to start file watcher: %w", err)
 	}
 
+	// Start the remote watcher; it polls once folders are added via WatchRemotePath
+	if m.remoteWatcher != nil {
+		if err := m.remoteWatcher.Start(m.ctx, []string{}); err != nil {
+			m.watcher.Stop()
+			return fmt.Errorf("failed to start remote watcher: %w", err)
+		}
+	}
+
 	// Start the change queue
 	if err := m.changeQueue.Start(); err != nil {
 		m.watcher.Stop()
+		if m.remoteWatcher != nil {
+			m.remoteWatcher.Stop()
+		}
 		return fmt.Errorf("failed to start change queue: %w", err)
 	}
 
-	// Start the event processor
+	// Start the event processors
 	m.wg.Add(1)
-	go m.pulsePointEventProcessor()
+	go m.pulsePointEventProcessor(m.watcher)
+	if m.remoteWatcher != nil {
+		m.wg.Add(1)
+		go m.pulsePointEventProcessor(m.remoteWatcher)
+	}
 
 	m.isRunning = true
 	m.logger.Info("PulsePoint watcher manager started")
@@ -159,6 +194,12 @@ func (m *PulsePointWatcherManager) Stop() error {
 		m.logger.Error("Failed to stop file watcher", zap.Error(err))
 	}
 
+	if m.remoteWatcher != nil {
+		if err := m.remoteWatcher.Stop(); err != nil {
+			m.logger.Error("Failed to stop remote watcher", zap.Error(err))
+		}
+	}
+
 	if err := m.changeQueue.Stop(); err != nil {
 		m.logger.Error("Failed to stop change queue", zap.Error(err))
 	}
@@ -198,9 +239,22 @@ func (m *PulsePointWatcherManager) UnwatchPath(path string) error {
 	return m.watcher.RemovePath(absPath)
 }
 
+// WatchRemotePath adds a remote folder to watch on the provider
+func (m *PulsePointWatcherManager) WatchRemotePath(path string) error {
+	if m.remoteWatcher == nil {
+		return fmt.Errorf("no remote watcher configured")
+	}
+	return m.remoteWatcher.AddPath(path)
+}
+
 // AddIgnorePatterns adds ignore patterns
 func (m *PulsePointWatcherManager) AddIgnorePatterns(patterns []string) error {
 	m.ignoreMatcher.AddPatterns(patterns)
+	if m.remoteWatcher != nil {
+		if err := m.remoteWatcher.SetIgnorePatterns(m.ignoreMatcher.GetPatterns()); err != nil {
+			return err
+		}
+	}
 	return m.watcher.SetIgnorePatterns(m.ignoreMatcher.GetPatterns())
 }
 
@@ -215,16 +269,19 @@ func (m *PulsePointWatcherManager) GetStats() map[string]interface{} {
 		"ignore_patterns": m.ignoreMatcher.GetPatterns(),
 		"queue_stats":     m.changeQueue.GetQueueStats(),
 	}
+	if m.remoteWatcher != nil {
+		stats["watched_remote_paths"] = m.remoteWatcher.GetWatchedPaths()
+	}
 
 	return stats
 }
 
-// pulsePointEventProcessor processes events from the file watcher
-func (m *PulsePointWatcherManager) pulsePointEventProcessor() {
+// pulsePointEventProcessor processes events from a watcher
+func (m *PulsePointWatcherManager) pulsePointEventProcessor(watcher interfaces.FileWatcher) {
 	defer m.wg.Done()
 
-	eventsChan := m.watcher.Watch()
-	errorsChan := m.watcher.Errors()
+	eventsChan := watcher.Watch()
+	errorsChan := watcher.Errors()
 
 	for {
 		select {
@@ -265,6 +322,20 @@ func (m *PulsePointWatcherManager) pulsePointEventProcessor() {
 	}
 }
 
+// pulsePointEnqueueRemote queues a page of remote changes and persists the
+// queue, so the remote watcher can advance its page token
+func (m *PulsePointWatcherManager) pulsePointEnqueueRemote(events []interfaces.ChangeEvent) error {
+	for _, event := range events {
+		if m.ignoreMatcher.ShouldIgnore(event.Path, event.IsDir) {
+			continue
+		}
+		if err := m.changeQueue.Add(m.pulsePointConvertEvent(event)); err != nil {
+			return err
+		}
+	}
+	return m.changeQueue.Persist()
+}
+
 // pulsePointProcessChanges processes a batch of changes
 func (m *PulsePointWatcherManager) pulsePointProcessChanges(events []*models.ChangeEvent) error {
 	if m.syncHandler == nil {
@@ -290,7 +361,26 @@ func (m *PulsePointWatcherManager) pulsePointProcessChanges(events []*models.Cha
 	}
 
 	// Call the sync handler
-	return m.syncHandler(events)
+	if err := m.syncHandler(events); err != nil {
+		return err
+	}
+
+	// Retry events the handler marked as failed
+	var retry []*models.ChangeEvent
+	for _, event := range events {
+		if event.CanRetry(m.maxRetries) {
+			retry = append(retry, event)
+		} else if event.Status == models.EventStatusFailed {
+			m.logger.Error("Giving up on change after repeated failures",
+				zap.String("path", event.Path),
+				zap.Int("retries", event.Retries),
+				zap.String("error", event.Error),
+			)
+		}
+	}
+	m.changeQueue.Requeue(retry)
+
+	return nil
 }
 
 // IsRunning returns whether the manager is running
@@ -315,12 +405,27 @@ func (m *PulsePointWatcherManager) ClearQueue() error {
 	return m.changeQueue.Clear()
 }
 
+// Enqueue adds changes found outside the watchers, such as a reconciliation scan
+func (m *PulsePointWatcherManager) Enqueue(events []*models.ChangeEvent) error {
+	for _, event := range events {
+		if err := m.changeQueue.Add(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // pulsePointConvertEvent converts an interfaces.ChangeEvent to models.ChangeEvent
 func (m *PulsePointWatcherManager) pulsePointConvertEvent(event interfaces.ChangeEvent) *models.ChangeEvent {
+	source := event.Source
+	if source == "" {
+		source = "local"
+	}
+
 	return &models.ChangeEvent{
 		ID:        fmt.Sprintf("%d-%s", time.Now().UnixNano(), event.Path),
 		Type:      models.ChangeType(event.Type),
-		Source:    "local",
+		Source:    source,
 		Path:      event.Path,
 		OldPath:   event.OldPath,
 		Timestamp: time.Unix(event.Timestamp, 0),
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -111,9 +112,11 @@ func (q *PulsePointChangeQueue) Add(event *models.ChangeEvent) error {
 		return fmt.Errorf("queue is at maximum capacity (%d items)", q.maxSize)
 	}
 
+	key := pulsePointQueueKey(event)
+
 	// Check if item is being processed
 	q.processingMu.RLock()
-	if q.processingItems[event.Path] {
+	if q.processingItems[key] {
 		q.processingMu.RUnlock()
 		q.logger.Debug("Skipping event for path being processed", zap.String("path", event.Path))
 		return nil
@@ -121,10 +124,10 @@ func (q *PulsePointChangeQueue) Add(event *models.ChangeEvent) error {
 	q.processingMu.RUnlock()
 
 	// Deduplication logic
-	if existing, exists := q.items[event.Path]; exists {
+	if existing, exists := q.items[key]; exists {
 		// Update with newer event based on rules
 		if q.pulsePointShouldReplace(existing, event) {
-			q.items[event.Path] = event
+			q.items[key] = event
 			q.logger.Debug("Replaced existing event",
 				zap.String("path", event.Path),
 				zap.String("old_type", string(existing.Type)),
@@ -138,7 +141,7 @@ func (q *PulsePointChangeQueue) Add(event *models.ChangeEvent) error {
 		}
 	} else {
 		// Add new event
-		q.items[event.Path] = event
+		q.items[key] = event
 		q.logger.Debug("Added new event to queue",
 			zap.String("path", event.Path),
 			zap.String("type", string(event.Type)),
@@ -151,6 +154,62 @@ func (q *PulsePointChangeQueue) Add(event *models.ChangeEvent) error {
 	return nil
 }
 
+// Requeue puts failed events back on the queue for another attempt.
+// A newer pending event for the same path takes precedence.
+func (q *PulsePointChangeQueue) Requeue(events []*models.ChangeEvent) {
+	if len(events) == 0 {
+		return
+	}
+
+	q.itemsMu.Lock()
+	for _, event := range events {
+		key := pulsePointQueueKey(event)
+		if _, exists := q.items[key]; exists {
+			continue
+		}
+		if len(q.items) >= q.maxSize {
+			q.logger.Warn("Queue full, dropping retry", zap.String("path", event.Path))
+			continue
+		}
+		q.items[key] = event
+		q.logger.Debug("Requeued failed event",
+			zap.String("path", event.Path),
+			zap.Int("retries", event.Retries),
+		)
+	}
+	q.itemsMu.Unlock()
+
+	go q.pulsePointPersistToDB()
+}
+
+// Drain removes and returns up to max pending events, oldest first, for callers
+// that process changes themselves. A max of zero or less drains the whole queue.
+func (q *PulsePointChangeQueue) Drain(max int) []*models.ChangeEvent {
+	q.itemsMu.Lock()
+	events := make([]*models.ChangeEvent, 0, len(q.items))
+	for _, event := range q.items {
+		events = append(events, event)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Timestamp.Before(events[j].Timestamp)
+	})
+	if max > 0 && len(events) > max {
+		events = events[:max]
+	}
+	for _, event := range events {
+		delete(q.items, pulsePointQueueKey(event))
+	}
+	q.itemsMu.Unlock()
+
+	if len(events) > 0 {
+		if err := q.pulsePointPersistToDB(); err != nil {
+			q.logger.Error("Failed to persist queue after drain", zap.Error(err))
+		}
+	}
+
+	return events
+}
+
 // GetPendingCount returns the number of pending items in the queue
 func (q *PulsePointChangeQueue) GetPendingCount() int {
 	q.itemsMu.RLock()
@@ -247,6 +306,15 @@ func (q *PulsePointChangeQueue) pulsePointProcessBatch() {
 	go q.pulsePointPersistToDB()
 }
 
+// pulsePointQueueKey returns the deduplication key for an event. Remote events
+// are kept apart from local events for the same path so neither hides the other.
+func pulsePointQueueKey(event *models.ChangeEvent) string {
+	if event.Source == "remote" {
+		return "remote:" + event.Path
+	}
+	return event.Path
+}
+
 // pulsePointShouldReplace determines if an existing event should be replaced
 func (q *PulsePointChangeQueue) pulsePointShouldReplace(existing, new *models.ChangeEvent) bool {
 	// Rules for deduplication:
@@ -289,12 +357,17 @@ func (q *PulsePointChangeQueue) pulsePointLoadFromDB() error {
 				return nil // Skip invalid entries
 			}
 
-			q.items[event.Path] = &event
+			q.items[pulsePointQueueKey(&event)] = &event
 			return nil
 		})
 	})
 }
 
+// Persist writes the queue to the database, returning once it is stored
+func (q *PulsePointChangeQueue) Persist() error {
+	return q.pulsePointPersistToDB()
+}
+
 // pulsePointPersistToDB persists the current queue to the database
 func (q *PulsePointChangeQueue) pulsePointPersistToDB() error {
 	q.itemsMu.RLock()
@@ -0,0 +1,456 @@
+// Package remote implements a FileWatcher that reports changes made on the cloud provider
+package remote
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"github.com/pulsepoint/pulsepoint/internal/database"
+	"github.com/pulsepoint/pulsepoint/pkg/logger"
+	"go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// PulsePointRemoteWatcher implements the FileWatcher interface by polling a provider's change feed
+type PulsePointRemoteWatcher struct {
+	feed           interfaces.RemoteChangeFeed
+	db             *bbolt.DB
+	tokenKey       string
+	pollInterval   time.Duration
+	paths          map[string]bool // remote folders being watched
+	pathsMu        sync.RWMutex
+	knownPaths     map[string]string // file ID -> last known remote path
+	knownMu        sync.Mutex
+	ignorePatterns []string
+	ignoreMu       sync.RWMutex
+	sink           func([]interfaces.ChangeEvent) error
+	eventsChan     chan interfaces.ChangeEvent
+	errorsChan     chan error
+	logger         *zap.Logger
+	ctx            context.Context
+	cancel         context.CancelFunc
+	wg             sync.WaitGroup
+	isRunning      bool
+	runningMu      sync.RWMutex
+}
+
+// NewPulsePointRemoteWatcher creates a new remote watcher. The page token is
+// stored in the metadata bucket under a key derived from name, so restarts
+// resume from the last processed change.
+func NewPulsePointRemoteWatcher(
+	feed interfaces.RemoteChangeFeed,
+	db *bbolt.DB,
+	name string,
+	pollInterval time.Duration,
+) *PulsePointRemoteWatcher {
+	if pollInterval == 0 {
+		pollInterval = 30 * time.Second // default poll interval
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &PulsePointRemoteWatcher{
+		feed:           feed,
+		db:             db,
+		tokenKey:       fmt.Sprintf("remote_watcher:%s:page_token", name),
+		pollInterval:   pollInterval,
+		paths:          make(map[string]bool),
+		knownPaths:     make(map[string]string),
+		ignorePatterns: []string{},
+		eventsChan:     make(chan interfaces.ChangeEvent, 100),
+		errorsChan:     make(chan error, 10),
+		logger:         logger.Get(),
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+}
+
+// SeedPaths records known file ID to path mappings so that permanent
+// deletions, which carry no path, can still be reported
+func (rw *PulsePointRemoteWatcher) SeedPaths(paths map[string]string) {
+	rw.knownMu.Lock()
+	defer rw.knownMu.Unlock()
+
+	for id, p := range paths {
+		rw.knownPaths[id] = p
+	}
+}
+
+// SetEventSink hands each page of changes to sink instead of the Watch
+// channel. The sink returns once the changes are stored, and the page token
+// only advances past changes the sink accepted, so a crash never loses them.
+// It must be set before Start.
+func (rw *PulsePointRemoteWatcher) SetEventSink(sink func([]interfaces.ChangeEvent) error) {
+	rw.sink = sink
+}
+
+// Start begins polling for remote changes
+func (rw *PulsePointRemoteWatcher) Start(ctx context.Context, paths []string) error {
+	rw.runningMu.Lock()
+	defer rw.runningMu.Unlock()
+
+	if rw.isRunning {
+		return fmt.Errorf("watcher is already running")
+	}
+
+	// Update context if provided
+	if ctx != nil {
+		rw.ctx, rw.cancel = context.WithCancel(ctx)
+	}
+
+	for _, p := range paths {
+		if err := rw.AddPath(p); err != nil {
+			return err
+		}
+	}
+
+	token, err := rw.pulsePointLoadToken()
+	if err != nil {
+		return fmt.Errorf("failed to load page token: %w", err)
+	}
+
+	// First run: start from the current position instead of replaying history
+	if token == "" {
+		token, err = rw.feed.GetStartPageToken(rw.ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get start page token: %w", err)
+		}
+		if err := rw.pulsePointSaveToken(token); err != nil {
+			return fmt.Errorf("failed to save page token: %w", err)
+		}
+	}
+
+	rw.wg.Add(1)
+	go rw.pulsePointPoller(token)
+
+	rw.isRunning = true
+	rw.logger.Info("PulsePoint remote watcher started",
+		zap.Duration("poll_interval", rw.pollInterval),
+		zap.Int("paths_count", len(paths)),
+	)
+
+	return nil
+}
+
+// Stop stops polling for remote changes
+func (rw *PulsePointRemoteWatcher) Stop() error {
+	rw.runningMu.Lock()
+	defer rw.runningMu.Unlock()
+
+	if !rw.isRunning {
+		return nil
+	}
+
+	rw.cancel()
+	rw.wg.Wait()
+
+	rw.isRunning = false
+	rw.logger.Info("PulsePoint remote watcher stopped")
+
+	return nil
+}
+
+// Watch returns a channel that emits remote change events
+func (rw *PulsePointRemoteWatcher) Watch() <-chan interfaces.ChangeEvent {
+	return rw.eventsChan
+}
+
+// Errors returns a channel that emits polling errors
+func (rw *PulsePointRemoteWatcher) Errors() <-chan error {
+	return rw.errorsChan
+}
+
+// AddPath adds a remote folder to watch
+func (rw *PulsePointRemoteWatcher) AddPath(p string) error {
+	rw.pathsMu.Lock()
+	defer rw.pathsMu.Unlock()
+
+	rw.paths[path.Join("/", p)] = true
+	return nil
+}
+
+// RemovePath removes a remote folder from watching
+func (rw *PulsePointRemoteWatcher) RemovePath(p string) error {
+	rw.pathsMu.Lock()
+	defer rw.pathsMu.Unlock()
+
+	delete(rw.paths, path.Join("/", p))
+	return nil
+}
+
+// SetIgnorePatterns sets patterns to ignore
+func (rw *PulsePointRemoteWatcher) SetIgnorePatterns(patterns []string) error {
+	rw.ignoreMu.Lock()
+	defer rw.ignoreMu.Unlock()
+
+	rw.ignorePatterns = patterns
+	return nil
+}
+
+// GetWatchedPaths returns a list of all watched remote folders
+func (rw *PulsePointRemoteWatcher) GetWatchedPaths() []string {
+	rw.pathsMu.RLock()
+	defer rw.pathsMu.RUnlock()
+
+	paths := make([]string, 0, len(rw.paths))
+	for p := range rw.paths {
+		paths = append(paths, p)
+	}
+	return paths
+}
+
+// IsWatching checks if the watcher is currently polling
+func (rw *PulsePointRemoteWatcher) IsWatching() bool {
+	rw.runningMu.RLock()
+	defer rw.runningMu.RUnlock()
+	return rw.isRunning
+}
+
+// pulsePointPoller is the main polling goroutine
+func (rw *PulsePointRemoteWatcher) pulsePointPoller(token string) {
+	defer rw.wg.Done()
+
+	ticker := time.NewTicker(rw.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		token = rw.pulsePointPoll(token)
+
+		select {
+		case <-rw.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pulsePointPoll drains the change feed from token and returns the token to resume from
+func (rw *PulsePointRemoteWatcher) pulsePointPoll(token string) string {
+	// Until folders are watched every change would be dropped as unwatched
+	if len(rw.GetWatchedPaths()) == 0 {
+		return token
+	}
+
+	for {
+		page, err := rw.feed.ListChanges(rw.ctx, token)
+		if err != nil {
+			if rw.ctx.Err() == nil {
+				rw.pulsePointReportError(err)
+			}
+			return token
+		}
+
+		// Known paths are only updated once the page is delivered, so a
+		// page that is read again is converted the same way
+		moves := make(map[string]string)
+		events := make([]interfaces.ChangeEvent, 0, len(page.Changes))
+		for _, change := range page.Changes {
+			if event, ok := rw.pulsePointConvertChange(change, moves); ok {
+				events = append(events, event)
+			}
+		}
+
+		if !rw.pulsePointDeliver(events) {
+			return token
+		}
+		rw.pulsePointApplyMoves(moves)
+
+		switch {
+		case page.NextPageToken != "":
+			token = page.NextPageToken
+		case page.NewStartPageToken != "":
+			token = page.NewStartPageToken
+		}
+
+		if err := rw.pulsePointSaveToken(token); err != nil {
+			rw.pulsePointReportError(fmt.Errorf("failed to save page token: %w", err))
+		}
+
+		if page.NextPageToken == "" {
+			return token
+		}
+	}
+}
+
+// pulsePointDeliver passes a page of events on, reporting whether the page
+// token may advance past them
+func (rw *PulsePointRemoteWatcher) pulsePointDeliver(events []interfaces.ChangeEvent) bool {
+	if rw.sink != nil {
+		if err := rw.sink(events); err != nil {
+			rw.pulsePointReportError(fmt.Errorf("failed to store remote changes: %w", err))
+			return false
+		}
+		return true
+	}
+
+	for _, event := range events {
+		select {
+		case rw.eventsChan <- event:
+		case <-rw.ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+// pulsePointApplyMoves records the paths a delivered page moved files to.
+// An empty path forgets the file.
+func (rw *PulsePointRemoteWatcher) pulsePointApplyMoves(moves map[string]string) {
+	rw.knownMu.Lock()
+	defer rw.knownMu.Unlock()
+
+	for id, p := range moves {
+		if p == "" {
+			delete(rw.knownPaths, id)
+		} else {
+			rw.knownPaths[id] = p
+		}
+	}
+}
+
+// pulsePointConvertChange converts a remote change into a change event,
+// reporting false for changes outside the watched folders. The file's new
+// path is recorded in moves.
+func (rw *PulsePointRemoteWatcher) pulsePointConvertChange(change interfaces.RemoteChange, moves map[string]string) (interfaces.ChangeEvent, bool) {
+	oldPath, moved := moves[change.FileID]
+	if !moved {
+		rw.knownMu.Lock()
+		oldPath = rw.knownPaths[change.FileID]
+		rw.knownMu.Unlock()
+	}
+	newPath := change.Path
+	if change.Removed {
+		if newPath == "" {
+			newPath = oldPath
+		}
+		moves[change.FileID] = ""
+	} else if newPath != "" {
+		moves[change.FileID] = newPath
+	}
+
+	event := interfaces.ChangeEvent{
+		Path:      newPath,
+		Timestamp: change.ModifiedTime.Unix(),
+		Size:      change.Size,
+		Hash:      change.Hash,
+		IsDir:     change.IsFolder,
+		Source:    "remote",
+	}
+	if change.ModifiedTime.IsZero() {
+		event.Timestamp = time.Now().Unix()
+	}
+
+	newWatched := newPath != "" && rw.pulsePointIsWatched(newPath)
+	oldWatched := oldPath != "" && rw.pulsePointIsWatched(oldPath)
+
+	switch {
+	case change.Removed:
+		if !newWatched {
+			return event, false
+		}
+		event.Type = interfaces.ChangeTypeDelete
+
+	case oldPath != "" && oldPath != newPath:
+		// Moving across the edge of a watched folder looks like a create or delete
+		switch {
+		case oldWatched && newWatched:
+			event.Type = interfaces.ChangeTypeRename
+			event.OldPath = oldPath
+		case newWatched:
+			event.Type = interfaces.ChangeTypeCreate
+		case oldWatched:
+			event.Path = oldPath
+			event.Type = interfaces.ChangeTypeDelete
+		default:
+			return event, false
+		}
+
+	case oldPath == "":
+		if !newWatched {
+			return event, false
+		}
+		event.Type = interfaces.ChangeTypeCreate
+
+	default:
+		if !newWatched {
+			return event, false
+		}
+		event.Type = interfaces.ChangeTypeModify
+	}
+
+	if rw.pulsePointShouldIgnore(event.Path) {
+		return event, false
+	}
+
+	rw.logger.Debug("Remote change detected",
+		zap.String("path", event.Path),
+		zap.String("type", string(event.Type)),
+	)
+	return event, true
+}
+
+// pulsePointIsWatched checks if a remote path lies inside a watched folder
+func (rw *PulsePointRemoteWatcher) pulsePointIsWatched(p string) bool {
+	rw.pathsMu.RLock()
+	defer rw.pathsMu.RUnlock()
+
+	for root := range rw.paths {
+		if root == "/" || strings.HasPrefix(p, root+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// pulsePointShouldIgnore checks if a remote path matches an ignore pattern
+func (rw *PulsePointRemoteWatcher) pulsePointShouldIgnore(p string) bool {
+	rw.ignoreMu.RLock()
+	defer rw.ignoreMu.RUnlock()
+
+	base := path.Base(p)
+	for _, pattern := range rw.ignorePatterns {
+		if matched, _ := path.Match(pattern, base); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// pulsePointReportError sends an error without blocking the poller
+func (rw *PulsePointRemoteWatcher) pulsePointReportError(err error) {
+	select {
+	case rw.errorsChan <- err:
+	default:
+		rw.logger.Warn("Remote watcher error channel full", zap.Error(err))
+	}
+}
+
+// pulsePointLoadToken reads the persisted page token
+func (rw *PulsePointRemoteWatcher) pulsePointLoadToken() (string, error) {
+	var token string
+	err := rw.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(database.BucketMetadata))
+		if bucket == nil {
+			return nil // No metadata bucket yet
+		}
+		token = string(bucket.Get([]byte(rw.tokenKey)))
+		return nil
+	})
+	return token, err
+}
+
+// pulsePointSaveToken persists the page token
+func (rw *PulsePointRemoteWatcher) pulsePointSaveToken(token string) error {
+	return rw.db.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(database.BucketMetadata))
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(rw.tokenKey), []byte(token))
+	})
+}
@@ -0,0 +1,208 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+// fakeFeed serves fixed pages of changes keyed by page token
+type fakeFeed struct {
+	mu         sync.Mutex
+	startToken string
+	pages      map[string]*interfaces.RemoteChangePage
+	listed     []string
+}
+
+func (f *fakeFeed) GetStartPageToken(ctx context.Context) (string, error) {
+	return f.startToken, nil
+}
+
+func (f *fakeFeed) ListChanges(ctx context.Context, pageToken string) (*interfaces.RemoteChangePage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.listed = append(f.listed, pageToken)
+	if page, ok := f.pages[pageToken]; ok {
+		return page, nil
+	}
+	return &interfaces.RemoteChangePage{NewStartPageToken: pageToken}, nil
+}
+
+func (f *fakeFeed) listedTokens() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.listed...)
+}
+
+// sinkRecorder stores delivered events, failing while err is set
+type sinkRecorder struct {
+	mu     sync.Mutex
+	err    error
+	events []interfaces.ChangeEvent
+}
+
+func (s *sinkRecorder) sink(events []interfaces.ChangeEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+	s.events = append(s.events, events...)
+	return nil
+}
+
+func (s *sinkRecorder) delivered() []interfaces.ChangeEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]interfaces.ChangeEvent(nil), s.events...)
+}
+
+func newTestDB(t *testing.T) *bbolt.DB {
+	db, err := bbolt.Open(filepath.Join(t.TempDir(), "pulsepoint.db"), 0600, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func newTestWatcher(t *testing.T, feed *fakeFeed, db *bbolt.DB, sink *sinkRecorder) *PulsePointRemoteWatcher {
+	watcher := NewPulsePointRemoteWatcher(feed, db, "test", time.Hour)
+	watcher.SetEventSink(sink.sink)
+	require.NoError(t, watcher.AddPath("/Sync"))
+	return watcher
+}
+
+func savedToken(t *testing.T, watcher *PulsePointRemoteWatcher) string {
+	token, err := watcher.pulsePointLoadToken()
+	require.NoError(t, err)
+	return token
+}
+
+func TestRemoteWatcherFollowsPages(t *testing.T) {
+	feed := &fakeFeed{pages: map[string]*interfaces.RemoteChangePage{
+		"1": {
+			Changes:       []interfaces.RemoteChange{{FileID: "a", Path: "/Sync/a.txt"}},
+			NextPageToken: "2",
+		},
+		"2": {
+			Changes:           []interfaces.RemoteChange{{FileID: "b", Path: "/Sync/b.txt"}},
+			NewStartPageToken: "3",
+		},
+	}}
+	sink := &sinkRecorder{}
+	watcher := newTestWatcher(t, feed, newTestDB(t), sink)
+
+	assert.Equal(t, "3", watcher.pulsePointPoll("1"))
+	assert.Equal(t, []string{"1", "2"}, feed.listedTokens())
+	assert.Equal(t, "3", savedToken(t, watcher))
+
+	events := sink.delivered()
+	require.Len(t, events, 2)
+	assert.Equal(t, "/Sync/a.txt", events[0].Path)
+	assert.Equal(t, "/Sync/b.txt", events[1].Path)
+	assert.Equal(t, interfaces.ChangeTypeCreate, events[0].Type)
+	assert.Equal(t, "remote", events[0].Source)
+}
+
+func TestRemoteWatcherKeepsTokenUntilChangesAreStored(t *testing.T) {
+	feed := &fakeFeed{pages: map[string]*interfaces.RemoteChangePage{
+		"1": {
+			Changes:       []interfaces.RemoteChange{{FileID: "a", Path: "/Sync/new.txt"}},
+			NextPageToken: "2",
+		},
+		"2": {
+			Changes:           []interfaces.RemoteChange{{FileID: "b", Path: "/Sync/b.txt"}},
+			NewStartPageToken: "3",
+		},
+	}}
+	sink := &sinkRecorder{err: errors.New("disk full")}
+	watcher := newTestWatcher(t, feed, newTestDB(t), sink)
+	watcher.SeedPaths(map[string]string{"a": "/Sync/old.txt"})
+	require.NoError(t, watcher.pulsePointSaveToken("1"))
+
+	// Nothing stored, nothing skipped
+	assert.Equal(t, "1", watcher.pulsePointPoll("1"))
+	assert.Equal(t, "1", savedToken(t, watcher))
+	assert.Empty(t, sink.delivered())
+
+	// The same page is read again and converted the same way
+	sink.err = nil
+	assert.Equal(t, "3", watcher.pulsePointPoll("1"))
+	assert.Equal(t, "3", savedToken(t, watcher))
+
+	events := sink.delivered()
+	require.Len(t, events, 2)
+	assert.Equal(t, interfaces.ChangeTypeRename, events[0].Type)
+	assert.Equal(t, "/Sync/old.txt", events[0].OldPath)
+	assert.Equal(t, "/Sync/new.txt", events[0].Path)
+}
+
+func TestRemoteWatcherFiltersChanges(t *testing.T) {
+	feed := &fakeFeed{pages: map[string]*interfaces.RemoteChangePage{
+		"1": {
+			Changes: []interfaces.RemoteChange{
+				{FileID: "keep", Path: "/Sync/keep.txt"},
+				{FileID: "tmp", Path: "/Sync/.keep.txt.1234.tmp"},
+				{FileID: "other", Path: "/Other/x.txt"},
+				{FileID: "known", Removed: true},
+				{FileID: "out", Path: "/Other/moved.txt"},
+			},
+			NewStartPageToken: "2",
+		},
+	}}
+	sink := &sinkRecorder{}
+	watcher := newTestWatcher(t, feed, newTestDB(t), sink)
+	require.NoError(t, watcher.SetIgnorePatterns([]string{"*.tmp"}))
+	watcher.SeedPaths(map[string]string{
+		"known": "/Sync/gone.txt",
+		"out":   "/Sync/leaving.txt",
+	})
+
+	watcher.pulsePointPoll("1")
+
+	events := sink.delivered()
+	require.Len(t, events, 3)
+	assert.Equal(t, "/Sync/keep.txt", events[0].Path)
+	assert.Equal(t, interfaces.ChangeTypeCreate, events[0].Type)
+	assert.Equal(t, "/Sync/gone.txt", events[1].Path, "a removal without a path uses the known path")
+	assert.Equal(t, interfaces.ChangeTypeDelete, events[1].Type)
+	assert.Equal(t, "/Sync/leaving.txt", events[2].Path, "moving out of a watched folder deletes")
+	assert.Equal(t, interfaces.ChangeTypeDelete, events[2].Type)
+}
+
+func TestRemoteWatcherWaitsForWatchedPaths(t *testing.T) {
+	feed := &fakeFeed{startToken: "1", pages: map[string]*interfaces.RemoteChangePage{
+		"1": {
+			Changes:           []interfaces.RemoteChange{{FileID: "a", Path: "/Sync/a.txt"}},
+			NewStartPageToken: "2",
+		},
+	}}
+	db := newTestDB(t)
+	sink := &sinkRecorder{}
+	watcher := NewPulsePointRemoteWatcher(feed, db, "test", time.Hour)
+	watcher.SetEventSink(sink.sink)
+
+	// The first start records the current position without polling
+	require.NoError(t, watcher.Start(context.Background(), nil))
+	require.NoError(t, watcher.Stop())
+	assert.Empty(t, feed.listedTokens(), "nothing is read before folders are watched")
+	assert.Equal(t, "1", savedToken(t, watcher))
+
+	// A restart resumes from the saved token
+	restarted := NewPulsePointRemoteWatcher(feed, db, "test", time.Hour)
+	restarted.SetEventSink(sink.sink)
+	require.NoError(t, restarted.Start(context.Background(), []string{"/Sync"}))
+	require.Eventually(t, func() bool { return len(sink.delivered()) == 1 }, time.Second, 10*time.Millisecond)
+	require.NoError(t, restarted.Stop())
+	assert.Equal(t, []string{"1"}, feed.listedTokens())
+	assert.Equal(t, "2", savedToken(t, restarted))
+}
@@ -2,6 +2,7 @@
 package errors
 
 import (
+	"errors"
 	"fmt"
 )
 
@@ -84,7 +85,8 @@ func NewRetryable(errType ErrorType, message string, err error) *PulseError {
 
 // IsNetworkError checks if the error is a network error
 func IsNetworkError(err error) bool {
-	if pe, ok := err.(*PulseError); ok {
+	var pe *PulseError
+	if errors.As(err, &pe) {
 		return pe.Type == NetworkError
 	}
 	return false
@@ -92,7 +94,8 @@ func IsNetworkError(err error) bool {
 
 // IsAuthError checks if the error is an authentication error
 func IsAuthError(err error) bool {
-	if pe, ok := err.(*PulseError); ok {
+	var pe *PulseError
+	if errors.As(err, &pe) {
 		return pe.Type == AuthError
 	}
 	return false
@@ -100,7 +103,8 @@ func IsAuthError(err error) bool {
 
 // IsFileSystemError checks if the error is a file system error
 func IsFileSystemError(err error) bool {
-	if pe, ok := err.(*PulseError); ok {
+	var pe *PulseError
+	if errors.As(err, &pe) {
 		return pe.Type == FileSystemError
 	}
 	return false
@@ -108,7 +112,8 @@ func IsFileSystemError(err error) bool {
 
 // IsValidationError checks if the error is a validation error
 func IsValidationError(err error) bool {
-	if pe, ok := err.(*PulseError); ok {
+	var pe *PulseError
+	if errors.As(err, &pe) {
 		return pe.Type == ValidationError
 	}
 	return false
@@ -116,7 +121,8 @@ func IsValidationError(err error) bool {
 
 // IsConfigError checks if the error is a configuration error
 func IsConfigError(err error) bool {
-	if pe, ok := err.(*PulseError); ok {
+	var pe *PulseError
+	if errors.As(err, &pe) {
 		return pe.Type == ConfigError
 	}
 	return false
@@ -124,7 +130,8 @@ func IsConfigError(err error) bool {
 
 // IsSyncError checks if the error is a sync error
 func IsSyncError(err error) bool {
-	if pe, ok := err.(*PulseError); ok {
+	var pe *PulseError
+	if errors.As(err, &pe) {
 		return pe.Type == SyncError
 	}
 	return false
@@ -132,7 +139,8 @@ func IsSyncError(err error) bool {
 
 // IsProviderError checks if the error is a provider error
 func IsProviderError(err error) bool {
-	if pe, ok := err.(*PulseError); ok {
+	var pe *PulseError
+	if errors.As(err, &pe) {
 		return pe.Type == ProviderError
 	}
 	return false
@@ -180,10 +188,15 @@ func NewDatabaseError(message string, err error) *PulseError {
 	return New(FileSystemError, message, err)
 }
 
-// IsNotFoundError checks if the error indicates a resource was not found
+// IsNotFoundError checks if the error, or any error it wraps, indicates a
+// resource was not found
 func IsNotFoundError(err error) bool {
-	if pe, ok := err.(*PulseError); ok {
-		return pe.StatusCode == 404
+	var pe *PulseError
+	for errors.As(err, &pe) {
+		if pe.StatusCode == 404 {
+			return true
+		}
+		err = pe.Unwrap()
 	}
 	return false
 }
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 )
@@ -44,6 +45,18 @@ func FileSHA256(path string) (string, error) {
 	return hex.EncodeToString(hash.Sum(nil)), nil
 }
 
+// HashFileWith calculates the hash of a file using md5 or sha256
+func HashFileWith(path, algorithm string) (string, error) {
+	switch algorithm {
+	case "md5":
+		return FileHash(path)
+	case "sha256", "":
+		return FileSHA256(path)
+	default:
+		return "", fmt.Errorf("unsupported hash algorithm: %s", algorithm)
+	}
+}
+
 // FormatBytes formats bytes into human-readable format
 func FormatBytes(bytes int64) string {
 	const unit = 1024
@@ -103,6 +116,30 @@ func RelativePath(base, target string) (string, error) {
 	return filepath.Rel(base, target)
 }
 
+// RemotePath maps a local path inside localRoot onto the equivalent
+// slash-separated path inside remoteRoot. Paths outside localRoot keep
+// their full local path beneath remoteRoot.
+func RemotePath(localRoot, remoteRoot, localPath string) string {
+	rel := localPath
+	if localRoot != "" {
+		if r, err := filepath.Rel(localRoot, localPath); err == nil && r != ".." && !strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+			rel = r
+		}
+	}
+	return path.Join("/", filepath.ToSlash(remoteRoot), filepath.ToSlash(rel))
+}
+
+// LocalPath maps a slash-separated path inside remoteRoot back onto the
+// equivalent path inside localRoot. It is the inverse of RemotePath.
+func LocalPath(localRoot, remoteRoot, remotePath string) string {
+	root := path.Join("/", filepath.ToSlash(remoteRoot))
+	rel := path.Join("/", remotePath)
+	if root != "/" && (rel == root || strings.HasPrefix(rel, root+"/")) {
+		rel = strings.TrimPrefix(rel, root)
+	}
+	return filepath.Join(localRoot, filepath.FromSlash(strings.TrimPrefix(rel, "/")))
+}
+
 // CopyFile copies a file from src to dst
 func CopyFile(src, dst string) error {
 	sourceFile, err := os.Open(src)
@@ -136,3 +173,44 @@ func CopyFile(src, dst string) error {
 
 	return os.Chmod(dst, sourceInfo.Mode())
 }
+
+// mergeableExts lists extensions of text files that can be merged line by line
+var mergeableExts = []string{
+	".txt", ".md", ".json", ".xml", ".yaml", ".yml",
+	".go", ".js", ".ts", ".py", ".java", ".c", ".cpp", ".h",
+	".html", ".css", ".scss", ".less",
+	".sh", ".bash", ".zsh",
+	".conf", ".config", ".ini",
+}
+
+// Conflict markers a three-way merge writes around hunks it cannot merge
+const (
+	MergeMarkerLocal  = "<<<<<<< local"
+	MergeMarkerRemote = ">>>>>>> remote"
+)
+
+// HasMergeMarkers checks if a file still holds conflict markers left by a merge
+func HasMergeMarkers(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if line == MergeMarkerLocal || line == MergeMarkerRemote {
+			return true
+		}
+	}
+	return false
+}
+
+// IsMergeable checks if a file is a text file that can be merged line by line
+func IsMergeable(path string) bool {
+	ext := filepath.Ext(path)
+	for _, mergeableExt := range mergeableExts {
+		if ext == mergeableExt {
+			return true
+		}
+	}
+	return false
+}
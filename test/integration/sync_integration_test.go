@@ -5,12 +5,15 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/pulsepoint/pulsepoint/internal/core/interfaces"
 	"github.com/pulsepoint/pulsepoint/internal/database"
 	"github.com/pulsepoint/pulsepoint/internal/database/repositories"
+	"github.com/pulsepoint/pulsepoint/internal/providers/filesystem"
+	"github.com/pulsepoint/pulsepoint/internal/providers/mock"
 	"github.com/pulsepoint/pulsepoint/internal/strategies"
 	"github.com/pulsepoint/pulsepoint/internal/watchers/local"
 	pplogger "github.com/pulsepoint/pulsepoint/pkg/logger"
@@ -188,6 +191,151 @@ func TestStrategyIntegration(t *testing.T) {
 	})
 }
 
+// Test mirror cleanup of remote files missing locally
+func TestMirrorCleanup(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	logger := pplogger.Get()
+
+	upload := func(t *testing.T, provider *mock.MockDriveProvider, paths ...string) {
+		for _, path := range paths {
+			require.NoError(t, provider.Upload(ctx, &interfaces.File{
+				Path:    path,
+				Content: strings.NewReader(path),
+			}))
+		}
+	}
+
+	t.Run("DeletesExtraFiles", func(t *testing.T) {
+		localDir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(localDir, "sub"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(localDir, "keep.txt"), []byte("keep"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(localDir, "sub", "keep.txt"), []byte("keep"), 0644))
+
+		provider := mock.NewMockDriveProvider()
+		upload(t, provider,
+			"/mirror/keep.txt",
+			"/mirror/sub/keep.txt",
+			"/mirror/old.txt",
+			"/mirror/gone/old.txt",
+			"/mirror/node_modules/lib.js",
+		)
+
+		strategy := strategies.NewPulsePointMirrorStrategy(provider, logger, nil)
+		result, err := strategy.CleanupRemote(ctx, localDir, "/mirror")
+		require.NoError(t, err)
+		assert.True(t, result.Success)
+		assert.Equal(t, 2, result.FilesDeleted)
+
+		files, folders, _ := provider.GetMockStats()
+		assert.Equal(t, 3, files)
+		assert.Equal(t, 3, folders)
+	})
+
+	t.Run("StopsAtDeleteThreshold", func(t *testing.T) {
+		provider := mock.NewMockDriveProvider()
+		upload(t, provider, "/mirror/a.txt", "/mirror/b.txt")
+
+		strategy := strategies.NewPulsePointMirrorStrategy(provider, logger, nil)
+		_, err := strategy.CleanupRemote(ctx, t.TempDir(), "/mirror")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "50% limit")
+
+		files, _, _ := provider.GetMockStats()
+		assert.Equal(t, 2, files)
+	})
+}
+
+// Test strategies against a real directory target instead of the mock
+func TestFilesystemProviderTarget(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	logger := pplogger.Get()
+
+	writeFile := func(t *testing.T, path, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	changeFor := func(t *testing.T, path string) interfaces.ChangeEvent {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		return interfaces.ChangeEvent{
+			Type:      interfaces.ChangeTypeModify,
+			Path:      path,
+			Timestamp: info.ModTime().UnixNano(),
+			Size:      info.Size(),
+		}
+	}
+
+	t.Run("BackupKeepsVersions", func(t *testing.T) {
+		localDir, nasDir := t.TempDir(), t.TempDir()
+		provider, err := filesystem.NewPulsePointFilesystemProvider(&filesystem.Config{Root: nasDir})
+		require.NoError(t, err)
+
+		report := filepath.Join(localDir, "reports", "q1.txt")
+		writeFile(t, report, "draft")
+
+		strategy := strategies.NewPulsePointBackupStrategy(provider, logger, nil)
+		result, err := strategy.Sync(ctx, localDir, "/backup", []interfaces.ChangeEvent{changeFor(t, report)})
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.FilesUploaded)
+
+		writeFile(t, report, "final")
+		result, err = strategy.Sync(ctx, localDir, "/backup", []interfaces.ChangeEvent{changeFor(t, report)})
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.FilesUploaded)
+
+		// The first copy is untouched and the second is stored as a version beside it
+		data, err := os.ReadFile(filepath.Join(nasDir, "backup", "reports", "q1.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "draft", string(data))
+
+		entries, err := os.ReadDir(filepath.Join(nasDir, "backup", "reports"))
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.True(t, strings.HasPrefix(entries[1].Name(), "q1_v"))
+	})
+
+	t.Run("MirrorMatchesSource", func(t *testing.T) {
+		localDir, nasDir := t.TempDir(), t.TempDir()
+		provider, err := filesystem.NewPulsePointFilesystemProvider(&filesystem.Config{Root: nasDir})
+		require.NoError(t, err)
+
+		for _, name := range []string{"a.txt", "c.txt", filepath.Join("sub", "b.txt")} {
+			writeFile(t, filepath.Join(localDir, name), "current")
+			writeFile(t, filepath.Join(nasDir, "mirror", name), "stale")
+		}
+		writeFile(t, filepath.Join(nasDir, "mirror", "old.txt"), "removed locally")
+
+		strategy := strategies.NewPulsePointMirrorStrategy(provider, logger, nil)
+		changes := []interfaces.ChangeEvent{changeFor(t, filepath.Join(localDir, "a.txt"))}
+		result, err := strategy.Sync(ctx, localDir, "/mirror", changes)
+		require.NoError(t, err)
+		assert.True(t, result.Success)
+		assert.Equal(t, 1, result.FilesUploaded)
+		assert.Zero(t, result.FilesDeleted)
+
+		data, err := os.ReadFile(filepath.Join(nasDir, "mirror", "a.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "current", string(data))
+
+		// Extra remote files are only removed by a full sync's cleanup
+		_, err = os.Stat(filepath.Join(nasDir, "mirror", "old.txt"))
+		require.NoError(t, err)
+		result, err = strategy.CleanupRemote(ctx, localDir, "/mirror")
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.FilesDeleted)
+		_, err = os.Stat(filepath.Join(nasDir, "mirror", "old.txt"))
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
 // Test watcher ignore patterns
 func TestWatcherIgnorePatterns(t *testing.T) {
 	if testing.Short() {